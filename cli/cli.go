@@ -24,6 +24,7 @@ import (
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/session"
 	"github.com/akamai/cli-terraform/pkg/commands"
 	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
 	akacli "github.com/akamai/cli/pkg/app"
 	"github.com/akamai/cli/pkg/log"
 	"github.com/akamai/cli/pkg/terminal"
@@ -37,7 +38,8 @@ var (
 )
 
 // Run initializes the cli and runs it
-func Run() error {
+// Extra exporters are run by export commands created next to the built-in ones, see exporter.Exporter
+func Run(extra ...exporter.Exporter) error {
 	term := terminal.Color()
 	ctx := context.Background()
 	ctx = terminal.Context(ctx, term)
@@ -47,7 +49,7 @@ func Run() error {
 		"Administer and Manage Supported Akamai Feature resources with Terraform",
 		Version)
 
-	cmds, err := commands.CommandLocator()
+	cmds, err := commands.CommandLocator(extra...)
 	if err != nil {
 		return fmt.Errorf(color.RedString("An error occurred initializing commands: %s"), err)
 	}
@@ -55,7 +57,9 @@ func Run() error {
 		app.Commands = append(cmds, app.Commands...)
 	}
 
-	app.Before = ensureBefore(putSessionInContext, putLoggerInContext, deprecationInfoForCreateCommands, deprecationInfoForSchemaFlags)
+	app.Flags = append(app.Flags, edgegrid.Flags()...)
+	app.Flags = append(app.Flags, commands.ConfigFlag())
+	app.Before = ensureBefore(commands.SetupConfig, putSessionInContext, putLoggerInContext, deprecationInfoForCreateCommands, deprecationInfoForSchemaFlags)
 	return app.RunContext(ctx, os.Args)
 }
 
@@ -72,9 +76,13 @@ func ensureBefore(bfs ...cli.BeforeFunc) cli.BeforeFunc {
 }
 
 func sessionRequired(c *cli.Context) bool {
+	// shell completion initializes the session only if completed values are not cached
+	if commands.IsShellCompletion(os.Args) {
+		return false
+	}
 	command := c.Args().First()
 
-	for _, cmd := range []string{"help", "list", ""} {
+	for _, cmd := range []string{"help", "list", "list-templates", "link", "completion", "docs", ""} {
 		if cmd == command {
 			return false
 		}
@@ -124,7 +132,7 @@ func putLoggerInContext(c *cli.Context) error {
 }
 
 func deprecationInfoForCreateCommands(c *cli.Context) error {
-	if !c.Args().Present() {
+	if !c.Args().Present() || commands.IsShellCompletion(os.Args) {
 		return nil
 	}
 	command := c.Args().First()
@@ -139,7 +147,7 @@ func deprecationInfoForCreateCommands(c *cli.Context) error {
 }
 
 func deprecationInfoForSchemaFlags(c *cli.Context) error {
-	if !c.Args().Present() {
+	if !c.Args().Present() || commands.IsShellCompletion(os.Args) {
 		return nil
 	}
 	command := c.Args().First()
@@ -72,6 +72,24 @@ func Test_sessionRequired(t *testing.T) {
 			},
 			expected: false,
 		},
+		"list-templates": {
+			c: func() *cli.Context {
+				return newContextFromStringSlice([]string{"list-templates", "export-property"}, newTemplateApp())
+			},
+			expected: false,
+		},
+		"link": {
+			c: func() *cli.Context {
+				return newContextFromStringSlice([]string{"link", "--tfworkpath", "dir"}, newTemplateApp())
+			},
+			expected: false,
+		},
+		"completion": {
+			c: func() *cli.Context {
+				return newContextFromStringSlice([]string{"completion", "bash"}, newTemplateApp())
+			},
+			expected: false,
+		},
 		"unknown command": {
 			c: func() *cli.Context {
 				return newContextFromStringSlice([]string{"unknown"}, newTemplateApp())
@@ -0,0 +1,394 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/appsec"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/clientlists"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudlets"
+	v3 "github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudlets/v3"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cps"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/dns"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/edgeworkers"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/gtm"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/imaging"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/session"
+	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/progress"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// accountClients holds API clients used to discover account objects
+	accountClients struct {
+		papi        papi.PAPI
+		dns         dns.DNS
+		gtm         gtm.GTM
+		cloudlets   cloudlets.Cloudlets
+		cloudletsV3 v3.Cloudlets
+		clientlists clientlists.ClientLists
+		cps         cps.CPS
+		edgeworkers edgeworkers.Edgeworkers
+		appsec      appsec.APPSEC
+		imaging     imaging.Imaging
+	}
+
+	// accountProduct describes how objects of a single product are discovered
+	accountProduct struct {
+		name     string
+		discover func(context.Context, *accountClients) ([]batchEntry, error)
+	}
+)
+
+var (
+	// ErrDiscovery is returned when objects of a product cannot be listed
+	ErrDiscovery = errors.New("discovery")
+	// ErrUnknownProduct is returned when an unsupported product is requested
+	ErrUnknownProduct = errors.New("unknown product")
+
+	// accountProducts lists products exported by export-account command, in the order of discovery
+	accountProducts = []accountProduct{
+		{name: "property", discover: discoverProperties},
+		{name: "dns", discover: discoverZones},
+		{name: "gtm", discover: discoverDomains},
+		{name: "cloudlets", discover: discoverCloudletsPolicies},
+		{name: "clientlists", discover: discoverClientLists},
+		{name: "cps", discover: discoverEnrollments},
+		{name: "edgeworkers", discover: discoverEdgeWorkers},
+		{name: "appsec", discover: discoverSecurityConfigurations},
+		{name: "iam", discover: discoverIAM},
+	}
+
+	dirNameRegexp = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// accountManifestName is the name of the manifest file written by export-account command
+const accountManifestName = "export-account.yaml"
+
+func cmdExportAccount(c *cli.Context) error {
+	clients := newAccountClients(edgegrid.GetSession(c.Context))
+
+	tfWorkPath := "./"
+	if c.IsSet("tfworkpath") {
+		tfWorkPath = c.String("tfworkpath")
+	}
+	tfWorkPath = filepath.FromSlash(tfWorkPath)
+	concurrency := c.Int("concurrency")
+	if concurrency < 1 {
+		return cli.Exit(color.RedString("concurrency has to be a positive number"), 1)
+	}
+	products, err := selectAccountProducts(c.StringSlice("products"))
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+
+	manifest, err := discoverAccount(c.Context, clients, products)
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+	for _, name := range []string{"import-style", "on-conflict"} {
+		if c.IsSet(name) {
+			manifest.Flags[name] = c.String(name)
+		}
+	}
+
+	manifestPath := filepath.Join(tfWorkPath, accountManifestName)
+	if err := writeBatchManifest(manifestPath, manifest); err != nil {
+		return cli.Exit(color.RedString("Error saving manifest: %s", err), 1)
+	}
+	term := terminal.Get(c.Context)
+	term.Printf("Manifest with %d entries saved to '%s'\n", len(manifest.Entries), manifestPath)
+	if c.Bool("discover-only") {
+		return nil
+	}
+
+	return exportBatch(c, manifest, tfWorkPath, concurrency)
+}
+
+// newAccountClients returns clients of all discovered APIs using the session
+func newAccountClients(sess session.Session) *accountClients {
+	return &accountClients{
+		papi:        papi.Client(sess),
+		dns:         dns.Client(sess),
+		gtm:         gtm.Client(sess),
+		cloudlets:   cloudlets.Client(sess),
+		cloudletsV3: v3.Client(sess),
+		clientlists: clientlists.Client(sess),
+		cps:         cps.Client(sess),
+		edgeworkers: edgeworkers.Client(sess),
+		appsec:      appsec.Client(sess),
+		imaging:     imaging.Client(sess),
+	}
+}
+
+// selectAccountProducts returns products with given, possibly comma separated, names or all products if no name is given
+func selectAccountProducts(names []string) ([]accountProduct, error) {
+	if len(names) == 0 {
+		return accountProducts, nil
+	}
+	var products []accountProduct
+	for _, name := range strings.Split(strings.Join(names, ","), ",") {
+		var found bool
+		for _, product := range accountProducts {
+			if product.name == strings.TrimSpace(name) {
+				products = append(products, product)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: '%s'", ErrUnknownProduct, name)
+		}
+	}
+	return products, nil
+}
+
+// discoverAccount lists objects of given products and builds a batch manifest exporting them
+// Products which cannot be listed, e.g. because of missing API permissions, are skipped with a warning
+func discoverAccount(ctx context.Context, clients *accountClients, products []accountProduct) (*batchManifest, error) {
+	reporter := progress.Get(ctx)
+	manifest := &batchManifest{Flags: map[string]interface{}{}}
+	for _, product := range products {
+		reporter.Start("Discovering %s ", product.name)
+		entries, err := product.discover(ctx, clients)
+		if err != nil {
+			reporter.Warn()
+			reporter.Printf("Warning: skipping %s: %s\n", product.name, err)
+			continue
+		}
+		reporter.OK()
+		manifest.Entries = append(manifest.Entries, entries...)
+	}
+	if len(manifest.Entries) == 0 {
+		return nil, fmt.Errorf("%w: no objects found", ErrDiscovery)
+	}
+	return manifest, nil
+}
+
+func writeBatchManifest(path string, manifest *batchManifest) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// accountDir returns manifest directory of the object, relative to tfworkpath
+func accountDir(product, name string) string {
+	return path.Join(product, strings.Trim(dirNameRegexp.ReplaceAllString(name, "_"), "_."))
+}
+
+func discoverProperties(ctx context.Context, clients *accountClients) ([]batchEntry, error) {
+	groups, err := clients.papi.GetGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var entries []batchEntry
+	seen := make(map[string]bool)
+	for _, group := range groups.Groups.Items {
+		for _, contractID := range group.ContractIDs {
+			properties, err := clients.papi.GetProperties(ctx, papi.GetPropertiesRequest{
+				ContractID: contractID,
+				GroupID:    group.GroupID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+			}
+			for _, property := range properties.Properties.Items {
+				if seen[property.PropertyID] {
+					continue
+				}
+				seen[property.PropertyID] = true
+				entries = append(entries, batchEntry{
+					Exporter: "export-property",
+					Args:     []string{property.PropertyName},
+					Dir:      accountDir("property", property.PropertyName),
+				})
+			}
+		}
+	}
+	return entries, nil
+}
+
+func discoverZones(ctx context.Context, clients *accountClients) ([]batchEntry, error) {
+	zones, err := clients.dns.ListZones(ctx, dns.ZoneListQueryArgs{ShowAll: true})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var entries []batchEntry
+	for _, zone := range zones.Zones {
+		entries = append(entries, batchEntry{
+			Exporter: "export-zone",
+			Args:     []string{zone.Zone},
+			Flags:    map[string]interface{}{"resources": true, "createconfig": true, "importscript": true},
+			Dir:      accountDir("dns", zone.Zone),
+		})
+	}
+	return entries, nil
+}
+
+func discoverDomains(ctx context.Context, clients *accountClients) ([]batchEntry, error) {
+	domains, err := clients.gtm.ListDomains(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var entries []batchEntry
+	for _, domain := range domains {
+		entries = append(entries, batchEntry{
+			Exporter: "export-domain",
+			Args:     []string{domain.Name},
+			Dir:      accountDir("gtm", domain.Name),
+		})
+	}
+	return entries, nil
+}
+
+func discoverCloudletsPolicies(ctx context.Context, clients *accountClients) ([]batchEntry, error) {
+	var names []string
+	pageSize, offset := 1000, 0
+	for {
+		policies, err := clients.cloudlets.ListPolicies(ctx, cloudlets.ListPoliciesRequest{
+			Offset:   offset,
+			PageSize: &pageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+		}
+		for _, policy := range policies {
+			names = append(names, policy.Name)
+		}
+		if len(policies) < pageSize {
+			break
+		}
+		offset += pageSize
+	}
+
+	page, size := 0, 1000
+	for {
+		policies, err := clients.cloudletsV3.ListPolicies(ctx, v3.ListPoliciesRequest{Page: page, Size: size})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+		}
+		for _, policy := range policies.Content {
+			names = append(names, policy.Name)
+		}
+		if len(policies.Content) < size {
+			break
+		}
+		page++
+	}
+
+	var entries []batchEntry
+	for _, name := range names {
+		entries = append(entries, batchEntry{
+			Exporter: "export-cloudlets-policy",
+			Args:     []string{name},
+			Dir:      accountDir("cloudlets", name),
+		})
+	}
+	return entries, nil
+}
+
+func discoverClientLists(ctx context.Context, clients *accountClients) ([]batchEntry, error) {
+	lists, err := clients.clientlists.GetClientLists(ctx, clientlists.GetClientListsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var entries []batchEntry
+	for _, list := range lists.Content {
+		if list.ReadOnly {
+			continue
+		}
+		entries = append(entries, batchEntry{
+			Exporter: "export-clientlist",
+			Args:     []string{list.ListID},
+			Dir:      accountDir("clientlists", list.ListID),
+		})
+	}
+	return entries, nil
+}
+
+func discoverEnrollments(ctx context.Context, clients *accountClients) ([]batchEntry, error) {
+	contracts, err := clients.papi.GetContracts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var entries []batchEntry
+	for _, contract := range contracts.Contracts.Items {
+		contractID := strings.TrimPrefix(contract.ContractID, "ctr_")
+		enrollments, err := clients.cps.ListEnrollments(ctx, cps.ListEnrollmentsRequest{ContractID: contractID})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+		}
+		for _, enrollment := range enrollments.Enrollments {
+			if enrollment.ValidationType != "dv" && enrollment.ValidationType != "third-party" {
+				continue
+			}
+			id := strconv.Itoa(enrollment.ID)
+			entries = append(entries, batchEntry{
+				Exporter: "export-cps",
+				Args:     []string{id, contractID},
+				Dir:      accountDir("cps", id),
+			})
+		}
+	}
+	return entries, nil
+}
+
+func discoverEdgeWorkers(ctx context.Context, clients *accountClients) ([]batchEntry, error) {
+	edgeWorkers, err := clients.edgeworkers.ListEdgeWorkersID(ctx, edgeworkers.ListEdgeWorkersIDRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var entries []batchEntry
+	for _, edgeWorker := range edgeWorkers.EdgeWorkers {
+		id := strconv.Itoa(edgeWorker.EdgeWorkerID)
+		entries = append(entries, batchEntry{
+			Exporter: "export-edgeworker",
+			Args:     []string{id},
+			Dir:      accountDir("edgeworkers", id),
+		})
+	}
+	return entries, nil
+}
+
+func discoverSecurityConfigurations(ctx context.Context, clients *accountClients) ([]batchEntry, error) {
+	configurations, err := clients.appsec.GetConfigurations(ctx, appsec.GetConfigurationsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var entries []batchEntry
+	for _, configuration := range configurations.Configurations {
+		entries = append(entries, batchEntry{
+			Exporter: "export-appsec",
+			Args:     []string{configuration.Name},
+			Dir:      accountDir("appsec", configuration.Name),
+		})
+	}
+	return entries, nil
+}
+
+func discoverIAM(_ context.Context, _ *accountClients) ([]batchEntry, error) {
+	return []batchEntry{{Exporter: "export-iam", Args: []string{"all"}, Dir: "iam"}}, nil
+}
@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/appsec"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/clientlists"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudlets"
+	v3 "github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudlets/v3"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cps"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/dns"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/edgeworkers"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/gtm"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
+	"github.com/akamai/cli-terraform/pkg/progress"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectAccountProducts(t *testing.T) {
+	tests := map[string]struct {
+		given     []string
+		expected  []string
+		withError error
+	}{
+		"all products by default": {
+			expected: []string{"property", "dns", "gtm", "cloudlets", "clientlists", "cps", "edgeworkers", "appsec", "iam"},
+		},
+		"selected products": {
+			given:    []string{"dns", "property, gtm"},
+			expected: []string{"dns", "property", "gtm"},
+		},
+		"unknown product": {
+			given:     []string{"dns", "foo"},
+			withError: ErrUnknownProduct,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			products, err := selectAccountProducts(test.given)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, product := range products {
+				names = append(names, product.name)
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestAccountDir(t *testing.T) {
+	tests := map[string]struct {
+		product  string
+		name     string
+		expected string
+	}{
+		"plain name": {
+			product:  "dns",
+			name:     "example.com",
+			expected: "dns/example.com",
+		},
+		"name with separators": {
+			product:  "property",
+			name:     "my property/../v2",
+			expected: "property/my_property_.._v2",
+		},
+		"name starting with a dot": {
+			product:  "appsec",
+			name:     "..config",
+			expected: "appsec/config",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, accountDir(test.product, test.name))
+		})
+	}
+}
+
+func TestDiscoverAccount(t *testing.T) {
+	mockClients := func() (*accountClients, []*mock.Mock) {
+		papiClient := new(papi.Mock)
+		papiClient.On("GetGroups", mock.Anything).Return(&papi.GetGroupsResponse{
+			Groups: papi.GroupItems{Items: []*papi.Group{
+				{GroupID: "grp_1", ContractIDs: []string{"ctr_C-1"}},
+				{GroupID: "grp_2", ContractIDs: []string{"ctr_C-1", "ctr_C-2"}},
+			}},
+		}, nil)
+		papiClient.On("GetProperties", mock.Anything, papi.GetPropertiesRequest{ContractID: "ctr_C-1", GroupID: "grp_1"}).Return(&papi.GetPropertiesResponse{
+			Properties: papi.PropertiesItems{Items: []*papi.Property{{PropertyID: "prp_1", PropertyName: "first"}}},
+		}, nil)
+		papiClient.On("GetProperties", mock.Anything, papi.GetPropertiesRequest{ContractID: "ctr_C-1", GroupID: "grp_2"}).Return(&papi.GetPropertiesResponse{
+			Properties: papi.PropertiesItems{Items: []*papi.Property{{PropertyID: "prp_2", PropertyName: "second"}}},
+		}, nil)
+		papiClient.On("GetProperties", mock.Anything, papi.GetPropertiesRequest{ContractID: "ctr_C-2", GroupID: "grp_2"}).Return(&papi.GetPropertiesResponse{
+			Properties: papi.PropertiesItems{Items: []*papi.Property{{PropertyID: "prp_2", PropertyName: "second"}}},
+		}, nil)
+		papiClient.On("GetContracts", mock.Anything).Return(&papi.GetContractsResponse{
+			Contracts: papi.ContractsItems{Items: []*papi.Contract{{ContractID: "ctr_C-1"}}},
+		}, nil)
+
+		dnsClient := new(dns.Mock)
+		dnsClient.On("ListZones", mock.Anything, dns.ZoneListQueryArgs{ShowAll: true}).Return(&dns.ZoneListResponse{
+			Zones: []*dns.ZoneResponse{{Zone: "example.com"}},
+		}, nil)
+
+		gtmClient := new(gtm.Mock)
+		gtmClient.On("ListDomains", mock.Anything).Return([]*gtm.DomainItem{{Name: "test.akadns.net"}}, nil)
+
+		cloudletsClient := new(cloudlets.Mock)
+		cloudletsClient.On("ListPolicies", mock.Anything, mock.Anything).Return([]cloudlets.Policy{{Name: "policy_v2"}}, nil)
+		cloudletsV3Client := new(v3.Mock)
+		cloudletsV3Client.On("ListPolicies", mock.Anything, v3.ListPoliciesRequest{Page: 0, Size: 1000}).Return(&v3.ListPoliciesResponse{
+			Content: []v3.Policy{{Name: "policy_v3"}},
+		}, nil)
+
+		clientlistsClient := new(clientlists.Mock)
+		clientlistsClient.On("GetClientLists", mock.Anything, clientlists.GetClientListsRequest{}).Return(&clientlists.GetClientListsResponse{
+			Content: []clientlists.ClientList{
+				{ListContent: clientlists.ListContent{ListID: "12_LIST"}},
+				{ListContent: clientlists.ListContent{ListID: "13_READONLY", ReadOnly: true}},
+			},
+		}, nil)
+
+		cpsClient := new(cps.Mock)
+		cpsClient.On("ListEnrollments", mock.Anything, cps.ListEnrollmentsRequest{ContractID: "C-1"}).Return(&cps.ListEnrollmentsResponse{
+			Enrollments: []cps.Enrollment{{ID: 1, ValidationType: "dv"}, {ID: 2, ValidationType: "ov"}},
+		}, nil)
+
+		edgeworkersClient := new(edgeworkers.Mock)
+		edgeworkersClient.On("ListEdgeWorkersID", mock.Anything, edgeworkers.ListEdgeWorkersIDRequest{}).
+			Return(nil, errors.New("forbidden"))
+
+		appsecClient := new(appsec.Mock)
+		configurations := appsec.GetConfigurationsResponse{}
+		configurations.Configurations = append(configurations.Configurations, struct {
+			Description         string   `json:"description,omitempty"`
+			FileType            string   `json:"fileType,omitempty"`
+			ID                  int      `json:"id,omitempty"`
+			LatestVersion       int      `json:"latestVersion,omitempty"`
+			Name                string   `json:"name,omitempty"`
+			StagingVersion      int      `json:"stagingVersion,omitempty"`
+			TargetProduct       string   `json:"targetProduct,omitempty"`
+			ProductionHostnames []string `json:"productionHostnames,omitempty"`
+			ProductionVersion   int      `json:"productionVersion,omitempty"`
+		}{ID: 1, Name: "security config"})
+		appsecClient.On("GetConfigurations", mock.Anything, appsec.GetConfigurationsRequest{}).Return(&configurations, nil)
+
+		clients := &accountClients{
+			papi:        papiClient,
+			dns:         dnsClient,
+			gtm:         gtmClient,
+			cloudlets:   cloudletsClient,
+			cloudletsV3: cloudletsV3Client,
+			clientlists: clientlistsClient,
+			cps:         cpsClient,
+			edgeworkers: edgeworkersClient,
+			appsec:      appsecClient,
+		}
+		return clients, []*mock.Mock{&papiClient.Mock, &dnsClient.Mock, &gtmClient.Mock, &cloudletsClient.Mock,
+			&cloudletsV3Client.Mock, &clientlistsClient.Mock, &cpsClient.Mock, &edgeworkersClient.Mock, &appsecClient.Mock}
+	}
+
+	ctx := terminal.Context(context.Background(), terminal.New(terminal.DiscardWriter(), nil, terminal.DiscardWriter()))
+
+	t.Run("all products", func(t *testing.T) {
+		clients, mocks := mockClients()
+		manifest, err := discoverAccount(ctx, clients, accountProducts)
+		require.NoError(t, err)
+		assert.Equal(t, &batchManifest{
+			Flags: map[string]interface{}{},
+			Entries: []batchEntry{
+				{Exporter: "export-property", Args: []string{"first"}, Dir: "property/first"},
+				{Exporter: "export-property", Args: []string{"second"}, Dir: "property/second"},
+				{Exporter: "export-zone", Args: []string{"example.com"}, Flags: map[string]interface{}{"resources": true, "createconfig": true, "importscript": true}, Dir: "dns/example.com"},
+				{Exporter: "export-domain", Args: []string{"test.akadns.net"}, Dir: "gtm/test.akadns.net"},
+				{Exporter: "export-cloudlets-policy", Args: []string{"policy_v2"}, Dir: "cloudlets/policy_v2"},
+				{Exporter: "export-cloudlets-policy", Args: []string{"policy_v3"}, Dir: "cloudlets/policy_v3"},
+				{Exporter: "export-clientlist", Args: []string{"12_LIST"}, Dir: "clientlists/12_LIST"},
+				{Exporter: "export-cps", Args: []string{"1", "C-1"}, Dir: "cps/1"},
+				{Exporter: "export-appsec", Args: []string{"security config"}, Dir: "appsec/security_config"},
+				{Exporter: "export-iam", Args: []string{"all"}, Dir: "iam"},
+			},
+		}, manifest)
+		for _, m := range mocks {
+			m.AssertExpectations(t)
+		}
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		clients, _ := mockClients()
+		products, err := selectAccountProducts([]string{"edgeworkers"})
+		require.NoError(t, err)
+		_, err = discoverAccount(ctx, clients, products)
+		assert.True(t, errors.Is(err, ErrDiscovery), "expected: %s; got: %s", ErrDiscovery, err)
+	})
+
+	t.Run("warning reported by the reporter of the context", func(t *testing.T) {
+		clients, _ := mockClients()
+		products, err := selectAccountProducts([]string{"edgeworkers"})
+		require.NoError(t, err)
+		var out bytes.Buffer
+		reporter := progress.New(progress.ModePlain, terminal.Get(ctx), &out, progress.Options{})
+		_, _ = discoverAccount(progress.WithReporter(ctx, reporter), clients, products)
+		assert.Contains(t, out.String(), "Discovering edgeworkers\nDiscovering edgeworkers [WARN]")
+	})
+}
+
+func TestWriteBatchManifest(t *testing.T) {
+	manifest := &batchManifest{
+		Flags: map[string]interface{}{"import-style": "blocks"},
+		Entries: []batchEntry{
+			{Exporter: "export-zone", Args: []string{"example.com"}, Flags: map[string]interface{}{"resources": true}, Dir: "dns/example.com"},
+			{Exporter: "export-iam", Args: []string{"all"}, Dir: "iam"},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "nested", accountManifestName)
+
+	require.NoError(t, writeBatchManifest(path, manifest))
+	res, err := readBatchManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, res)
+}
@@ -0,0 +1,330 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/progress"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// batchManifest describes exports run by export-batch command
+	batchManifest struct {
+		// Flags are applied to every entry unless the entry overrides them
+		Flags   map[string]interface{} `yaml:"flags,omitempty"`
+		Entries []batchEntry           `yaml:"entries"`
+	}
+
+	// batchEntry is a single export run by export-batch command
+	batchEntry struct {
+		Exporter string                 `yaml:"exporter"`
+		Args     []string               `yaml:"args,omitempty"`
+		Flags    map[string]interface{} `yaml:"flags,omitempty"`
+		Dir      string                 `yaml:"dir"`
+	}
+
+	batchResult struct {
+		entry batchEntry
+		err   error
+	}
+
+	// versionContextKey holds version of the application passed to exporters run by another command
+	versionContextKey struct{}
+)
+
+var (
+	// ErrInvalidManifest is returned when batch manifest cannot be read or is not valid
+	ErrInvalidManifest = errors.New("invalid batch manifest")
+	// ErrBatchEntry is returned when a batch entry cannot be run
+	ErrBatchEntry = errors.New("batch entry")
+	// ErrExporter is returned when export command run by another command panics or exits without an error message
+	ErrExporter = errors.New("exporter")
+
+	// batchReservedFlags are managed by export-batch and cannot be set in the manifest
+	batchReservedFlags = []string{"tfworkpath", "output-mode", "output-file"}
+)
+
+func cmdExportBatch(c *cli.Context) error {
+	manifest, err := readBatchManifest(c.Args().First())
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+	tfWorkPath := "./"
+	if c.IsSet("tfworkpath") {
+		tfWorkPath = c.String("tfworkpath")
+	}
+	concurrency := c.Int("concurrency")
+	if concurrency < 1 {
+		return cli.Exit(color.RedString("concurrency has to be a positive number"), 1)
+	}
+	return exportBatch(c, manifest, filepath.FromSlash(tfWorkPath), concurrency)
+}
+
+// exportBatch runs all manifest entries and prints a summary of their results
+func exportBatch(c *cli.Context, manifest *batchManifest, tfWorkPath string, concurrency int) error {
+	term := terminal.Get(c.Context)
+	term.Spinner().Start(fmt.Sprintf("Running %d exports ", len(manifest.Entries)))
+	ctx := context.WithValue(c.Context, versionContextKey{}, c.App.Version)
+	registry := getRegistry(c.Context)
+	commands := func() []*cli.Command { return exportCommands(registry) }
+	results := runBatch(ctx, commands, manifest, tfWorkPath, globalArgs(c), concurrency)
+
+	var failed int
+	for _, res := range results {
+		if res.err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		term.Spinner().Fail()
+	} else {
+		term.Spinner().OK()
+	}
+
+	term.Writeln("Batch export summary:")
+	for _, res := range results {
+		entry := strings.TrimSpace(fmt.Sprintf("%s %s", res.entry.Exporter, strings.Join(res.entry.Args, " ")))
+		if res.err != nil {
+			term.Printf("  %s %s -> %s: %s\n", color.RedString("FAILED"), entry, res.entry.Dir, res.err)
+			continue
+		}
+		term.Printf("  %s     %s -> %s\n", color.GreenString("OK"), entry, res.entry.Dir)
+	}
+	term.Printf("%d entries, %d succeeded, %d failed\n", len(results), len(results)-failed, failed)
+
+	if c.Bool("link") {
+		links, skipped, err := linkWorkPath(tfWorkPath, false)
+		if err != nil {
+			return cli.Exit(color.RedString("Error linking files: %s", err), 1)
+		}
+		printLinks(term, links, skipped)
+	}
+
+	if failed > 0 {
+		return cli.Exit(color.RedString("Batch export failed for %d of %d entries", failed, len(results)), 1)
+	}
+	return nil
+}
+
+// globalArgs returns global flags passed to exporters run by the command
+func globalArgs(c *cli.Context) []string {
+	args := []string{"--edgerc", edgegrid.GetEdgercPath(c), "--section", edgegrid.GetEdgercSection(c)}
+	if c.IsSet("accountkey") {
+		args = append(args, "--accountkey", c.String("accountkey"))
+	}
+	return args
+}
+
+func readBatchManifest(path string) (*batchManifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidManifest, err)
+	}
+	var manifest batchManifest
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidManifest, err)
+	}
+	if len(manifest.Entries) == 0 {
+		return nil, fmt.Errorf("%w: no entries", ErrInvalidManifest)
+	}
+	for i, entry := range manifest.Entries {
+		if entry.Exporter == "" {
+			return nil, fmt.Errorf("%w: entry %d: missing exporter", ErrInvalidManifest, i+1)
+		}
+		if entry.Dir == "" {
+			return nil, fmt.Errorf("%w: entry %d: missing dir", ErrInvalidManifest, i+1)
+		}
+		for _, flags := range []map[string]interface{}{manifest.Flags, entry.Flags} {
+			for _, name := range batchReservedFlags {
+				if _, ok := flags[name]; ok {
+					return nil, fmt.Errorf("%w: entry %d: flag '%s' cannot be used in batch export", ErrInvalidManifest, i+1, name)
+				}
+			}
+		}
+	}
+	return &manifest, nil
+}
+
+// runBatch runs manifest entries using at most concurrency goroutines and returns results in the order of entries
+// Every entry runs its own export command returned by commands, as commands keep values of their flags, while
+// exporters keep the state of each export to itself, see exporter.Export, so any entries may run in parallel
+func runBatch(ctx context.Context, commands func() []*cli.Command, manifest *batchManifest, tfWorkPath string, globalArgs []string, concurrency int) []batchResult {
+	results := make([]batchResult, len(manifest.Entries))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, entry := range manifest.Entries {
+		wg.Add(1)
+		go func(i int, entry batchEntry) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = batchResult{entry: entry}
+			command := findCommand(commands(), entry.Exporter)
+			if command == nil {
+				results[i].err = fmt.Errorf("%w: unknown exporter '%s'", ErrBatchEntry, entry.Exporter)
+				return
+			}
+			results[i].err = runBatchEntry(ctx, command, manifest.Flags, entry, tfWorkPath, globalArgs)
+		}(i, entry)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func runBatchEntry(ctx context.Context, command *cli.Command, defaultFlags map[string]interface{}, entry batchEntry, tfWorkPath string, globalArgs []string) error {
+	dir := filepath.Join(tfWorkPath, filepath.FromSlash(entry.Dir))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("%w: %s", ErrBatchEntry, err)
+	}
+	flags := make(map[string]interface{}, len(defaultFlags)+len(entry.Flags)+1)
+	for name, value := range defaultFlags {
+		flags[name] = value
+	}
+	for name, value := range entry.Flags {
+		flags[name] = value
+	}
+	flags["tfworkpath"] = dir
+
+	args, err := batchEntryArgs(command, entry.Args, flags)
+	if err != nil {
+		return err
+	}
+	return runExporter(ctx, command, args, globalArgs)
+}
+
+// runExporter runs export command in a separate application with output discarded
+// Errors of the command are returned instead of terminating the process
+func runExporter(ctx context.Context, command *cli.Command, args, globalArgs []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrExporter, r)
+		}
+	}()
+
+	app := cli.NewApp()
+	app.Name = "batch"
+	if version, ok := ctx.Value(versionContextKey{}).(string); ok {
+		app.Version = version
+	}
+	app.Writer = terminal.DiscardWriter()
+	app.ErrWriter = terminal.DiscardWriter()
+	app.Flags = []cli.Flag{
+		&cli.StringFlag{Name: "edgerc"},
+		&cli.StringFlag{Name: "section"},
+		&cli.StringFlag{Name: "accountkey"},
+	}
+	app.Commands = []*cli.Command{command}
+	app.ExitErrHandler = func(*cli.Context, error) {}
+
+	// steps of the exporter are not reported by the reporter of the running command, e.g. export-account
+	ctx = terminal.Context(ctx, terminal.New(terminal.DiscardWriter(), nil, terminal.DiscardWriter()))
+	ctx = progress.WithReporter(ctx, progress.Discard())
+	if err := app.RunContext(ctx, append(append([]string{app.Name}, globalArgs...), args...)); err != nil {
+		msg := strings.TrimSpace(err.Error())
+		var exitErr cli.ExitCoder
+		if msg == "" && errors.As(err, &exitErr) {
+			// e.g. invalid arguments, for which help of the command is printed
+			return fmt.Errorf("%w: exited with code %d", ErrExporter, exitErr.ExitCode())
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// batchEntryArgs builds command line for the entry
+// Each flag is placed right after the deepest (sub)command which defines it
+func batchEntryArgs(command *cli.Command, args []string, flags map[string]interface{}) ([]string, error) {
+	path := []*cli.Command{command}
+	for len(args) > 0 {
+		sub := findCommand(path[len(path)-1].Subcommands, args[0])
+		if sub == nil {
+			break
+		}
+		path = append(path, sub)
+		args = args[1:]
+	}
+
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	flagArgs := make([][]string, len(path))
+	for _, name := range names {
+		level := -1
+		for i, cmd := range path {
+			if hasFlag(cmd, name) {
+				level = i
+			}
+		}
+		if level < 0 {
+			return nil, fmt.Errorf("%w: unknown flag '%s' for exporter '%s'", ErrBatchEntry, name, command.Name)
+		}
+		values, err := flagValues(name, flags[name])
+		if err != nil {
+			return nil, err
+		}
+		flagArgs[level] = append(flagArgs[level], values...)
+	}
+
+	var res []string
+	for i, cmd := range path {
+		res = append(res, cmd.Name)
+		res = append(res, flagArgs[i]...)
+	}
+	return append(res, args...), nil
+}
+
+func flagValues(name string, value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case bool:
+		return []string{fmt.Sprintf("--%s=%t", name, v)}, nil
+	case string, int, float64:
+		return []string{fmt.Sprintf("--%s=%v", name, v)}, nil
+	case []interface{}:
+		var res []string
+		for _, item := range v {
+			values, err := flagValues(name, item)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, values...)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported value of flag '%s': %v", ErrBatchEntry, name, value)
+}
+
+func findCommand(commands []*cli.Command, name string) *cli.Command {
+	for _, command := range commands {
+		if command.HasName(name) {
+			return command
+		}
+	}
+	return nil
+}
+
+func hasFlag(command *cli.Command, name string) bool {
+	for _, flag := range command.Flags {
+		for _, flagName := range flag.Names() {
+			if flagName == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestReadBatchManifest(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  *batchManifest
+		withError string
+	}{
+		"valid manifest": {
+			given: `
+flags:
+  import-style: blocks
+entries:
+  - exporter: export-property
+    args: [my-property]
+    flags:
+      rules-as-hcl: true
+    dir: properties/my-property
+  - exporter: export-iam
+    args: [user, test@example.com]
+    dir: iam
+`,
+			expected: &batchManifest{
+				Flags: map[string]interface{}{"import-style": "blocks"},
+				Entries: []batchEntry{
+					{Exporter: "export-property", Args: []string{"my-property"}, Flags: map[string]interface{}{"rules-as-hcl": true}, Dir: "properties/my-property"},
+					{Exporter: "export-iam", Args: []string{"user", "test@example.com"}, Dir: "iam"},
+				},
+			},
+		},
+		"no entries": {
+			given:     "flags: {}\n",
+			withError: "no entries",
+		},
+		"missing exporter": {
+			given:     "entries:\n  - dir: a\n",
+			withError: "entry 1: missing exporter",
+		},
+		"missing dir": {
+			given:     "entries:\n  - exporter: export-zone\n",
+			withError: "entry 1: missing dir",
+		},
+		"reserved flag": {
+			given:     "entries:\n  - exporter: export-zone\n    dir: a\n    flags:\n      tfworkpath: b\n",
+			withError: "flag 'tfworkpath' cannot be used in batch export",
+		},
+		"invalid yaml": {
+			given:     "entries: [",
+			withError: "invalid batch manifest",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "manifest.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(test.given), 0644))
+
+			manifest, err := readBatchManifest(path)
+			if test.withError != "" {
+				assert.True(t, errors.Is(err, ErrInvalidManifest), "expected: %s; got: %s", ErrInvalidManifest, err)
+				assert.ErrorContains(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, manifest)
+		})
+	}
+}
+
+func TestBatchEntryArgs(t *testing.T) {
+	command := &cli.Command{
+		Name: "export-test",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "tfworkpath"},
+			&cli.BoolFlag{Name: "schema", Aliases: []string{"rules-as-hcl"}},
+			&cli.StringSliceFlag{Name: "recordname"},
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "sub",
+				Flags: []cli.Flag{&cli.StringFlag{Name: "version"}},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		args      []string
+		flags     map[string]interface{}
+		expected  []string
+		withError string
+	}{
+		"flags and arguments": {
+			args:     []string{"name"},
+			flags:    map[string]interface{}{"tfworkpath": "dir", "rules-as-hcl": true, "recordname": []interface{}{"a", "b"}},
+			expected: []string{"export-test", "--recordname=a", "--recordname=b", "--rules-as-hcl=true", "--tfworkpath=dir", "name"},
+		},
+		"subcommand flags": {
+			args:     []string{"sub", "name"},
+			flags:    map[string]interface{}{"tfworkpath": "dir", "version": 3},
+			expected: []string{"export-test", "--tfworkpath=dir", "sub", "--version=3", "name"},
+		},
+		"unknown flag": {
+			flags:     map[string]interface{}{"foo": "bar"},
+			withError: "unknown flag 'foo' for exporter 'export-test'",
+		},
+		"unsupported value": {
+			flags:     map[string]interface{}{"tfworkpath": map[string]interface{}{}},
+			withError: "unsupported value of flag 'tfworkpath'",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			args, err := batchEntryArgs(command, test.args, test.flags)
+			if test.withError != "" {
+				assert.ErrorContains(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, args)
+		})
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string][]string{}
+	record := func(c *cli.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[c.Args().First()] = []string{c.String("tfworkpath"), c.String("import-style"), c.String("section")}
+		return nil
+	}
+	commands := func() []*cli.Command {
+		return []*cli.Command{
+			{
+				Name:    "export-ok",
+				Aliases: []string{"create-ok"},
+				Action:  validatedAction(record, requireNArguments(1)),
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "tfworkpath"},
+					&cli.StringFlag{Name: "import-style"},
+				},
+			},
+			{
+				Name: "export-fail",
+				Action: func(c *cli.Context) error {
+					return cli.Exit("export failed", 1)
+				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "tfworkpath"},
+					&cli.StringFlag{Name: "import-style"},
+				},
+			},
+		}
+	}
+	manifest := &batchManifest{
+		Flags: map[string]interface{}{"import-style": "blocks"},
+		Entries: []batchEntry{
+			{Exporter: "export-ok", Args: []string{"first"}, Dir: "first"},
+			{Exporter: "export-fail", Dir: "failed"},
+			{Exporter: "create-ok", Args: []string{"second"}, Flags: map[string]interface{}{"import-style": "script"}, Dir: "nested/second"},
+			{Exporter: "export-ok", Args: []string{"too", "many"}, Dir: "exit"},
+			{Exporter: "export-unknown", Dir: "unknown"},
+		},
+	}
+	tfWorkPath := t.TempDir()
+	ctx := terminal.Context(context.Background(), terminal.New(terminal.DiscardWriter(), nil, terminal.DiscardWriter()))
+
+	results := runBatch(ctx, commands, manifest, tfWorkPath, []string{"--section", "test"}, 2)
+
+	require.Len(t, results, 5)
+	assert.NoError(t, results[0].err)
+	assert.ErrorContains(t, results[1].err, "export failed")
+	assert.NoError(t, results[2].err)
+	assert.True(t, errors.Is(results[3].err, ErrExporter), "expected: %s; got: %s", ErrExporter, results[3].err)
+	assert.ErrorContains(t, results[4].err, "unknown exporter 'export-unknown'")
+	for i, entry := range manifest.Entries {
+		assert.Equal(t, entry, results[i].entry)
+	}
+
+	assert.Equal(t, map[string][]string{
+		"first":  {filepath.Join(tfWorkPath, "first"), "blocks", "test"},
+		"second": {filepath.Join(tfWorkPath, "nested", "second"), "script", "test"},
+	}, calls)
+	assert.DirExists(t, filepath.Join(tfWorkPath, "nested", "second"))
+}
@@ -0,0 +1,640 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/appsec"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/clientlists"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudlets"
+	v3 "github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudlets/v3"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cps"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/dns"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/edgeworkers"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/imaging"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
+	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/tools"
+	"github.com/akamai/cli/pkg/autocomplete"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+type (
+	// discoveryCommand describes a list-* command printing objects of a single product
+	discoveryCommand struct {
+		name        string
+		description string
+		// columns are printed in the given order, contract and group filters are available if they include
+		// columnContract and columnGroup respectively
+		columns []string
+		// nameColumn is matched against the name filter
+		nameColumn string
+		list       func(context.Context, *accountClients, discoveryFilter) ([]discoveryRow, error)
+	}
+
+	// discoveryFilter selects listed objects by their contract, group and name, empty fields select all objects
+	discoveryFilter struct {
+		contract string
+		group    string
+		name     string
+	}
+
+	// discoveryRow holds values of a listed object by column name
+	discoveryRow map[string]string
+
+	// discoveryOutput is the format in which listed objects are printed
+	discoveryOutput string
+)
+
+const (
+	// discoveryOutputTable prints an aligned table with a header
+	discoveryOutputTable discoveryOutput = "table"
+	// discoveryOutputJSON prints an array of objects with column names as keys
+	discoveryOutputJSON discoveryOutput = "json"
+	// discoveryOutputCSV prints comma separated values with a header
+	discoveryOutputCSV discoveryOutput = "csv"
+
+	columnContract = "contract_id"
+	columnGroup    = "group_id"
+)
+
+var (
+	// ErrInvalidDiscoveryOutput is returned when unknown output format of a list command is requested
+	ErrInvalidDiscoveryOutput = errors.New("invalid output")
+	// ErrInvalidNamePattern is returned when the name filter is not a valid glob pattern
+	ErrInvalidNamePattern = errors.New("invalid name pattern")
+
+	// discoveryCommands lists list-* commands in the order of their registration
+	discoveryCommands = []discoveryCommand{
+		{
+			name:        "list-properties",
+			description: "Lists properties available to the credentials",
+			columns:     []string{"property_id", "property_name", columnContract, columnGroup, "latest_version", "staging_version", "production_version"},
+			nameColumn:  "property_name",
+			list:        listProperties,
+		},
+		{
+			name:        "list-includes",
+			description: "Lists includes available to the credentials",
+			columns:     []string{"include_id", "include_name", "include_type", columnContract, columnGroup, "latest_version", "staging_version", "production_version"},
+			nameColumn:  "include_name",
+			list:        listIncludes,
+		},
+		{
+			name:        "list-zones",
+			description: "Lists Edge DNS zones available to the credentials",
+			columns:     []string{"zone", "type", columnContract, "activation_state"},
+			nameColumn:  "zone",
+			list:        listZones,
+		},
+		{
+			name:        "list-gtm-domains",
+			description: "Lists GTM domains available to the credentials",
+			columns:     []string{"domain_name", "status", "last_modified"},
+			nameColumn:  "domain_name",
+			list:        listDomains,
+		},
+		{
+			name:        "list-cloudlets-policies",
+			description: "Lists Cloudlets policies, both legacy and shared ones, available to the credentials",
+			columns:     []string{"policy_id", "policy_name", "cloudlet", columnGroup, "api"},
+			nameColumn:  "policy_name",
+			list:        listCloudletsPolicies,
+		},
+		{
+			name:        "list-edgeworkers",
+			description: "Lists EdgeWorkers available to the credentials",
+			columns:     []string{"edgeworker_id", "name", columnGroup, "resource_tier_id"},
+			nameColumn:  "name",
+			list:        listEdgeWorkers,
+		},
+		{
+			name:        "list-edgekv",
+			description: "Lists EdgeKV namespaces of both networks available to the credentials",
+			columns:     []string{"namespace", "network", columnGroup, "geo_location", "retention"},
+			nameColumn:  "namespace",
+			list:        listEdgeKVNamespaces,
+		},
+		{
+			name:        "list-enrollments",
+			description: "Lists CPS enrollments available to the credentials",
+			columns:     []string{"enrollment_id", "common_name", columnContract, "validation_type", "certificate_type"},
+			nameColumn:  "common_name",
+			list:        listEnrollments,
+		},
+		{
+			name:        "list-client-lists",
+			description: "Lists client lists available to the credentials",
+			columns:     []string{"list_id", "name", "type", "items_count", "read_only"},
+			nameColumn:  "name",
+			list:        listClientLists,
+		},
+		{
+			name:        "list-policy-sets",
+			description: "Lists Image and Video Manager policy sets available to the credentials",
+			columns:     []string{"policy_set_id", "name", columnContract, "region", "type"},
+			nameColumn:  "name",
+			list:        listPolicySets,
+		},
+		{
+			name:        "list-appsec-configs",
+			description: "Lists Application Security configurations available to the credentials",
+			columns:     []string{"config_id", "name", "latest_version", "staging_version", "production_version"},
+			nameColumn:  "name",
+			list:        listSecurityConfigurations,
+		},
+	}
+)
+
+// newDiscoveryCommands returns list-* commands
+func newDiscoveryCommands() []*cli.Command {
+	var commands []*cli.Command
+	for _, discovery := range discoveryCommands {
+		commands = append(commands, &cli.Command{
+			Name:         discovery.name,
+			Description:  discovery.description,
+			Usage:        discovery.name,
+			Action:       validatedAction(discoveryAction(discovery), requireNArguments(0)),
+			Flags:        discovery.flags(),
+			BashComplete: autocomplete.Default,
+		})
+	}
+	return commands
+}
+
+// flags returns filter flags supported by the columns of the command followed by the output flag
+func (d discoveryCommand) flags() []cli.Flag {
+	var flags []cli.Flag
+	if d.hasColumn(columnContract) {
+		flags = append(flags, &cli.StringFlag{
+			Name:  "contract",
+			Usage: "List only objects of the contract, with or without 'ctr_' prefix",
+		})
+	}
+	if d.hasColumn(columnGroup) {
+		flags = append(flags, &cli.StringFlag{
+			Name:  "group",
+			Usage: "List only objects of the group, with or without 'grp_' prefix",
+		})
+	}
+	return append(flags,
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: fmt.Sprintf("List only objects with %s matching the glob pattern, e.g. 'www.*'", d.nameColumn),
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Usage:       "Format of the printed list: 'table', 'json' or 'csv'",
+			DefaultText: string(discoveryOutputTable),
+		},
+	)
+}
+
+func (d discoveryCommand) hasColumn(name string) bool {
+	for _, column := range d.columns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+// discoveryAction returns action printing objects listed by the command which match the filter flags
+func discoveryAction(d discoveryCommand) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		output, err := parseDiscoveryOutput(c.String("output"))
+		if err != nil {
+			return cli.Exit(color.RedString(err.Error()), 1)
+		}
+		filter := discoveryFilter{
+			contract: strings.TrimPrefix(c.String("contract"), "ctr_"),
+			group:    strings.TrimPrefix(c.String("group"), "grp_"),
+			name:     c.String("name"),
+		}
+		if _, err := path.Match(filter.name, ""); err != nil {
+			return cli.Exit(color.RedString("%s: '%s'", ErrInvalidNamePattern, filter.name), 1)
+		}
+
+		rows, err := d.list(c.Context, newAccountClients(edgegrid.GetSession(c.Context)), filter)
+		if err != nil {
+			return cli.Exit(color.RedString(err.Error()), 1)
+		}
+		rows = filter.apply(rows, d.nameColumn)
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i][d.nameColumn] < rows[j][d.nameColumn]
+		})
+		if err := writeDiscoveryRows(c.App.Writer, output, d.columns, rows); err != nil {
+			return cli.Exit(color.RedString("Error printing list: %s", err), 1)
+		}
+		return nil
+	}
+}
+
+// parseDiscoveryOutput returns output format with the given name, table if the name is empty
+func parseDiscoveryOutput(name string) (discoveryOutput, error) {
+	return tools.ParseEnum(name, discoveryOutputTable, ErrInvalidDiscoveryOutput, discoveryOutputTable, discoveryOutputJSON, discoveryOutputCSV)
+}
+
+// apply returns rows matching the filter, the name pattern is matched against the value of the name column
+func (f discoveryFilter) apply(rows []discoveryRow, nameColumn string) []discoveryRow {
+	filtered := make([]discoveryRow, 0, len(rows))
+	for _, row := range rows {
+		if f.contract != "" && strings.TrimPrefix(row[columnContract], "ctr_") != f.contract {
+			continue
+		}
+		if f.group != "" && strings.TrimPrefix(row[columnGroup], "grp_") != f.group {
+			continue
+		}
+		if f.name != "" {
+			if matched, _ := path.Match(f.name, row[nameColumn]); !matched {
+				continue
+			}
+		}
+		filtered = append(filtered, row)
+	}
+	return filtered
+}
+
+// contractMatches returns true if objects of the contract can pass the filter
+// It lets list functions skip API calls for contracts which are filtered out anyway
+func (f discoveryFilter) contractMatches(contractID string) bool {
+	return f.contract == "" || strings.TrimPrefix(contractID, "ctr_") == f.contract
+}
+
+// groupMatches returns true if objects of the group can pass the filter
+func (f discoveryFilter) groupMatches(groupID string) bool {
+	return f.group == "" || strings.TrimPrefix(groupID, "grp_") == f.group
+}
+
+// writeDiscoveryRows prints values of the columns of the rows in the output format
+func writeDiscoveryRows(w io.Writer, output discoveryOutput, columns []string, rows []discoveryRow) error {
+	switch output {
+	case discoveryOutputJSON:
+		objects := make([]map[string]string, 0, len(rows))
+		for _, row := range rows {
+			object := make(map[string]string, len(columns))
+			for _, column := range columns {
+				object[column] = row[column]
+			}
+			objects = append(objects, object)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(objects)
+	case discoveryOutputCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := writer.Write(row.values(columns)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		header := make([]string, 0, len(columns))
+		for _, column := range columns {
+			header = append(header, strings.ToUpper(strings.ReplaceAll(column, "_", " ")))
+		}
+		fmt.Fprintln(writer, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(writer, strings.Join(row.values(columns), "\t"))
+		}
+		return writer.Flush()
+	}
+}
+
+func (r discoveryRow) values(columns []string) []string {
+	values := make([]string, 0, len(columns))
+	for _, column := range columns {
+		values = append(values, r[column])
+	}
+	return values
+}
+
+// listContracts returns IDs of the contracts matching the filter, without 'ctr_' prefix
+func listContracts(ctx context.Context, clients *accountClients, filter discoveryFilter) ([]string, error) {
+	if filter.contract != "" {
+		return []string{filter.contract}, nil
+	}
+	contracts, err := clients.papi.GetContracts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	ids := make([]string, 0, len(contracts.Contracts.Items))
+	for _, contract := range contracts.Contracts.Items {
+		ids = append(ids, strings.TrimPrefix(contract.ContractID, "ctr_"))
+	}
+	return ids, nil
+}
+
+// optionalInt returns the number as a string or an empty string if it is not set
+func optionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func listProperties(ctx context.Context, clients *accountClients, filter discoveryFilter) ([]discoveryRow, error) {
+	groups, err := clients.papi.GetGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var rows []discoveryRow
+	seen := make(map[string]bool)
+	for _, group := range groups.Groups.Items {
+		if !filter.groupMatches(group.GroupID) {
+			continue
+		}
+		for _, contractID := range group.ContractIDs {
+			if !filter.contractMatches(contractID) {
+				continue
+			}
+			properties, err := clients.papi.GetProperties(ctx, papi.GetPropertiesRequest{
+				ContractID: contractID,
+				GroupID:    group.GroupID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+			}
+			for _, property := range properties.Properties.Items {
+				if seen[property.PropertyID] {
+					continue
+				}
+				seen[property.PropertyID] = true
+				rows = append(rows, discoveryRow{
+					"property_id":        property.PropertyID,
+					"property_name":      property.PropertyName,
+					columnContract:       property.ContractID,
+					columnGroup:          property.GroupID,
+					"latest_version":     strconv.Itoa(property.LatestVersion),
+					"staging_version":    optionalInt(property.StagingVersion),
+					"production_version": optionalInt(property.ProductionVersion),
+				})
+			}
+		}
+	}
+	return rows, nil
+}
+
+func listIncludes(ctx context.Context, clients *accountClients, filter discoveryFilter) ([]discoveryRow, error) {
+	contractIDs, err := listContracts(ctx, clients, filter)
+	if err != nil {
+		return nil, err
+	}
+	var rows []discoveryRow
+	for _, contractID := range contractIDs {
+		includes, err := clients.papi.ListIncludes(ctx, papi.ListIncludesRequest{ContractID: "ctr_" + contractID})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+		}
+		for _, include := range includes.Includes.Items {
+			rows = append(rows, discoveryRow{
+				"include_id":         include.IncludeID,
+				"include_name":       include.IncludeName,
+				"include_type":       string(include.IncludeType),
+				columnContract:       include.ContractID,
+				columnGroup:          include.GroupID,
+				"latest_version":     strconv.Itoa(include.LatestVersion),
+				"staging_version":    optionalInt(include.StagingVersion),
+				"production_version": optionalInt(include.ProductionVersion),
+			})
+		}
+	}
+	return rows, nil
+}
+
+func listZones(ctx context.Context, clients *accountClients, filter discoveryFilter) ([]discoveryRow, error) {
+	zones, err := clients.dns.ListZones(ctx, dns.ZoneListQueryArgs{ShowAll: true, ContractIDs: filter.contract})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var rows []discoveryRow
+	for _, zone := range zones.Zones {
+		rows = append(rows, discoveryRow{
+			"zone":             zone.Zone,
+			"type":             zone.Type,
+			columnContract:     zone.ContractID,
+			"activation_state": zone.ActivationState,
+		})
+	}
+	return rows, nil
+}
+
+func listDomains(ctx context.Context, clients *accountClients, _ discoveryFilter) ([]discoveryRow, error) {
+	domains, err := clients.gtm.ListDomains(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var rows []discoveryRow
+	for _, domain := range domains {
+		rows = append(rows, discoveryRow{
+			"domain_name":   domain.Name,
+			"status":        domain.Status,
+			"last_modified": domain.LastModified,
+		})
+	}
+	return rows, nil
+}
+
+func listCloudletsPolicies(ctx context.Context, clients *accountClients, _ discoveryFilter) ([]discoveryRow, error) {
+	var rows []discoveryRow
+	pageSize, offset := 1000, 0
+	for {
+		policies, err := clients.cloudlets.ListPolicies(ctx, cloudlets.ListPoliciesRequest{
+			Offset:   offset,
+			PageSize: &pageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+		}
+		for _, policy := range policies {
+			rows = append(rows, discoveryRow{
+				"policy_id":   strconv.FormatInt(policy.PolicyID, 10),
+				"policy_name": policy.Name,
+				"cloudlet":    policy.CloudletCode,
+				columnGroup:   strconv.FormatInt(policy.GroupID, 10),
+				"api":         "v2",
+			})
+		}
+		if len(policies) < pageSize {
+			break
+		}
+		offset += pageSize
+	}
+
+	page, size := 0, 1000
+	for {
+		policies, err := clients.cloudletsV3.ListPolicies(ctx, v3.ListPoliciesRequest{Page: page, Size: size})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+		}
+		for _, policy := range policies.Content {
+			rows = append(rows, discoveryRow{
+				"policy_id":   strconv.FormatInt(policy.ID, 10),
+				"policy_name": policy.Name,
+				"cloudlet":    string(policy.CloudletType),
+				columnGroup:   strconv.FormatInt(policy.GroupID, 10),
+				"api":         "v3",
+			})
+		}
+		if len(policies.Content) < size {
+			break
+		}
+		page++
+	}
+	return rows, nil
+}
+
+func listEdgeWorkers(ctx context.Context, clients *accountClients, _ discoveryFilter) ([]discoveryRow, error) {
+	edgeWorkers, err := clients.edgeworkers.ListEdgeWorkersID(ctx, edgeworkers.ListEdgeWorkersIDRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var rows []discoveryRow
+	for _, edgeWorker := range edgeWorkers.EdgeWorkers {
+		rows = append(rows, discoveryRow{
+			"edgeworker_id":    strconv.Itoa(edgeWorker.EdgeWorkerID),
+			"name":             edgeWorker.Name,
+			columnGroup:        strconv.FormatInt(edgeWorker.GroupID, 10),
+			"resource_tier_id": strconv.Itoa(edgeWorker.ResourceTierID),
+		})
+	}
+	return rows, nil
+}
+
+func listEdgeKVNamespaces(ctx context.Context, clients *accountClients, _ discoveryFilter) ([]discoveryRow, error) {
+	var rows []discoveryRow
+	for _, network := range []edgeworkers.NamespaceNetwork{edgeworkers.NamespaceStagingNetwork, edgeworkers.NamespaceProductionNetwork} {
+		namespaces, err := clients.edgeworkers.ListEdgeKVNamespaces(ctx, edgeworkers.ListEdgeKVNamespacesRequest{
+			Network: network,
+			Details: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+		}
+		for _, namespace := range namespaces.Namespaces {
+			rows = append(rows, discoveryRow{
+				"namespace":    namespace.Name,
+				"network":      string(network),
+				columnGroup:    optionalInt(namespace.GroupID),
+				"geo_location": namespace.GeoLocation,
+				"retention":    optionalInt(namespace.Retention),
+			})
+		}
+	}
+	return rows, nil
+}
+
+func listEnrollments(ctx context.Context, clients *accountClients, filter discoveryFilter) ([]discoveryRow, error) {
+	contractIDs, err := listContracts(ctx, clients, filter)
+	if err != nil {
+		return nil, err
+	}
+	var rows []discoveryRow
+	for _, contractID := range contractIDs {
+		enrollments, err := clients.cps.ListEnrollments(ctx, cps.ListEnrollmentsRequest{ContractID: contractID})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+		}
+		for _, enrollment := range enrollments.Enrollments {
+			var commonName string
+			if enrollment.CSR != nil {
+				commonName = enrollment.CSR.CN
+			}
+			rows = append(rows, discoveryRow{
+				"enrollment_id":    strconv.Itoa(enrollment.ID),
+				"common_name":      commonName,
+				columnContract:     contractID,
+				"validation_type":  enrollment.ValidationType,
+				"certificate_type": enrollment.CertificateType,
+			})
+		}
+	}
+	return rows, nil
+}
+
+func listClientLists(ctx context.Context, clients *accountClients, _ discoveryFilter) ([]discoveryRow, error) {
+	lists, err := clients.clientlists.GetClientLists(ctx, clientlists.GetClientListsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var rows []discoveryRow
+	for _, list := range lists.Content {
+		rows = append(rows, discoveryRow{
+			"list_id":     list.ListID,
+			"name":        list.Name,
+			"type":        string(list.Type),
+			"items_count": strconv.FormatInt(list.ItemsCount, 10),
+			"read_only":   strconv.FormatBool(list.ReadOnly),
+		})
+	}
+	return rows, nil
+}
+
+func listPolicySets(ctx context.Context, clients *accountClients, filter discoveryFilter) ([]discoveryRow, error) {
+	contractIDs, err := listContracts(ctx, clients, filter)
+	if err != nil {
+		return nil, err
+	}
+	var rows []discoveryRow
+	for _, contractID := range contractIDs {
+		policySets, err := clients.imaging.ListPolicySets(ctx, imaging.ListPolicySetsRequest{
+			ContractID: contractID,
+			Network:    imaging.NetworkBoth,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+		}
+		for _, policySet := range policySets {
+			rows = append(rows, discoveryRow{
+				"policy_set_id": policySet.ID,
+				"name":          policySet.Name,
+				columnContract:  contractID,
+				"region":        string(policySet.Region),
+				"type":          policySet.Type,
+			})
+		}
+	}
+	return rows, nil
+}
+
+func listSecurityConfigurations(ctx context.Context, clients *accountClients, _ discoveryFilter) ([]discoveryRow, error) {
+	configurations, err := clients.appsec.GetConfigurations(ctx, appsec.GetConfigurationsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDiscovery, err)
+	}
+	var rows []discoveryRow
+	for _, configuration := range configurations.Configurations {
+		rows = append(rows, discoveryRow{
+			"config_id":          strconv.Itoa(configuration.ID),
+			"name":               configuration.Name,
+			"latest_version":     strconv.Itoa(configuration.LatestVersion),
+			"staging_version":    optionalVersion(configuration.StagingVersion),
+			"production_version": optionalVersion(configuration.ProductionVersion),
+		})
+	}
+	return rows, nil
+}
+
+// optionalVersion returns the version as a string or an empty string if it is 0, i.e. not active
+func optionalVersion(version int) string {
+	if version == 0 {
+		return ""
+	}
+	return strconv.Itoa(version)
+}
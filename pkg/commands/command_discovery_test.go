@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cps"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/imaging"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDiscoveryOutput(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  discoveryOutput
+		withError error
+	}{
+		"table by default": {expected: discoveryOutputTable},
+		"json":             {given: "json", expected: discoveryOutputJSON},
+		"csv":              {given: "csv", expected: discoveryOutputCSV},
+		"unknown":          {given: "xml", withError: ErrInvalidDiscoveryOutput},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			output, err := parseDiscoveryOutput(test.given)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, output)
+		})
+	}
+}
+
+func TestDiscoveryCommandFlags(t *testing.T) {
+	tests := map[string]struct {
+		columns  []string
+		expected []string
+	}{
+		"contract and group": {
+			columns:  []string{"id", "name", columnContract, columnGroup},
+			expected: []string{"contract", "group", "name", "output"},
+		},
+		"contract only": {
+			columns:  []string{"name", columnContract},
+			expected: []string{"contract", "name", "output"},
+		},
+		"no contract and group": {
+			columns:  []string{"name"},
+			expected: []string{"name", "output"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var names []string
+			for _, flag := range (discoveryCommand{columns: test.columns, nameColumn: "name"}).flags() {
+				names = append(names, flag.Names()[0])
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestDiscoveryFilter(t *testing.T) {
+	rows := []discoveryRow{
+		{"name": "www.example.com", columnContract: "ctr_C-1", columnGroup: "grp_1"},
+		{"name": "api.example.com", columnContract: "ctr_C-2", columnGroup: "grp_1"},
+		{"name": "www.example.org", columnContract: "C-2", columnGroup: "2"},
+	}
+
+	tests := map[string]struct {
+		filter   discoveryFilter
+		expected []string
+	}{
+		"no filter": {
+			expected: []string{"www.example.com", "api.example.com", "www.example.org"},
+		},
+		"contract": {
+			filter:   discoveryFilter{contract: "C-2"},
+			expected: []string{"api.example.com", "www.example.org"},
+		},
+		"group": {
+			filter:   discoveryFilter{group: "1"},
+			expected: []string{"www.example.com", "api.example.com"},
+		},
+		"name pattern": {
+			filter:   discoveryFilter{name: "www.*"},
+			expected: []string{"www.example.com", "www.example.org"},
+		},
+		"all filters": {
+			filter:   discoveryFilter{contract: "C-2", group: "2", name: "*.org"},
+			expected: []string{"www.example.org"},
+		},
+		"nothing matches": {
+			filter:   discoveryFilter{name: "foo*"},
+			expected: []string{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			names := []string{}
+			for _, row := range test.filter.apply(rows, "name") {
+				names = append(names, row["name"])
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestWriteDiscoveryRows(t *testing.T) {
+	columns := []string{"zone", columnContract}
+	rows := []discoveryRow{
+		{"zone": "example.com", columnContract: "C-1", "ignored": "x"},
+		{"zone": "example, org", columnContract: ""},
+	}
+
+	tests := map[string]struct {
+		output   discoveryOutput
+		rows     []discoveryRow
+		expected string
+	}{
+		"table": {
+			output:   discoveryOutputTable,
+			rows:     rows,
+			expected: "ZONE          CONTRACT ID\nexample.com   C-1\nexample, org  \n",
+		},
+		"json": {
+			output: discoveryOutputJSON,
+			rows:   rows,
+			expected: `[
+  {
+    "contract_id": "C-1",
+    "zone": "example.com"
+  },
+  {
+    "contract_id": "",
+    "zone": "example, org"
+  }
+]
+`,
+		},
+		"empty json": {
+			output:   discoveryOutputJSON,
+			expected: "[]\n",
+		},
+		"csv": {
+			output:   discoveryOutputCSV,
+			rows:     rows,
+			expected: "zone,contract_id\nexample.com,C-1\n\"example, org\",\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeDiscoveryRows(&buf, test.output, columns, test.rows))
+			assert.Equal(t, test.expected, buf.String())
+		})
+	}
+}
+
+func TestListProperties(t *testing.T) {
+	productionVersion := 2
+	papiClient := new(papi.Mock)
+	papiClient.On("GetGroups", mock.Anything).Return(&papi.GetGroupsResponse{
+		Groups: papi.GroupItems{Items: []*papi.Group{
+			{GroupID: "grp_1", ContractIDs: []string{"ctr_C-1", "ctr_C-2"}},
+			{GroupID: "grp_2", ContractIDs: []string{"ctr_C-1"}},
+		}},
+	}, nil)
+	papiClient.On("GetProperties", mock.Anything, papi.GetPropertiesRequest{ContractID: "ctr_C-1", GroupID: "grp_1"}).Return(&papi.GetPropertiesResponse{
+		Properties: papi.PropertiesItems{Items: []*papi.Property{{
+			PropertyID:        "prp_1",
+			PropertyName:      "www.example.com",
+			ContractID:        "ctr_C-1",
+			GroupID:           "grp_1",
+			LatestVersion:     3,
+			ProductionVersion: &productionVersion,
+		}}},
+	}, nil).Once()
+
+	rows, err := listProperties(context.Background(), &accountClients{papi: papiClient}, discoveryFilter{contract: "C-1", group: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, []discoveryRow{{
+		"property_id":        "prp_1",
+		"property_name":      "www.example.com",
+		columnContract:       "ctr_C-1",
+		columnGroup:          "grp_1",
+		"latest_version":     "3",
+		"staging_version":    "",
+		"production_version": "2",
+	}}, rows)
+	papiClient.AssertExpectations(t)
+}
+
+func TestListContracts(t *testing.T) {
+	t.Run("contracts of the credentials", func(t *testing.T) {
+		papiClient := new(papi.Mock)
+		papiClient.On("GetContracts", mock.Anything).Return(&papi.GetContractsResponse{
+			Contracts: papi.ContractsItems{Items: []*papi.Contract{{ContractID: "ctr_C-1"}, {ContractID: "ctr_C-2"}}},
+		}, nil).Once()
+		cpsClient := new(cps.Mock)
+		cpsClient.On("ListEnrollments", mock.Anything, cps.ListEnrollmentsRequest{ContractID: "C-1"}).Return(&cps.ListEnrollmentsResponse{
+			Enrollments: []cps.Enrollment{{ID: 1, CSR: &cps.CSR{CN: "example.com"}, ValidationType: "dv", CertificateType: "san"}},
+		}, nil).Once()
+		cpsClient.On("ListEnrollments", mock.Anything, cps.ListEnrollmentsRequest{ContractID: "C-2"}).Return(&cps.ListEnrollmentsResponse{
+			Enrollments: []cps.Enrollment{{ID: 2, ValidationType: "ov"}},
+		}, nil).Once()
+
+		rows, err := listEnrollments(context.Background(), &accountClients{papi: papiClient, cps: cpsClient}, discoveryFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []discoveryRow{
+			{"enrollment_id": "1", "common_name": "example.com", columnContract: "C-1", "validation_type": "dv", "certificate_type": "san"},
+			{"enrollment_id": "2", "common_name": "", columnContract: "C-2", "validation_type": "ov", "certificate_type": ""},
+		}, rows)
+		papiClient.AssertExpectations(t)
+		cpsClient.AssertExpectations(t)
+	})
+
+	t.Run("contract given by the filter", func(t *testing.T) {
+		imagingClient := new(imaging.Mock)
+		imagingClient.On("ListPolicySets", mock.Anything, imaging.ListPolicySetsRequest{ContractID: "C-1", Network: imaging.NetworkBoth}).Return([]imaging.PolicySet{
+			{ID: "set_1", Name: "images", Region: imaging.RegionUS, Type: "IMAGE"},
+		}, nil).Once()
+
+		rows, err := listPolicySets(context.Background(), &accountClients{imaging: imagingClient}, discoveryFilter{contract: "C-1"})
+		require.NoError(t, err)
+		assert.Equal(t, []discoveryRow{
+			{"policy_set_id": "set_1", "name": "images", columnContract: "C-1", "region": "US", "type": "IMAGE"},
+		}, rows)
+		imagingClient.AssertExpectations(t)
+	})
+
+	t.Run("listing failed", func(t *testing.T) {
+		papiClient := new(papi.Mock)
+		papiClient.On("GetContracts", mock.Anything).Return(nil, errors.New("forbidden")).Once()
+
+		_, err := listIncludes(context.Background(), &accountClients{papi: papiClient}, discoveryFilter{})
+		assert.True(t, errors.Is(err, ErrDiscovery), "expected: %s; got: %s", ErrDiscovery, err)
+		papiClient.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdDocs(c *cli.Context) error {
+	terminal.Get(c.Context).Printf("%s", exportersReference(getRegistry(c.Context)))
+	return nil
+}
+
+// exportersReference returns Markdown reference of the export commands of the registry,
+// with their arguments, flags and generated files, followed by the flags shared by all of them
+func exportersReference(registry *exporter.Registry) string {
+	var sb strings.Builder
+	sb.WriteString("# Export commands\n\n")
+	sb.WriteString("This reference is generated from the registered exporters with `akamai terraform docs` command.\n\n")
+	for _, e := range registry.Exporters() {
+		writeExporterReference(&sb, e, "", 2)
+	}
+
+	sb.WriteString("## Flags of all export commands\n\n")
+	writeFlagsReference(&sb, append([]cli.Flag{&cli.StringFlag{
+		Name:        "tfworkpath",
+		Usage:       "Directory used to store files created when running commands.",
+		DefaultText: "current directory",
+	}}, exportFlags()...))
+	return sb.String()
+}
+
+func writeExporterReference(sb *strings.Builder, e exporter.Exporter, parent string, level int) {
+	spec := e.Spec()
+	name := strings.TrimSpace(parent + " " + spec.Name)
+	sb.WriteString(fmt.Sprintf("%s %s\n\n", strings.Repeat("#", level), name))
+	if spec.Description != "" {
+		sb.WriteString(spec.Description + "\n\n")
+	}
+
+	usage := "akamai terraform " + name
+	if len(spec.Flags) > 0 || parent == "" {
+		usage += " [flags]"
+	}
+	if len(spec.Args) > 0 {
+		usage += fmt.Sprintf(" <%s>", strings.Join(spec.Args, "> <"))
+	} else if len(spec.Subcommands) > 0 {
+		usage += " <subcommand>"
+	}
+	sb.WriteString(fmt.Sprintf("Usage: `%s`\n\n", usage))
+	if len(spec.Aliases) > 0 {
+		sb.WriteString(fmt.Sprintf("Aliases: `%s`\n\n", strings.Join(spec.Aliases, "`, `")))
+	}
+
+	if len(spec.Flags) > 0 {
+		sb.WriteString("Flags:\n\n")
+		writeFlagsReference(sb, spec.Flags)
+	}
+
+	if len(spec.Templates.Files) > 0 {
+		files := make([]string, 0, len(spec.Templates.Files))
+		for _, file := range spec.Templates.Files {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		sb.WriteString(fmt.Sprintf("Generated files: `%s`\n\n", strings.Join(files, "`, `")))
+	}
+
+	for _, sub := range spec.Subcommands {
+		writeExporterReference(sb, sub, name, level+1)
+	}
+}
+
+func writeFlagsReference(sb *strings.Builder, flags []cli.Flag) {
+	for _, flag := range flags {
+		// flags are printed in the same way as in the help of the command, i.e. 'names<tab>usage (default: value)'
+		parts := strings.SplitN(flag.String(), "\t", 2)
+		line := fmt.Sprintf("- `%s`", parts[0])
+		if len(parts) == 2 && strings.TrimSpace(parts[1]) != "" {
+			line += " " + strings.TrimSpace(parts[1])
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("\n")
+}
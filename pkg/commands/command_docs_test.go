@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportersReference(t *testing.T) {
+	registry, err := exporter.NewRegistry(append(builtinExporters(), testExporter{spec: exporter.Spec{
+		Name:        "export-test",
+		Description: "Generates Terraform configuration for test resources",
+		Args:        []string{"id"},
+	}})...)
+	require.NoError(t, err)
+
+	res := exportersReference(registry)
+	assert.True(t, strings.HasPrefix(res, "# Export commands\n"), res)
+	for _, e := range registry.Exporters() {
+		assert.Contains(t, res, "\n## "+e.Spec().Name+"\n")
+	}
+	assert.Contains(t, res, "Usage: `akamai terraform export-cps [flags] <enrollment_id> <contract_id>`\n\nAliases: `create-cps`\n")
+	assert.Contains(t, res, "- `--secrets value` Where values of secrets")
+	assert.Contains(t, res, "Generated files: `enrollment.tf`, `import.sh`, `variables.tf`\n")
+	assert.Contains(t, res, "### export-iam user\n\nExports Terraform User resource with relevant groups and roles resources\n\nUsage: `akamai terraform export-iam user <user_email>`\n")
+	assert.Contains(t, res, "Usage: `akamai terraform export-iam [flags] <subcommand>`\n")
+	assert.Contains(t, res, "## export-test\n\nGenerates Terraform configuration for test resources\n\nUsage: `akamai terraform export-test [flags] <id>`\n")
+	assert.Contains(t, res, "## Flags of all export commands\n\n- `--tfworkpath value` Directory used to store files created when running commands. (default: current directory)\n")
+}
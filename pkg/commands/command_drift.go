@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// driftExitCode is returned by drift command when the existing configuration differs from the live one
+const driftExitCode = 2
+
+var changeSymbols = map[templates.ChangeType]string{
+	templates.ChangeAdded:    "+",
+	templates.ChangeRemoved:  "-",
+	templates.ChangeModified: "~",
+}
+
+func cmdDrift(c *cli.Context) error {
+	command := findCommand(exportCommands(getRegistry(c.Context)), c.Args().First())
+	tfWorkPath := "./"
+	if c.IsSet("tfworkpath") {
+		tfWorkPath = c.String("tfworkpath")
+	}
+	tfWorkPath = filepath.FromSlash(tfWorkPath)
+
+	term := terminal.Get(c.Context)
+	progress := term
+	if c.Bool("json") {
+		// keep standard output parsable
+		progress = terminal.New(os.Stderr, os.Stdin, os.Stderr)
+	}
+	progress.Spinner().Start("Fetching live configuration ")
+	changes, err := detectDrift(c.Context, command, c.Args().Tail(), tfWorkPath, globalArgs(c))
+	if err != nil {
+		progress.Spinner().Fail()
+		return cli.Exit(color.RedString("Error detecting drift: %s", err), 1)
+	}
+	progress.Spinner().OK()
+
+	if c.Bool("json") {
+		if changes == nil {
+			changes = []templates.Change{}
+		}
+		out, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return cli.Exit(color.RedString("Error printing drift: %s", err), 1)
+		}
+		term.Printf("%s\n", out)
+	} else if len(changes) > 0 {
+		term.Printf("%s", formatDrift(changes))
+	}
+
+	if len(changes) > 0 {
+		return cli.Exit(color.YellowString("Drift detected: %d change(s)", len(changes)), driftExitCode)
+	}
+	progress.Writeln("No drift detected")
+	return nil
+}
+
+func requireExportCommand(c *cli.Context) error {
+	commands := exportCommands(getRegistry(c.Context))
+	if findCommand(commands, c.Args().First()) == nil {
+		var names []string
+		for _, command := range commands {
+			names = append(names, command.Name)
+		}
+		if err := showHelpCommandWithErr(c, fmt.Sprintf("One of the export commands is required: %s", names)); err != nil {
+			return err
+		}
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// detectDrift runs the export command with generated files kept in memory and compares them with the files in tfWorkPath
+// Existing files are visible to the export, e.g. versions.tf shared by several exports, as if it wrote into tfWorkPath
+func detectDrift(ctx context.Context, command *cli.Command, args []string, tfWorkPath string, globalArgs []string) ([]templates.Change, error) {
+	args, err := batchEntryArgs(command, args, map[string]interface{}{"tfworkpath": tfWorkPath})
+	if err != nil {
+		return nil, err
+	}
+
+	// the export command handles its flags and the config file as usual, but writes into the sink from the context
+	sink := templates.NewReadThroughSink()
+	ctx = context.WithValue(ctx, outputSinkContextKey{}, sink)
+
+	if err := runExporter(ctx, command, args, globalArgs); err != nil {
+		return nil, err
+	}
+
+	var changes []templates.Change
+	for _, path := range sink.Files() {
+		generated, _ := sink.File(path)
+		name := path
+		if rel, err := filepath.Rel(tfWorkPath, path); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+		existing, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			changes = append(changes, templates.Change{Type: templates.ChangeAdded, File: name})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		fileChanges, err := templates.DiffFiles(name, existing, generated)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, fileChanges...)
+	}
+	return changes, nil
+}
+
+// formatDrift returns changes grouped by files and blocks, e.g.
+//
+//	~ property.tf
+//	    ~ resource "akamai_property" "my-property"
+//	        ~ rule_format: "v2023-01-05" -> "v2024-01-01"
+func formatDrift(changes []templates.Change) string {
+	var sb strings.Builder
+	var file, block string
+	for i, change := range changes {
+		if i == 0 || change.File != file {
+			file, block = change.File, ""
+			if change.Block == "" && change.Attribute == "" {
+				if change.Type == templates.ChangeAdded {
+					sb.WriteString(fmt.Sprintf("+ %s (not found in tfworkpath)\n", change.File))
+				} else {
+					sb.WriteString(fmt.Sprintf("~ %s (content differs)\n", change.File))
+				}
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("~ %s\n", change.File))
+		}
+
+		indent := "    "
+		if change.Block != "" {
+			if change.Attribute == "" {
+				block = ""
+				sb.WriteString(fmt.Sprintf("    %s %s\n", changeSymbols[change.Type], change.Block))
+				continue
+			}
+			if change.Block != block {
+				block = change.Block
+				sb.WriteString(fmt.Sprintf("    ~ %s\n", change.Block))
+			}
+			indent = "        "
+		}
+
+		switch {
+		case change.Type == templates.ChangeModified:
+			sb.WriteString(fmt.Sprintf("%s~ %s: %s -> %s\n", indent, change.Attribute, change.Existing, change.Generated))
+		case change.Existing+change.Generated == "":
+			sb.WriteString(fmt.Sprintf("%s%s %s\n", indent, changeSymbols[change.Type], change.Attribute))
+		default:
+			sb.WriteString(fmt.Sprintf("%s%s %s = %s\n", indent, changeSymbols[change.Type], change.Attribute, change.Existing+change.Generated))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestDetectDrift(t *testing.T) {
+	command := &cli.Command{
+		Name: "export-test",
+		Action: validatedAction(func(c *cli.Context) error {
+			output := templates.GetOutput(c.Context)
+			tfWorkPath := c.String("tfworkpath")
+			if err := output.WriteFile(filepath.Join(tfWorkPath, "main.tf"), []byte(`resource "a" "b" {
+  name  = "`+c.Args().First()+`"
+  value = "`+c.String("value")+`"
+}
+`)); err != nil {
+				return err
+			}
+			if err := templates.GetVersions(c.Context).WriteVersions(output, tfWorkPath, "6.0.0"); err != nil {
+				return err
+			}
+			return output.WriteFile(filepath.Join(tfWorkPath, "rules", "main.json"), []byte(`{"a": 1}`))
+		}, requireNArguments(1)),
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{Name: "tfworkpath"},
+			&cli.StringFlag{Name: "value"},
+		}, exportFlags()...),
+		After: closeOutput,
+	}
+	command.Before = applyConfig(command, setupOutput)
+	// defaults of the config file are applied to the export command as usual
+	config := &projectConfig{Commands: map[string]map[string]interface{}{"export-test": {"value": "config"}}}
+	ctx := terminal.Context(context.Background(), terminal.New(terminal.DiscardWriter(), nil, terminal.DiscardWriter()))
+	ctx = context.WithValue(ctx, configContextKey{}, config)
+
+	tests := map[string]struct {
+		args      []string
+		files     map[string]string
+		expected  []templates.Change
+		withError error
+	}{
+		"no drift": {
+			args: []string{"name"},
+			files: map[string]string{
+				"main.tf":         "resource \"a\" \"b\" {\n  name  = \"name\"\n  value = \"config\"\n}\n",
+				"rules/main.json": "{\n  \"a\": 1\n}\n",
+			},
+		},
+		"changed attribute and missing file": {
+			args: []string{"new"},
+			files: map[string]string{
+				"main.tf": "resource \"a\" \"b\" {\n  name  = \"old\"\n  value = \"config\"\n}\n",
+			},
+			expected: []templates.Change{
+				{Type: templates.ChangeModified, File: "main.tf", Block: `resource "a" "b"`, Attribute: "name", Existing: `"old"`, Generated: `"new"`},
+				{Type: templates.ChangeAdded, File: "rules/main.json"},
+			},
+		},
+		"exporter error": {
+			args:      []string{"too", "many"},
+			withError: ErrExporter,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tfWorkPath := t.TempDir()
+			for path, content := range test.files {
+				path = filepath.Join(tfWorkPath, filepath.FromSlash(path))
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+			}
+			// versions.tf shared with an export requiring a higher provider version is kept as it is
+			require.NoError(t, templates.Versions{}.WriteVersions(templates.DiskSink{}, tfWorkPath, "6.4.0"))
+			out := os.Stdout
+
+			changes, err := detectDrift(ctx, command, test.args, tfWorkPath, nil)
+			assert.Equal(t, out, os.Stdout)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, changes)
+		})
+	}
+}
+
+func TestFormatDrift(t *testing.T) {
+	changes := []templates.Change{
+		{Type: templates.ChangeModified, File: "property.tf", Block: `resource "akamai_property" "p"`, Attribute: "rule_format", Existing: `"v2023-01-05"`, Generated: `"v2024-01-01"`},
+		{Type: templates.ChangeAdded, File: "property.tf", Block: `resource "akamai_property" "p"`, Attribute: "hostnames[1]"},
+		{Type: templates.ChangeRemoved, File: "property.tf", Block: `resource "akamai_property" "p"`, Attribute: "version_notes", Existing: `"old"`},
+		{Type: templates.ChangeAdded, File: "property.tf", Block: `resource "akamai_edge_hostname" "e"`},
+		{Type: templates.ChangeModified, File: "variables.tf", Attribute: "locals", Existing: "1", Generated: "2"},
+		{Type: templates.ChangeModified, File: "rules/main.json"},
+		{Type: templates.ChangeAdded, File: "rules/new.json"},
+	}
+
+	assert.Equal(t, `~ property.tf
+    ~ resource "akamai_property" "p"
+        ~ rule_format: "v2023-01-05" -> "v2024-01-01"
+        + hostnames[1]
+        - version_notes = "old"
+    + resource "akamai_edge_hostname" "e"
+~ variables.tf
+    ~ locals: 1 -> 2
+~ rules/main.json (content differs)
++ rules/new.json (not found in tfworkpath)
+`, formatDrift(changes))
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdLink(c *cli.Context) error {
+	tfWorkPath := "./"
+	if c.IsSet("tfworkpath") {
+		tfWorkPath = c.String("tfworkpath")
+	}
+	tfWorkPath = filepath.FromSlash(tfWorkPath)
+
+	links, skipped, err := linkWorkPath(tfWorkPath, c.Bool("dry-run"))
+	if err != nil {
+		return cli.Exit(color.RedString("Error linking files: %s", err), 1)
+	}
+	printLinks(terminal.Get(c.Context), links, skipped)
+	return nil
+}
+
+// linkWorkPath replaces literal IDs with references in terraform files of tfWorkPath and its subdirectories,
+// files are not changed in dry run
+// Hidden directories, e.g. .terraform with downloaded modules, are skipped, files in terraform JSON syntax
+// are not linked and are returned, so that they can be reported
+func linkWorkPath(tfWorkPath string, dryRun bool) ([]templates.Link, []string, error) {
+	files := make(map[string][]byte)
+	var skipped []string
+	err := filepath.WalkDir(tfWorkPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != tfWorkPath && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(tfWorkPath, path)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(path, ".tf.json") {
+			skipped = append(skipped, filepath.ToSlash(rel))
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".tf" && ext != ".sh" && ext != ".script" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = content
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changed, links := templates.LinkFiles(files)
+	if dryRun {
+		return links, skipped, nil
+	}
+	for name, content := range changed {
+		if err := os.WriteFile(filepath.Join(tfWorkPath, filepath.FromSlash(name)), content, 0644); err != nil {
+			return nil, nil, err
+		}
+	}
+	return links, skipped, nil
+}
+
+// printLinks prints summary of the literal IDs replaced with references and warns about files which were not linked
+func printLinks(term terminal.Terminal, links []templates.Link, skipped []string) {
+	if len(skipped) > 0 {
+		term.Printf("Warning: files in terraform JSON syntax are not linked: %s\n", strings.Join(skipped, ", "))
+	}
+	if len(links) == 0 {
+		term.Writeln("No literal IDs of resources declared in the same module found")
+		return
+	}
+	term.Printf("Linked %d reference(s):\n", len(links))
+	for _, link := range links {
+		term.Printf("  %s\n", link)
+	}
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkWorkPath(t *testing.T) {
+	property := `resource "akamai_edge_hostname" "www" {
+  certificate = 1234
+}
+`
+	files := map[string]string{
+		"property.tf":   property,
+		"enrollment.tf": "resource \"akamai_cps_dv_enrollment\" \"enrollment_id_1234\" {\n}\n",
+		"import.sh":     "terraform import akamai_cps_dv_enrollment.enrollment_id_1234 1234,ctr_C-1\n",
+		// modules downloaded by terraform are not linked
+		".terraform/modules/property.tf": property,
+		// files in terraform JSON syntax are reported instead of being linked
+		"edgehostname.tf.json": `{"resource": {"akamai_edge_hostname": {"other": {"certificate": 1234}}}}`,
+	}
+	expectedLinks := []templates.Link{{
+		File:      "property.tf",
+		Block:     "akamai_edge_hostname.www",
+		Attribute: "certificate",
+		Value:     "1234",
+		Reference: "akamai_cps_dv_enrollment.enrollment_id_1234.id",
+	}}
+
+	tests := map[string]struct {
+		dryRun   bool
+		expected string
+	}{
+		"files are changed": {
+			expected: "resource \"akamai_edge_hostname\" \"www\" {\n  certificate = akamai_cps_dv_enrollment.enrollment_id_1234.id\n}\n",
+		},
+		"dry run": {
+			dryRun:   true,
+			expected: property,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range files {
+				path := filepath.Join(dir, filepath.FromSlash(name))
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+			}
+
+			links, skipped, err := linkWorkPath(dir, test.dryRun)
+			require.NoError(t, err)
+			assert.Equal(t, expectedLinks, links)
+			assert.Equal(t, []string{"edgehostname.tf.json"}, skipped)
+			content, err := os.ReadFile(filepath.Join(dir, "property.tf"))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(content))
+			content, err = os.ReadFile(filepath.Join(dir, ".terraform", "modules", "property.tf"))
+			require.NoError(t, err)
+			assert.Equal(t, property, string(content))
+		})
+	}
+
+	_, _, err := linkWorkPath(filepath.Join(t.TempDir(), "missing"), false)
+	assert.Error(t, err)
+}
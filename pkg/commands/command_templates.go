@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdListTemplates(c *cli.Context) error {
+	spec := getRegistry(c.Context).Lookup(c.Args().First()).Spec()
+	set := spec.Templates
+	if set.FS == nil {
+		return cli.Exit(color.RedString("Command '%s' does not use templates", spec.Name), 1)
+	}
+	files, err := templateFiles(set)
+	if err != nil {
+		return cli.Exit(color.RedString("Error listing templates: %s", err), 1)
+	}
+
+	if dir := c.String("dir"); dir != "" {
+		if err := saveTemplates(set.FS, files, dir); err != nil {
+			return cli.Exit(color.RedString("Error saving templates: %s", err), 1)
+		}
+		terminal.Get(c.Context).Printf("Templates of %s were saved into '%s'\n", spec.Name, dir)
+		return nil
+	}
+
+	terminal.Get(c.Context).Printf("%s", describeTemplates(spec.Name, set, files))
+	return nil
+}
+
+// templateFiles returns paths of the templates from the set, sorted by their names
+func templateFiles(set exporter.TemplateSet) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(set.FS, ".", func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && path.Ext(filePath) == ".tmpl" && strings.HasPrefix(path.Base(filePath), set.Prefix) {
+			files = append(files, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return path.Base(files[i]) < path.Base(files[j])
+	})
+	return files, nil
+}
+
+// templateData returns data passed to the template with the given name
+func templateData(set exporter.TemplateSet, name string) interface{} {
+	if data, ok := set.Data[name]; ok {
+		return data
+	}
+	return set.Data[""]
+}
+
+// describeTemplates returns names of the templates grouped by the type of data passed to them
+// followed by definitions of these types
+func describeTemplates(command string, set exporter.TemplateSet, files []string) string {
+	var sb strings.Builder
+	var types []string
+	groups := make(map[string][]string)
+	dataByType := make(map[string]interface{})
+	for _, file := range files {
+		data := templateData(set, path.Base(file))
+		typeName := ""
+		if data != nil {
+			typeName = reflect.TypeOf(data).String()
+		}
+		if _, ok := groups[typeName]; !ok {
+			types = append(types, typeName)
+			dataByType[typeName] = data
+		}
+		groups[typeName] = append(groups[typeName], path.Base(file))
+	}
+
+	for _, typeName := range types {
+		if typeName == "" {
+			sb.WriteString(fmt.Sprintf("Templates of %s executed without data:\n", command))
+		} else {
+			sb.WriteString(fmt.Sprintf("Templates of %s executed with %s:\n", command, typeName))
+		}
+		for _, name := range groups[typeName] {
+			sb.WriteString(fmt.Sprintf("  %s\n", name))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("Data types:\n\n")
+	for _, typeName := range types {
+		if typeName != "" {
+			sb.WriteString(templates.DescribeData(dataByType[typeName]))
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString(fmt.Sprintf("Templates can be replaced by files of the same name in the directory passed with --template-dir flag or %s environment variable\n", templates.TemplateDirEnv))
+	return sb.String()
+}
+
+// saveTemplates writes the templates into dir under their names, existing files are not overwritten
+func saveTemplates(files fs.FS, paths []string, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, p := range paths {
+		target := filepath.Join(dir, path.Base(p))
+		if _, err := os.Stat(target); err == nil {
+			return fmt.Errorf("file '%s' already exists", target)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	for _, p := range paths {
+		content, err := fs.ReadFile(files, p)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, path.Base(p)), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandTemplates(t *testing.T) {
+	for _, e := range builtinRegistry().Exporters() {
+		spec := e.Spec()
+		t.Run(spec.Name, func(t *testing.T) {
+			require.NotNil(t, spec.Templates.FS, "templates of %s are not listed", spec.Name)
+			files, err := templateFiles(spec.Templates)
+			require.NoError(t, err)
+			assert.NotEmpty(t, files)
+			// every generated file has its template
+			for name := range spec.Templates.Files {
+				assert.Contains(t, files, "templates/"+name)
+			}
+		})
+	}
+}
+
+func TestTemplateFiles(t *testing.T) {
+	tests := map[string]struct {
+		command  string
+		expected []string
+	}{
+		"edgekv templates only": {
+			command:  "export-edgekv",
+			expected: []string{"edgekv-imports.tmpl", "edgekv-variables.tmpl", "edgekv.tmpl"},
+		},
+		"edgeworker templates only": {
+			command:  "export-edgeworker",
+			expected: []string{"edgeworker-imports.tmpl", "edgeworker-variables.tmpl", "edgeworker.tmpl"},
+		},
+		"templates in sub-directories": {
+			command:  "export-domain",
+			expected: []string{"asmap.tmpl", "cirdmap.tmpl", "datacenters.tmpl", "domain.tmpl", "geomap.tmpl", "imports.tmpl", "maps.tmpl", "multiline.tmpl", "properties.tmpl", "resources.tmpl", "variables.tmpl"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			files, err := templateFiles(builtinRegistry().Lookup(test.command).Spec().Templates)
+			require.NoError(t, err)
+			var names []string
+			for _, file := range files {
+				names = append(names, path.Base(file))
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestDescribeTemplates(t *testing.T) {
+	set := builtinRegistry().Lookup("export-zone").Spec().Templates
+	files, err := templateFiles(set)
+	require.NoError(t, err)
+
+	res := describeTemplates("export-zone", set, files)
+	assert.True(t, strings.HasPrefix(res, "Templates of export-zone executed with dns.ZoneData:\n  config.tmpl\n"), res)
+	assert.Contains(t, res, "Templates of export-zone executed without data:\n  dnsvars.tmpl\n")
+	assert.Contains(t, res, "Templates of export-zone executed with dns.RecordsetData:\n  module-set.tmpl\n  recordset-modsegment.tmpl\n  resource-set.tmpl\n")
+	assert.Contains(t, res, "type dns.ImportData struct {\n")
+}
+
+func TestSaveTemplates(t *testing.T) {
+	set := builtinRegistry().Lookup("export-clientlist").Spec().Templates
+	files, err := templateFiles(set)
+	require.NoError(t, err)
+	dir := filepath.Join(t.TempDir(), "templates")
+
+	require.NoError(t, saveTemplates(set.FS, files, dir))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"client-list.tmpl", "imports.tmpl", "variables.tmpl"}, names)
+
+	err = saveTemplates(set.FS, files, dir)
+	assert.ErrorContains(t, err, "already exists")
+}
@@ -16,6 +16,12 @@
 package commands
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/akamai/cli-terraform/pkg/exporter"
 	"github.com/akamai/cli-terraform/pkg/providers/appsec"
 	"github.com/akamai/cli-terraform/pkg/providers/clientlists"
 	"github.com/akamai/cli-terraform/pkg/providers/cloudaccess"
@@ -28,375 +34,408 @@ import (
 	"github.com/akamai/cli-terraform/pkg/providers/iam"
 	"github.com/akamai/cli-terraform/pkg/providers/imaging"
 	"github.com/akamai/cli-terraform/pkg/providers/papi"
-	"github.com/akamai/cli-terraform/pkg/tools"
+	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli/pkg/apphelp"
 	"github.com/akamai/cli/pkg/autocomplete"
+	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
 
+// registryContextKey holds the registry of exporters used by commands running them, e.g. export-batch
+type registryContextKey struct{}
+
 // CommandLocator creates and returns a list of subcommands
-func CommandLocator() ([]*cli.Command, error) {
-	var commands []*cli.Command
+// Export commands are created for built-in exporters followed by the extra ones, e.g. registered by third-party code
+func CommandLocator(extra ...exporter.Exporter) ([]*cli.Command, error) {
+	registry, err := exporter.NewRegistry(append(builtinExporters(), extra...)...)
+	if err != nil {
+		return nil, err
+	}
 
-	commands = append(commands, &cli.Command{
-		Name:        "export-domain",
-		Aliases:     []string{"create-domain"},
-		Description: "Generates Terraform configuration for Domain resources",
-		Usage:       "export-domain",
-		ArgsUsage:   "<domain>",
-		Action:      validatedAction(gtm.CmdCreateDomain, requireValidWorkpath, requireNArguments(1)),
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
-				DefaultText: "current directory",
-			},
-		},
-		BashComplete: autocomplete.Default,
-	})
+	commands := exportCommands(registry)
+	exportCount := len(commands)
 
 	commands = append(commands, &cli.Command{
-		Name:        "export-zone",
-		Aliases:     []string{"create-zone"},
-		Description: "Generates Terraform configuration for Zone resources",
-		Usage:       "export-zone",
-		ArgsUsage:   "<zone>",
-		Action:      validatedAction(dns.CmdCreateZone, requireValidWorkpath, requireNArguments(1)),
+		Name:        "export-batch",
+		Description: "Runs multiple exports described in a manifest file",
+		Usage:       "export-batch",
+		ArgsUsage:   "<manifest.yaml>",
+		Action:      validatedAction(cmdExportBatch, requireValidWorkpath, requireNArguments(1)),
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
+				Usage:       "Directory in which sub-directories of manifest entries are created.",
 				DefaultText: "current directory",
 			},
-			&cli.BoolFlag{
-				Name:  "resources",
-				Usage: "Create json formatted resource import list file, <zone>_resources.json. Used as input by createconfig.",
-			},
-			&cli.BoolFlag{
-				Name:  "createconfig",
-				Usage: "Create Terraform configuration (<zone>.tf), dnsvars.tf from generated resources file. Saves zone config for import.",
-			},
-			&cli.BoolFlag{
-				Name:  "importscript",
-				Usage: "Create import script for generated Terraform configuration script (<zone>_import.script) files",
-			},
-			&cli.BoolFlag{
-				Name:  "segmentconfig",
-				Usage: "Directive for createconfig. Group and segment records by name into separate config files.",
-			},
-			&cli.BoolFlag{
-				Name:  "configonly",
-				Usage: "Directive for createconfig. Create entire Terraform zone and recordsets configuration (<zone>.tf), dnsvars.tf. Saves zone config for importscript. Ignores any existing resource json file.",
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Maximum number of exports running at the same time",
+				Value: 4,
 			},
 			&cli.BoolFlag{
-				Name:  "namesonly",
-				Usage: "Directive for both resource gathering and config generation. All record set types assumed.",
-			},
-			&cli.StringSliceFlag{
-				Name:  "recordname",
-				Usage: "Used in resources gathering or with configonly to filter recordsets. Multiple recordname flags may be specified.",
-			},
-		},
-		BashComplete: autocomplete.Default,
-	})
-
-	commands = append(commands, &cli.Command{
-		Name:        "export-appsec",
-		Aliases:     []string{"create-appsec"},
-		Description: "Generates Terraform configuration for Application Security resources",
-		Usage:       "export-appsec",
-		ArgsUsage:   "<security configuration name>",
-		Action:      validatedAction(appsec.CmdCreateAppsec, requireValidWorkpath, requireNArguments(1)),
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
-				DefaultText: "current directory",
+				Name:  "link",
+				Usage: "After the exports, replace literal IDs with references to resources exported into the same directory, see link command",
 			},
 		},
 		BashComplete: autocomplete.Default,
 	})
 
 	commands = append(commands, &cli.Command{
-		Name:        "export-clientlist",
-		Description: "Generates Terraform configuration for Client List resources",
-		Usage:       "export-clientlist",
-		ArgsUsage:   "<list_id>",
-		Action:      validatedAction(clientlists.CmdCreateClientList, requireValidWorkpath, requireNArguments(1)),
+		Name:        "export-account",
+		Description: "Discovers objects available to the credentials and exports each of them into a separate directory",
+		Usage:       "export-account",
+		Action:      validatedAction(cmdExportAccount, requireValidWorkpath, requireNArguments(0), setupProgress),
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
+				Usage:       "Directory in which sub-directories of exported objects and the manifest are created.",
 				DefaultText: "current directory",
 			},
-		},
-		BashComplete: autocomplete.Default,
-	})
-
-	commands = append(commands, &cli.Command{
-		Name:        "export-property",
-		Aliases:     []string{"create-property"},
-		Description: "Generates Terraform configuration for Property resources",
-		Usage:       "export-property",
-		ArgsUsage:   "<property name>",
-		Action:      validatedAction(papi.CmdCreateProperty, requireValidWorkpath, requireNArguments(1)),
-		Subcommands: []*cli.Command{
-			{
-				Name:        "include",
-				Description: "Generates Terraform configuration for Include resources. Deprecated, use `export-property-include` command instead",
-				ArgsUsage:   "<contract_id> <include_name>",
-				Action:      validatedAction(papi.CmdCreateInclude, requireValidWorkpath, requireNArguments(2)),
-			},
-		},
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
-				DefaultText: "current directory",
+			&cli.StringSliceFlag{
+				Name:        "products",
+				Usage:       "Products to discover: property, dns, gtm, cloudlets, clientlists, cps, edgeworkers, appsec, iam. Can be comma separated or specified multiple times",
+				DefaultText: "all",
 			},
-			&cli.StringFlag{
-				Name:        "version",
-				Usage:       "Property version to import",
-				DefaultText: "LATEST",
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Maximum number of exports running at the same time",
+				Value: 4,
 			},
 			&cli.BoolFlag{
-				Name:  "with-includes",
-				Usage: "Referenced includes will also be exported along with property. Deprecated.",
+				Name:  "discover-only",
+				Usage: "Only save the manifest of discovered objects, without exporting them",
 			},
-			&cli.BoolFlag{
-				Name:    "rules-as-hcl",
-				Aliases: []string{"schema"},
-				Usage:   "Referenced rules will be exported as data source",
+			&cli.StringFlag{
+				Name:        "import-style",
+				Usage:       "Format of generated imports passed to every export: 'script' or 'blocks'",
+				DefaultText: "script",
 			},
-			&cli.BoolFlag{
-				Name:  "akamai-property-bootstrap",
-				Usage: "Referenced property will be exported using combination of 'akamai-property-bootstrap' and 'akamai-property' resources",
+			&cli.StringFlag{
+				Name:        "on-conflict",
+				Usage:       "Action taken by every export when a generated file already exists: 'fail', 'overwrite', 'backup' or 'merge'",
+				DefaultText: "fail",
 			},
+			progressFlag(),
 		},
 		BashComplete: autocomplete.Default,
 	})
 
+	commands = append(commands, newDiscoveryCommands()...)
+
 	commands = append(commands, &cli.Command{
-		Name:        "export-property-include",
-		Description: "Generates Terraform configuration for Include resources",
-		Usage:       "export-property-include",
-		ArgsUsage:   "<contract_id> <include_name>",
-		Action:      validatedAction(papi.CmdCreateInclude, requireValidWorkpath, requireNArguments(2)),
+		Name:        "drift",
+		Description: "Compares previously exported configuration with the live one",
+		Usage:       "drift",
+		ArgsUsage:   "<export command> [command flags] [arguments...]",
+		Action:      validatedAction(cmdDrift, requireValidWorkpath, requireExportCommand),
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
+				Usage:       "Directory with previously exported configuration.",
 				DefaultText: "current directory",
 			},
 			&cli.BoolFlag{
-				Name:    "rules-as-hcl",
-				Aliases: []string{"schema"},
-				Usage:   "Referenced rules will be exported as data source",
+				Name:  "json",
+				Usage: "Print detected changes in JSON format",
 			},
 		},
 		BashComplete: autocomplete.Default,
 	})
 
 	commands = append(commands, &cli.Command{
-		Name:        "export-property-include-rule",
-		Description: "Generates Terraform configuration for a single Include rule",
-		Usage:       "export-property-include-rule",
-		ArgsUsage:   "<contract_id> <include_name> <rule_name>",
-		Action:      validatedAction(papi.CmdCreateIncludeRule, requireValidWorkpath, requireNArguments(3)),
+		Name:        "link",
+		Description: "Replaces literal IDs in exported terraform files with references to resources exported into the same directory. Files in terraform JSON syntax (.tf.json), exported with --format json, are not linked and are listed in a warning",
+		Usage:       "link",
+		Action:      validatedAction(cmdLink, requireValidWorkpath, requireNArguments(0)),
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
+				Usage:       "Directory with exported configuration, subdirectories are linked as separate modules.",
 				DefaultText: "current directory",
 			},
 			&cli.BoolFlag{
-				Name:    "rules-as-hcl",
-				Aliases: []string{"schema"},
-				Usage:   "Referenced rules will be exported as data source",
+				Name:  "dry-run",
+				Usage: "Do not change any files, only list the literal IDs which would be replaced",
 			},
 		},
 		BashComplete: autocomplete.Default,
 	})
 
 	commands = append(commands, &cli.Command{
-		Name:        "export-cloudwrapper",
-		Description: "Generates Terraform configuration for CloudWrapper resources",
-		Usage:       "export-cloudwrapper",
-		ArgsUsage:   "<config_id>",
-		Action:      validatedAction(cloudwrapper.CmdCreateCloudWrapper, requireValidWorkpath, requireNArguments(1)),
+		Name:        "list-templates",
+		Description: "Lists templates used by the export command with the types of data passed to them or saves them into a directory",
+		Usage:       "list-templates",
+		ArgsUsage:   "<export command>",
+		Action:      validatedAction(cmdListTemplates, requireExportCommand),
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
-				DefaultText: "current directory",
+				Name:  "dir",
+				Usage: "Directory into which templates are saved, to be customized and passed with --template-dir flag",
 			},
 		},
 		BashComplete: autocomplete.Default,
 	})
 
 	commands = append(commands, &cli.Command{
-		Name:        "export-cloudlets-policy",
-		Aliases:     []string{"create-cloudlets-policy"},
-		Description: "Generates Terraform configuration for Cloudlets Policy resources",
-		Usage:       "export-cloudlets-policy",
-		ArgsUsage:   "<policy_name>",
-		Action:      validatedAction(cloudlets.CmdCreatePolicy, requireValidWorkpath, requireNArguments(1)),
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
-				DefaultText: "current directory",
-			},
-		},
-		BashComplete: autocomplete.Default,
+		Name:         "completion",
+		Description:  "Prints script completing commands, flags and names of exported objects in bash, zsh or fish",
+		Usage:        "completion",
+		ArgsUsage:    "<bash|zsh|fish>",
+		Action:       validatedAction(cmdCompletion, requireNArguments(1)),
+		BashComplete: completeCompletion,
 	})
 
 	commands = append(commands, &cli.Command{
-		Name:        "export-edgekv",
-		Aliases:     []string{"create-edgekv"},
-		Description: "Generates Terraform configuration for EdgeKV resources",
-		Usage:       "export-edgekv",
-		ArgsUsage:   "<namespace_name> <network>",
-		Action:      validatedAction(edgeworkers.CmdCreateEdgeKV, requireValidWorkpath, requireNArguments(2)),
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
-				DefaultText: "current directory",
-			},
-		},
-		BashComplete: autocomplete.Default,
+		Name:        "docs",
+		Description: "Prints reference of export commands in Markdown",
+		Usage:       "docs",
+		Action:      validatedAction(cmdDocs, requireNArguments(0)),
+		Hidden:      true,
 	})
 
 	commands = append(commands, &cli.Command{
-		Name:        "export-edgeworker",
-		Aliases:     []string{"create-edgeworker"},
-		Description: "Generates Terraform configuration for EdgeWorker resources",
-		Usage:       "export-edgeworker",
-		ArgsUsage:   "<edgeworker_id>",
-		Action:      validatedAction(edgeworkers.CmdCreateEdgeWorker, requireValidWorkpath, requireNArguments(1)),
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:  "bundlepath",
-				Usage: "Path location for placement of EdgeWorkers tgz code bundle. Default: same value as tfworkpath",
-			},
-			&cli.StringFlag{
-				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
-				DefaultText: "current directory",
-			},
-		},
-		BashComplete: autocomplete.Default,
+		Name:               "list",
+		Description:        "List commands",
+		Action:             cmdList,
+		CustomHelpTemplate: apphelp.SimplifiedHelpTemplate,
 	})
 
-	commands = append(commands, &cli.Command{
-		Name:            "export-iam",
-		Aliases:         []string{"create-iam"},
-		Description:     "Generates Terraform configuration for Identity and Access Management resources",
-		Usage:           "export-iam",
-		HideHelpCommand: true,
-		Action:          validatedAction(iam.CmdCreateIAM, requireValidWorkpath, validateSubCommands),
-		Subcommands: []*cli.Command{
-			{
-				Name:        "all",
-				Description: "Exports all available Terraform Users, Groups and Roles",
-				Action:      validatedAction(iam.CmdCreateIAMAll, requireValidWorkpath),
-			},
-			{
-				Name:        "group",
-				Description: "Exports Terraform Group resource with relevant users and roles resources",
-				ArgsUsage:   "<group_id>",
-				Action:      validatedAction(iam.CmdCreateIAMGroup, requireValidWorkpath, requireNArguments(1)),
-			},
-			{
-				Name:        "role",
-				Description: "Exports Terraform Role resource with relevant users and groups resources",
-				ArgsUsage:   "<role_id>",
-				Action:      validatedAction(iam.CmdCreateIAMRole, requireValidWorkpath, requireNArguments(1)),
-			},
-			{
-				Name:        "user",
-				Description: "Exports Terraform User resource with relevant groups and roles resources",
-				ArgsUsage:   "<user_email>",
-				Action:      validatedAction(iam.CmdCreateIAMUser, requireValidWorkpath, requireNArguments(1)),
-			},
-		},
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
-				DefaultText: "current directory",
-			},
-		},
-		BashComplete: autocomplete.Default,
-	})
+	// export commands already apply the config file before setting up their output
+	for _, command := range commands[exportCount:] {
+		command.Before = applyConfig(command, withRegistry(registry, command.Before))
+	}
 
-	commands = append(commands, &cli.Command{
-		Name:        "export-imaging",
-		Aliases:     []string{"create-imaging"},
-		Description: "Generates Terraform configuration for Image and Video Manager resources",
-		Usage:       "export-imaging",
-		ArgsUsage:   "<contract_id> <policy_set_id>",
-		Action:      validatedAction(imaging.CmdCreateImaging, requireValidWorkpath, requireNArguments(2)),
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:  "policy-json-dir",
-				Usage: "Path location for placement of policy jsons. Default: same value as tfworkpath",
-			},
-			&cli.StringFlag{
-				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
-				DefaultText: "current directory",
-			},
-			&cli.BoolFlag{
-				Name:        "policy-as-hcl",
-				Aliases:     []string{"schema"},
-				Usage:       "Generate content of the policy using HCL instead of JSON file",
-				Destination: &tools.PolicyAsHCL,
-			},
-		},
-		BashComplete: autocomplete.Default,
-	})
+	if err := checkCommandNames(commands); err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
 
-	commands = append(commands, &cli.Command{
-		Name:        "export-cps",
-		Aliases:     []string{"create-cps"},
-		Description: "Generates Terraform configuration for CPS (Certificate Provisioning System) resources",
-		Usage:       "export-cps",
-		ArgsUsage:   "<enrollment_id> <contract_id>",
-		Action:      validatedAction(cps.CmdCreateCPS, requireValidWorkpath, requireNArguments(2)),
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:        "tfworkpath",
-				Usage:       "Directory used to store files created when running commands.",
-				DefaultText: "current directory",
-			},
-		},
-		BashComplete: autocomplete.Default,
-	})
+// builtinExporters returns exporters of the products supported by the cli, in the order of their commands
+func builtinExporters() []exporter.Exporter {
+	return []exporter.Exporter{
+		gtm.Exporter{},
+		dns.Exporter{},
+		appsec.Exporter{},
+		clientlists.Exporter{},
+		papi.PropertyExporter{},
+		papi.IncludeExporter{},
+		papi.IncludeRuleExporter{},
+		cloudwrapper.Exporter{},
+		cloudlets.Exporter{},
+		edgeworkers.EdgeKVExporter{},
+		edgeworkers.EdgeWorkerExporter{},
+		iam.Exporter{},
+		imaging.Exporter{},
+		cps.Exporter{},
+		cloudaccess.Exporter{},
+	}
+}
 
-	commands = append(commands, &cli.Command{
-		Name:        "export-cloudaccess",
-		Description: "Generates Terraform configuration for CAM (Cloud Access Manager) resources",
-		Usage:       "export-cloudaccess",
-		ArgsUsage:   "<access_key_uid>",
-		Action:      validatedAction(cloudaccess.CmdCreateCloudAccess, requireValidWorkpath, requireNArguments(1)),
-		Flags: []cli.Flag{
+// builtinRegistry returns registry of the built-in exporters
+func builtinRegistry() *exporter.Registry {
+	registry, err := exporter.NewRegistry(builtinExporters()...)
+	if err != nil {
+		// names of built-in exporters are unique
+		panic(err)
+	}
+	return registry
+}
+
+// withRegistry puts the registry into the context of the command before running the given BeforeFunc
+func withRegistry(registry *exporter.Registry, before cli.BeforeFunc) cli.BeforeFunc {
+	return func(c *cli.Context) error {
+		c.Context = context.WithValue(c.Context, registryContextKey{}, registry)
+		if before != nil {
+			return before(c)
+		}
+		return nil
+	}
+}
+
+// getRegistry returns the registry of exporters from the context, registry of the built-in exporters if it is not set
+func getRegistry(ctx context.Context) *exporter.Registry {
+	if registry, ok := ctx.Value(registryContextKey{}).(*exporter.Registry); ok {
+		return registry
+	}
+	return builtinRegistry()
+}
+
+// checkCommandNames returns an error if a name or an alias is used by more than one command,
+// e.g. when an extra exporter is named after one of the other commands
+func checkCommandNames(commands []*cli.Command) error {
+	names := make(map[string]struct{})
+	for _, command := range commands {
+		for _, name := range command.Names() {
+			if _, ok := names[name]; ok {
+				return fmt.Errorf("%w: '%s'", exporter.ErrDuplicateExporter, name)
+			}
+			names[name] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// exportCommands returns export commands of the exporters from the registry
+func exportCommands(registry *exporter.Registry) []*cli.Command {
+	var commands []*cli.Command
+	for _, e := range registry.Exporters() {
+		command := exportCommand(e, false)
+		command.Usage = command.Name
+		command.Flags = append(append([]cli.Flag{
 			&cli.StringFlag{
 				Name:        "tfworkpath",
 				Usage:       "Directory used to store files created when running commands.",
 				DefaultText: "current directory",
 			},
-		},
-		BashComplete: autocomplete.Default,
-	})
+		}, command.Flags...), exportFlags()...)
+		command.Before = applyConfig(command, setupOutput)
+		command.After = closeOutput
+		commands = append(commands, command)
+	}
+	return commands
+}
 
-	commands = append(commands, &cli.Command{
-		Name:               "list",
-		Description:        "List commands",
-		Action:             cmdList,
-		CustomHelpTemplate: apphelp.SimplifiedHelpTemplate,
-	})
+// exportCommand returns command running the exporter with subcommands running its subcommand exporters
+// Concurrency is validated if the command or any of its parents has concurrency flag
+func exportCommand(e exporter.Exporter, concurrency bool) *cli.Command {
+	spec := e.Spec()
+	command := &cli.Command{
+		Name:         spec.Name,
+		Aliases:      spec.Aliases,
+		Description:  spec.Description,
+		Flags:        spec.Flags,
+		BashComplete: completeExport(spec),
+	}
+	if len(spec.Args) > 0 {
+		command.ArgsUsage = fmt.Sprintf("<%s>", strings.Join(spec.Args, "> <"))
+	}
 
-	return commands, nil
+	validators := []actionValidator{requireValidWorkpath}
+	if concurrency = concurrency || hasFlag(command, "concurrency"); concurrency {
+		validators = append(validators, requirePositiveConcurrency)
+	}
+	if len(spec.Subcommands) > 0 && len(spec.Args) == 0 {
+		command.HideHelpCommand = true
+		validators = append(validators, validateSubCommands)
+	} else {
+		validators = append(validators, requireNArguments(len(spec.Args)))
+	}
+	command.Action = validatedAction(exportAction(e), validators...)
+
+	for _, sub := range spec.Subcommands {
+		command.Subcommands = append(command.Subcommands, exportCommand(sub, concurrency))
+	}
+	return command
+}
+
+// exportAction returns action running the exporter with the flags and arguments of the command
+func exportAction(e exporter.Exporter) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		export, err := exporter.NewExport(c, e.Spec())
+		if err != nil {
+			return cli.Exit(color.RedString(err.Error()), 1)
+		}
+		err = e.Export(c.Context, export)
+		var exitErr cli.ExitCoder
+		if err != nil && !errors.As(err, &exitErr) {
+			return cli.Exit(color.RedString(err.Error()), 1)
+		}
+		return err
+	}
+}
+
+// progressFlag returns the flag selecting how steps of the command are reported, see setupProgress
+func progressFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:        "progress",
+		Usage:       "Progress reporting: 'spinner' animates steps in the terminal, 'plain' prints a line when a step starts and ends, 'json' prints a JSON event for every step to standard error",
+		DefaultText: "spinner",
+	}
+}
+
+// exportFlags returns flags which are common for all export commands
+func exportFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "import-style",
+			Usage:       "Format of generated imports: 'script' creates a shell script with 'terraform import' commands, 'blocks' creates terraform 'import' blocks (requires terraform 1.5 or later)",
+			DefaultText: "script",
+		},
+		&cli.StringFlag{
+			Name:        "engine",
+			Usage:       "Tool used to apply generated configuration: 'terraform' or 'opentofu', decides the registry of the provider in versions.tf and the commands in import scripts",
+			DefaultText: "terraform",
+		},
+		&cli.StringFlag{
+			Name:        "provider-version",
+			Usage:       "Version constraint of the akamai provider written into versions.tf, e.g. '~> 6.4'",
+			DefaultText: "minimal version required by the exporter",
+		},
+		&cli.StringFlag{
+			Name:        "required-version",
+			Usage:       "Version constraint of terraform or OpenTofu written into versions.tf",
+			DefaultText: templates.DefaultRequiredVersion,
+		},
+		&cli.StringFlag{
+			Name:  "backend",
+			Usage: "Type of terraform backend written into backend.tf together with bootstrap.sh initializing it: 'local', 's3', 'gcs', 'azurerm', 'http' or 'pg'",
+		},
+		&cli.StringSliceFlag{
+			Name:  "backend-config",
+			Usage: "Argument of the backend block in key=value format, can be repeated, e.g. --backend-config bucket=tfstate --backend-config key=akamai.tfstate",
+		},
+		&cli.StringFlag{
+			Name:        "format",
+			Usage:       "Syntax of generated terraform files: 'hcl' creates *.tf files, 'json' creates *.tf.json files in terraform JSON syntax",
+			DefaultText: "hcl",
+		},
+		&cli.StringFlag{
+			Name:        "output-mode",
+			Usage:       "Destination of generated files: 'disk' writes them into tfworkpath, 'stdout' prints them to standard output, 'tar' and 'zip' create an archive",
+			DefaultText: "disk",
+		},
+		&cli.StringFlag{
+			Name:        "output-file",
+			Usage:       "Path of the archive created with 'tar' and 'zip' output modes",
+			DefaultText: "standard output",
+		},
+		&cli.StringFlag{
+			Name:        "on-conflict",
+			Usage:       "Action taken when a generated file already exists: 'fail' stops the export, 'overwrite' replaces the file, 'backup' renames it with a timestamp suffix, 'merge' merges generated blocks into it",
+			DefaultText: "fail",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Do not write any files, only list the files which would be generated",
+		},
+		&cli.StringFlag{
+			Name:    "template-dir",
+			Usage:   "Directory with custom templates replacing the embedded ones of the same file name, see list-templates command",
+			EnvVars: []string{templates.TemplateDirEnv},
+		},
+		&cli.StringFlag{
+			Name:        "naming",
+			Usage:       "Style of generated resource, data source and module names: 'default' keeps the names chosen by the exporter, 'snake' uses snake_case, 'kebab' uses kebab-case",
+			DefaultText: "default",
+		},
+		&cli.StringFlag{
+			Name:  "name-prefix",
+			Usage: "Prefix added to generated resource, data source and module names",
+		},
+		&cli.StringFlag{
+			Name:  "name-suffix",
+			Usage: "Suffix added to generated resource, data source and module names",
+		},
+		&cli.StringFlag{
+			Name:        "name-collision",
+			Usage:       "Action taken when two blocks get the same name within a module: 'suffix' appends a number, 'hash' appends a hash of the original name, 'fail' stops the export",
+			DefaultText: "suffix",
+		},
+		progressFlag(),
+		&cli.BoolFlag{
+			Name:  "report",
+			Usage: "Write export-report.json with fetched objects, generated files, resources with their import IDs and warnings into tfworkpath",
+		},
+	}
 }
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/session"
+	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+type testExporter struct {
+	spec   exporter.Spec
+	export func(context.Context, *exporter.Export) error
+}
+
+func (e testExporter) Spec() exporter.Spec {
+	return e.spec
+}
+
+func (e testExporter) Export(ctx context.Context, export *exporter.Export) error {
+	if e.export == nil {
+		return nil
+	}
+	return e.export(ctx, export)
+}
+
+func TestCommandLocator(t *testing.T) {
+	tests := map[string]struct {
+		extra     []exporter.Exporter
+		withError error
+	}{
+		"built-in exporters": {},
+		"extra exporter": {
+			extra: []exporter.Exporter{testExporter{spec: exporter.Spec{Name: "export-test", Aliases: []string{"create-test"}}}},
+		},
+		"extra exporter named after built-in one": {
+			extra:     []exporter.Exporter{testExporter{spec: exporter.Spec{Name: "export-test", Aliases: []string{"create-domain"}}}},
+			withError: exporter.ErrDuplicateExporter,
+		},
+		"extra exporter named after other command": {
+			extra:     []exporter.Exporter{testExporter{spec: exporter.Spec{Name: "drift"}}},
+			withError: exporter.ErrDuplicateExporter,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			commands, err := CommandLocator(test.extra...)
+			if test.withError != nil {
+				assert.ErrorIs(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+
+			exporters := append(builtinExporters(), test.extra...)
+			require.Greater(t, len(commands), len(exporters))
+			for i, e := range exporters {
+				assert.Equal(t, e.Spec().Name, commands[i].Name)
+				assert.Equal(t, e.Spec().Aliases, commands[i].Aliases)
+				assert.NotNil(t, commands[i].Before)
+				assert.NotNil(t, commands[i].After)
+				assert.NotNil(t, commands[i].BashComplete)
+			}
+			assert.Equal(t, "export-batch", commands[len(exporters)].Name)
+		})
+	}
+}
+
+func TestExportCommand(t *testing.T) {
+	commands := exportCommands(builtinRegistry())
+
+	cps := findCommand(commands, "export-cps")
+	require.NotNil(t, cps)
+	assert.Equal(t, "export-cps", cps.Usage)
+	assert.Equal(t, "<enrollment_id> <contract_id>", cps.ArgsUsage)
+	assert.Equal(t, "tfworkpath", cps.Flags[0].Names()[0])
+	assert.True(t, hasFlag(cps, "secrets"))
+	for _, flag := range exportFlags() {
+		assert.True(t, hasFlag(cps, flag.Names()[0]), flag.Names()[0])
+	}
+
+	iam := findCommand(commands, "create-iam")
+	require.NotNil(t, iam)
+	assert.True(t, iam.HideHelpCommand)
+	assert.Empty(t, iam.ArgsUsage)
+	var names []string
+	for _, sub := range iam.Subcommands {
+		names = append(names, sub.Name)
+		assert.Empty(t, sub.Flags)
+	}
+	assert.Equal(t, []string{"all", "group", "role", "user"}, names)
+	assert.Equal(t, "<group_id>", findCommand(iam.Subcommands, "group").ArgsUsage)
+
+	include := findCommand(findCommand(commands, "export-property").Subcommands, "include")
+	require.NotNil(t, include)
+	assert.Equal(t, "<contract_id> <include_name>", include.ArgsUsage)
+}
+
+func TestExportAction(t *testing.T) {
+	var export *exporter.Export
+	e := testExporter{
+		spec: exporter.Spec{
+			Name: "export-test",
+			Args: []string{"id"},
+			Flags: []cli.Flag{
+				&cli.BoolFlag{Name: "with-children"},
+			},
+			Subcommands: []exporter.Exporter{testExporter{
+				spec: exporter.Spec{Name: "child", Args: []string{"child_id"}},
+				export: func(_ context.Context, e *exporter.Export) error {
+					export = e
+					return errors.New("child failed")
+				},
+			}},
+		},
+		export: func(_ context.Context, e *exporter.Export) error {
+			export = e
+			return e.Output.WriteFile(e.Path("test.tf"), []byte("resource \"a\" \"b\" {}\n"))
+		},
+	}
+	registry, err := exporter.NewRegistry(e)
+	require.NoError(t, err)
+	tfWorkPath := t.TempDir()
+	sess, err := session.New()
+	require.NoError(t, err)
+	sink := templates.NewMemorySink()
+	ctx := terminal.Context(context.Background(), terminal.New(terminal.DiscardWriter(), nil, terminal.DiscardWriter()))
+	ctx = templates.WithOutput(edgegrid.WithSession(ctx, sess), sink)
+
+	command := exportCommands(registry)[0]
+	// output is taken from the context
+	command.Before, command.After = nil, nil
+
+	err = runExporter(ctx, command, []string{"export-test", "--tfworkpath", tfWorkPath, "--with-children", "123"}, []string{"--section", "test"})
+	require.NoError(t, err)
+	require.NotNil(t, export)
+	assert.Equal(t, []string{"123"}, export.Args)
+	assert.Equal(t, tfWorkPath, export.WorkPath)
+	assert.Equal(t, "test", export.Section)
+	assert.True(t, export.Flags.Bool("with-children"))
+	assert.Equal(t, []string{filepath.Join(tfWorkPath, "test.tf")}, sink.Files())
+
+	export = nil
+	err = runExporter(ctx, command, []string{"export-test", "--with-children", "child", "456"}, nil)
+	assert.ErrorContains(t, err, "child failed")
+	require.NotNil(t, export)
+	assert.Equal(t, "child", export.Spec.Name)
+	assert.Equal(t, []string{"456"}, export.Args)
+	assert.True(t, export.Flags.Bool("with-children"))
+
+	err = runExporter(ctx, command, []string{"export-test", "1", "2"}, nil)
+	assert.ErrorIs(t, err, ErrExporter)
+}
@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli/pkg/autocomplete"
+	"github.com/akamai/cli/pkg/log"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+type (
+	// completionCache keeps values of completed arguments in files of its directory for the time of its ttl
+	completionCache struct {
+		dir string
+		ttl time.Duration
+		now func() time.Time
+	}
+
+	// completionCacheEntry is the content of a file of the completion cache
+	completionCacheEntry struct {
+		Time   time.Time `json:"time"`
+		Values []string  `json:"values"`
+	}
+)
+
+const (
+	// CompletionTTLEnv is the environment variable with the time values of completed arguments are cached for,
+	// e.g. '1h'; '0' disables the cache
+	CompletionTTLEnv = "AKAMAI_TERRAFORM_COMPLETION_TTL"
+
+	// completionFlag is appended to the command line by shell completion scripts
+	completionFlag = "--generate-bash-completion"
+
+	defaultCompletionTTL = 5 * time.Minute
+	completionTimeout    = 10 * time.Second
+)
+
+var (
+	// ErrUnknownShell is returned when completion script of an unsupported shell is requested
+	ErrUnknownShell = errors.New("unknown shell")
+
+	// completionScripts are scripts registering completion of akamai-terraform and akamai commands by shell name
+	completionScripts = map[string]string{
+		"bash": `# bash completion of akamai terraform, add to ~/.bashrc:
+#   eval "$(akamai terraform completion bash)"
+_akamai_terraform_complete() {
+	local cur opts
+	COMPREPLY=()
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	opts=$( "${COMP_WORDS[@]:0:$COMP_CWORD}" ` + completionFlag + ` 2>/dev/null )
+	COMPREPLY=( $(compgen -W "${opts}" -- "${cur}") )
+	return 0
+}
+complete -o bashdefault -o default -F _akamai_terraform_complete akamai-terraform akamai
+`,
+		"zsh": `# zsh completion of akamai terraform, add to ~/.zshrc after compinit:
+#   eval "$(akamai terraform completion zsh)"
+_akamai_terraform_complete() {
+	local -a opts
+	opts=("${(@f)$(${words[1,CURRENT-1]} ` + completionFlag + ` 2>/dev/null)}")
+	if [[ -n "${opts[1]}" ]]; then
+		compadd -a opts
+	else
+		_files
+	fi
+}
+compdef _akamai_terraform_complete akamai-terraform akamai
+`,
+		"fish": `# fish completion of akamai terraform, save to ~/.config/fish/completions/akamai-terraform.fish:
+#   akamai terraform completion fish > ~/.config/fish/completions/akamai-terraform.fish
+function __akamai_terraform_complete
+	set -l tokens (commandline -opc)
+	set -l values (command $tokens ` + completionFlag + ` 2>/dev/null)
+	if test (count $values) -gt 0
+		printf '%s\n' $values
+	else
+		__fish_complete_path (commandline -ct)
+	end
+end
+complete -c akamai-terraform -f -a '(__akamai_terraform_complete)'
+complete -c akamai -f -a '(__akamai_terraform_complete)'
+`,
+	}
+)
+
+// IsShellCompletion returns true if the command line is run by shell completion to list completion candidates
+func IsShellCompletion(args []string) bool {
+	return len(args) > 0 && args[len(args)-1] == completionFlag
+}
+
+func cmdCompletion(c *cli.Context) error {
+	shell := c.Args().First()
+	script, ok := completionScripts[shell]
+	if !ok {
+		return cli.Exit(color.RedString("%s: '%s', expected one of: %s", ErrUnknownShell, shell, strings.Join(completionShells(), ", ")), 1)
+	}
+	_, err := fmt.Fprint(c.App.Writer, script)
+	return err
+}
+
+// completeCompletion completes the shell argument of completion command
+func completeCompletion(c *cli.Context) {
+	if c.NArg() == 0 {
+		for _, shell := range completionShells() {
+			fmt.Fprintln(c.App.Writer, shell)
+		}
+	}
+}
+
+func completionShells() []string {
+	shells := make([]string, 0, len(completionScripts))
+	for shell := range completionScripts {
+		shells = append(shells, shell)
+	}
+	sort.Strings(shells)
+	return shells
+}
+
+// completeExport returns completion of the export command printing values of its next argument, completed
+// by the exporter, followed by its subcommands and flags
+// Nothing is printed after a flag expecting a value, so that the shell completes file names
+func completeExport(spec exporter.Spec) cli.BashCompleteFunc {
+	return func(c *cli.Context) {
+		flags := append(append([]cli.Flag{}, c.Command.Flags...), c.App.Flags...)
+		if expectsFlagValue(flags, os.Args) {
+			return
+		}
+		if spec.Complete != nil && c.NArg() < len(spec.Args) {
+			values, err := completeArg(c, spec)
+			if err != nil {
+				log.FromContext(c.Context).Debugf("completing %s: %s", spec.Name, err)
+			}
+			for _, value := range values {
+				fmt.Fprintln(c.App.Writer, value)
+			}
+		}
+		autocomplete.Default(c)
+	}
+}
+
+// completeArg returns values of the next argument of the export command, from the cache if they were already fetched
+func completeArg(c *cli.Context, spec exporter.Spec) ([]string, error) {
+	cache, err := newCompletionCache()
+	if err != nil {
+		return nil, err
+	}
+	key := completionKey(edgegrid.GetEdgercPath(c), edgegrid.GetEdgercSection(c), c.String("accountkey"), spec.Name, c.Args().Slice())
+	if values, ok := cache.get(key); ok {
+		return values, nil
+	}
+
+	sess, err := edgegrid.InitializeSession(c)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(c.Context, completionTimeout)
+	defer cancel()
+	values, err := spec.Complete(ctx, &exporter.Export{
+		Spec:    spec,
+		Args:    c.Args().Slice(),
+		Flags:   c,
+		Session: sess,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(values)
+	return values, cache.put(key, values)
+}
+
+// expectsFlagValue returns true if the last argument before the completion flag is a flag which takes a value
+func expectsFlagValue(flags []cli.Flag, args []string) bool {
+	if len(args) < 2 || !IsShellCompletion(args) {
+		return false
+	}
+	last := args[len(args)-2]
+	if !strings.HasPrefix(last, "-") || strings.Contains(last, "=") {
+		return false
+	}
+	name := strings.TrimLeft(last, "-")
+	for _, flag := range flags {
+		for _, flagName := range flag.Names() {
+			if flagName == name {
+				_, isBool := flag.(*cli.BoolFlag)
+				return !isBool
+			}
+		}
+	}
+	return false
+}
+
+// completionKey returns the name of the cache file of values completed with the given credentials, command and arguments
+func completionKey(edgercPath, section, accountKey, command string, args []string) string {
+	hash := sha256.New()
+	for _, part := range append([]string{edgercPath, section, accountKey, command}, args...) {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// newCompletionCache returns cache in the user cache directory with ttl given by CompletionTTLEnv
+func newCompletionCache() (*completionCache, error) {
+	ttl := defaultCompletionTTL
+	if value, ok := os.LookupEnv(CompletionTTLEnv); ok {
+		var err error
+		if ttl, err = time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", CompletionTTLEnv, err)
+		}
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &completionCache{dir: filepath.Join(dir, "akamai-terraform", "completion"), ttl: ttl, now: time.Now}, nil
+}
+
+// get returns values stored under the key, if they are not older than the ttl of the cache
+func (c *completionCache) get(key string) ([]string, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	content, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var entry completionCacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil || c.now().Sub(entry.Time) > c.ttl {
+		return nil, false
+	}
+	return entry.Values, true
+}
+
+// put stores values under the key, the file is replaced at once, so that concurrent completions read whole entries
+func (c *completionCache) put(key string, values []string) error {
+	if c.ttl <= 0 {
+		return nil
+	}
+	content, err := json.Marshal(completionCacheEntry{Time: c.now(), Values: values})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(c.dir, key+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), filepath.Join(c.dir, key+".json"))
+}
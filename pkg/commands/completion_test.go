@@ -0,0 +1,198 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCompleteExport(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	edgerc := "[default]\nhost = test.luna.akamaiapis.net\nclient_token = token\nclient_secret = secret\naccess_token = token\n"
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".edgerc"), []byte(edgerc), 0600))
+
+	tests := map[string]struct {
+		args      []string
+		ttl       string
+		values    []string
+		err       error
+		expected  []string
+		completed []string
+	}{
+		"first argument": {
+			values:    []string{"b", "a"},
+			expected:  []string{"a", "b", "--with-children"},
+			completed: []string{},
+		},
+		"second argument": {
+			args:      []string{"a"},
+			values:    []string{"x"},
+			expected:  []string{"x", "--with-children"},
+			completed: []string{"a"},
+		},
+		"all arguments given": {
+			args:     []string{"a", "x"},
+			expected: []string{"--with-children"},
+		},
+		"completion failed": {
+			args:      []string{"b"},
+			err:       errors.New("oops"),
+			expected:  []string{"--with-children"},
+			completed: []string{"b"},
+		},
+		"cache disabled": {
+			ttl:       "0",
+			values:    []string{"c"},
+			expected:  []string{"c", "--with-children"},
+			completed: []string{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.ttl != "" {
+				t.Setenv(CompletionTTLEnv, test.ttl)
+			}
+			var completed [][]string
+			e := testExporter{spec: exporter.Spec{
+				Name:  "export-" + strings.ReplaceAll(name, " ", "-"),
+				Args:  []string{"id", "child_id"},
+				Flags: []cli.Flag{&cli.BoolFlag{Name: "with-children"}},
+				Complete: func(_ context.Context, e *exporter.Export) ([]string, error) {
+					assert.NotNil(t, e.Session)
+					completed = append(completed, e.Args)
+					return append([]string{}, test.values...), test.err
+				},
+			}}
+
+			// values are fetched once and taken from the cache afterwards, unless it is disabled
+			for i := 0; i < 2; i++ {
+				out := runCompletion(t, exportCommand(e, false), test.args)
+				assert.Equal(t, test.expected, out[:len(test.expected)])
+			}
+			switch {
+			case test.completed == nil:
+				assert.Empty(t, completed)
+			case test.ttl == "0" || test.err != nil:
+				assert.Equal(t, [][]string{test.completed, test.completed}, completed)
+			default:
+				assert.Equal(t, [][]string{test.completed}, completed)
+			}
+		})
+	}
+}
+
+func TestCmdCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		t.Run(shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			app := cli.NewApp()
+			app.Writer = &buf
+			app.Commands = []*cli.Command{{Name: "completion", Action: cmdCompletion}}
+			require.NoError(t, app.Run([]string{"test", "completion", shell}))
+			assert.Contains(t, buf.String(), "akamai-terraform")
+			assert.Contains(t, buf.String(), completionFlag)
+		})
+	}
+
+	app := cli.NewApp()
+	app.Commands = []*cli.Command{{Name: "completion", Action: cmdCompletion}}
+	app.ExitErrHandler = func(*cli.Context, error) {}
+	err := app.Run([]string{"test", "completion", "tcsh"})
+	assert.ErrorContains(t, err, "unknown shell: 'tcsh', expected one of: bash, fish, zsh")
+}
+
+func TestExpectsFlagValue(t *testing.T) {
+	flags := []cli.Flag{
+		&cli.StringFlag{Name: "tfworkpath"},
+		&cli.BoolFlag{Name: "rules-as-hcl", Aliases: []string{"schema"}},
+		&cli.IntFlag{Name: "concurrency"},
+	}
+
+	tests := map[string]struct {
+		args     []string
+		expected bool
+	}{
+		"string flag":          {args: []string{"cmd", "--tfworkpath", completionFlag}, expected: true},
+		"int flag":             {args: []string{"cmd", "-concurrency", completionFlag}, expected: true},
+		"bool flag":            {args: []string{"cmd", "--rules-as-hcl", completionFlag}},
+		"bool flag alias":      {args: []string{"cmd", "--schema", completionFlag}},
+		"flag with value":      {args: []string{"cmd", "--tfworkpath=dir", completionFlag}},
+		"unknown flag":         {args: []string{"cmd", "--unknown", completionFlag}},
+		"argument":             {args: []string{"cmd", "--tfworkpath", "dir", completionFlag}},
+		"no completion":        {args: []string{"cmd", "--tfworkpath"}},
+		"completion flag only": {args: []string{completionFlag}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, expectsFlagValue(flags, test.args))
+		})
+	}
+}
+
+func TestCompletionCache(t *testing.T) {
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	cache := &completionCache{dir: filepath.Join(t.TempDir(), "completion"), ttl: time.Minute, now: func() time.Time { return now }}
+
+	_, ok := cache.get("key")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.put("key", []string{"a", "b"}))
+	values, ok := cache.get("key")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "b"}, values)
+	_, ok = cache.get("other")
+	assert.False(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = cache.get("key")
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(filepath.Join(cache.dir, "broken.json"), []byte("{"), 0600))
+	_, ok = cache.get("broken")
+	assert.False(t, ok)
+
+	entries, err := os.ReadDir(cache.dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "temporary files are removed")
+
+	disabled := &completionCache{dir: filepath.Join(t.TempDir(), "disabled"), now: time.Now}
+	require.NoError(t, disabled.put("key", []string{"a"}))
+	_, ok = disabled.get("key")
+	assert.False(t, ok)
+	assert.NoDirExists(t, disabled.dir)
+}
+
+func TestCompletionKey(t *testing.T) {
+	key := completionKey("~/.edgerc", "default", "", "export-test", []string{"a"})
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, completionKey("~/.edgerc", "default", "", "export-test", []string{"a"}))
+	assert.NotEqual(t, key, completionKey("~/.edgerc", "other", "", "export-test", []string{"a"}))
+	assert.NotEqual(t, key, completionKey("~/.edgerc", "default", "key", "export-test", []string{"a"}))
+	assert.NotEqual(t, key, completionKey("~/.edgerc", "default", "", "export-test", []string{"b"}))
+	assert.NotEqual(t, completionKey("", "", "", "ab", nil), completionKey("", "", "", "a", []string{"b"}))
+}
+
+// runCompletion runs completion of the command with the given arguments and returns printed candidates
+func runCompletion(t *testing.T, command *cli.Command, args []string) []string {
+	var buf bytes.Buffer
+	app := cli.NewApp()
+	app.EnableBashCompletion = true
+	app.Writer = &buf
+	app.Commands = []*cli.Command{command}
+	require.NoError(t, app.Run(append(append([]string{"test", command.Name}, args...), completionFlag)))
+	return strings.Fields(buf.String())
+}
@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// projectConfig holds defaults of flags read from the config file
+	// Defaults apply to flags of the application and of every command, Commands to flags of the given command only
+	projectConfig struct {
+		Defaults map[string]interface{}
+		Commands map[string]map[string]interface{}
+	}
+
+	configContextKey struct{}
+)
+
+const (
+	// ConfigFileName is the name of the config file discovered in the working directory
+	ConfigFileName = ".akamai-terraform.yaml"
+	// ConfigEnv is the environment variable with the path of the config file, it can be used instead of --config flag
+	ConfigEnv = "AKAMAI_TERRAFORM_CONFIG"
+)
+
+// ErrInvalidConfig is returned when the config file cannot be read or is not valid
+var ErrInvalidConfig = errors.New("invalid config file")
+
+// ConfigFlag returns the global flag with the path of the config file
+func ConfigFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:        "config",
+		Usage:       "Config file with default values of global and command flags",
+		DefaultText: ConfigFileName + " in the working directory, if it exists",
+		EnvVars:     []string{ConfigEnv},
+	}
+}
+
+// SetupConfig reads the config file, applies its defaults to global flags which are not set
+// and stores it in the context, so that commands apply it to their flags
+// The precedence of values is: flag, environment variable, config file, default value of the flag
+func SetupConfig(c *cli.Context) error {
+	config, err := readConfig(c.String("config"), c.App.Flags, c.App.Commands)
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+	if err := config.apply(c, c.App.Flags); err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+	c.Context = context.WithValue(c.Context, configContextKey{}, config)
+	return nil
+}
+
+// applyConfig returns BeforeFunc applying defaults of the config file to flags of the command, which runs before afterwards
+// Subcommands of the command apply their sections of the config file the same way, before their own BeforeFunc
+func applyConfig(command *cli.Command, before cli.BeforeFunc) cli.BeforeFunc {
+	return applyConfigSection(command, command.Name, before)
+}
+
+// applyConfigSection returns BeforeFunc applying sections of the command named by its path, e.g. 'export-iam user',
+// and of its subcommand given by the arguments, so that the section of the subcommand sets flags of its parents too
+func applyConfigSection(command *cli.Command, name string, before cli.BeforeFunc) cli.BeforeFunc {
+	for _, sub := range command.Subcommands {
+		sub.Before = applyConfigSection(sub, name+" "+sub.Name, sub.Before)
+	}
+	return func(c *cli.Context) error {
+		if config, ok := c.Context.Value(configContextKey{}).(*projectConfig); ok {
+			sections := append([]string{name}, subcommandSections(command, name, c.Args().Slice())...)
+			if err := config.apply(c, command.Flags, sections...); err != nil {
+				return cli.Exit(color.RedString(err.Error()), 1)
+			}
+		}
+		if before == nil {
+			return nil
+		}
+		return before(c)
+	}
+}
+
+// subcommandSections returns paths of the subcommands of the command called with args, e.g. 'export-iam user'
+func subcommandSections(command *cli.Command, name string, args []string) []string {
+	var sections []string
+	for _, arg := range args {
+		if command = findCommand(command.Subcommands, arg); command == nil {
+			break
+		}
+		name += " " + command.Name
+		sections = append(sections, name)
+	}
+	return sections
+}
+
+// readConfig reads the config file from path or, if path is empty, from ConfigFileName in the working directory
+// Top level keys with a mapping value are defaults of the command with the same name or alias,
+// other keys are defaults of global flags or of flags of any command
+// Keys with a mapping value within the section of the command are defaults of its subcommand, e.g.
+// export-iam: {user: {...}}
+func readConfig(path string, globalFlags []cli.Flag, commands []*cli.Command) (*projectConfig, error) {
+	config := &projectConfig{}
+	if path == "" {
+		path = ConfigFileName
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return config, nil
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidConfig, err)
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("%w: '%s': %s", ErrInvalidConfig, path, err)
+	}
+
+	for _, name := range sortedKeys(values) {
+		value := values[name]
+		section, ok := value.(map[string]interface{})
+		if !ok {
+			if !knownFlag(name, globalFlags, commands) {
+				return nil, fmt.Errorf("%w: '%s': unknown flag '%s'", ErrInvalidConfig, path, name)
+			}
+			if config.Defaults == nil {
+				config.Defaults = make(map[string]interface{})
+			}
+			config.Defaults[name] = value
+			continue
+		}
+		command := findCommand(commands, name)
+		if command == nil {
+			return nil, fmt.Errorf("%w: '%s': unknown command '%s'", ErrInvalidConfig, path, name)
+		}
+		if err := config.addSection(command, command.Name, section, nil); err != nil {
+			return nil, fmt.Errorf("%w: '%s': %s", ErrInvalidConfig, path, err)
+		}
+	}
+	return config, nil
+}
+
+// addSection adds defaults of the command named by its path and, recursively, sections of its subcommands,
+// which can set flags of the command's parents too
+func (p *projectConfig) addSection(command *cli.Command, name string, section map[string]interface{}, parents []*cli.Command) error {
+	parents = append(append([]*cli.Command(nil), parents...), command)
+	values := make(map[string]interface{}, len(section))
+	for _, key := range sortedKeys(section) {
+		if subSection, ok := section[key].(map[string]interface{}); ok {
+			if sub := findCommand(command.Subcommands, key); sub != nil {
+				if err := p.addSection(sub, name+" "+sub.Name, subSection, parents); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !hasFlagOf(parents, key) {
+			return fmt.Errorf("unknown flag '%s' of command '%s'", key, name)
+		}
+		values[key] = section[key]
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if p.Commands == nil {
+		p.Commands = make(map[string]map[string]interface{})
+	}
+	p.Commands[name] = values
+	return nil
+}
+
+// hasFlagOf checks if any of the commands has the flag
+func hasFlagOf(commands []*cli.Command, name string) bool {
+	for _, command := range commands {
+		if hasFlag(command, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// knownFlag checks if the flag is defined globally or by any of the commands or their subcommands
+func knownFlag(name string, globalFlags []cli.Flag, commands []*cli.Command) bool {
+	if hasFlag(&cli.Command{Flags: globalFlags}, name) {
+		return true
+	}
+	for _, command := range commands {
+		if hasFlag(command, name) || knownFlag(name, nil, command.Subcommands) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply sets flags, which are not set with the command line or environment variables, to values from the config
+// Values of the commands take precedence over the defaults and over values of the preceding commands,
+// commands are empty for global flags
+func (p *projectConfig) apply(c *cli.Context, flags []cli.Flag, commands ...string) error {
+	values := make(map[string]interface{}, len(p.Defaults))
+	for name, value := range p.Defaults {
+		values[name] = value
+	}
+	for _, command := range commands {
+		for name, value := range p.Commands[command] {
+			values[name] = value
+		}
+	}
+
+	for _, flag := range flags {
+		names := flag.Names()
+		value, ok := configValue(values, names)
+		if !ok || c.IsSet(names[0]) {
+			continue
+		}
+		items := []interface{}{value}
+		if list, isList := value.([]interface{}); isList {
+			items = list
+		}
+		for _, item := range items {
+			if _, isMap := item.(map[string]interface{}); isMap || item == nil {
+				return fmt.Errorf("%w: unsupported value of flag '%s': %v", ErrInvalidConfig, names[0], value)
+			}
+			if err := c.Set(names[0], fmt.Sprint(item)); err != nil {
+				return fmt.Errorf("%w: flag '%s': %s", ErrInvalidConfig, names[0], err)
+			}
+		}
+	}
+	return nil
+}
+
+// configValue returns the value of the flag given by any of its names
+func configValue(values map[string]interface{}, names []string) (interface{}, bool) {
+	for _, name := range names {
+		if value, ok := values[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
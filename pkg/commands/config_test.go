@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestReadConfig(t *testing.T) {
+	commands := []*cli.Command{
+		{
+			Name:    "export-property",
+			Aliases: []string{"create-property"},
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "tfworkpath"},
+				&cli.StringFlag{Name: "version"},
+				&cli.BoolFlag{Name: "rules-as-hcl", Aliases: []string{"schema"}},
+			},
+		},
+		{
+			Name:  "export-iam",
+			Flags: []cli.Flag{&cli.StringFlag{Name: "tfworkpath"}},
+			Subcommands: []*cli.Command{
+				{Name: "user", Flags: []cli.Flag{&cli.BoolFlag{Name: "only"}}},
+			},
+		},
+	}
+	globalFlags := []cli.Flag{&cli.StringFlag{Name: "section"}}
+
+	tests := map[string]struct {
+		content   string
+		expected  *projectConfig
+		withError string
+	}{
+		"defaults and command": {
+			content: "section: prod\ntfworkpath: ./out\nexport-property:\n  rules-as-hcl: true\n  version: LATEST\n",
+			expected: &projectConfig{
+				Defaults: map[string]interface{}{"section": "prod", "tfworkpath": "./out"},
+				Commands: map[string]map[string]interface{}{
+					"export-property": {"rules-as-hcl": true, "version": "LATEST"},
+				},
+			},
+		},
+		"command alias and flag alias": {
+			content: "create-property:\n  schema: true\n",
+			expected: &projectConfig{
+				Commands: map[string]map[string]interface{}{
+					"export-property": {"schema": true},
+				},
+			},
+		},
+		"subcommand": {
+			content: "only: false\nexport-iam:\n  tfworkpath: ./iam\n  user:\n    tfworkpath: ./users\n    only: true\n",
+			expected: &projectConfig{
+				Defaults: map[string]interface{}{"only": false},
+				Commands: map[string]map[string]interface{}{
+					"export-iam":      {"tfworkpath": "./iam"},
+					"export-iam user": {"tfworkpath": "./users", "only": true},
+				},
+			},
+		},
+		"empty file": {
+			expected: &projectConfig{},
+		},
+		"unknown flag": {
+			content:   "foo: bar\n",
+			withError: "unknown flag 'foo'",
+		},
+		"unknown command": {
+			content:   "export-foo:\n  tfworkpath: ./out\n",
+			withError: "unknown command 'export-foo'",
+		},
+		"unknown flag of command": {
+			content:   "export-property:\n  section: prod\n",
+			withError: "unknown flag 'section' of command 'export-property'",
+		},
+		"unknown flag of subcommand": {
+			content:   "export-iam:\n  user:\n    section: prod\n",
+			withError: "unknown flag 'section' of command 'export-iam user'",
+		},
+		"invalid yaml": {
+			content:   "export-property: [",
+			withError: "invalid config file",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ConfigFileName)
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0644))
+
+			config, err := readConfig(path, globalFlags, commands)
+			if test.withError != "" {
+				assert.ErrorIs(t, err, ErrInvalidConfig)
+				assert.ErrorContains(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, config)
+		})
+	}
+}
+
+func TestReadConfigDiscovery(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.Chdir(dir))
+	defer func() { require.NoError(t, os.Chdir(wd)) }()
+	globalFlags := []cli.Flag{&cli.StringFlag{Name: "section"}}
+
+	config, err := readConfig("", globalFlags, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &projectConfig{}, config)
+
+	require.NoError(t, os.WriteFile(ConfigFileName, []byte("section: prod\n"), 0644))
+	config, err = readConfig("", globalFlags, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &projectConfig{Defaults: map[string]interface{}{"section": "prod"}}, config)
+
+	_, err = readConfig(filepath.Join(dir, "missing.yaml"), globalFlags, nil)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	config := `
+section: config
+tfworkpath: ./default
+export-property:
+  tfworkpath: ./property
+  version: "7"
+  schema: true
+  products: [property, dns]
+export-iam:
+  tfworkpath: ./iam
+  user:
+    tfworkpath: ./users
+    only: true
+`
+	tests := map[string]struct {
+		args     []string
+		env      map[string]string
+		expected map[string]interface{}
+	}{
+		"config file": {
+			args: []string{"export-property"},
+			expected: map[string]interface{}{
+				"section":      "config",
+				"tfworkpath":   "./property",
+				"version":      "7",
+				"rules-as-hcl": true,
+				"products":     []string{"property", "dns"},
+				"template-dir": "",
+			},
+		},
+		"flags and environment variables": {
+			args: []string{"--section", "flag", "export-property", "--version", "8", "--products", "gtm"},
+			env:  map[string]string{"TEST_TEMPLATE_DIR": "./templates", "TEST_TFWORKPATH": "./env"},
+			expected: map[string]interface{}{
+				"section":      "flag",
+				"tfworkpath":   "./env",
+				"version":      "8",
+				"rules-as-hcl": true,
+				"products":     []string{"gtm"},
+				"template-dir": "./templates",
+			},
+		},
+		"subcommand": {
+			args: []string{"export-iam", "user"},
+			expected: map[string]interface{}{
+				"section":    "config",
+				"tfworkpath": "./users",
+				"only":       true,
+			},
+		},
+		"other subcommand": {
+			args: []string{"export-iam", "role"},
+			expected: map[string]interface{}{
+				"section":    "config",
+				"tfworkpath": "./iam",
+			},
+		},
+		"global defaults of other command": {
+			args: []string{"export-zone"},
+			expected: map[string]interface{}{
+				"section":    "config",
+				"tfworkpath": "./default",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ConfigFileName)
+			require.NoError(t, os.WriteFile(path, []byte(config), 0644))
+			for k, v := range test.env {
+				t.Setenv(k, v)
+			}
+
+			result := make(map[string]interface{})
+			property := &cli.Command{
+				Name: "export-property",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "tfworkpath", EnvVars: []string{"TEST_TFWORKPATH"}},
+					&cli.StringFlag{Name: "version", Value: "LATEST"},
+					&cli.BoolFlag{Name: "rules-as-hcl", Aliases: []string{"schema"}},
+					&cli.StringSliceFlag{Name: "products"},
+					&cli.StringFlag{Name: "template-dir", EnvVars: []string{"TEST_TEMPLATE_DIR"}},
+				},
+				Action: func(c *cli.Context) error {
+					result["section"] = c.String("section")
+					result["tfworkpath"] = c.String("tfworkpath")
+					result["version"] = c.String("version")
+					result["rules-as-hcl"] = c.Bool("rules-as-hcl")
+					result["products"] = c.StringSlice("products")
+					result["template-dir"] = c.String("template-dir")
+					return nil
+				},
+			}
+			zone := &cli.Command{
+				Name:  "export-zone",
+				Flags: []cli.Flag{&cli.StringFlag{Name: "tfworkpath"}},
+				Action: func(c *cli.Context) error {
+					result["section"] = c.String("section")
+					result["tfworkpath"] = c.String("tfworkpath")
+					return nil
+				},
+			}
+			iam := &cli.Command{
+				Name:  "export-iam",
+				Flags: []cli.Flag{&cli.StringFlag{Name: "tfworkpath"}},
+				Subcommands: []*cli.Command{
+					{
+						Name:  "user",
+						Flags: []cli.Flag{&cli.BoolFlag{Name: "only"}},
+						Action: func(c *cli.Context) error {
+							result["section"] = c.String("section")
+							result["tfworkpath"] = c.String("tfworkpath")
+							result["only"] = c.Bool("only")
+							return nil
+						},
+					},
+					{
+						Name: "role",
+						Action: func(c *cli.Context) error {
+							result["section"] = c.String("section")
+							result["tfworkpath"] = c.String("tfworkpath")
+							return nil
+						},
+					},
+				},
+			}
+			for _, command := range []*cli.Command{property, zone, iam} {
+				command.Before = applyConfig(command, command.Before)
+			}
+			app := cli.NewApp()
+			app.Flags = []cli.Flag{&cli.StringFlag{Name: "section", Value: "default"}, ConfigFlag()}
+			app.Commands = []*cli.Command{property, zone, iam}
+			app.Before = SetupConfig
+
+			require.NoError(t, app.Run(append([]string{"test", "--config", path}, test.args...)))
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/akamai/cli-terraform/pkg/progress"
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// outputSinkContextKey holds the sink receiving files of an export command run by another command, e.g. drift,
+// instead of the sink selected by output flags
+type outputSinkContextKey struct{}
+
+// setupOutput creates output sink based on output flags and puts it into the context
+// When generated files are streamed to standard output, the terminal in the context prints to standard error
+func setupOutput(c *cli.Context) error {
+	mode, err := templates.ParseOutputMode(c.String("output-mode"))
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+
+	tfWorkPath := "./"
+	if c.IsSet("tfworkpath") {
+		tfWorkPath = c.String("tfworkpath")
+	}
+	tfWorkPath = filepath.FromSlash(tfWorkPath)
+
+	conflict, err := templates.ParseConflictPolicy(c.String("on-conflict"))
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+
+	format, err := templates.ParseFormat(c.String("format"))
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+
+	naming, err := parseNaming(c)
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+
+	engine, err := templates.ParseEngine(c.String("engine"))
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+
+	backend, err := templates.ParseBackend(c.String("backend"), c.StringSlice("backend-config"))
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+
+	var report *templates.Report
+	if c.Bool("report") {
+		report = templates.NewReport(c.App.Version, c.Command.Name, c.Args().Slice(), setFlags(c))
+		c.Context = templates.WithReport(c.Context, report)
+	}
+
+	dryRun := c.Bool("dry-run")
+	base, _ := c.Context.Value(outputSinkContextKey{}).(templates.OutputSink)
+	sink, err := templates.NewOutputSink(templates.OutputOptions{
+		Mode:        mode,
+		Root:        tfWorkPath,
+		ArchivePath: c.String("output-file"),
+		DryRun:      dryRun,
+		Conflict:    conflict,
+		Stdout:      os.Stdout,
+		Warnings:    os.Stderr,
+		Format:      format,
+		Report:      report,
+		Naming:      naming,
+		Sink:        base,
+	})
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+
+	streamed := mode == templates.OutputModeStdout || mode.IsArchive() && c.String("output-file") == ""
+	if streamed && !dryRun && base == nil {
+		c.Context = terminal.Context(c.Context, terminal.New(os.Stderr, os.Stdin, os.Stderr))
+	}
+	c.Context = templates.WithOutput(c.Context, sink)
+	c.Context = templates.WithVersions(c.Context, templates.Versions{
+		Engine:          engine,
+		ProviderVersion: c.String("provider-version"),
+		RequiredVersion: c.String("required-version"),
+	})
+	c.Context = templates.WithBackend(c.Context, backend)
+
+	return setupProgress(c)
+}
+
+// setupProgress puts the reporter of steps selected with progress flag into the context
+// Without a reporter in the context the spinner of the terminal is used
+func setupProgress(c *cli.Context) error {
+	mode, err := progress.ParseMode(c.String("progress"))
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
+	if mode != progress.ModeSpinner {
+		reporter := progress.New(mode, terminal.Get(c.Context), os.Stderr, progress.Options{
+			Command: c.Command.Name,
+			Object:  strings.Join(c.Args().Slice(), " "),
+		})
+		c.Context = progress.WithReporter(c.Context, reporter)
+	}
+	return nil
+}
+
+// closeOutput closes output sink stored in the context
+func closeOutput(c *cli.Context) error {
+	if err := templates.GetOutput(c.Context).Close(); err != nil {
+		return cli.Exit(color.RedString("Error writing output: %s", err), 1)
+	}
+	return nil
+}
+
+// parseNaming returns naming strategy of generated resources selected with naming flags
+func parseNaming(c *cli.Context) (templates.Naming, error) {
+	style, err := templates.ParseNamingStyle(c.String("naming"))
+	if err != nil {
+		return templates.Naming{}, err
+	}
+	collision, err := templates.ParseNameCollisionPolicy(c.String("name-collision"))
+	if err != nil {
+		return templates.Naming{}, err
+	}
+	return templates.Naming{
+		Style:     style,
+		Prefix:    c.String("name-prefix"),
+		Suffix:    c.String("name-suffix"),
+		Collision: collision,
+	}, nil
+}
+
+// setFlags returns values of the flags set for the command
+func setFlags(c *cli.Context) map[string]string {
+	names := c.LocalFlagNames()
+	if len(names) == 0 {
+		return nil
+	}
+	flags := make(map[string]string, len(names))
+	for _, name := range names {
+		flags[name] = fmt.Sprint(c.Value(name))
+	}
+	return flags
+}
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestSetupOutput(t *testing.T) {
+	tests := map[string]struct {
+		flags            map[string]string
+		expected         templates.OutputSink
+		terminalReplaced bool
+		versions         *templates.Versions
+		backend          *templates.Backend
+		withError        string
+	}{
+		"default": {
+			expected: templates.NewStagingSink(templates.DiskSink{Conflict: templates.ConflictFail, Warnings: os.Stderr}, "./"),
+		},
+		"conflict policy": {
+			flags:    map[string]string{"on-conflict": "merge"},
+			expected: templates.NewStagingSink(templates.DiskSink{Conflict: templates.ConflictMerge, Warnings: os.Stderr}, "./"),
+		},
+		"invalid conflict policy": {
+			flags:     map[string]string{"on-conflict": "foo"},
+			withError: "invalid conflict policy",
+		},
+		"dry run": {
+			flags:    map[string]string{"dry-run": "true"},
+			expected: &templates.DryRunSink{},
+		},
+		"stdout": {
+			flags:            map[string]string{"output-mode": "stdout"},
+			expected:         &templates.StreamSink{},
+			terminalReplaced: true,
+		},
+		"archive to stdout": {
+			flags:            map[string]string{"output-mode": "zip"},
+			expected:         &templates.ArchiveSink{},
+			terminalReplaced: true,
+		},
+		"dry run with stdout": {
+			flags:    map[string]string{"output-mode": "stdout", "dry-run": "true"},
+			expected: &templates.DryRunSink{},
+		},
+		"json format": {
+			flags:    map[string]string{"format": "json"},
+			expected: templates.JSONSink{OutputSink: templates.NewStagingSink(templates.DiskSink{Conflict: templates.ConflictFail, Warnings: os.Stderr}, "./")},
+		},
+		"report": {
+			flags:    map[string]string{"report": "true"},
+			expected: &templates.ReportSink{},
+		},
+		"invalid format": {
+			flags:     map[string]string{"format": "yaml"},
+			withError: "invalid format",
+		},
+		"invalid output mode": {
+			flags:     map[string]string{"output-mode": "foo"},
+			withError: "invalid output mode",
+		},
+		"opentofu with versions": {
+			flags:    map[string]string{"engine": "opentofu", "provider-version": "~> 6.4", "required-version": ">= 1.6"},
+			expected: templates.NewStagingSink(templates.DiskSink{Conflict: templates.ConflictFail, Warnings: os.Stderr}, "./"),
+			versions: &templates.Versions{Engine: templates.EngineOpenTofu, ProviderVersion: "~> 6.4", RequiredVersion: ">= 1.6"},
+		},
+		"invalid engine": {
+			flags:     map[string]string{"engine": "pulumi"},
+			withError: "invalid engine",
+		},
+		"backend with config": {
+			flags:    map[string]string{"backend": "s3", "backend-config": "bucket=tfstate"},
+			expected: templates.NewStagingSink(templates.DiskSink{Conflict: templates.ConflictFail, Warnings: os.Stderr}, "./"),
+			backend:  &templates.Backend{Type: templates.BackendS3, Config: map[string]string{"bucket": "tfstate"}},
+		},
+		"invalid backend": {
+			flags:     map[string]string{"backend": "consul"},
+			withError: "invalid backend",
+		},
+		"backend config without backend": {
+			flags:     map[string]string{"backend-config": "bucket=tfstate"},
+			withError: "invalid backend config",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			flagset := flag.NewFlagSet("test", flag.PanicOnError)
+			flagset.String("tfworkpath", "", "")
+			flagset.String("output-mode", "", "")
+			flagset.String("output-file", "", "")
+			flagset.String("on-conflict", "", "")
+			flagset.String("format", "", "")
+			flagset.String("engine", "", "")
+			flagset.String("provider-version", "", "")
+			flagset.String("required-version", "", "")
+			flagset.String("backend", "", "")
+			flagset.Var(cli.NewStringSlice(), "backend-config", "")
+			flagset.Bool("dry-run", false, "")
+			flagset.Bool("report", false, "")
+			for k, v := range test.flags {
+				require.NoError(t, flagset.Set(k, v))
+			}
+			ctx := cli.NewContext(cli.NewApp(), flagset, nil)
+			term := terminal.New(terminal.DiscardWriter(), nil, terminal.DiscardWriter())
+			ctx.Context = terminal.Context(ctx.Context, term)
+
+			err := setupOutput(ctx)
+			if test.withError != "" {
+				assert.ErrorContains(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+			switch test.expected.(type) {
+			case *templates.StagingSink, templates.JSONSink:
+				assert.Equal(t, test.expected, templates.GetOutput(ctx.Context))
+			default:
+				assert.IsType(t, test.expected, templates.GetOutput(ctx.Context))
+			}
+			assert.Equal(t, test.terminalReplaced, terminal.Get(ctx.Context) != term)
+			if test.versions != nil {
+				assert.Equal(t, *test.versions, templates.GetVersions(ctx.Context))
+			}
+			if test.backend != nil {
+				assert.Equal(t, *test.backend, templates.GetBackend(ctx.Context))
+			}
+			if sink, ok := templates.GetOutput(ctx.Context).(*templates.ReportSink); ok {
+				assert.Same(t, sink.Report, templates.GetReport(ctx.Context))
+			}
+
+			// replace the sink so that closing does not write into test output
+			ctx.Context = templates.WithOutput(ctx.Context, templates.NewMemorySink())
+			require.NoError(t, closeOutput(ctx))
+		})
+	}
+}
@@ -5,22 +5,29 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/akamai/cli-terraform/pkg/progress"
+	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
 
-var osExiter = os.Exit
-
 type actionValidator func(*cli.Context) error
 
 func validatedAction(action cli.ActionFunc, validators ...actionValidator) cli.ActionFunc {
-	return func(ctx *cli.Context) error {
+	return func(ctx *cli.Context) (err error) {
 		for _, validator := range validators {
 			if err := validator(ctx); err != nil {
 				return err
 			}
 		}
-		return action(ctx)
+		if ctx.Context != nil {
+			defer func() { progress.Get(ctx.Context).Finish(err) }()
+		}
+		if err = action(ctx); err != nil && ctx.Context != nil {
+			// drop staged files, so that failed export leaves the work path untouched
+			_ = templates.DiscardOutput(templates.GetOutput(ctx.Context))
+		}
+		return err
 	}
 }
 
@@ -36,13 +43,21 @@ func requireValidWorkpath(ctx *cli.Context) error {
 	return nil
 }
 
+func requirePositiveConcurrency(ctx *cli.Context) error {
+	if ctx.Int("concurrency") < 1 {
+		return cli.Exit(color.RedString("concurrency has to be a positive number"), 1)
+	}
+	return nil
+}
+
 func requireNArguments(n int) actionValidator {
 	return func(ctx *cli.Context) error {
 		if ctx.NArg() != n {
 			if err := showHelpCommandWithErr(ctx, fmt.Sprintf("Invalid arguments usage, next arguments are required: %s", ctx.Command.ArgsUsage)); err != nil {
 				return err
 			}
-			osExiter(1)
+			// the error is already printed with the help
+			return cli.Exit("", 1)
 		}
 		return nil
 	}
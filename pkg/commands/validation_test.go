@@ -2,12 +2,16 @@ package commands
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -35,6 +39,25 @@ func TestValidatedAction(t *testing.T) {
 		assert.ErrorContains(t, err, "action error")
 	})
 
+	t.Run("action error discards staged files", func(t *testing.T) {
+		dir := t.TempDir()
+		sink := templates.NewStagingSink(templates.DiskSink{}, dir)
+		action := func(ctx *cli.Context) error {
+			if err := templates.GetOutput(ctx.Context).WriteFile(filepath.Join(dir, "main.tf"), []byte("")); err != nil {
+				return err
+			}
+			return fmt.Errorf("action error")
+		}
+		actionFunc := validatedAction(action)
+
+		err := actionFunc(&cli.Context{Context: templates.WithOutput(context.Background(), sink)})
+		assert.ErrorContains(t, err, "action error")
+		require.NoError(t, sink.Close())
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
 	t.Run("action with validation", func(t *testing.T) {
 		action := func(ctx *cli.Context) error {
 			return nil
@@ -165,20 +188,12 @@ func TestRequireNArguments(t *testing.T) {
 		ctx := cli.NewContext(app, flagSet, nil)
 		ctx.Command.ArgsUsage = "<example usage>"
 
-		exitOsCalled := false
-		// patch osExiter
-		defer func(restore func(_ int)) {
-			osExiter = restore
-		}(osExiter)
-		osExiter = func(_ int) {
-			exitOsCalled = true
-		}
-
 		validateNArgsFunc := requireNArguments(3) // expecting 3 arguments
 
 		err = validateNArgsFunc(ctx)
-		assert.NoError(t, err)
-		assert.True(t, exitOsCalled)
+		var exitErr cli.ExitCoder
+		require.True(t, errors.As(err, &exitErr), "expected exit error; got: %v", err)
+		assert.Equal(t, 1, exitErr.ExitCode())
 		assert.Contains(t, errBuffer.String(), "Invalid arguments usage, next arguments are required: <example usage>")
 	})
 }
@@ -0,0 +1,394 @@
+package edgegrid
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	// RecordEnv is the environment variable with the directory into which API interactions are recorded
+	RecordEnv = "AKAMAI_CLI_RECORD"
+	// ReplayEnv is the environment variable with the directory from which recorded API interactions are served
+	ReplayEnv = "AKAMAI_CLI_REPLAY"
+
+	// RedactedValue replaces credentials and other secrets in cassettes
+	RedactedValue = "REDACTED"
+	// RedactedHost replaces the API host, which identifies the credentials, in cassettes
+	RedactedHost = "redacted.luna.akamaiapis.net"
+
+	bodyEncodingJSON   = "json"
+	bodyEncodingText   = "text"
+	bodyEncodingBase64 = "base64"
+)
+
+type (
+	// Interaction is a single API request with its response, saved as a cassette file
+	Interaction struct {
+		Request  RecordedRequest  `json:"request"`
+		Response RecordedResponse `json:"response"`
+	}
+
+	// RecordedRequest is a redacted API request
+	RecordedRequest struct {
+		Method       string          `json:"method"`
+		URL          string          `json:"url"`
+		Header       http.Header     `json:"header,omitempty"`
+		Body         json.RawMessage `json:"body,omitempty"`
+		BodyEncoding string          `json:"body_encoding,omitempty"`
+	}
+
+	// RecordedResponse is a redacted API response
+	RecordedResponse struct {
+		StatusCode   int             `json:"status_code"`
+		Header       http.Header     `json:"header,omitempty"`
+		Body         json.RawMessage `json:"body,omitempty"`
+		BodyEncoding string          `json:"body_encoding,omitempty"`
+	}
+
+	// RecordingTransport sends requests using the wrapped transport and saves every interaction in a separate
+	// cassette file in the directory
+	RecordingTransport struct {
+		mu        sync.Mutex
+		dir       string
+		count     int
+		transport http.RoundTripper
+	}
+
+	// ReplayTransport serves responses recorded by RecordingTransport without sending any requests
+	// Interactions are matched by method, path, query and body of the request, if the same request was recorded
+	// several times, the responses are served in the recorded order and the last one is repeated
+	ReplayTransport struct {
+		mu           sync.Mutex
+		interactions []Interaction
+		used         []bool
+	}
+)
+
+var (
+	// ErrCassette is returned when cassette files cannot be read or written
+	ErrCassette = errors.New("cassette")
+	// ErrNoInteraction is returned in replay mode when there is no recorded interaction for the request
+	ErrNoInteraction = errors.New("no recorded interaction")
+
+	// redactedHeaders are removed from recorded requests and responses
+	redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+	// redactedQueryParams have their values replaced in recorded URLs
+	redactedQueryParams = []string{"accountSwitchKey"}
+	// secretKeyRegexp matches names of JSON fields holding secrets, compared in lower case without separators
+	secretKeyRegexp = regexp.MustCompile(`secret|password|privatekey|token$`)
+
+	fileNameRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+	_ http.RoundTripper = &RecordingTransport{}
+	_ http.RoundTripper = &ReplayTransport{}
+)
+
+// CassetteTransport returns transport recording or replaying API interactions, depending on RecordEnv and ReplayEnv
+// environment variables, it returns nil if none of them is set
+func CassetteTransport() (http.RoundTripper, error) {
+	recordDir, replayDir := os.Getenv(RecordEnv), os.Getenv(ReplayEnv)
+	switch {
+	case recordDir != "" && replayDir != "":
+		return nil, fmt.Errorf("%w: only one of %s and %s can be set", ErrCassette, RecordEnv, ReplayEnv)
+	case recordDir != "":
+		return NewRecordingTransport(recordDir, http.DefaultTransport)
+	case replayDir != "":
+		return NewReplayTransport(replayDir)
+	}
+	return nil, nil
+}
+
+// NewRecordingTransport returns RecordingTransport saving cassettes into dir, which is created if needed
+// Numbering of the cassette files continues after the ones already present in dir
+func NewRecordingTransport(dir string, transport http.RoundTripper) (*RecordingTransport, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCassette, err)
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCassette, err)
+	}
+	return &RecordingTransport{dir: dir, count: len(existing), transport: transport}, nil
+}
+
+// RoundTrip sends the request and saves the redacted interaction
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    redactURL(req.URL),
+			Header: redactHeader(req.Header),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyEncoding = encodeBody(reqBody)
+	name := fmt.Sprintf("%s-%s", strings.ToLower(req.Method), strings.Trim(fileNameRegexp.ReplaceAllString(strings.ToLower(req.URL.Path), "-"), "-"))
+
+	req = req.Clone(req.Context())
+	req.Body = bodyReader(reqBody)
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = bodyReader(respBody)
+
+	interaction.Response = RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     redactHeader(resp.Header),
+	}
+	interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(respBody)
+
+	if err := t.save(name, interaction); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *RecordingTransport) save(name string, interaction Interaction) error {
+	content, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrCassette, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.count++
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	path := filepath.Join(t.dir, fmt.Sprintf("%04d-%s.json", t.count, strings.TrimSuffix(name, "-")))
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("%w: %s", ErrCassette, err)
+	}
+	return nil
+}
+
+// NewReplayTransport returns ReplayTransport serving interactions from cassette files in dir
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCassette, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w: no cassette files found in '%s'", ErrCassette, dir)
+	}
+	sort.Strings(paths)
+
+	t := &ReplayTransport{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCassette, err)
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(content, &interaction); err != nil {
+			return nil, fmt.Errorf("%w: '%s': %s", ErrCassette, path, err)
+		}
+		t.interactions = append(t.interactions, interaction)
+	}
+	t.used = make([]bool, len(t.interactions))
+	return t, nil
+}
+
+// RoundTrip returns recorded response for the request
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	recorded := RecordedRequest{Method: req.Method, URL: redactURL(req.URL)}
+	recorded.Body, recorded.BodyEncoding = encodeBody(body)
+
+	interaction, ok := t.find(recorded)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.RequestURI())
+	}
+	respBody, err := decodeBody(interaction.Response.Body, interaction.Response.BodyEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCassette, err)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Response.Header.Clone(),
+		Body:          bodyReader(respBody),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+func (t *ReplayTransport) find(req RecordedRequest) (Interaction, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last := -1
+	for i, interaction := range t.interactions {
+		if !sameRequest(interaction.Request, req) {
+			continue
+		}
+		if !t.used[i] {
+			t.used[i] = true
+			return interaction, true
+		}
+		last = i
+	}
+	if last < 0 {
+		return Interaction{}, false
+	}
+	return t.interactions[last], true
+}
+
+func sameRequest(recorded, req RecordedRequest) bool {
+	if recorded.Method != req.Method || recorded.BodyEncoding != req.BodyEncoding {
+		return false
+	}
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	reqURL, err := url.Parse(req.URL)
+	if err != nil {
+		return false
+	}
+	if recordedURL.Path != reqURL.Path || recordedURL.Query().Encode() != reqURL.Query().Encode() {
+		return false
+	}
+	if recorded.BodyEncoding == bodyEncodingJSON {
+		var recordedBody, reqBody interface{}
+		if json.Unmarshal(recorded.Body, &recordedBody) != nil || json.Unmarshal(req.Body, &reqBody) != nil {
+			return false
+		}
+		recordedJSON, _ := json.Marshal(recordedBody)
+		reqJSON, _ := json.Marshal(reqBody)
+		return bytes.Equal(recordedJSON, reqJSON)
+	}
+	return bytes.Equal(recorded.Body, req.Body)
+}
+
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil || body == http.NoBody {
+		return nil, nil
+	}
+	defer func() { _ = body.Close() }()
+	return io.ReadAll(body)
+}
+
+func bodyReader(body []byte) io.ReadCloser {
+	if body == nil {
+		return http.NoBody
+	}
+	return io.NopCloser(bytes.NewReader(body))
+}
+
+// encodeBody returns body stored in a cassette, JSON bodies are stored as JSON with secrets redacted,
+// other UTF-8 bodies as JSON strings and binary bodies, e.g. EdgeWorker bundles, as base64 encoded JSON strings
+func encodeBody(body []byte) (json.RawMessage, string) {
+	if len(body) == 0 {
+		return nil, ""
+	}
+	if json.Valid(body) {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err == nil {
+			if redactJSON(value) {
+				if redacted, err := json.Marshal(value); err == nil {
+					return redacted, bodyEncodingJSON
+				}
+			}
+		}
+		return append(json.RawMessage(nil), body...), bodyEncodingJSON
+	}
+	if utf8.Valid(body) {
+		encoded, _ := json.Marshal(string(body))
+		return encoded, bodyEncodingText
+	}
+	encoded, _ := json.Marshal(base64.StdEncoding.EncodeToString(body))
+	return encoded, bodyEncodingBase64
+}
+
+func decodeBody(body json.RawMessage, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return nil, nil
+	case bodyEncodingJSON:
+		return body, nil
+	}
+	var s string
+	if err := json.Unmarshal(body, &s); err != nil {
+		return nil, err
+	}
+	if encoding == bodyEncodingBase64 {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return []byte(s), nil
+}
+
+// redactJSON replaces values of fields holding secrets and reports whether anything was replaced
+func redactJSON(value interface{}) bool {
+	var redacted bool
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			name := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+			if _, isString := item.(string); isString && secretKeyRegexp.MatchString(name) {
+				v[key] = RedactedValue
+				redacted = true
+				continue
+			}
+			redacted = redactJSON(item) || redacted
+		}
+	case []interface{}:
+		for _, item := range v {
+			redacted = redactJSON(item) || redacted
+		}
+	}
+	return redacted
+}
+
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.Host = RedactedHost
+	redacted.User = nil
+	query := redacted.Query()
+	for _, param := range redactedQueryParams {
+		if query.Has(param) {
+			query.Set(param, RedactedValue)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range redactedHeaders {
+		redacted.Del(name)
+	}
+	if len(redacted) == 0 {
+		return nil
+	}
+	return redacted
+}
@@ -0,0 +1,202 @@
+package edgegrid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=abc")
+		switch r.URL.Path {
+		case "/papi/v1/properties":
+			w.Header().Set("Content-Type", "application/json")
+			body, _ := io.ReadAll(r.Body)
+			_, _ = w.Write([]byte(`{"request": ` + string(body) + `, "clientSecret": "secret", "items": [{"id": 1}]}`))
+		case "/edgeworkers/v1/bundle":
+			_, _ = w.Write([]byte{0x1f, 0x8b, 0xff})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("not found"))
+		}
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: http.MethodPost, path: "/papi/v1/properties?contractId=ctr_1&accountSwitchKey=1-ABC", body: `{"name": "test", "password": "pass"}`},
+		{method: http.MethodGet, path: "/edgeworkers/v1/bundle"},
+		{method: http.MethodGet, path: "/missing"},
+	}
+	send := func(t *testing.T, client *http.Client, method, path, body string) (int, []byte) {
+		req, err := http.NewRequest(method, "https://"+RedactedHost+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "EG1-HMAC-SHA256 client_token=akab-token")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		content, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, content
+	}
+
+	dir := t.TempDir()
+	recorder, err := NewRecordingTransport(dir, roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		r.URL.Scheme, r.URL.Host = serverURL.Scheme, serverURL.Host
+		return http.DefaultTransport.RoundTrip(r)
+	}))
+	require.NoError(t, err)
+	type result struct {
+		status int
+		body   []byte
+	}
+	var recorded []result
+	for _, r := range requests {
+		status, body := send(t, &http.Client{Transport: recorder}, r.method, r.path, r.body)
+		recorded = append(recorded, result{status: status, body: body})
+	}
+	assert.Equal(t, http.StatusOK, recorded[0].status)
+	assert.Equal(t, []byte{0x1f, 0x8b, 0xff}, recorded[1].body)
+	assert.Equal(t, http.StatusNotFound, recorded[2].status)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "0001-post-papi-v1-properties.json"),
+		filepath.Join(dir, "0002-get-edgeworkers-v1-bundle.json"),
+		filepath.Join(dir, "0003-get-missing.json"),
+	}, files)
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	for _, secret := range []string{"akab-token", "1-ABC", "pass\"", "\"secret\"", "session=abc", serverURL.Host} {
+		assert.NotContains(t, string(content), secret)
+	}
+	var interaction Interaction
+	require.NoError(t, json.Unmarshal(content, &interaction))
+	assert.Equal(t, "https://"+RedactedHost+"/papi/v1/properties?accountSwitchKey=REDACTED&contractId=ctr_1", interaction.Request.URL)
+	assert.JSONEq(t, `{"name": "test", "password": "REDACTED"}`, string(interaction.Request.Body))
+
+	replay, err := NewReplayTransport(dir)
+	require.NoError(t, err)
+	client := &http.Client{Transport: replay}
+	for i, r := range requests {
+		status, body := send(t, client, r.method, r.path, r.body)
+		assert.Equal(t, recorded[i].status, status)
+		if i == 0 {
+			assert.JSONEq(t, `{"request": {"name": "test", "password": "REDACTED"}, "clientSecret": "REDACTED", "items": [{"id": 1}]}`, string(body))
+			continue
+		}
+		assert.Equal(t, recorded[i].body, body)
+	}
+
+	// repeated request is served with the last recorded response
+	status, _ := send(t, client, http.MethodGet, "/missing", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	req, err := http.NewRequest(http.MethodGet, "https://"+RedactedHost+"/papi/v1/groups", nil)
+	require.NoError(t, err)
+	_, err = client.Do(req)
+	assert.True(t, errors.Is(err, ErrNoInteraction), err)
+}
+
+func TestCassetteTransport(t *testing.T) {
+	replayDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(replayDir, "0001-get-test.json"), []byte(`{
+  "request": {"method": "GET", "url": "https://redacted.luna.akamaiapis.net/test"},
+  "response": {"status_code": 200, "body": {"ok": true}, "body_encoding": "json"}
+}`), 0644))
+
+	tests := map[string]struct {
+		record    string
+		replay    string
+		expected  interface{}
+		withError error
+	}{
+		"no cassette": {},
+		"record": {
+			record:   filepath.Join(t.TempDir(), "new"),
+			expected: &RecordingTransport{},
+		},
+		"replay": {
+			replay:   replayDir,
+			expected: &ReplayTransport{},
+		},
+		"replay from empty directory": {
+			replay:    t.TempDir(),
+			withError: ErrCassette,
+		},
+		"record and replay": {
+			record:    t.TempDir(),
+			replay:    replayDir,
+			withError: ErrCassette,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(RecordEnv, test.record)
+			t.Setenv(ReplayEnv, test.replay)
+			transport, err := CassetteTransport()
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "want: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			if test.expected == nil {
+				assert.Nil(t, transport)
+				return
+			}
+			assert.IsType(t, test.expected, transport)
+		})
+	}
+}
+
+func TestInitializeSessionReplay(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0001-get-test.json"), []byte(`{
+  "request": {"method": "GET", "url": "https://redacted.luna.akamaiapis.net/test"},
+  "response": {"status_code": 200, "body": {"ok": true}, "body_encoding": "json"}
+}`), 0644))
+	t.Setenv(ReplayEnv, dir)
+
+	set := flag.NewFlagSet("test", 0)
+	set.String("edgerc", "./testdata/edgerc-invalid", "")
+	s, err := InitializeSession(cli.NewContext(cli.NewApp(), set, nil))
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/test", nil)
+	require.NoError(t, err)
+	var res struct {
+		OK bool `json:"ok"`
+	}
+	resp, err := s.Exec(req, &res)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, res.OK)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
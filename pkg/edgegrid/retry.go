@@ -0,0 +1,228 @@
+package edgegrid
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/edgegrid"
+	"github.com/urfave/cli/v2"
+)
+
+type (
+	// RetryPolicy defines how failed API requests are retried
+	RetryPolicy struct {
+		// MaxRetries is the maximum number of retries of a single request, 0 disables retries
+		MaxRetries int
+		// MinWait is the wait before the first retry, it doubles with every next retry
+		MinWait time.Duration
+		// MaxWait limits the wait between retries, waits requested with Retry-After header are not limited
+		MaxWait time.Duration
+	}
+
+	// RetryTransport retries idempotent requests failed with a network error, 429 or 5xx status,
+	// waiting with exponential backoff with jitter or as long as requested with Retry-After header
+	// Every attempt, including the first one, waits for the Limiter
+	RetryTransport struct {
+		Transport http.RoundTripper
+		Policy    RetryPolicy
+		Limiter   *RateLimiter
+		// Signer, if set, signs retried requests again, as signatures contain timestamp and nonce
+		Signer edgegrid.Signer
+	}
+
+	// RateLimiter is a token bucket limiting the number of requests per second
+	RateLimiter struct {
+		mu     sync.Mutex
+		rate   float64
+		burst  float64
+		tokens float64
+		last   time.Time
+	}
+)
+
+const (
+	// MaxRetriesEnv is the environment variable with the maximum number of retries of failed API requests
+	MaxRetriesEnv = "AKAMAI_CLI_MAX_RETRIES"
+	// RetryMaxWaitEnv is the environment variable with the maximum wait between retries
+	RetryMaxWaitEnv = "AKAMAI_CLI_RETRY_MAX_WAIT"
+	// RateLimitEnv is the environment variable with the maximum number of API requests per second
+	RateLimitEnv = "AKAMAI_CLI_RATE_LIMIT"
+
+	defaultMaxRetries   = 3
+	defaultRetryMinWait = 500 * time.Millisecond
+	defaultRetryMaxWait = 30 * time.Second
+)
+
+var _ http.RoundTripper = &RetryTransport{}
+
+// Flags returns global flags configuring retries and rate limiting of API requests
+func Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:    "max-retries",
+			Usage:   "Maximum number of retries of GET requests failed with a network error, 429 or 5xx status, 0 disables retries",
+			Value:   defaultMaxRetries,
+			EnvVars: []string{MaxRetriesEnv},
+		},
+		&cli.DurationFlag{
+			Name:    "retry-max-wait",
+			Usage:   "Maximum wait between retries, longer waits requested by the API with Retry-After header are honored",
+			Value:   defaultRetryMaxWait,
+			EnvVars: []string{RetryMaxWaitEnv},
+		},
+		&cli.Float64Flag{
+			Name:    "rate-limit",
+			Usage:   "Maximum number of API requests per second, 0 disables the limit",
+			EnvVars: []string{RateLimitEnv},
+		},
+	}
+}
+
+// NewRetryTransport returns RetryTransport based on retry and rate limit flags, http.DefaultTransport is used
+// if transport is nil
+func NewRetryTransport(c *cli.Context, transport http.RoundTripper, signer edgegrid.Signer) *RetryTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	t := &RetryTransport{
+		Transport: transport,
+		Policy: RetryPolicy{
+			MaxRetries: c.Int("max-retries"),
+			MinWait:    defaultRetryMinWait,
+			MaxWait:    c.Duration("retry-max-wait"),
+		},
+		Signer: signer,
+	}
+	if rate := c.Float64("rate-limit"); rate > 0 {
+		t.Limiter = NewRateLimiter(rate, int(math.Ceil(rate)))
+	}
+	return t
+}
+
+// RoundTrip sends the request, retrying it according to the policy
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if t.Limiter != nil {
+			if err := t.Limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := t.Transport.RoundTrip(req)
+		if attempt >= t.Policy.MaxRetries || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := t.Policy.Wait(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		req = req.Clone(req.Context())
+		if t.Signer != nil {
+			t.Signer.SignRequest(req)
+		}
+	}
+}
+
+// retryable returns true if the request is idempotent and failed with an error which may be temporary
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrNoInteraction)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Wait returns how long to wait before the retry following the given attempt, counted from 0
+func (p RetryPolicy) Wait(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return wait
+		}
+	}
+	wait := p.MaxWait
+	if attempt < 32 && p.MinWait<<attempt < p.MaxWait {
+		wait = p.MinWait << attempt
+	}
+	// half of the backoff is randomized, so that concurrent requests do not retry at once
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// retryAfter parses the value of Retry-After header, which is either a number of seconds or an HTTP date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if wait := date.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// NewRateLimiter returns RateLimiter allowing rate requests per second on average and up to burst requests at once
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Wait blocks until a request is allowed or the context is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if err := sleep(ctx, wait); err != nil {
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
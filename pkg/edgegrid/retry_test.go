@@ -0,0 +1,225 @@
+package edgegrid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingSigner struct {
+	signed int
+}
+
+func (s *countingSigner) SignRequest(r *http.Request) {
+	s.signed++
+	r.Header.Set("Authorization", "signed")
+}
+
+func (s *countingSigner) CheckRequestLimit(int) {}
+
+func TestRetryTransport(t *testing.T) {
+	tests := map[string]struct {
+		method           string
+		statuses         []int
+		retryAfter       string
+		expectedStatus   int
+		expectedAttempts int
+	}{
+		"retried until success": {
+			method:           http.MethodGet,
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 3,
+		},
+		"too many requests with retry after": {
+			method:           http.MethodGet,
+			statuses:         []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:       "0",
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 2,
+		},
+		"retries exhausted": {
+			method:           http.MethodGet,
+			statuses:         []int{http.StatusInternalServerError},
+			expectedStatus:   http.StatusInternalServerError,
+			expectedAttempts: 3,
+		},
+		"client error not retried": {
+			method:           http.MethodGet,
+			statuses:         []int{http.StatusNotFound},
+			expectedStatus:   http.StatusNotFound,
+			expectedAttempts: 1,
+		},
+		"post not retried": {
+			method:           http.MethodPost,
+			statuses:         []int{http.StatusServiceUnavailable},
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedAttempts: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var attempts int
+			var authorization []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = append(authorization, r.Header.Get("Authorization"))
+				status := test.statuses[len(test.statuses)-1]
+				if attempts < len(test.statuses) {
+					status = test.statuses[attempts]
+				}
+				attempts++
+				if test.retryAfter != "" {
+					w.Header().Set("Retry-After", test.retryAfter)
+				}
+				w.WriteHeader(status)
+			}))
+			defer srv.Close()
+
+			signer := &countingSigner{}
+			transport := &RetryTransport{
+				Transport: http.DefaultTransport,
+				Policy:    RetryPolicy{MaxRetries: 2, MinWait: time.Millisecond, MaxWait: 5 * time.Millisecond},
+				Signer:    signer,
+			}
+			req, err := http.NewRequest(test.method, srv.URL+"/papi/v1/groups", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "original")
+
+			resp, err := transport.RoundTrip(req)
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, resp.Body.Close())
+			}()
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedAttempts, attempts)
+			assert.Equal(t, test.expectedAttempts-1, signer.signed)
+			assert.Equal(t, "original", authorization[0])
+		})
+	}
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	transport := &RetryTransport{
+		Transport: http.DefaultTransport,
+		Policy:    RetryPolicy{MaxRetries: 5, MinWait: time.Minute, MaxWait: time.Minute},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRetryPolicyWait(t *testing.T) {
+	policy := RetryPolicy{MinWait: time.Second, MaxWait: 10 * time.Second}
+	tests := map[string]struct {
+		attempt    int
+		retryAfter string
+		min, max   time.Duration
+	}{
+		"first retry": {
+			attempt: 0,
+			min:     500 * time.Millisecond,
+			max:     time.Second,
+		},
+		"backoff doubles": {
+			attempt: 2,
+			min:     2 * time.Second,
+			max:     4 * time.Second,
+		},
+		"backoff limited": {
+			attempt: 10,
+			min:     5 * time.Second,
+			max:     10 * time.Second,
+		},
+		"retry after seconds": {
+			attempt:    0,
+			retryAfter: "120",
+			min:        2 * time.Minute,
+			max:        2 * time.Minute,
+		},
+		"invalid retry after": {
+			attempt:    0,
+			retryAfter: "soon",
+			min:        500 * time.Millisecond,
+			max:        time.Second,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if test.retryAfter != "" {
+				resp.Header.Set("Retry-After", test.retryAfter)
+			}
+			wait := policy.Wait(test.attempt, resp)
+			assert.GreaterOrEqual(t, wait, test.min)
+			assert.LessOrEqual(t, wait, test.max)
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 9, 4, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		given    string
+		expected time.Duration
+		ok       bool
+	}{
+		"seconds": {
+			given:    "30",
+			expected: 30 * time.Second,
+			ok:       true,
+		},
+		"http date": {
+			given:    "Wed, 04 Sep 2024 12:01:00 GMT",
+			expected: time.Minute,
+			ok:       true,
+		},
+		"date in the past": {
+			given: "Wed, 04 Sep 2024 11:00:00 GMT",
+			ok:    true,
+		},
+		"empty": {},
+		"negative": {
+			given: "-1",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wait, ok := retryAfter(test.given, now)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, wait)
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(100, 2)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, limiter.Wait(context.Background()))
+	}
+	// 2 requests are allowed at once, the next 3 wait 10ms each
+	assert.GreaterOrEqual(t, time.Since(start), 25*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := NewRateLimiter(0.001, 1)
+	require.NoError(t, slow.Wait(ctx))
+	assert.ErrorIs(t, slow.Wait(ctx), context.Canceled)
+}
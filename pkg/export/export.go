@@ -0,0 +1,173 @@
+// Package export runs exporters of cli-terraform from Go programs, e.g. services generating terraform configuration
+// Every export takes API clients and the sink of generated files from its options and keeps all of its state to itself,
+// so that exports may run at the same time in one process
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/edgegrid"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/session"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
+	"github.com/akamai/cli-terraform/pkg/templates"
+)
+
+type (
+	// Options are options shared by all exports
+	Options struct {
+		// Session is used to create API clients which are not set in Clients
+		Session session.Session
+		// Clients are API clients used instead of the ones created from Session
+		Clients exporter.Clients
+		// Output receives generated files, if it is nil the files are written to the disk, failing if any of them exists
+		Output templates.OutputSink
+		// WorkPath is the directory of generated files, current directory if it is empty
+		WorkPath string
+		// ImportStyle decides whether imports are generated as a script or as import blocks, script if it is empty
+		ImportStyle templates.ImportStyle
+		// Secrets decides where values of secrets are kept, secrets.auto.tfvars if it is empty
+		Secrets templates.SecretsMode
+		// Section is the section of the edgerc file used by generated configuration, 'default' if it is empty
+		Section string
+		// EdgercPath is the path of the edgerc file used by generated configuration, '~/.edgerc' if it is empty
+		EdgercPath string
+		// TemplateDir is the directory with custom templates replacing the embedded ones of the same file name
+		TemplateDir string
+		// Versions are versions of the engine and the provider written into versions.tf
+		Versions templates.Versions
+		// Backend is the terraform backend written into backend.tf, no backend is written if its type is empty
+		Backend templates.Backend
+		// Reporter reports progress of the export steps, they are not reported if it is nil
+		Reporter progress.Reporter
+	}
+
+	// Flags are values of the flags of an exporter by flag name, a flag with zero value is not set
+	Flags map[string]interface{}
+)
+
+var (
+	// ErrInvalidArguments is returned when the number of arguments does not match the exporter
+	ErrInvalidArguments = errors.New("invalid arguments")
+	// ErrInvalidOptions is returned when the options of the export are not valid
+	ErrInvalidOptions = errors.New("invalid options")
+
+	_ exporter.Flags = Flags{}
+)
+
+// defaultConcurrency is the maximum number of API calls made at the same time, if it is not set in the options
+const defaultConcurrency = 4
+
+// Run runs the exporter with the given arguments and flags
+// The exporter may be one of the built-in ones, e.g. papi.PropertyExporter, or a custom one
+func Run(ctx context.Context, e exporter.Exporter, args []string, flags Flags, opts Options) error {
+	spec := e.Spec()
+	if len(args) != len(spec.Args) {
+		return fmt.Errorf("%w: %s expects %d arguments, got %d", ErrInvalidArguments, spec.Name, len(spec.Args), len(args))
+	}
+	if len(spec.Args) == 0 && len(spec.Subcommands) > 0 {
+		return fmt.Errorf("%w: %s exports objects with its subcommands", ErrInvalidArguments, spec.Name)
+	}
+
+	export, err := newExport(spec, args, flags, opts)
+	if err != nil {
+		return err
+	}
+	reporter := opts.Reporter
+	if reporter == nil {
+		reporter = progress.Discard()
+	}
+	ctx = progress.WithReporter(ctx, reporter)
+	ctx = templates.WithOutput(ctx, export.Output)
+	return e.Export(ctx, export)
+}
+
+func newExport(spec exporter.Spec, args []string, flags Flags, opts Options) (*exporter.Export, error) {
+	importStyle, err := templates.ParseImportStyle(string(opts.ImportStyle))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidOptions, err)
+	}
+	secrets, err := templates.ParseSecretsMode(string(opts.Secrets))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidOptions, err)
+	}
+
+	workPath := "./"
+	if opts.WorkPath != "" {
+		workPath = opts.WorkPath
+	}
+	output := opts.Output
+	if output == nil {
+		output = templates.DiskSink{Conflict: templates.ConflictFail}
+	}
+	edgercPath := edgegrid.DefaultConfigFile
+	if opts.EdgercPath != "" {
+		edgercPath = opts.EdgercPath
+	}
+	section := edgegrid.DefaultSection
+	if opts.Section != "" {
+		section = opts.Section
+	}
+	if flags == nil {
+		flags = Flags{}
+	}
+
+	return &exporter.Export{
+		Spec:        spec,
+		Args:        args,
+		Flags:       flags,
+		WorkPath:    filepath.FromSlash(workPath),
+		ImportStyle: importStyle,
+		Secrets:     secrets,
+		Output:      output,
+		Session:     opts.Session,
+		Clients:     opts.Clients,
+		EdgercPath:  edgercPath,
+		Section:     section,
+		TemplateDir: opts.TemplateDir,
+		Versions:    opts.Versions,
+		Backend:     opts.Backend,
+	}, nil
+}
+
+// IsSet returns true if the flag has a value other than the zero value of its type
+func (f Flags) IsSet(name string) bool {
+	value, ok := f[name]
+	return ok && value != nil && !reflect.ValueOf(value).IsZero()
+}
+
+// String returns value of the string flag
+func (f Flags) String(name string) string {
+	value, _ := f[name].(string)
+	return value
+}
+
+// Bool returns value of the bool flag
+func (f Flags) Bool(name string) bool {
+	value, _ := f[name].(bool)
+	return value
+}
+
+// Int returns value of the int flag
+func (f Flags) Int(name string) int {
+	value, _ := f[name].(int)
+	return value
+}
+
+// StringSlice returns value of the string slice flag
+func (f Flags) StringSlice(name string) []string {
+	value, _ := f[name].([]string)
+	return value
+}
+
+// concurrency returns the given concurrency or the default one, if it is not set
+func concurrency(c int) int {
+	if c < 1 {
+		return defaultConcurrency
+	}
+	return c
+}
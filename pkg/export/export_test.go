@@ -0,0 +1,350 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/clientlists"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudaccess"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+type testExporter struct {
+	spec   exporter.Spec
+	export func(context.Context, *exporter.Export) error
+}
+
+func (e testExporter) Spec() exporter.Spec {
+	return e.spec
+}
+
+func (e testExporter) Export(ctx context.Context, export *exporter.Export) error {
+	if e.export == nil {
+		return nil
+	}
+	return e.export(ctx, export)
+}
+
+func TestRun(t *testing.T) {
+	spec := exporter.Spec{
+		Name:  "export-test",
+		Args:  []string{"id"},
+		Flags: []cli.Flag{&cli.BoolFlag{Name: "with-children"}},
+	}
+	sink := templates.NewMemorySink()
+
+	tests := map[string]struct {
+		spec      exporter.Spec
+		args      []string
+		flags     Flags
+		opts      Options
+		check     func(*testing.T, context.Context, *exporter.Export)
+		withError error
+	}{
+		"defaults": {
+			spec: spec,
+			args: []string{"123"},
+			check: func(t *testing.T, ctx context.Context, e *exporter.Export) {
+				assert.Equal(t, []string{"123"}, e.Args)
+				assert.Equal(t, "./", e.WorkPath)
+				assert.Equal(t, "default", e.Section)
+				assert.Equal(t, "~/.edgerc", e.EdgercPath)
+				assert.Equal(t, templates.ImportStyleScript, e.ImportStyle)
+				assert.Equal(t, templates.SecretsTFVars, e.Secrets)
+				assert.Equal(t, templates.DiskSink{Conflict: templates.ConflictFail}, e.Output)
+				assert.Equal(t, e.Output, templates.GetOutput(ctx))
+				assert.Equal(t, progress.Discard(), progress.Get(ctx))
+				assert.False(t, e.Flags.IsSet("with-children"))
+			},
+		},
+		"options and flags": {
+			spec:  spec,
+			args:  []string{"123"},
+			flags: Flags{"with-children": true, "name": "test", "concurrency": 0},
+			opts: Options{
+				Output:      sink,
+				WorkPath:    "out/test",
+				ImportStyle: templates.ImportStyleBlocks,
+				Secrets:     templates.SecretsEnv,
+				Section:     "test",
+				EdgercPath:  "/edgerc",
+			},
+			check: func(t *testing.T, ctx context.Context, e *exporter.Export) {
+				assert.Equal(t, filepath.FromSlash("out/test"), e.WorkPath)
+				assert.Equal(t, "test", e.Section)
+				assert.Equal(t, "/edgerc", e.EdgercPath)
+				assert.Equal(t, templates.ImportStyleBlocks, e.ImportStyle)
+				assert.Equal(t, templates.SecretsEnv, e.Secrets)
+				assert.Equal(t, sink, e.Output)
+				assert.Equal(t, sink, templates.GetOutput(ctx))
+				assert.True(t, e.Flags.IsSet("with-children"))
+				assert.True(t, e.Flags.Bool("with-children"))
+				assert.Equal(t, "test", e.Flags.String("name"))
+				assert.False(t, e.Flags.IsSet("concurrency"))
+			},
+		},
+		"too many arguments": {
+			spec:      spec,
+			args:      []string{"123", "456"},
+			withError: ErrInvalidArguments,
+		},
+		"exporter with subcommands only": {
+			spec:      exporter.Spec{Name: "export-test", Subcommands: []exporter.Exporter{testExporter{spec: spec}}},
+			withError: ErrInvalidArguments,
+		},
+		"invalid import style": {
+			spec:      spec,
+			args:      []string{"123"},
+			opts:      Options{ImportStyle: "invalid"},
+			withError: ErrInvalidOptions,
+		},
+		"invalid secrets mode": {
+			spec:      spec,
+			args:      []string{"123"},
+			opts:      Options{Secrets: "invalid"},
+			withError: ErrInvalidOptions,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var called bool
+			e := testExporter{
+				spec: test.spec,
+				export: func(ctx context.Context, e *exporter.Export) error {
+					called = true
+					test.check(t, ctx, e)
+					return nil
+				},
+			}
+
+			err := Run(context.Background(), e, test.args, test.flags, test.opts)
+			if test.withError != nil {
+				assert.ErrorIs(t, err, test.withError)
+				assert.False(t, called)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, called)
+		})
+	}
+}
+
+func TestFlags(t *testing.T) {
+	flags := Flags{
+		"bool":         true,
+		"string":       "value",
+		"int":          5,
+		"string-slice": []string{"a", "b"},
+		"empty":        "",
+		"nil":          nil,
+	}
+
+	for _, name := range []string{"bool", "string", "int", "string-slice"} {
+		assert.True(t, flags.IsSet(name), name)
+	}
+	for _, name := range []string{"empty", "nil", "missing"} {
+		assert.False(t, flags.IsSet(name), name)
+	}
+	assert.True(t, flags.Bool("bool"))
+	assert.Equal(t, "value", flags.String("string"))
+	assert.Equal(t, 5, flags.Int("int"))
+	assert.Equal(t, []string{"a", "b"}, flags.StringSlice("string-slice"))
+	// values of other types are zero values
+	assert.False(t, flags.Bool("string"))
+	assert.Equal(t, "", flags.String("int"))
+	assert.Equal(t, 0, flags.Int("missing"))
+	assert.Nil(t, flags.StringSlice("nil"))
+}
+
+func TestClientListConcurrently(t *testing.T) {
+	const exports = 5
+	m := &clientlists.Mock{}
+	for i := 0; i < exports; i++ {
+		listID := fmt.Sprintf("%d_LIST", i)
+		m.On("GetClientList", mock.Anything, clientlists.GetClientListRequest{ListID: listID, IncludeItems: true}).
+			Return(&clientlists.GetClientListResponse{
+				ListContent: clientlists.ListContent{ListID: listID, Name: "list " + listID, Type: "IP"},
+				ContractID:  "C-1",
+				GroupID:     1,
+				Items:       []clientlists.ListItemContent{{Value: fmt.Sprintf("1.1.1.%d", i)}},
+			}, nil).Once()
+		m.On("GetActivationStatus", mock.Anything, mock.MatchedBy(func(r clientlists.GetActivationStatusRequest) bool {
+			return r.ListID == listID
+		})).Return(&clientlists.GetActivationStatusResponse{ListID: listID, ActivationStatus: "INACTIVE"}, nil).Twice()
+	}
+
+	sinks := make([]*templates.MemorySink, exports)
+	errs := make([]error, exports)
+	var wg sync.WaitGroup
+	for i := 0; i < exports; i++ {
+		i := i
+		sinks[i] = templates.NewMemorySink()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = ClientList(context.Background(), ClientListOptions{
+				Options: Options{
+					Clients:  exporter.Clients{ClientLists: m},
+					Output:   sinks[i],
+					WorkPath: fmt.Sprintf("list%d", i),
+					Section:  fmt.Sprintf("section%d", i),
+				},
+				ListID: fmt.Sprintf("%d_LIST", i),
+			})
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < exports; i++ {
+		require.NoError(t, errs[i])
+		listID := fmt.Sprintf("%d_LIST", i)
+		assert.Contains(t, sinks[i].Files(), filepath.Join(fmt.Sprintf("list%d", i), listID+".json"))
+		for _, file := range sinks[i].Files() {
+			content, _ := sinks[i].File(file)
+			for j := 0; j < exports; j++ {
+				if j != i {
+					assert.False(t, strings.Contains(string(content), fmt.Sprintf("%d_LIST", j)), "%s contains list %d", file, j)
+					assert.False(t, strings.Contains(string(content), fmt.Sprintf("section%d", j)), "%s contains section%d", file, j)
+				}
+			}
+		}
+		variables, ok := sinks[i].File(filepath.Join(fmt.Sprintf("list%d", i), "variables.tf"))
+		require.True(t, ok, sinks[i].Files())
+		assert.Contains(t, string(variables), fmt.Sprintf("section%d", i))
+	}
+	m.AssertExpectations(t)
+}
+
+func TestTwoProductsInOneDirectoryInJSONSyntax(t *testing.T) {
+	lists := &clientlists.Mock{}
+	lists.On("GetClientList", mock.Anything, clientlists.GetClientListRequest{ListID: "1_LIST", IncludeItems: true}).
+		Return(&clientlists.GetClientListResponse{
+			ListContent: clientlists.ListContent{ListID: "1_LIST", Name: "list", Type: "IP"},
+			ContractID:  "C-1",
+			GroupID:     1,
+		}, nil).Once()
+	lists.On("GetActivationStatus", mock.Anything, mock.Anything).
+		Return(&clientlists.GetActivationStatusResponse{ListID: "1_LIST", ActivationStatus: "INACTIVE"}, nil).Twice()
+	keys := &cloudaccess.Mock{}
+	keys.On("GetAccessKey", mock.Anything, cloudaccess.AccessKeyRequest{AccessKeyUID: 2}).
+		Return(&cloudaccess.GetAccessKeyResponse{
+			AccessKeyUID:         2,
+			AccessKeyName:        "key",
+			AuthenticationMethod: "AWS4_HMAC_SHA256",
+			NetworkConfiguration: &cloudaccess.SecureNetwork{SecurityNetwork: "ENHANCED_TLS"},
+			Groups:               []cloudaccess.Group{{GroupID: 1, ContractIDs: []string{"C-1"}}},
+		}, nil).Once()
+	keys.On("ListAccessKeyVersions", mock.Anything, cloudaccess.ListAccessKeyVersionsRequest{AccessKeyUID: 2}).
+		Return(&cloudaccess.ListAccessKeyVersionsResponse{}, nil).Once()
+
+	dir := t.TempDir()
+	opts := Options{
+		Clients:  exporter.Clients{ClientLists: lists, CloudAccess: keys},
+		Output:   templates.JSONSink{OutputSink: templates.DiskSink{Conflict: templates.ConflictMerge}},
+		WorkPath: dir,
+	}
+	require.NoError(t, ClientList(context.Background(), ClientListOptions{Options: opts, ListID: "1_LIST"}))
+	require.NoError(t, AccessKey(context.Background(), AccessKeyOptions{Options: opts, AccessKeyUID: 2}))
+
+	content, err := os.ReadFile(filepath.Join(dir, "variables.tf.json"))
+	require.NoError(t, err)
+	var variables struct {
+		Variable map[string]interface{} `json:"variable"`
+	}
+	require.NoError(t, json.Unmarshal(content, &variables))
+	assert.Contains(t, variables.Variable, "contract_id", "variables of the client list are kept")
+	assert.Contains(t, variables.Variable, "group_id", "variables of the client list are kept")
+	assert.Contains(t, variables.Variable, "config_section")
+	for _, file := range []string{"client-list.tf.json", "cloudaccess.tf.json"} {
+		_, err := os.Stat(filepath.Join(dir, file))
+		assert.NoError(t, err, file)
+	}
+	lists.AssertExpectations(t)
+	keys.AssertExpectations(t)
+}
+
+func TestTwoExportsWithSecretsInOneDirectory(t *testing.T) {
+	e := testExporter{
+		spec: exporter.Spec{
+			Name: "export-key",
+			Args: []string{"name", "value"},
+			Templates: exporter.TemplateSet{
+				FS: fstest.MapFS{"key.tmpl": {Data: []byte(`resource "akamai_key" "{{.Name}}" {
+  secret = {{secret .Name .Value}}
+}
+`)}},
+				Files:           map[string]string{"key.tmpl": "keys.tf"},
+				ProviderVersion: "6.0.0",
+			},
+		},
+		export: func(_ context.Context, e *exporter.Export) error {
+			return e.Processor().ProcessTemplates(struct{ Name, Value string }{e.Arg(0), e.Arg(1)})
+		},
+	}
+
+	dir := t.TempDir()
+	opts := Options{Output: templates.DiskSink{Conflict: templates.ConflictMerge}, WorkPath: dir}
+	require.NoError(t, Run(context.Background(), e, []string{"first", "secret1"}, nil, opts))
+	require.NoError(t, Run(context.Background(), e, []string{"second", "secret2"}, nil, opts))
+
+	declarations, err := os.ReadFile(filepath.Join(dir, templates.SecretsFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(declarations), `variable "first"`)
+	assert.Contains(t, string(declarations), `variable "second"`)
+	values, err := os.ReadFile(filepath.Join(dir, templates.SecretsVarsFileName))
+	require.NoError(t, err)
+	assert.Equal(t, "first  = \"secret1\"\nsecond = \"secret2\"\n", string(values))
+}
+
+func TestExportInJSONSyntaxIntoExistingDirectory(t *testing.T) {
+	e := testExporter{
+		spec: exporter.Spec{
+			Name: "export-key",
+			Args: []string{"name"},
+			Templates: exporter.TemplateSet{
+				FS: fstest.MapFS{"key.tmpl": {Data: []byte(`resource "akamai_key" "{{.}}" {
+  name = var.shared
+}
+`)}},
+				Files:           map[string]string{"key.tmpl": "key.tf"},
+				ProviderVersion: "6.0.0",
+			},
+		},
+		export: func(_ context.Context, e *exporter.Export) error {
+			return e.Processor().ProcessTemplates(e.Arg(0))
+		},
+	}
+
+	dir := t.TempDir()
+	output := templates.JSONSink{OutputSink: templates.DiskSink{Conflict: templates.ConflictOverwrite}}
+	require.NoError(t, templates.Versions{}.WriteVersions(output, dir, "9.0.0"))
+	provider := []byte(`{"provider": {"akamai": {"edgerc": "~/.edgerc", "config_section": "custom"}}}`)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "provider.tf.json"), provider, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared.tf.json"), []byte(`{"variable": {"shared": {}}}`), 0644))
+
+	require.NoError(t, Run(context.Background(), e, []string{"a"}, nil, Options{Output: output, WorkPath: dir}),
+		"var.shared declared in existing shared.tf.json is resolved")
+
+	versions, err := os.ReadFile(filepath.Join(dir, "versions.tf.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(versions), `">= 9.0.0"`, "higher provider version of existing versions.tf.json is kept")
+	content, err := os.ReadFile(filepath.Join(dir, "provider.tf.json"))
+	require.NoError(t, err)
+	assert.Equal(t, provider, content, "existing provider.tf.json is kept")
+	_, err = os.Stat(filepath.Join(dir, "key.tf.json"))
+	assert.NoError(t, err)
+}
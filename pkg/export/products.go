@@ -0,0 +1,289 @@
+package export
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/edgeworkers"
+	"github.com/akamai/cli-terraform/pkg/providers/appsec"
+	"github.com/akamai/cli-terraform/pkg/providers/clientlists"
+	"github.com/akamai/cli-terraform/pkg/providers/cloudaccess"
+	"github.com/akamai/cli-terraform/pkg/providers/cloudlets"
+	"github.com/akamai/cli-terraform/pkg/providers/cloudwrapper"
+	"github.com/akamai/cli-terraform/pkg/providers/cps"
+	"github.com/akamai/cli-terraform/pkg/providers/dns"
+	ew "github.com/akamai/cli-terraform/pkg/providers/edgeworkers"
+	"github.com/akamai/cli-terraform/pkg/providers/gtm"
+	"github.com/akamai/cli-terraform/pkg/providers/iam"
+	"github.com/akamai/cli-terraform/pkg/providers/imaging"
+	"github.com/akamai/cli-terraform/pkg/providers/papi"
+)
+
+type (
+	// DomainOptions are options of the export of a GTM domain, see export-domain command
+	DomainOptions struct {
+		Options
+		Domain string
+	}
+
+	// ZoneOptions are options of the export of a DNS zone, see export-zone command
+	ZoneOptions struct {
+		Options
+		Zone string
+		// Resources writes <zone>_resources.json with the record sets of the zone, used as input by CreateConfig
+		Resources bool
+		// CreateConfig writes configuration of the zone and its record sets
+		CreateConfig bool
+		// ImportScript writes the import script of the configuration
+		ImportScript bool
+		// SegmentConfig groups record sets by name into modules
+		SegmentConfig bool
+		// ConfigOnly writes configuration of all record sets, ignoring existing resources file
+		ConfigOnly bool
+		// NamesOnly assumes all record set types of the record names
+		NamesOnly bool
+		// RecordNames limits exported record sets to the ones with the given names
+		RecordNames []string
+	}
+
+	// SecurityConfigurationOptions are options of the export of a security configuration, see export-appsec command
+	SecurityConfigurationOptions struct {
+		Options
+		Name string
+	}
+
+	// ClientListOptions are options of the export of a client list, see export-clientlist command
+	ClientListOptions struct {
+		Options
+		ListID string
+	}
+
+	// PropertyOptions are options of the export of a property, see export-property command
+	PropertyOptions struct {
+		Options
+		PropertyName string
+		// Version is the exported version of the property, the latest one if it is empty
+		Version string
+		// WithIncludes exports includes referenced by the property as well
+		WithIncludes bool
+		// RulesAsHCL exports rules as data sources instead of JSON snippets
+		RulesAsHCL bool
+		// Bootstrap exports the property with akamai_property_bootstrap resource
+		Bootstrap bool
+		// Concurrency is the maximum number of includes fetched at the same time, 4 if it is not set
+		Concurrency int
+	}
+
+	// IncludeOptions are options of the export of an include, see export-property-include command
+	IncludeOptions struct {
+		Options
+		ContractID  string
+		IncludeName string
+		// RulesAsHCL exports rules as data sources instead of JSON snippets
+		RulesAsHCL bool
+	}
+
+	// IncludeRuleOptions are options of the export of a single rule of an include, see export-property-include-rule command
+	IncludeRuleOptions struct {
+		IncludeOptions
+		RuleName string
+	}
+
+	// CloudWrapperOptions are options of the export of a CloudWrapper configuration, see export-cloudwrapper command
+	CloudWrapperOptions struct {
+		Options
+		ConfigID int64
+	}
+
+	// CloudletsPolicyOptions are options of the export of a cloudlets policy, see export-cloudlets-policy command
+	CloudletsPolicyOptions struct {
+		Options
+		PolicyName string
+	}
+
+	// EdgeKVOptions are options of the export of an EdgeKV namespace, see export-edgekv command
+	EdgeKVOptions struct {
+		Options
+		Namespace string
+		Network   edgeworkers.NamespaceNetwork
+		// Concurrency is the maximum number of items fetched at the same time, 4 if it is not set
+		Concurrency int
+	}
+
+	// EdgeWorkerOptions are options of the export of an EdgeWorker, see export-edgeworker command
+	EdgeWorkerOptions struct {
+		Options
+		EdgeWorkerID int
+		// BundlePath is the directory of the code bundle, WorkPath if it is empty
+		BundlePath string
+	}
+
+	// IAMOptions are options of the exports of IAM users, groups and roles, see export-iam command
+	IAMOptions struct {
+		Options
+		// Concurrency is the maximum number of users fetched at the same time, 4 if it is not set
+		Concurrency int
+	}
+
+	// IAMGroupOptions are options of the export of an IAM group, see export-iam group command
+	IAMGroupOptions struct {
+		IAMOptions
+		GroupID int64
+	}
+
+	// IAMRoleOptions are options of the export of an IAM role, see export-iam role command
+	IAMRoleOptions struct {
+		IAMOptions
+		RoleID int64
+	}
+
+	// IAMUserOptions are options of the export of an IAM user, see export-iam user command
+	IAMUserOptions struct {
+		IAMOptions
+		Email string
+	}
+
+	// PolicySetOptions are options of the export of an image and video manager policy set, see export-imaging command
+	PolicySetOptions struct {
+		Options
+		ContractID  string
+		PolicySetID string
+		// PolicyJSONDir is the directory of policy JSON files, relative to WorkPath
+		PolicyJSONDir string
+		// PolicyAsHCL generates content of the policies using HCL instead of JSON files
+		PolicyAsHCL bool
+	}
+
+	// EnrollmentOptions are options of the export of a CPS enrollment, see export-cps command
+	EnrollmentOptions struct {
+		Options
+		EnrollmentID int
+		ContractID   string
+	}
+
+	// AccessKeyOptions are options of the export of a cloud access key, see export-cloudaccess command
+	AccessKeyOptions struct {
+		Options
+		AccessKeyUID int64
+	}
+)
+
+// Domain exports GTM domain
+func Domain(ctx context.Context, opts DomainOptions) error {
+	return Run(ctx, gtm.Exporter{}, []string{opts.Domain}, nil, opts.Options)
+}
+
+// Zone exports DNS zone, depending on the options it gathers its resources, creates the configuration
+// and the import script
+func Zone(ctx context.Context, opts ZoneOptions) error {
+	flags := Flags{
+		"resources":     opts.Resources,
+		"createconfig":  opts.CreateConfig,
+		"importscript":  opts.ImportScript,
+		"segmentconfig": opts.SegmentConfig,
+		"configonly":    opts.ConfigOnly,
+		"namesonly":     opts.NamesOnly,
+		"recordname":    opts.RecordNames,
+	}
+	return Run(ctx, dns.Exporter{}, []string{opts.Zone}, flags, opts.Options)
+}
+
+// SecurityConfiguration exports application security configuration
+func SecurityConfiguration(ctx context.Context, opts SecurityConfigurationOptions) error {
+	return Run(ctx, appsec.Exporter{}, []string{opts.Name}, nil, opts.Options)
+}
+
+// ClientList exports client list
+func ClientList(ctx context.Context, opts ClientListOptions) error {
+	return Run(ctx, clientlists.Exporter{}, []string{opts.ListID}, nil, opts.Options)
+}
+
+// Property exports property, with its includes if requested
+func Property(ctx context.Context, opts PropertyOptions) error {
+	flags := Flags{
+		"version":                   opts.Version,
+		"with-includes":             opts.WithIncludes,
+		"rules-as-hcl":              opts.RulesAsHCL,
+		"akamai-property-bootstrap": opts.Bootstrap,
+		"concurrency":               concurrency(opts.Concurrency),
+	}
+	return Run(ctx, papi.PropertyExporter{}, []string{opts.PropertyName}, flags, opts.Options)
+}
+
+// Include exports property include
+func Include(ctx context.Context, opts IncludeOptions) error {
+	flags := Flags{"rules-as-hcl": opts.RulesAsHCL}
+	return Run(ctx, papi.IncludeExporter{}, []string{opts.ContractID, opts.IncludeName}, flags, opts.Options)
+}
+
+// IncludeRule exports single rule of property include
+func IncludeRule(ctx context.Context, opts IncludeRuleOptions) error {
+	flags := Flags{"rules-as-hcl": opts.RulesAsHCL}
+	args := []string{opts.ContractID, opts.IncludeName, opts.RuleName}
+	return Run(ctx, papi.IncludeRuleExporter{}, args, flags, opts.Options)
+}
+
+// CloudWrapper exports CloudWrapper configuration
+func CloudWrapper(ctx context.Context, opts CloudWrapperOptions) error {
+	return Run(ctx, cloudwrapper.Exporter{}, []string{strconv.FormatInt(opts.ConfigID, 10)}, nil, opts.Options)
+}
+
+// CloudletsPolicy exports cloudlets policy
+func CloudletsPolicy(ctx context.Context, opts CloudletsPolicyOptions) error {
+	return Run(ctx, cloudlets.Exporter{}, []string{opts.PolicyName}, nil, opts.Options)
+}
+
+// EdgeKV exports EdgeKV namespace with its items
+func EdgeKV(ctx context.Context, opts EdgeKVOptions) error {
+	flags := Flags{"concurrency": concurrency(opts.Concurrency)}
+	return Run(ctx, ew.EdgeKVExporter{}, []string{opts.Namespace, string(opts.Network)}, flags, opts.Options)
+}
+
+// EdgeWorker exports EdgeWorker with its code bundle
+func EdgeWorker(ctx context.Context, opts EdgeWorkerOptions) error {
+	flags := Flags{"bundlepath": opts.BundlePath}
+	return Run(ctx, ew.EdgeWorkerExporter{}, []string{strconv.Itoa(opts.EdgeWorkerID)}, flags, opts.Options)
+}
+
+// IAMAll exports all IAM users, groups and roles
+func IAMAll(ctx context.Context, opts IAMOptions) error {
+	return Run(ctx, iam.AllExporter{}, nil, opts.flags(), opts.Options)
+}
+
+// IAMGroup exports IAM group with its users and roles
+func IAMGroup(ctx context.Context, opts IAMGroupOptions) error {
+	return Run(ctx, iam.GroupExporter{}, []string{strconv.FormatInt(opts.GroupID, 10)}, opts.flags(), opts.Options)
+}
+
+// IAMRole exports IAM role with its users and groups
+func IAMRole(ctx context.Context, opts IAMRoleOptions) error {
+	return Run(ctx, iam.RoleExporter{}, []string{strconv.FormatInt(opts.RoleID, 10)}, opts.flags(), opts.Options)
+}
+
+// IAMUser exports IAM user with its groups and roles
+func IAMUser(ctx context.Context, opts IAMUserOptions) error {
+	return Run(ctx, iam.UserExporter{}, []string{opts.Email}, opts.flags(), opts.Options)
+}
+
+// PolicySet exports image and video manager policy set with its policies
+func PolicySet(ctx context.Context, opts PolicySetOptions) error {
+	flags := Flags{
+		"policy-json-dir": opts.PolicyJSONDir,
+		"policy-as-hcl":   opts.PolicyAsHCL,
+	}
+	return Run(ctx, imaging.Exporter{}, []string{opts.ContractID, opts.PolicySetID}, flags, opts.Options)
+}
+
+// Enrollment exports CPS enrollment
+func Enrollment(ctx context.Context, opts EnrollmentOptions) error {
+	return Run(ctx, cps.Exporter{}, []string{strconv.Itoa(opts.EnrollmentID), opts.ContractID}, nil, opts.Options)
+}
+
+// AccessKey exports cloud access key
+func AccessKey(ctx context.Context, opts AccessKeyOptions) error {
+	return Run(ctx, cloudaccess.Exporter{}, []string{strconv.FormatInt(opts.AccessKeyUID, 10)}, nil, opts.Options)
+}
+
+func (o IAMOptions) flags() Flags {
+	return Flags{"concurrency": concurrency(o.Concurrency)}
+}
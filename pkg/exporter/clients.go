@@ -0,0 +1,159 @@
+package exporter
+
+import (
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/appsec"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/botman"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/clientlists"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudaccess"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudlets"
+	v3 "github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudlets/v3"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudwrapper"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cps"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/dns"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/edgeworkers"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/gtm"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/hapi"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/iam"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/imaging"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
+)
+
+// Clients holds API clients used by exporters instead of the ones created from the session of the export,
+// e.g. clients configured by programs running exporters as a library, clients which are not set are created from the session
+type Clients struct {
+	APPSEC       appsec.APPSEC
+	Botman       botman.BotMan
+	ClientLists  clientlists.ClientLists
+	CloudAccess  cloudaccess.CloudAccess
+	Cloudlets    cloudlets.Cloudlets
+	CloudletsV3  v3.Cloudlets
+	CloudWrapper cloudwrapper.CloudWrapper
+	CPS          cps.CPS
+	DNS          dns.DNS
+	EdgeWorkers  edgeworkers.Edgeworkers
+	GTM          gtm.GTM
+	HAPI         hapi.HAPI
+	IAM          iam.IAM
+	Imaging      imaging.Imaging
+	PAPI         papi.PAPI
+}
+
+// APPSECClient returns application security client of the export
+func (e *Export) APPSECClient() appsec.APPSEC {
+	if e.Clients.APPSEC != nil {
+		return e.Clients.APPSEC
+	}
+	return appsec.Client(e.Session)
+}
+
+// BotmanClient returns bot manager client of the export
+func (e *Export) BotmanClient() botman.BotMan {
+	if e.Clients.Botman != nil {
+		return e.Clients.Botman
+	}
+	return botman.Client(e.Session)
+}
+
+// ClientListsClient returns client lists client of the export
+func (e *Export) ClientListsClient() clientlists.ClientLists {
+	if e.Clients.ClientLists != nil {
+		return e.Clients.ClientLists
+	}
+	return clientlists.Client(e.Session)
+}
+
+// CloudAccessClient returns cloud access manager client of the export
+func (e *Export) CloudAccessClient() cloudaccess.CloudAccess {
+	if e.Clients.CloudAccess != nil {
+		return e.Clients.CloudAccess
+	}
+	return cloudaccess.Client(e.Session)
+}
+
+// CloudletsClient returns cloudlets v2 client of the export
+func (e *Export) CloudletsClient() cloudlets.Cloudlets {
+	if e.Clients.Cloudlets != nil {
+		return e.Clients.Cloudlets
+	}
+	return cloudlets.Client(e.Session)
+}
+
+// CloudletsV3Client returns cloudlets v3 client of the export
+func (e *Export) CloudletsV3Client() v3.Cloudlets {
+	if e.Clients.CloudletsV3 != nil {
+		return e.Clients.CloudletsV3
+	}
+	return v3.Client(e.Session)
+}
+
+// CloudWrapperClient returns cloud wrapper client of the export
+func (e *Export) CloudWrapperClient() cloudwrapper.CloudWrapper {
+	if e.Clients.CloudWrapper != nil {
+		return e.Clients.CloudWrapper
+	}
+	return cloudwrapper.Client(e.Session)
+}
+
+// CPSClient returns certificate provisioning system client of the export
+func (e *Export) CPSClient() cps.CPS {
+	if e.Clients.CPS != nil {
+		return e.Clients.CPS
+	}
+	return cps.Client(e.Session)
+}
+
+// DNSClient returns edge DNS client of the export
+func (e *Export) DNSClient() dns.DNS {
+	if e.Clients.DNS != nil {
+		return e.Clients.DNS
+	}
+	return dns.Client(e.Session)
+}
+
+// EdgeWorkersClient returns edgeworkers and edgekv client of the export
+func (e *Export) EdgeWorkersClient() edgeworkers.Edgeworkers {
+	if e.Clients.EdgeWorkers != nil {
+		return e.Clients.EdgeWorkers
+	}
+	return edgeworkers.Client(e.Session)
+}
+
+// GTMClient returns global traffic management client of the export
+func (e *Export) GTMClient() gtm.GTM {
+	if e.Clients.GTM != nil {
+		return e.Clients.GTM
+	}
+	return gtm.Client(e.Session)
+}
+
+// HAPIClient returns edge hostnames client of the export
+func (e *Export) HAPIClient() hapi.HAPI {
+	if e.Clients.HAPI != nil {
+		return e.Clients.HAPI
+	}
+	return hapi.Client(e.Session)
+}
+
+// IAMClient returns identity and access management client of the export
+func (e *Export) IAMClient() iam.IAM {
+	if e.Clients.IAM != nil {
+		return e.Clients.IAM
+	}
+	return iam.Client(e.Session)
+}
+
+// ImagingClient returns image and video manager client of the export
+func (e *Export) ImagingClient() imaging.Imaging {
+	if e.Clients.Imaging != nil {
+		return e.Clients.Imaging
+	}
+	return imaging.Client(e.Session)
+}
+
+// PAPIClient returns property manager client of the export
+func (e *Export) PAPIClient() papi.PAPI {
+	if e.Clients.PAPI != nil {
+		return e.Clients.PAPI
+	}
+	return papi.Client(e.Session)
+}
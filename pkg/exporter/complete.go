@@ -0,0 +1,41 @@
+package exporter
+
+import (
+	"context"
+	"strings"
+)
+
+// CompleteFunc returns values of the argument following the arguments of the export, e.g. names of the objects
+// which can be exported; API clients are taken from the export
+type CompleteFunc func(ctx context.Context, e *Export) ([]string, error)
+
+// CompleteArgs returns CompleteFunc completing each argument with the function at its position,
+// arguments without a function or with a nil one are not completed
+func CompleteArgs(completers ...CompleteFunc) CompleteFunc {
+	return func(ctx context.Context, e *Export) ([]string, error) {
+		if len(e.Args) >= len(completers) || completers[len(e.Args)] == nil {
+			return nil, nil
+		}
+		return completers[len(e.Args)](ctx, e)
+	}
+}
+
+// CompleteValues returns CompleteFunc completing an argument with one of the given values, e.g. network name
+func CompleteValues(values ...string) CompleteFunc {
+	return func(context.Context, *Export) ([]string, error) {
+		return values, nil
+	}
+}
+
+// CompleteContractIDs returns IDs of the contracts available to the credentials, without 'ctr_' prefix
+func CompleteContractIDs(ctx context.Context, e *Export) ([]string, error) {
+	contracts, err := e.PAPIClient().GetContracts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(contracts.Contracts.Items))
+	for _, contract := range contracts.Contracts.Items {
+		ids = append(ids, strings.TrimPrefix(contract.ContractID, "ctr_"))
+	}
+	return ids, nil
+}
@@ -0,0 +1,54 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteArgs(t *testing.T) {
+	first := func(context.Context, *Export) ([]string, error) {
+		return []string{"first"}, nil
+	}
+	complete := CompleteArgs(first, nil, CompleteValues("staging", "production"))
+
+	tests := map[string]struct {
+		args     []string
+		expected []string
+	}{
+		"first argument":             {expected: []string{"first"}},
+		"argument without completer": {args: []string{"a"}},
+		"argument with values":       {args: []string{"a", "b"}, expected: []string{"staging", "production"}},
+		"all arguments given":        {args: []string{"a", "b", "c"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			values, err := complete(context.Background(), &Export{Args: test.args})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, values)
+		})
+	}
+}
+
+func TestCompleteContractIDs(t *testing.T) {
+	m := &papi.Mock{}
+	m.On("GetContracts", mock.Anything).Return(&papi.GetContractsResponse{
+		Contracts: papi.ContractsItems{Items: []*papi.Contract{{ContractID: "ctr_C-1"}, {ContractID: "C-2"}}},
+	}, nil).Once()
+	m.On("GetContracts", mock.Anything).Return(nil, errors.New("oops")).Once()
+	e := &Export{Clients: Clients{PAPI: m}}
+
+	ids, err := CompleteContractIDs(context.Background(), e)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"C-1", "C-2"}, ids)
+
+	_, err = CompleteContractIDs(context.Background(), e)
+	assert.ErrorContains(t, err, "oops")
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,189 @@
+// Package exporter defines exporters generating terraform configuration of Akamai products and their registry,
+// from which export commands are created
+package exporter
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/session"
+	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/urfave/cli/v2"
+)
+
+type (
+	// Exporter generates terraform configuration of objects of one product
+	Exporter interface {
+		// Spec describes the export command running the exporter
+		Spec() Spec
+		// Export fetches objects given by the arguments of the export and writes their configuration
+		// generated with the templates of the exporter
+		Export(ctx context.Context, e *Export) error
+	}
+
+	// Spec describes an export command
+	// Args are names of the required arguments, e.g. 'contract_id'
+	// Flags are specific to the exporter, flags shared by all export commands, e.g. tfworkpath, are added to the command
+	// Subcommands are exporters run as subcommands of the command, e.g. 'export-iam user'; if the command has no Args,
+	// one of the subcommands is required
+	// Complete, if set, returns values of the next argument of the command for shell completion, see CompleteArgs
+	Spec struct {
+		Name        string
+		Aliases     []string
+		Description string
+		Args        []string
+		Flags       []cli.Flag
+		Subcommands []Exporter
+		Templates   TemplateSet
+		Complete    CompleteFunc
+	}
+
+	// TemplateSet describes templates used by an exporter
+	// Files are names of the files generated from the templates, relative to tfworkpath, by template name,
+	// exporters which generate files named after the exported objects add these targets to the processor themselves
+	// Imports is the name of the template generating import commands of the exported resources, its file is written
+	// either as a script or as terraform import blocks, depending on the import style
+	// Prefix, if set, limits the templates of the set to the ones with names starting with it
+	// Data are values of the types passed to the templates by template name,
+	// data stored under empty name is passed to all other templates
+	// ProviderVersion is the minimal version of the akamai provider required by generated configuration
+	// SkipProvider prevents writing provider.tf, if the templates configure the provider themselves
+	TemplateSet struct {
+		FS              fs.FS
+		Files           map[string]string
+		Imports         string
+		Prefix          string
+		Funcs           template.FuncMap
+		Data            map[string]interface{}
+		ProviderVersion string
+		SkipProvider    bool
+	}
+
+	// Flags provides values of the flags of the export command, it is implemented by *cli.Context
+	Flags interface {
+		IsSet(name string) bool
+		String(name string) string
+		Bool(name string) bool
+		Int(name string) int
+		StringSlice(name string) []string
+	}
+
+	// Export holds arguments, flags and output of a single export
+	// Exporters take API clients from Clients, creating the ones which are not set from Session, and keep all the state
+	// of the export in it or in the context, so that exports may run at the same time in one process
+	Export struct {
+		Spec        Spec
+		Args        []string
+		Flags       Flags
+		WorkPath    string
+		ImportStyle templates.ImportStyle
+		Secrets     templates.SecretsMode
+		Output      templates.OutputSink
+		Session     session.Session
+		Clients     Clients
+		EdgercPath  string
+		Section     string
+		TemplateDir string
+		Versions    templates.Versions
+		Backend     templates.Backend
+	}
+)
+
+// SecretsFlag returns the flag of exporters which declare secrets as sensitive variables in secrets.tf
+func SecretsFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:        "secrets",
+		Usage:       "Where values of secrets, which are declared as sensitive variables in secrets.tf, are kept: 'tfvars' writes them into secrets.auto.tfvars added to .gitignore, 'env' leaves them to be set with TF_VAR_<name> environment variables",
+		DefaultText: "tfvars",
+	}
+}
+
+// NewExport returns Export of the command with the given spec, run with the flags and arguments of the context
+func NewExport(c *cli.Context, spec Spec) (*Export, error) {
+	importStyle, err := templates.ParseImportStyle(c.String("import-style"))
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := templates.ParseSecretsMode(c.String("secrets"))
+	if err != nil {
+		return nil, err
+	}
+
+	// WorkPath is a target directory for generated terraform resources
+	workPath := "./"
+	if c.IsSet("tfworkpath") {
+		workPath = c.String("tfworkpath")
+	}
+
+	return &Export{
+		Spec:        spec,
+		Args:        c.Args().Slice(),
+		Flags:       c,
+		WorkPath:    filepath.FromSlash(workPath),
+		ImportStyle: importStyle,
+		Secrets:     secrets,
+		Output:      templates.GetOutput(c.Context),
+		Session:     edgegrid.GetSession(c.Context),
+		EdgercPath:  edgegrid.GetEdgercPath(c),
+		Section:     edgegrid.GetEdgercSection(c),
+		TemplateDir: c.String("template-dir"),
+		Versions:    templates.GetVersions(c.Context),
+		Backend:     templates.GetBackend(c.Context),
+	}, nil
+}
+
+// Arg returns the argument at the given position or empty string if it was not given
+func (e *Export) Arg(i int) string {
+	if i < len(e.Args) {
+		return e.Args[i]
+	}
+	return ""
+}
+
+// Path returns the path of the file with the given name, relative to tfworkpath
+func (e *Export) Path(name string) string {
+	return filepath.Join(e.WorkPath, name)
+}
+
+// Targets returns paths of the files generated from the templates of the exporter by template name
+func (e *Export) Targets() map[string]string {
+	targets := make(map[string]string, len(e.Spec.Templates.Files))
+	for name, file := range e.Spec.Templates.Files {
+		if name == e.Spec.Templates.Imports {
+			file = e.ImportStyle.FileName(file)
+		}
+		targets[name] = e.Path(file)
+	}
+	return targets
+}
+
+// Check returns an error if any of the files generated from the templates or any of the given files cannot be written
+func (e *Export) Check(paths ...string) error {
+	targets := make([]string, 0, len(e.Spec.Templates.Files)+len(paths))
+	for _, path := range e.Targets() {
+		targets = append(targets, path)
+	}
+	sort.Strings(targets)
+	return e.Output.Check(append(targets, paths...)...)
+}
+
+// Processor returns template processor writing the files generated from the templates of the exporter into the output
+func (e *Export) Processor() templates.FSTemplateProcessor {
+	return templates.FSTemplateProcessor{
+		TemplatesFS:        e.Spec.Templates.FS,
+		TemplateTargets:    e.Targets(),
+		AdditionalFuncs:    e.Spec.Templates.Funcs,
+		ImportStyle:        e.ImportStyle,
+		Output:             e.Output,
+		TemplateDir:        e.TemplateDir,
+		Secrets:            e.Secrets,
+		Versions:           e.Versions,
+		MinProviderVersion: e.Spec.Templates.ProviderVersion,
+		SkipProvider:       e.Spec.Templates.SkipProvider,
+		Backend:            e.Backend,
+	}
+}
@@ -0,0 +1,157 @@
+package exporter
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/session"
+	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+var testSpec = Spec{
+	Name: "export-test",
+	Args: []string{"id"},
+	Templates: TemplateSet{
+		Files: map[string]string{
+			"main.tmpl":    "main.tf",
+			"imports.tmpl": "import.sh",
+			"module.tmpl":  "modules/test/main.tf",
+		},
+		Imports:         "imports.tmpl",
+		ProviderVersion: "6.0.0",
+	},
+}
+
+func TestNewExport(t *testing.T) {
+	tests := map[string]struct {
+		flags     map[string]string
+		args      []string
+		expected  Export
+		withError string
+	}{
+		"defaults": {
+			args: []string{"123"},
+			expected: Export{
+				Args:        []string{"123"},
+				WorkPath:    "./",
+				ImportStyle: templates.ImportStyleScript,
+				Secrets:     templates.SecretsTFVars,
+				EdgercPath:  "~/.edgerc",
+				Section:     "default",
+			},
+		},
+		"flags": {
+			flags: map[string]string{"tfworkpath": "out/test", "import-style": "blocks", "section": "test", "template-dir": "custom"},
+			args:  []string{"123"},
+			expected: Export{
+				Args:        []string{"123"},
+				WorkPath:    filepath.FromSlash("out/test"),
+				ImportStyle: templates.ImportStyleBlocks,
+				Secrets:     templates.SecretsTFVars,
+				EdgercPath:  "~/.edgerc",
+				Section:     "test",
+				TemplateDir: "custom",
+			},
+		},
+		"invalid import style": {
+			flags:     map[string]string{"import-style": "foo"},
+			withError: "invalid import style",
+		},
+		"invalid secrets mode": {
+			flags:     map[string]string{"secrets": "foo"},
+			withError: "invalid secrets mode",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			flagset := flag.NewFlagSet("test", flag.PanicOnError)
+			for _, name := range []string{"tfworkpath", "import-style", "secrets", "edgerc", "section", "template-dir"} {
+				flagset.String(name, "", "")
+			}
+			for k, v := range test.flags {
+				require.NoError(t, flagset.Set(k, v))
+			}
+			require.NoError(t, flagset.Parse(test.args))
+			sess, err := session.New()
+			require.NoError(t, err)
+			sink := templates.NewMemorySink()
+			c := cli.NewContext(cli.NewApp(), flagset, nil)
+			c.Context = templates.WithOutput(edgegrid.WithSession(c.Context, sess), sink)
+
+			e, err := NewExport(c, testSpec)
+			if test.withError != "" {
+				assert.ErrorContains(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testSpec, e.Spec)
+			assert.Equal(t, sink, e.Output)
+			assert.Equal(t, sess, e.Session)
+			assert.Equal(t, c, e.Flags)
+			test.expected.Spec, test.expected.Output, test.expected.Session, test.expected.Flags = e.Spec, e.Output, e.Session, e.Flags
+			assert.Equal(t, test.expected, *e)
+		})
+	}
+}
+
+func TestExportTargets(t *testing.T) {
+	tests := map[string]struct {
+		importStyle templates.ImportStyle
+		expected    map[string]string
+	}{
+		"import script": {
+			importStyle: templates.ImportStyleScript,
+			expected: map[string]string{
+				"main.tmpl":    filepath.Join("out", "main.tf"),
+				"imports.tmpl": filepath.Join("out", "import.sh"),
+				"module.tmpl":  filepath.Join("out", "modules", "test", "main.tf"),
+			},
+		},
+		"import blocks": {
+			importStyle: templates.ImportStyleBlocks,
+			expected: map[string]string{
+				"main.tmpl":    filepath.Join("out", "main.tf"),
+				"imports.tmpl": filepath.Join("out", "imports.tf"),
+				"module.tmpl":  filepath.Join("out", "modules", "test", "main.tf"),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := Export{Spec: testSpec, WorkPath: "out", ImportStyle: test.importStyle}
+			assert.Equal(t, test.expected, e.Targets())
+
+			processor := e.Processor()
+			assert.Equal(t, test.expected, processor.TemplateTargets)
+			assert.Equal(t, test.importStyle, processor.ImportStyle)
+			assert.Equal(t, "6.0.0", processor.MinProviderVersion)
+		})
+	}
+}
+
+func TestExportCheck(t *testing.T) {
+	dir := t.TempDir()
+	e := Export{Spec: testSpec, WorkPath: dir, Output: templates.DiskSink{Conflict: templates.ConflictFail}}
+	require.NoError(t, e.Check(e.Path("123.json")))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "123.json"), []byte("{}"), 0644))
+	assert.ErrorContains(t, e.Check(e.Path("123.json")), "123.json")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(""), 0644))
+	assert.ErrorContains(t, e.Check(), "main.tf")
+}
+
+func TestExportArg(t *testing.T) {
+	e := Export{Args: []string{"a", "b"}}
+	assert.Equal(t, "a", e.Arg(0))
+	assert.Equal(t, "b", e.Arg(1))
+	assert.Equal(t, "", e.Arg(2))
+}
@@ -0,0 +1,83 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Registry holds exporters in the order of their registration
+type Registry struct {
+	mu        sync.RWMutex
+	exporters []Exporter
+	names     map[string]struct{}
+}
+
+var (
+	// ErrInvalidExporter is returned when the spec of an exporter is not valid
+	ErrInvalidExporter = errors.New("invalid exporter")
+	// ErrDuplicateExporter is returned when the name or an alias of an exporter is already registered
+	ErrDuplicateExporter = errors.New("duplicate exporter")
+)
+
+// NewRegistry returns Registry with the given exporters
+func NewRegistry(exporters ...Exporter) (*Registry, error) {
+	r := &Registry{names: make(map[string]struct{})}
+	for _, e := range exporters {
+		if err := r.Register(e); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds the exporter to the registry
+// Names and aliases of the exporters have to be unique
+func (r *Registry) Register(e Exporter) error {
+	spec := e.Spec()
+	if spec.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidExporter)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names == nil {
+		r.names = make(map[string]struct{})
+	}
+	names := append([]string{spec.Name}, spec.Aliases...)
+	for _, name := range names {
+		if _, ok := r.names[name]; ok {
+			return fmt.Errorf("%w: '%s'", ErrDuplicateExporter, name)
+		}
+	}
+	for _, name := range names {
+		r.names[name] = struct{}{}
+	}
+	r.exporters = append(r.exporters, e)
+	return nil
+}
+
+// Exporters returns registered exporters in the order of their registration
+func (r *Registry) Exporters() []Exporter {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Exporter(nil), r.exporters...)
+}
+
+// Lookup returns the exporter with the given name or alias, or nil if it is not registered
+func (r *Registry) Lookup(name string) Exporter {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range r.exporters {
+		spec := e.Spec()
+		if spec.Name == name {
+			return e
+		}
+		for _, alias := range spec.Aliases {
+			if alias == name {
+				return e
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testExporter Spec
+
+func (e testExporter) Spec() Spec {
+	return Spec(e)
+}
+
+func (e testExporter) Export(_ context.Context, _ *Export) error {
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	tests := map[string]struct {
+		exporters []Exporter
+		expected  []string
+		withError error
+	}{
+		"exporters in the order of registration": {
+			exporters: []Exporter{
+				testExporter{Name: "export-b", Aliases: []string{"create-b"}},
+				testExporter{Name: "export-a"},
+			},
+			expected: []string{"export-b", "export-a"},
+		},
+		"duplicate name": {
+			exporters: []Exporter{testExporter{Name: "export-a"}, testExporter{Name: "export-a"}},
+			withError: ErrDuplicateExporter,
+		},
+		"name used as alias": {
+			exporters: []Exporter{
+				testExporter{Name: "export-a", Aliases: []string{"create-a"}},
+				testExporter{Name: "create-a"},
+			},
+			withError: ErrDuplicateExporter,
+		},
+		"missing name": {
+			exporters: []Exporter{testExporter{Aliases: []string{"create-a"}}},
+			withError: ErrInvalidExporter,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			registry, err := NewRegistry(test.exporters...)
+			if test.withError != nil {
+				assert.ErrorIs(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, e := range registry.Exporters() {
+				names = append(names, e.Spec().Name)
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	registry, err := NewRegistry(testExporter{Name: "export-a", Aliases: []string{"create-a"}})
+	require.NoError(t, err)
+
+	require.NotNil(t, registry.Lookup("export-a"))
+	require.NotNil(t, registry.Lookup("create-a"))
+	assert.Equal(t, "export-a", registry.Lookup("create-a").Spec().Name)
+	assert.Nil(t, registry.Lookup("export-b"))
+
+	// exporters can be registered after the registry is created
+	require.NoError(t, registry.Register(testExporter{Name: "export-b"}))
+	assert.NotNil(t, registry.Lookup("export-b"))
+	assert.ErrorIs(t, registry.Register(testExporter{Name: "create-a"}), ErrDuplicateExporter)
+}
@@ -0,0 +1,288 @@
+// Package progress reports progress of export steps in a way selected with --progress flag
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akamai/cli-terraform/pkg/tools"
+	"github.com/akamai/cli/pkg/terminal"
+)
+
+type (
+	// Reporter reports export steps and prints messages for the user
+	// A step is started with Start and ends with OK, Warn or Fail, only one step is in progress at a time
+	Reporter interface {
+		// Start starts a step described by the formatted string
+		Start(format string, args ...interface{})
+		// OK ends the current step successfully
+		OK()
+		// Warn ends the current step with a warning
+		Warn()
+		// Fail ends the current step with a failure
+		Fail()
+		// Printf prints a formatted message
+		Printf(format string, args ...interface{})
+		// Writeln prints a message followed by a new line
+		Writeln(args ...interface{}) (int, error)
+		// Finish reports the end of the whole command with the error it returned, if any
+		Finish(err error)
+	}
+
+	// Mode is the way in which progress is reported
+	Mode string
+
+	// Options contains information about the command passed to the reporter
+	Options struct {
+		// Command is the name of the command
+		Command string
+		// Object identifies exported object, e.g. with command arguments
+		Object string
+	}
+
+	// Event is a single progress event printed in ModeJSON
+	Event struct {
+		Time       time.Time `json:"time"`
+		Event      string    `json:"event"`
+		Step       string    `json:"step"`
+		Object     string    `json:"object,omitempty"`
+		DurationMS *int64    `json:"duration_ms,omitempty"`
+		Error      string    `json:"error,omitempty"`
+	}
+
+	spinnerReporter struct {
+		term func() terminal.Terminal
+	}
+
+	// streamReporter reports steps as lines of text or JSON events written to out, messages are printed to the terminal
+	streamReporter struct {
+		term     terminal.Terminal
+		out      io.Writer
+		opts     Options
+		json     bool
+		now      func() time.Time
+		mu       sync.Mutex
+		started  time.Time
+		step     string
+		stepTime time.Time
+		running  bool
+		finished bool
+	}
+
+	// discardReporter ignores all steps and messages, e.g. of exporters run as a library without a terminal
+	discardReporter struct{}
+
+	reporterContextKey struct{}
+)
+
+const (
+	// ModeSpinner shows an animated spinner, suited for interactive terminals
+	ModeSpinner Mode = "spinner"
+	// ModePlain prints a line of plain text when a step starts and ends
+	ModePlain Mode = "plain"
+	// ModeJSON prints a JSON event when a step starts and ends
+	ModeJSON Mode = "json"
+
+	// EventStart is the event of a started step
+	EventStart = "start"
+	// EventOK is the event of a step ended successfully
+	EventOK = "ok"
+	// EventWarn is the event of a step ended with a warning
+	EventWarn = "warn"
+	// EventFail is the event of a failed step
+	EventFail = "fail"
+)
+
+var (
+	// ErrInvalidMode is returned when unknown progress mode is requested
+	ErrInvalidMode = errors.New("invalid progress mode")
+
+	ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+	_ Reporter = &spinnerReporter{}
+	_ Reporter = &streamReporter{}
+	_ Reporter = discardReporter{}
+)
+
+// ParseMode returns Mode for the given name, empty name results in ModeSpinner
+func ParseMode(name string) (Mode, error) {
+	return tools.ParseEnum(name, ModeSpinner, ErrInvalidMode, ModeSpinner, ModePlain, ModeJSON)
+}
+
+// New returns Reporter for the given mode
+// Steps are reported to out in ModePlain and ModeJSON, messages are always printed to the terminal
+func New(mode Mode, term terminal.Terminal, out io.Writer, opts Options) Reporter {
+	switch mode {
+	case ModePlain, ModeJSON:
+		return &streamReporter{term: term, out: out, opts: opts, json: mode == ModeJSON, now: time.Now, started: time.Now()}
+	}
+	return &spinnerReporter{term: func() terminal.Terminal { return term }}
+}
+
+// Discard returns Reporter ignoring all steps and messages
+func Discard() Reporter {
+	return discardReporter{}
+}
+
+// WithReporter returns context with the given reporter
+func WithReporter(ctx context.Context, reporter Reporter) context.Context {
+	return context.WithValue(ctx, reporterContextKey{}, reporter)
+}
+
+// Get returns reporter stored in the context, if there is none the spinner of the terminal stored in the context is used
+func Get(ctx context.Context) Reporter {
+	if reporter, ok := ctx.Value(reporterContextKey{}).(Reporter); ok {
+		return reporter
+	}
+	return &spinnerReporter{term: func() terminal.Terminal { return terminal.Get(ctx) }}
+}
+
+func (r *spinnerReporter) Start(format string, args ...interface{}) {
+	r.term().Spinner().Start(format, args...)
+}
+
+func (r *spinnerReporter) OK() {
+	r.term().Spinner().OK()
+}
+
+func (r *spinnerReporter) Warn() {
+	r.term().Spinner().Warn()
+}
+
+func (r *spinnerReporter) Fail() {
+	r.term().Spinner().Fail()
+}
+
+func (r *spinnerReporter) Printf(format string, args ...interface{}) {
+	r.term().Printf(format, args...)
+}
+
+func (r *spinnerReporter) Writeln(args ...interface{}) (int, error) {
+	return r.term().Writeln(args...)
+}
+
+// Finish does nothing, as errors are printed by the command itself
+func (r *spinnerReporter) Finish(error) {}
+
+func (r *streamReporter) Start(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.step = strings.TrimSpace(fmt.Sprintf(format, args...))
+	r.stepTime = r.now()
+	r.running = true
+	r.report(r.stepTime, EventStart, r.step, nil, nil)
+}
+
+func (r *streamReporter) OK() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.end(EventOK, nil)
+}
+
+func (r *streamReporter) Warn() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.end(EventWarn, nil)
+}
+
+func (r *streamReporter) Fail() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.end(EventFail, nil)
+}
+
+func (r *streamReporter) Printf(format string, args ...interface{}) {
+	r.term.Printf(format, args...)
+}
+
+func (r *streamReporter) Writeln(args ...interface{}) (int, error) {
+	return r.term.Writeln(args...)
+}
+
+// Finish fails the step still in progress, if the command returned an error, and reports the end of the command
+func (r *streamReporter) Finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.finished {
+		return
+	}
+	r.finished = true
+	if err != nil {
+		r.end(EventFail, err)
+	}
+	event := EventOK
+	if err != nil {
+		event = EventFail
+	}
+	now := r.now()
+	duration := now.Sub(r.started)
+	r.report(now, event, r.opts.Command, &duration, err)
+}
+
+// end ends the current step, it has to be called with the mutex locked
+func (r *streamReporter) end(event string, err error) {
+	// steps ended without being started, e.g. after an error in a nested call, are not reported twice
+	if !r.running {
+		return
+	}
+	r.running = false
+	now := r.now()
+	duration := now.Sub(r.stepTime)
+	r.report(now, event, r.step, &duration, err)
+}
+
+func (r *streamReporter) report(now time.Time, event, step string, duration *time.Duration, err error) {
+	if r.json {
+		e := Event{Time: now.UTC(), Event: event, Step: step, Object: r.opts.Object}
+		if duration != nil {
+			ms := duration.Milliseconds()
+			e.DurationMS = &ms
+		}
+		if err != nil {
+			e.Error = errorMessage(err)
+		}
+		line, _ := json.Marshal(e)
+		_, _ = fmt.Fprintf(r.out, "%s\n", line)
+		return
+	}
+
+	line := step
+	if event != EventStart {
+		line += fmt.Sprintf(" [%s]", strings.ToUpper(event))
+	}
+	if duration != nil {
+		line += fmt.Sprintf(" (%s)", duration.Round(time.Millisecond))
+	}
+	if err != nil {
+		line += ": " + errorMessage(err)
+	}
+	_, _ = fmt.Fprintln(r.out, line)
+}
+
+// errorMessage returns the error message without colors added for the terminal
+func errorMessage(err error) string {
+	return strings.TrimSpace(ansiEscape.ReplaceAllString(err.Error(), ""))
+}
+
+func (discardReporter) Start(string, ...interface{}) {}
+
+func (discardReporter) OK() {}
+
+func (discardReporter) Warn() {}
+
+func (discardReporter) Fail() {}
+
+func (discardReporter) Printf(string, ...interface{}) {}
+
+func (discardReporter) Writeln(...interface{}) (int, error) {
+	return 0, nil
+}
+
+func (discardReporter) Finish(error) {}
@@ -0,0 +1,176 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/akamai/cli/pkg/terminal"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  Mode
+		withError bool
+	}{
+		"default": {
+			expected: ModeSpinner,
+		},
+		"spinner": {
+			given:    "spinner",
+			expected: ModeSpinner,
+		},
+		"plain": {
+			given:    "plain",
+			expected: ModePlain,
+		},
+		"json": {
+			given:    "json",
+			expected: ModeJSON,
+		},
+		"invalid": {
+			given:     "xml",
+			withError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mode, err := ParseMode(test.given)
+			if test.withError {
+				assert.ErrorIs(t, err, ErrInvalidMode)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, mode)
+		})
+	}
+}
+
+func TestStreamReporter(t *testing.T) {
+	tests := map[string]struct {
+		mode     Mode
+		run      func(Reporter)
+		expected string
+	}{
+		"plain steps": {
+			mode: ModePlain,
+			run: func(r Reporter) {
+				r.Start("Fetching property %s ", "test")
+				r.OK()
+				r.Start("Saving files ")
+				r.Warn()
+				r.Finish(nil)
+			},
+			expected: `Fetching property test
+Fetching property test [OK] (1s)
+Saving files
+Saving files [WARN] (1s)
+export-property [OK] (5s)
+`,
+		},
+		"plain failure": {
+			mode: ModePlain,
+			run: func(r Reporter) {
+				r.Start("Fetching property ")
+				r.Fail()
+				r.Finish(errors.New(color.RedString("property not found")))
+			},
+			expected: `Fetching property
+Fetching property [FAIL] (1s)
+export-property [FAIL] (3s): property not found
+`,
+		},
+		"json steps": {
+			mode: ModeJSON,
+			run: func(r Reporter) {
+				r.Start("Fetching property ")
+				r.OK()
+				r.Finish(nil)
+			},
+			expected: `{"time":"2024-09-04T12:00:01Z","event":"start","step":"Fetching property","object":"test"}
+{"time":"2024-09-04T12:00:02Z","event":"ok","step":"Fetching property","object":"test","duration_ms":1000}
+{"time":"2024-09-04T12:00:03Z","event":"ok","step":"export-property","object":"test","duration_ms":3000}
+`,
+		},
+		"json step in progress failed by command error": {
+			mode: ModeJSON,
+			run: func(r Reporter) {
+				r.Start("Fetching rules ")
+				r.Finish(errors.New("oops"))
+				r.Finish(errors.New("oops"))
+			},
+			expected: `{"time":"2024-09-04T12:00:01Z","event":"start","step":"Fetching rules","object":"test"}
+{"time":"2024-09-04T12:00:02Z","event":"fail","step":"Fetching rules","object":"test","duration_ms":1000,"error":"oops"}
+{"time":"2024-09-04T12:00:03Z","event":"fail","step":"export-property","object":"test","duration_ms":3000,"error":"oops"}
+`,
+		},
+		"json step ended twice": {
+			mode: ModeJSON,
+			run: func(r Reporter) {
+				r.Start("Fetching rules ")
+				r.Fail()
+				r.Fail()
+			},
+			expected: `{"time":"2024-09-04T12:00:01Z","event":"start","step":"Fetching rules","object":"test"}
+{"time":"2024-09-04T12:00:02Z","event":"fail","step":"Fetching rules","object":"test","duration_ms":1000}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			reporter := New(test.mode, terminal.New(terminal.DiscardWriter(), nil, terminal.DiscardWriter()), &out, Options{
+				Command: "export-property",
+				Object:  "test",
+			}).(*streamReporter)
+			now := time.Date(2024, 9, 4, 12, 0, 0, 0, time.UTC)
+			reporter.started = now
+			reporter.now = func() time.Time {
+				now = now.Add(time.Second)
+				return now
+			}
+
+			test.run(reporter)
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
+
+type recordingSpinner struct {
+	terminal.Spinner
+	calls []string
+}
+
+func (s *recordingSpinner) Start(f string, args ...interface{}) {
+	s.calls = append(s.calls, "start "+fmt.Sprintf(f, args...))
+}
+
+func (s *recordingSpinner) OK() {
+	s.calls = append(s.calls, "ok")
+}
+
+func TestGet(t *testing.T) {
+	term := &terminal.Mock{}
+	spinner := &recordingSpinner{}
+	term.On("Spinner").Return(spinner).Twice()
+	ctx := terminal.Context(context.Background(), term)
+
+	reporter := Get(ctx)
+	reporter.Start("Fetching %s ", "property")
+	reporter.OK()
+	reporter.Finish(errors.New("oops"))
+	term.AssertExpectations(t)
+	assert.Equal(t, []string{"start Fetching property ", "ok"}, spinner.calls)
+
+	stream := New(ModeJSON, term, &bytes.Buffer{}, Options{})
+	assert.Equal(t, stream, Get(WithReporter(ctx, stream)))
+}
@@ -7,25 +7,26 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/appsec"
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/botman"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
 
+// providerVersion is the minimal version of the akamai provider required by generated configuration
+const providerVersion = "6.4.0"
+
 //go:embed templates/*
 var templateFiles embed.FS
-var client appsec.APPSEC
-var botmanClient botman.BotMan
 
 var (
 	// ErrFetchingPolicy is returned when fetching policy fails
@@ -36,160 +37,164 @@ var (
 	ErrCloudletTypeNotSupported = errors.New("cloudlet type not supported")
 	// ErrSavingFiles is returned when an issue with processing templates occurs
 	ErrSavingFiles = errors.New("saving terraform project files")
-
-	section string
 )
 
-// CmdCreateAppsec is an entrypoint to create-appsec command
-func CmdCreateAppsec(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(ctx)
-	client = appsec.Client(sess)
-	botmanClient = botman.Client(sess)
-
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
-	}
-
-	// Directory Paths
-	modulesPath := filepath.Join(tfWorkPath, "modules")
-	securityModulePath := filepath.Join(modulesPath, "security")
-	activateSecurityModulePath := filepath.Join(modulesPath, "activate-security")
-	paths := []string{modulesPath, securityModulePath, activateSecurityModulePath}
+// additionalFuncs provide custom helper functions to get data that does not exist in the security config export
+// Functions calling the API or returning the section are added to them for each export, see templateFuncs
+var additionalFuncs = tools.DecorateWithMultilineHandlingFunctions(map[string]any{
+	"exportJSON":                             exportJSON,
+	"getCustomRuleNameByID":                  getCustomRuleNameByID,
+	"getMalwareNameByID":                     getMalwareNameByID,
+	"getPolicyNameByID":                      getPolicyNameByID,
+	"getPrefixFromID":                        getPrefixFromID,
+	"getRateNameByID":                        getRateNameByID,
+	"getRepNameByID":                         getRepNameByID,
+	"getRuleDescByID":                        getRuleDescByID,
+	"getRuleNameByID":                        getRuleNameByID,
+	"isStructuredRule":                       isStructuredRule,
+	"exportJSONWithoutKeys":                  exportJSONWithoutKeys,
+	"getCustomBotCategoryResourceNamesByIDs": getCustomBotCategoryResourceNamesByIDs,
+	"getCustomBotCategoryNameByID":           getCustomBotCategoryNameByID,
+	"getCustomClientResourceNamesByIDs":      getCustomClientResourceNamesByIDs,
+})
+
+// Exporter exports security configurations, see export-appsec command
+type Exporter struct{}
+
+// exportFuncs provide template functions which need the client and the section of the export
+type exportFuncs struct {
+	client  appsec.APPSEC
+	section string
+}
 
-	for _, path := range paths {
-		err := os.MkdirAll(path, 0755)
-		if err != nil {
-			return cli.NewExitError(color.RedString(err.Error()), 1)
-		}
+// Spec describes export-appsec command
+func (Exporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-appsec",
+		Aliases:     []string{"create-appsec"},
+		Description: "Generates Terraform configuration for Application Security resources",
+		Args:        []string{"security configuration name"},
+		Templates: exporter.TemplateSet{
+			FS: templateFiles,
+			Files: map[string]string{
+				"appsec.tmpl":                         "appsec.tf",
+				"imports.tmpl":                        "appsec-import.sh",
+				"main.tmpl":                           "appsec-main.tf",
+				"modules-activate-security-main.tmpl": "modules/activate-security/main.tf",
+				"modules-activate-security-variables.tmpl":      "modules/activate-security/variables.tf",
+				"modules-activate-security-versions.tmpl":       "modules/activate-security/versions.tf",
+				"modules-security-advanced.tmpl":                "modules/security/advanced.tf",
+				"modules-security-api.tmpl":                     "modules/security/api.tf",
+				"modules-security-custom-deny.tmpl":             "modules/security/custom-deny.tf",
+				"modules-security-custom-rules.tmpl":            "modules/security/custom-rules.tf",
+				"modules-security-firewall.tmpl":                "modules/security/firewall.tf",
+				"modules-security-main.tmpl":                    "modules/security/main.tf",
+				"modules-security-malware-policies.tmpl":        "modules/security/malware-policies.tf",
+				"modules-security-malware-policy-actions.tmpl":  "modules/security/malware-policy-actions.tf",
+				"modules-security-match-targets.tmpl":           "modules/security/match-targets.tf",
+				"modules-security-penalty-box.tmpl":             "modules/security/penalty-box.tf",
+				"modules-security-eval-penalty-box.tmpl":        "modules/security/eval-penalty-box.tf",
+				"modules-security-policies.tmpl":                "modules/security/policies.tf",
+				"modules-security-protections.tmpl":             "modules/security/protections.tf",
+				"modules-security-rate-policies.tmpl":           "modules/security/rate-policies.tf",
+				"modules-security-rate-policy-actions.tmpl":     "modules/security/rate-policy-actions.tf",
+				"modules-security-reputation-profiles.tmpl":     "modules/security/reputation-profiles.tf",
+				"modules-security-reputation.tmpl":              "modules/security/reputation.tf",
+				"modules-security-siem.tmpl":                    "modules/security/siem.tf",
+				"modules-security-slow-post.tmpl":               "modules/security/slow-post.tf",
+				"modules-security-variables.tmpl":               "modules/security/variables.tf",
+				"modules-security-versions.tmpl":                "modules/security/versions.tf",
+				"modules-security-waf.tmpl":                     "modules/security/waf.tf",
+				"modules-security-bot-directory.tmpl":           "modules/security/bot-directory.tf",
+				"modules-security-bot-directory-actions.tmpl":   "modules/security/bot-directory-actions.tf",
+				"modules-security-custom-client.tmpl":           "modules/security/custom-client.tf",
+				"modules-security-response-actions.tmpl":        "modules/security/response-actions.tf",
+				"modules-security-advanced-settings.tmpl":       "modules/security/advanced-settings.tf",
+				"modules-security-javascript-injection.tmpl":    "modules/security/javascript-injection.tf",
+				"modules-security-transactional-endpoints.tmpl": "modules/security/transactional-endpoints.tf",
+				"variables.tmpl":                                "appsec-variables.tf",
+			},
+			Imports:         "imports.tmpl",
+			Funcs:           templateFuncs(nil, ""),
+			Data:            map[string]interface{}{"": appsec.GetExportConfigurationResponse{}},
+			ProviderVersion: providerVersion,
+			SkipProvider:    true,
+		},
 	}
+}
 
-	// File Paths
-	appsecPath := filepath.Join(tfWorkPath, "appsec.tf")
+// Export exports the security configuration
+func (Exporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.APPSECClient()
 
-	err := tools.CheckFiles(appsecPath)
-	if err != nil {
+	if err := e.Output.Check(e.Path("appsec.tf")); err != nil {
 		return cli.NewExitError(color.RedString(err.Error()), 1)
 	}
 
-	// Save our section for use later
-	section = edgegrid.GetEdgercSection(c)
-
-	// Template to path mappings
-	templateToFile := map[string]string{
-		"appsec.tmpl":                         appsecPath,
-		"imports.tmpl":                        filepath.Join(tfWorkPath, "appsec-import.sh"),
-		"main.tmpl":                           filepath.Join(tfWorkPath, "appsec-main.tf"),
-		"modules-activate-security-main.tmpl": filepath.Join(activateSecurityModulePath, "main.tf"),
-		"modules-activate-security-variables.tmpl":      filepath.Join(activateSecurityModulePath, "variables.tf"),
-		"modules-activate-security-versions.tmpl":       filepath.Join(activateSecurityModulePath, "versions.tf"),
-		"modules-security-advanced.tmpl":                filepath.Join(securityModulePath, "advanced.tf"),
-		"modules-security-api.tmpl":                     filepath.Join(securityModulePath, "api.tf"),
-		"modules-security-custom-deny.tmpl":             filepath.Join(securityModulePath, "custom-deny.tf"),
-		"modules-security-custom-rules.tmpl":            filepath.Join(securityModulePath, "custom-rules.tf"),
-		"modules-security-firewall.tmpl":                filepath.Join(securityModulePath, "firewall.tf"),
-		"modules-security-main.tmpl":                    filepath.Join(securityModulePath, "main.tf"),
-		"modules-security-malware-policies.tmpl":        filepath.Join(securityModulePath, "malware-policies.tf"),
-		"modules-security-malware-policy-actions.tmpl":  filepath.Join(securityModulePath, "malware-policy-actions.tf"),
-		"modules-security-match-targets.tmpl":           filepath.Join(securityModulePath, "match-targets.tf"),
-		"modules-security-penalty-box.tmpl":             filepath.Join(securityModulePath, "penalty-box.tf"),
-		"modules-security-eval-penalty-box.tmpl":        filepath.Join(securityModulePath, "eval-penalty-box.tf"),
-		"modules-security-policies.tmpl":                filepath.Join(securityModulePath, "policies.tf"),
-		"modules-security-protections.tmpl":             filepath.Join(securityModulePath, "protections.tf"),
-		"modules-security-rate-policies.tmpl":           filepath.Join(securityModulePath, "rate-policies.tf"),
-		"modules-security-rate-policy-actions.tmpl":     filepath.Join(securityModulePath, "rate-policy-actions.tf"),
-		"modules-security-reputation-profiles.tmpl":     filepath.Join(securityModulePath, "reputation-profiles.tf"),
-		"modules-security-reputation.tmpl":              filepath.Join(securityModulePath, "reputation.tf"),
-		"modules-security-siem.tmpl":                    filepath.Join(securityModulePath, "siem.tf"),
-		"modules-security-slow-post.tmpl":               filepath.Join(securityModulePath, "slow-post.tf"),
-		"modules-security-variables.tmpl":               filepath.Join(securityModulePath, "variables.tf"),
-		"modules-security-versions.tmpl":                filepath.Join(securityModulePath, "versions.tf"),
-		"modules-security-waf.tmpl":                     filepath.Join(securityModulePath, "waf.tf"),
-		"modules-security-bot-directory.tmpl":           filepath.Join(securityModulePath, "bot-directory.tf"),
-		"modules-security-bot-directory-actions.tmpl":   filepath.Join(securityModulePath, "bot-directory-actions.tf"),
-		"modules-security-custom-client.tmpl":           filepath.Join(securityModulePath, "custom-client.tf"),
-		"modules-security-response-actions.tmpl":        filepath.Join(securityModulePath, "response-actions.tf"),
-		"modules-security-advanced-settings.tmpl":       filepath.Join(securityModulePath, "advanced-settings.tf"),
-		"modules-security-javascript-injection.tmpl":    filepath.Join(securityModulePath, "javascript-injection.tf"),
-		"modules-security-transactional-endpoints.tmpl": filepath.Join(securityModulePath, "transactional-endpoints.tf"),
-		"variables.tmpl":                                filepath.Join(tfWorkPath, "appsec-variables.tf"),
-		"versions.tmpl":                                 filepath.Join(tfWorkPath, "appsec-versions.tf"),
-	}
-
-	// Provide custom helper functions to get data that does not exist in the security config export
-	additionalFuncs := tools.DecorateWithMultilineHandlingFunctions(map[string]any{
-		"exportJSON":                             exportJSON,
-		"getConfigDescription":                   getConfigDescription,
-		"getCustomRuleNameByID":                  getCustomRuleNameByID,
-		"getMalwareNameByID":                     getMalwareNameByID,
-		"getPolicyNameByID":                      getPolicyNameByID,
-		"getPrefixFromID":                        getPrefixFromID,
-		"getRateNameByID":                        getRateNameByID,
-		"getRepNameByID":                         getRepNameByID,
-		"getRuleDescByID":                        getRuleDescByID,
-		"getRuleNameByID":                        getRuleNameByID,
-		"getSection":                             getSection,
-		"getWAFMode":                             getWAFMode,
-		"isStructuredRule":                       isStructuredRule,
-		"exportJSONWithoutKeys":                  exportJSONWithoutKeys,
-		"getCustomBotCategoryResourceNamesByIDs": getCustomBotCategoryResourceNamesByIDs,
-		"getCustomBotCategoryNameByID":           getCustomBotCategoryNameByID,
-		"getCustomClientResourceNamesByIDs":      getCustomClientResourceNamesByIDs,
-	})
-
-	// The template processor
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: additionalFuncs,
-	}
-
-	appsecName := c.Args().First()
-	if err = createAppsec(ctx, appsecName, client, processor); err != nil {
+	processor := e.Processor()
+	processor.AdditionalFuncs = templateFuncs(client, e.Section)
+	if err := createAppsec(ctx, e.Arg(0), client, e.BotmanClient(), processor); err != nil {
 		return cli.NewExitError(color.RedString(fmt.Sprintf("Error exporting appsec config HCL: %s", err)), 1)
 	}
 	return nil
 }
 
-func createAppsec(ctx context.Context, configName string, client appsec.APPSEC, templateProcessor templates.TemplateProcessor) error {
+// templateFuncs returns additional functions of the templates bound to the given client and section
+func templateFuncs(client appsec.APPSEC, section string) template.FuncMap {
+	f := exportFuncs{client: client, section: section}
+	funcs := make(template.FuncMap, len(additionalFuncs))
+	for name, fn := range additionalFuncs {
+		funcs[name] = fn
+	}
+	funcs["getConfigDescription"] = f.getConfigDescription
+	funcs["getSection"] = f.getSection
+	funcs["getWAFMode"] = f.getWAFMode
+	return funcs
+}
+
+func createAppsec(ctx context.Context, configName string, client appsec.APPSEC, botmanClient botman.BotMan, templateProcessor templates.TemplateProcessor) error {
 
-	term := terminal.Get(ctx)
+	reporter := progress.Get(ctx)
 
-	fmt.Println("Configuring Appsec")
-	term.Spinner().Start("Finding appsec configuration " + configName)
+	reporter.Writeln("Configuring Appsec")
+	reporter.Start("Finding appsec configuration " + configName)
 
 	id, version, err := findConfigurationIDByName(ctx, configName, client)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingPolicy, err)
 	}
+	templates.GetReport(ctx).AddObject(templates.ReportObject{
+		Type:    "appsec_configuration",
+		ID:      strconv.Itoa(id),
+		Name:    configName,
+		Version: strconv.Itoa(version),
+	})
 
-	term.Spinner().OK()
+	reporter.OK()
 
-	term.Spinner().Start("Fetching appsec configuration " + configName)
+	reporter.Start("Fetching appsec configuration " + configName)
 
 	configuration, err := exportConfiguration(ctx, id, version, client)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingPolicy, err)
 	}
 
-	if err := addBotmanCommonResources(ctx, configuration); err != nil {
-		term.Spinner().Fail()
+	if err := addBotmanCommonResources(ctx, configuration, botmanClient); err != nil {
+		reporter.Fail()
 		return fmt.Errorf("error fetching botman common values: %s", err)
 	}
 
-	term.Spinner().OK()
+	reporter.OK()
 
-	term.Spinner().Start("Saving TF configurations")
+	reporter.Start("Saving TF configurations")
 	if err := templateProcessor.ProcessTemplates(configuration); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrSavingFiles, err)
 	}
-	term.Spinner().OK()
-	fmt.Printf("Terraform configuration for configuration '%s' was saved successfully\n", configName)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for configuration '%s' was saved successfully\n", configName)
 
 	return nil
 }
@@ -226,9 +231,9 @@ func exportConfiguration(ctx context.Context, id int, version int, client appsec
 }
 
 // Get the description for the given security configuration id
-func getConfigDescription(configid int) (string, error) {
+func (f exportFuncs) getConfigDescription(configid int) (string, error) {
 
-	getConfigurationResponse, err := client.GetConfiguration(context.Background(), appsec.GetConfigurationRequest{
+	getConfigurationResponse, err := f.client.GetConfiguration(context.Background(), appsec.GetConfigurationRequest{
 		ConfigID: configid,
 	})
 	if err != nil {
@@ -288,9 +293,9 @@ func exportJSON(source interface{}) (string, error) {
 }
 
 // Get the WAF mode for the given security policy
-func getWAFMode(configid int, version int, policyid string) (string, error) {
+func (f exportFuncs) getWAFMode(configid int, version int, policyid string) (string, error) {
 
-	getWAFModeResponse, err := client.GetWAFMode(context.Background(), appsec.GetWAFModeRequest{
+	getWAFModeResponse, err := f.client.GetWAFMode(context.Background(), appsec.GetWAFModeRequest{
 		ConfigID: configid,
 		PolicyID: policyid,
 		Version:  version,
@@ -312,8 +317,8 @@ func getPrefixFromID(s string) string {
 }
 
 // Get our config section
-func getSection() string {
-	return section
+func (f exportFuncs) getSection() string {
+	return f.section
 }
 
 // Get the reputation profile name by id
@@ -412,7 +417,7 @@ func isStructuredRule(configuration *appsec.GetExportConfigurationResponse, id i
 }
 
 // addBotmanCommonResources makes api call to get akamaiBotCategories, botDetection and akamaiDefinedBots to fetch names to be used in terraform resource names
-func addBotmanCommonResources(ctx context.Context, configuration *appsec.GetExportConfigurationResponse) error {
+func addBotmanCommonResources(ctx context.Context, configuration *appsec.GetExportConfigurationResponse, botmanClient botman.BotMan) error {
 	hasAkamaiBotCategoryAction := false
 	for _, policy := range configuration.SecurityPolicies {
 		if policy.BotManagement != nil && len(policy.BotManagement.AkamaiBotCategoryActions) > 0 {
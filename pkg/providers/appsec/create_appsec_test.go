@@ -14,7 +14,6 @@ import (
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/appsec"
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/botman"
 	"github.com/akamai/cli-terraform/pkg/templates"
-	"github.com/akamai/cli-terraform/pkg/tools"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -30,13 +29,13 @@ func TestGetConfigDescription(t *testing.T) {
 	ma := new(appsec.Mock)
 	mocks(ma)
 
-	client = ma
+	f := exportFuncs{client: ma}
 
-	description, err := getConfigDescription(12345)
+	description, err := f.getConfigDescription(12345)
 	assert.NoError(t, err)
 	assert.Equal(t, "description", description)
 
-	description, err = getConfigDescription(12346)
+	description, err = f.getConfigDescription(12346)
 	assert.NoError(t, err)
 	assert.Equal(t, "Created by Terraform", description)
 }
@@ -49,9 +48,9 @@ func TestGetWAFMode(t *testing.T) {
 	ma := new(appsec.Mock)
 	mocks(ma)
 
-	client = ma
+	f := exportFuncs{client: ma}
 
-	wafMode, err := getWAFMode(12345, 1, "ASE1_156138")
+	wafMode, err := f.getWAFMode(12345, 1, "ASE1_156138")
 	assert.NoError(t, err)
 	assert.Equal(t, "KRS", wafMode)
 }
@@ -282,27 +281,6 @@ func TestProcessPolicyTemplates(t *testing.T) {
 		c.On("GetConfiguration", mock.Anything, mock.Anything).Return(&appsec.GetConfigurationResponse{Description: "A security config for demo"}, nil)
 	}
 
-	// Additional functions for the template processor
-	additionalFuncs := tools.DecorateWithMultilineHandlingFunctions(map[string]any{
-		"getCustomRuleNameByID":                  getCustomRuleNameByID,
-		"getRepNameByID":                         getRepNameByID,
-		"getRuleNameByID":                        getRuleNameByID,
-		"getRuleDescByID":                        getRuleDescByID,
-		"getRateNameByID":                        getRateNameByID,
-		"getMalwareNameByID":                     getMalwareNameByID,
-		"getPolicyNameByID":                      getPolicyNameByID,
-		"getWAFMode":                             getWAFMode,
-		"getConfigDescription":                   getConfigDescription,
-		"getPrefixFromID":                        getPrefixFromID,
-		"getSection":                             getSection,
-		"isStructuredRule":                       isStructuredRule,
-		"exportJSON":                             exportJSON,
-		"exportJSONWithoutKeys":                  exportJSONWithoutKeys,
-		"getCustomBotCategoryNameByID":           getCustomBotCategoryNameByID,
-		"getCustomBotCategoryResourceNamesByIDs": getCustomBotCategoryResourceNamesByIDs,
-		"getCustomClientResourceNamesByIDs":      getCustomClientResourceNamesByIDs,
-	})
-
 	// Template to path mappings
 	security := filepath.Join("modules", "security")
 	activateSecurity := filepath.Join("modules", "activate-security")
@@ -312,7 +290,6 @@ func TestProcessPolicyTemplates(t *testing.T) {
 		"imports.tmpl":                        "appsec-import.sh",
 		"main.tmpl":                           "appsec-main.tf",
 		"variables.tmpl":                      "appsec-variables.tf",
-		"versions.tmpl":                       "appsec-versions.tf",
 		"modules-activate-security-main.tmpl": filepath.Join(activateSecurity, "main.tf"),
 		"modules-activate-security-variables.tmpl":     filepath.Join(activateSecurity, "variables.tf"),
 		"modules-activate-security-versions.tmpl":      filepath.Join(activateSecurity, "versions.tf"),
@@ -341,31 +318,31 @@ func TestProcessPolicyTemplates(t *testing.T) {
 
 	// Let's run our tests
 	for _, config := range configs {
-		for name, output := range tests {
-			t.Run(name, func(t *testing.T) {
-
-				// Create mock client
-				ma := new(appsec.Mock)
-				mp := new(templates.MockProcessor)
-				mocks(ma, mp)
-				client = ma
+		// Create mock client
+		ma := new(appsec.Mock)
+		mp := new(templates.MockProcessor)
+		mocks(ma, mp)
 
-				// Ensure test directory exists
-				require.NoError(t, os.MkdirAll(fmt.Sprintf("./testdata/res/%s/modules/security", config), 0755))
-				require.NoError(t, os.MkdirAll(fmt.Sprintf("./testdata/res/%s/modules/activate-security", config), 0755))
+		// Ensure test directory exists
+		require.NoError(t, os.MkdirAll(fmt.Sprintf("./testdata/res/%s/modules/security", config), 0755))
+		require.NoError(t, os.MkdirAll(fmt.Sprintf("./testdata/res/%s/modules/activate-security", config), 0755))
 
-				// Run the template
-				processor := templates.FSTemplateProcessor{
-					TemplatesFS: templateFiles,
-					TemplateTargets: map[string]string{
-						name: fmt.Sprintf("./testdata/res/%s/%s", config, output),
-					},
-					AdditionalFuncs: additionalFuncs,
-				}
+		// Run the templates together, so that references between generated files resolve
+		targets := make(map[string]string, len(tests))
+		for name, output := range tests {
+			targets[name] = fmt.Sprintf("./testdata/res/%s/%s", config, output)
+		}
+		processor := templates.FSTemplateProcessor{
+			TemplatesFS:     templateFiles,
+			TemplateTargets: targets,
+			AdditionalFuncs: templateFuncs(ma, ""),
+		}
 
-				getExportConfigurationResponse := getExportConfiguratonResponse(config)
-				require.NoError(t, processor.ProcessTemplates(getExportConfigurationResponse))
+		getExportConfigurationResponse := getExportConfiguratonResponse(config)
+		require.NoError(t, processor.ProcessTemplates(getExportConfigurationResponse))
 
+		for name, output := range tests {
+			t.Run(name, func(t *testing.T) {
 				// Validate output
 				expected, err := ioutil.ReadFile(fmt.Sprintf("./testdata/%s/%s", config, output))
 				require.NoError(t, err)
@@ -403,27 +380,6 @@ func TestProcessPolicyTemplatesWithBotman(t *testing.T) {
 		}}, nil)
 	}
 
-	// Additional functions for the template processor
-	additionalFuncs := tools.DecorateWithMultilineHandlingFunctions(map[string]any{
-		"getCustomRuleNameByID":                  getCustomRuleNameByID,
-		"getRepNameByID":                         getRepNameByID,
-		"getRuleNameByID":                        getRuleNameByID,
-		"getRuleDescByID":                        getRuleDescByID,
-		"getRateNameByID":                        getRateNameByID,
-		"getMalwareNameByID":                     getMalwareNameByID,
-		"getPolicyNameByID":                      getPolicyNameByID,
-		"getWAFMode":                             getWAFMode,
-		"getConfigDescription":                   getConfigDescription,
-		"getPrefixFromID":                        getPrefixFromID,
-		"getSection":                             getSection,
-		"isStructuredRule":                       isStructuredRule,
-		"exportJSON":                             exportJSON,
-		"exportJSONWithoutKeys":                  exportJSONWithoutKeys,
-		"getCustomBotCategoryNameByID":           getCustomBotCategoryNameByID,
-		"getCustomBotCategoryResourceNamesByIDs": getCustomBotCategoryResourceNamesByIDs,
-		"getCustomClientResourceNamesByIDs":      getCustomClientResourceNamesByIDs,
-	})
-
 	// Template to path mappings
 	security := filepath.Join("modules", "security")
 	activateSecurity := filepath.Join("modules", "activate-security")
@@ -433,7 +389,6 @@ func TestProcessPolicyTemplatesWithBotman(t *testing.T) {
 		"imports.tmpl":                        "appsec-import.sh",
 		"main.tmpl":                           "appsec-main.tf",
 		"variables.tmpl":                      "appsec-variables.tf",
-		"versions.tmpl":                       "appsec-versions.tf",
 		"modules-activate-security-main.tmpl": filepath.Join(activateSecurity, "main.tf"),
 		"modules-activate-security-variables.tmpl":      filepath.Join(activateSecurity, "variables.tf"),
 		"modules-activate-security-versions.tmpl":       filepath.Join(activateSecurity, "versions.tf"),
@@ -469,35 +424,34 @@ func TestProcessPolicyTemplatesWithBotman(t *testing.T) {
 
 	// Let's run our tests
 	for _, config := range configs {
+		// Create mock client
+		ma := new(appsec.Mock)
+		mp := new(templates.MockProcessor)
+		mocks(ma, mp)
+		mb := new(botman.Mock)
+		botmanMocks(mb, new(templates.MockProcessor))
+
+		// Ensure test directory exists
+		require.NoError(t, os.MkdirAll(fmt.Sprintf("./testdata/res/%s/modules/security", config), 0755))
+		require.NoError(t, os.MkdirAll(fmt.Sprintf("./testdata/res/%s/modules/activate-security", config), 0755))
+
+		// Run the templates together, so that references between generated files resolve
+		targets := make(map[string]string, len(tests))
 		for name, output := range tests {
-			t.Run(name, func(t *testing.T) {
+			targets[name] = fmt.Sprintf("./testdata/res/%s/%s", config, output)
+		}
+		processor := templates.FSTemplateProcessor{
+			TemplatesFS:     templateFiles,
+			TemplateTargets: targets,
+			AdditionalFuncs: templateFuncs(ma, ""),
+		}
 
-				// Create mock client
-				ma := new(appsec.Mock)
-				mp := new(templates.MockProcessor)
-				mocks(ma, mp)
-				client = ma
-				mb := new(botman.Mock)
-				botmanMocks(mb, new(templates.MockProcessor))
-				botmanClient = mb
-
-				// Ensure test directory exists
-				require.NoError(t, os.MkdirAll(fmt.Sprintf("./testdata/res/%s/modules/security", config), 0755))
-				require.NoError(t, os.MkdirAll(fmt.Sprintf("./testdata/res/%s/modules/activate-security", config), 0755))
-
-				// Run the template
-				processor := templates.FSTemplateProcessor{
-					TemplatesFS: templateFiles,
-					TemplateTargets: map[string]string{
-						name: fmt.Sprintf("./testdata/res/%s/%s", config, output),
-					},
-					AdditionalFuncs: additionalFuncs,
-				}
-
-				getExportConfigurationResponse := getExportConfiguratonResponse(config)
-				require.NoError(t, addBotmanCommonResources(context.Background(), getExportConfigurationResponse))
-				require.NoError(t, processor.ProcessTemplates(getExportConfigurationResponse))
+		getExportConfigurationResponse := getExportConfiguratonResponse(config)
+		require.NoError(t, addBotmanCommonResources(context.Background(), getExportConfigurationResponse, mb))
+		require.NoError(t, processor.ProcessTemplates(getExportConfigurationResponse))
 
+		for name, output := range tests {
+			t.Run(name, func(t *testing.T) {
 				// Validate output
 				expected, err := ioutil.ReadFile(fmt.Sprintf("./testdata/%s/%s", config, output))
 				require.NoError(t, err)
@@ -7,18 +7,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/clientlists"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
-	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
 
+// providerVersion is the minimal version of the akamai provider required by generated configuration
+const providerVersion = "5.4.0"
+
 //go:embed templates/*
 var templateFiles embed.FS
 
@@ -62,44 +64,40 @@ type TFListData struct {
 	ProductionActivation TFActivationData
 }
 
-// CmdCreateClientList is an entrypoint to create-clientlist command
-func CmdCreateClientList(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(c.Context)
-	client := clientlists.Client(sess)
-
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
+// Exporter exports client lists, see export-clientlist command
+type Exporter struct{}
+
+// Spec describes export-clientlist command
+func (Exporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-clientlist",
+		Description: "Generates Terraform configuration for Client List resources",
+		Args:        []string{"list_id"},
+		Complete:    completeListIDs,
+		Templates: exporter.TemplateSet{
+			FS: templateFiles,
+			Files: map[string]string{
+				"client-list.tmpl": "client-list.tf",
+				"variables.tmpl":   "variables.tf",
+				"imports.tmpl":     "imports.sh",
+			},
+			Imports:         "imports.tmpl",
+			Data:            map[string]interface{}{"": TFData{}},
+			ProviderVersion: providerVersion,
+		},
 	}
+}
 
-	listID := c.Args().First()
-
-	clientListPath := filepath.Join(tfWorkPath, "client-list.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-	importPath := filepath.Join(tfWorkPath, "imports.sh")
-	jsonPath := filepath.Join(tfWorkPath, fmt.Sprintf("%s.json", listID))
+// Export exports the client list with its items
+func (Exporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.ClientListsClient()
+	listID := e.Arg(0)
 
-	if err := tools.CheckFiles(clientListPath, variablesPath, importPath, jsonPath); err != nil {
+	if err := e.Check(e.Path(fmt.Sprintf("%s.json", listID))); err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
 
-	templateToFile := map[string]string{
-		"client-list.tmpl": clientListPath,
-		"variables.tmpl":   variablesPath,
-		"imports.tmpl":     importPath,
-	}
-
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-	}
-
-	section := edgegrid.GetEdgercSection(c)
-	edgercPath := edgegrid.GetEdgercPath(c)
-
-	if err := createClientList(ctx, listID, edgercPath, section, tfWorkPath, client, processor); err != nil {
+	if err := createClientList(ctx, listID, e.EdgercPath, e.Section, e.WorkPath, client, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting client list: %s", err)), 1)
 	}
 
@@ -107,25 +105,32 @@ func CmdCreateClientList(c *cli.Context) error {
 }
 
 func createClientList(ctx context.Context, listID, edgercPath, section, tfWorkPath string, client clientlists.ClientLists, processor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
+	reporter := progress.Get(ctx)
 
-	term.Spinner().Start("Fetching client list " + listID)
+	reporter.Start("Fetching client list " + listID)
 	clientList, err := client.GetClientList(ctx, clientlists.GetClientListRequest{
 		ListID:       listID,
 		IncludeItems: true,
 	})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrClientListNotFound, err)
 	}
 
 	stagingActivation, errStaging := getActivationDataByNetwork(ctx, client, clientList, clientlists.Staging)
 	productionActivation, errProd := getActivationDataByNetwork(ctx, client, clientList, clientlists.Production)
 	if errStaging != nil || errProd != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrActivationDetails, err)
 	}
-	term.Spinner().OK()
+	reporter.OK()
+
+	templates.GetReport(ctx).AddObject(templates.ReportObject{
+		Type:    "client_list",
+		ID:      clientList.ListID,
+		Name:    clientList.Name,
+		Version: strconv.FormatInt(clientList.Version, 10),
+	})
 
 	tfData := TFData{
 		ClientList: TFListData{
@@ -144,24 +149,24 @@ func createClientList(ctx context.Context, listID, edgercPath, section, tfWorkPa
 		EdgercPath: edgercPath,
 	}
 
-	term.Spinner().Start("Saving TF configurations ")
+	reporter.Start("Saving TF configurations ")
 	if err = processor.ProcessTemplates(tfData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrSavingFiles, err)
 	}
 
-	if err := saveListItemsJSON(clientList, tfWorkPath); err != nil {
-		term.Spinner().Fail()
+	if err := saveListItemsJSON(templates.GetOutput(ctx), clientList, tfWorkPath); err != nil {
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrSavingListItems, err)
 	}
 
-	term.Spinner().OK()
-	term.Printf("Terraform configuration for client list '%s' was saved successfully\n", clientList.Name)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for client list '%s' was saved successfully\n", clientList.Name)
 
 	return nil
 }
 
-func saveListItemsJSON(clientList *clientlists.GetClientListResponse, tfWorkPath string) error {
+func saveListItemsJSON(output templates.OutputSink, clientList *clientlists.GetClientListResponse, tfWorkPath string) error {
 	listItems := []clientlists.ListItemPayload{}
 
 	for _, v := range clientList.Items {
@@ -183,7 +188,7 @@ func saveListItemsJSON(clientList *clientlists.GetClientListResponse, tfWorkPath
 
 	path := filepath.Join(tfWorkPath, fmt.Sprintf("%s.json", clientList.ListID))
 
-	if err = os.WriteFile(path, jsonBody, 0644); err != nil {
+	if err = output.WriteFile(path, jsonBody); err != nil {
 		return fmt.Errorf("can't write list items json: %s", err)
 	}
 	return nil
@@ -218,3 +223,18 @@ func getActivationDataByNetwork(ctx context.Context, client clientlists.ClientLi
 
 	return activationData, nil
 }
+
+// completeListIDs returns IDs of the client lists which are not read-only
+func completeListIDs(ctx context.Context, e *exporter.Export) ([]string, error) {
+	lists, err := e.ClientListsClient().GetClientLists(ctx, clientlists.GetClientListsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, list := range lists.Content {
+		if !list.ReadOnly {
+			ids = append(ids, list.ListID)
+		}
+	}
+	return ids, nil
+}
@@ -146,7 +146,7 @@ func TestTemplateProcessing(t *testing.T) {
 			err := createClientList(ctx, listID, "edgerc_path", "test_section", resDir, mc, processor)
 			require.NoError(t, err)
 
-			for _, f := range []string{"client-list.tf", "variables.tf", "imports.sh"} {
+			for _, f := range []string{"client-list.tf", "variables.tf", "imports.sh", "versions.tf", "provider.tf"} {
 				expected, err := os.ReadFile(fmt.Sprintf("%s/%s", testDir, f))
 				require.NoError(t, err)
 				result, err := os.ReadFile(fmt.Sprintf("%s/%s", resDir, f))
@@ -250,7 +250,8 @@ func buildTemplateProcessor(dir string) *templates.FSTemplateProcessor {
 	}
 
 	return &templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
+		TemplatesFS:        templateFiles,
+		TemplateTargets:    templateToFile,
+		MinProviderVersion: providerVersion,
 	}
 }
@@ -1,18 +1,3 @@
-terraform {
-  required_providers {
-    akamai = {
-      source  = "akamai/akamai"
-      version = ">= 5.4.0"
-    }
-  }
-  required_version = ">= 1.0"
-}
-
-provider "akamai" {
-  edgerc         = var.edgerc_path
-  config_section = var.config_section
-}
-
 resource "akamai_clientlist_list" "list_123_ABC" {
   name  = "Test Client List"
   type  = "IP"
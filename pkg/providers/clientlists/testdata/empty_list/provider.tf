@@ -0,0 +1,4 @@
+provider "akamai" {
+  edgerc         = var.edgerc_path
+  config_section = var.config_section
+}
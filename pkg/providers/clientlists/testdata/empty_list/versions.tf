@@ -2,9 +2,8 @@ terraform {
   required_providers {
     akamai = {
       source  = "akamai/akamai"
-      version = ">= 6.4.0"
+      version = ">= 5.4.0"
     }
   }
   required_version = ">= 1.0"
-
 }
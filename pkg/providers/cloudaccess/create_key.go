@@ -7,16 +7,15 @@ import (
 	"embed"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudaccess"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -53,6 +52,9 @@ type (
 	}
 )
 
+// providerVersion is the minimal version of the akamai provider required by generated configuration
+const providerVersion = "6.3.0"
+
 var (
 	//go:embed templates/*
 	templateFiles embed.FS
@@ -71,55 +73,57 @@ var (
 	ErrNonUniqueCloudAccessKeyID = errors.New("'cloud_access_key_id' should be unique for each pair of credentials")
 )
 
-// CmdCreateCloudAccess is an entrypoint to export-cloudaccess command
-func CmdCreateCloudAccess(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(ctx)
-	client := cloudaccess.Client(sess)
-
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
-	}
-	cloudAccessPath := filepath.Join(tfWorkPath, "cloudaccess.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
-	if err := tools.CheckFiles(cloudAccessPath, variablesPath, importPath); err != nil {
-		return cli.Exit(color.RedString(err.Error()), 1)
+// Exporter exports cloud access keys, see export-cloudaccess command
+type Exporter struct{}
+
+// Spec describes export-cloudaccess command
+func (Exporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-cloudaccess",
+		Description: "Generates Terraform configuration for CAM (Cloud Access Manager) resources",
+		Args:        []string{"access_key_uid"},
+		Flags:       []cli.Flag{exporter.SecretsFlag()},
+		Templates: exporter.TemplateSet{
+			FS: templateFiles,
+			Files: map[string]string{
+				"cloudaccess.tmpl": "cloudaccess.tf",
+				"variables.tmpl":   "variables.tf",
+				"imports.tmpl":     "import.sh",
+			},
+			Imports:         "imports.tmpl",
+			Funcs:           additionalFunctions,
+			Data:            map[string]interface{}{"": TFCloudAccessData{}},
+			ProviderVersion: providerVersion,
+		},
 	}
+}
 
-	templateToFile := map[string]string{
-		"cloudaccess.tmpl": cloudAccessPath,
-		"variables.tmpl":   variablesPath,
-		"imports.tmpl":     importPath,
-	}
+// Export exports the access key
+func (Exporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.CloudAccessClient()
 
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: additionalFunctions,
+	if err := e.Check(); err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
 	}
 
-	keyUID, err := strconv.ParseInt(c.Args().Get(0), 10, 64)
+	keyUID, err := strconv.ParseInt(e.Arg(0), 10, 64)
 	if err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
-	section := edgegrid.GetEdgercSection(c)
-	if err = createCloudAccess(ctx, keyUID, section, client, processor); err != nil {
+	if err = createCloudAccess(ctx, keyUID, e.Section, client, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting cloudaccess: %s", err)), 1)
 	}
 	return nil
 }
 
 func createCloudAccess(ctx context.Context, accessKeyUID int64, section string, client cloudaccess.CloudAccess, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
-	term.Spinner().Start("Fetching cloudaccess key " + strconv.Itoa(int(accessKeyUID)))
+	reporter := progress.Get(ctx)
+	reporter.Start("Fetching cloudaccess key " + strconv.Itoa(int(accessKeyUID)))
 	key, err := client.GetAccessKey(ctx, cloudaccess.AccessKeyRequest{
 		AccessKeyUID: accessKeyUID,
 	})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingKey, err)
 	}
 
@@ -131,25 +135,35 @@ func createCloudAccess(ctx context.Context, accessKeyUID int64, section string,
 		AccessKeyUID: accessKeyUID,
 	})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrListingKeyVersions, err)
 	}
 	if len(versions.AccessKeyVersions) > 1 {
 		if *versions.AccessKeyVersions[0].CloudAccessKeyID == *versions.AccessKeyVersions[1].CloudAccessKeyID {
-			term.Spinner().Fail()
+			reporter.Fail()
 			return fmt.Errorf("%w", ErrNonUniqueCloudAccessKeyID)
 		}
 	}
 	tfCloudAccessData := populateCloudAccessData(section, key, versions.AccessKeyVersions)
 
-	term.Spinner().Start("Saving TF configurations ")
+	reportObject := templates.ReportObject{
+		Type: "cloudaccess_key",
+		ID:   strconv.FormatInt(accessKeyUID, 10),
+		Name: key.AccessKeyName,
+	}
+	if len(versions.AccessKeyVersions) > 0 {
+		reportObject.Version = strconv.FormatInt(versions.AccessKeyVersions[0].Version, 10)
+	}
+	templates.GetReport(ctx).AddObject(reportObject)
+
+	reporter.Start("Saving TF configurations ")
 	if err = templateProcessor.ProcessTemplates(tfCloudAccessData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrSavingFiles, err)
 	}
 
-	term.Spinner().OK()
-	term.Printf("Terraform configuration for cloudaccess key '%s' was saved successfully\n", tfCloudAccessData.Key.AccessKeyName)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for cloudaccess key '%s' was saved successfully\n", tfCloudAccessData.Key.AccessKeyName)
 
 	return nil
 }
@@ -181,7 +195,7 @@ func populateCloudAccessData(section string, key *cloudaccess.GetAccessKeyRespon
 	tfCloudAccessData := TFCloudAccessData{
 		Section: section,
 		Key: TFCloudAccessKey{
-			KeyResourceName:      strings.ReplaceAll(key.AccessKeyName, "-", "_"),
+			KeyResourceName:      tools.TerraformIdentifier(strings.ReplaceAll(key.AccessKeyName, "-", "_")),
 			AccessKeyName:        key.AccessKeyName,
 			AuthenticationMethod: key.AuthenticationMethod,
 			GroupID:              groupID,
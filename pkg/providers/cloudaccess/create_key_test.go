@@ -266,7 +266,7 @@ func TestProcessCloudAccessTemplates(t *testing.T) {
 				Section: "test_section",
 			},
 			dir:          "two_versions",
-			filesToCheck: []string{"cloudaccess.tf", "import.sh", "variables.tf"},
+			filesToCheck: []string{"cloudaccess.tf", "import.sh", "variables.tf", "secrets.tf", "secrets.auto.tfvars"},
 		},
 		"access key with all data and one version": {
 			givenData: TFCloudAccessData{
@@ -286,7 +286,7 @@ func TestProcessCloudAccessTemplates(t *testing.T) {
 				Section: "test_section",
 			},
 			dir:          "one_version",
-			filesToCheck: []string{"cloudaccess.tf", "import.sh", "variables.tf"},
+			filesToCheck: []string{"cloudaccess.tf", "import.sh", "variables.tf", "secrets.tf", "secrets.auto.tfvars"},
 		},
 		"access key with no versions": {
 			givenData: TFCloudAccessData{
@@ -398,7 +398,7 @@ func mockProcessTemplates(p *templates.MockProcessor, data testDataForCloudAcces
 	}
 	p.On("ProcessTemplates", TFCloudAccessData{
 		Key: TFCloudAccessKey{
-			KeyResourceName:      strings.ReplaceAll(data.accessKeyName, "-", "_"),
+			KeyResourceName:      tools.TerraformIdentifier(strings.ReplaceAll(data.accessKeyName, "-", "_")),
 			AccessKeyName:        data.accessKeyName,
 			AuthenticationMethod: data.authenticationMethod,
 			GroupID:              groupID,
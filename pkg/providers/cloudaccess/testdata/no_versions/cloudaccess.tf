@@ -1,18 +1,3 @@
-terraform {
-  required_providers {
-    akamai = {
-      source  = "akamai/akamai"
-      version = ">= 6.3.0"
-    }
-  }
-  required_version = ">= 1.0"
-}
-
-provider "akamai" {
-  edgerc         = var.edgerc_path
-  config_section = var.config_section
-}
-
 resource "akamai_cloudaccess_key" "TestKeyName" {
   access_key_name       = "TestKeyName"
   authentication_method = "AWS4_HMAC_SHA256"
@@ -1,18 +1,3 @@
-terraform {
-  required_providers {
-    akamai = {
-      source  = "akamai/akamai"
-      version = ">= 6.3.0"
-    }
-  }
-  required_version = ">= 1.0"
-}
-
-provider "akamai" {
-  edgerc         = var.edgerc_path
-  config_section = var.config_section
-}
-
 resource "akamai_cloudaccess_key" "TestKeyName" {
   access_key_name       = "TestKeyName"
   authentication_method = "AWS4_HMAC_SHA256"
@@ -24,7 +9,7 @@ resource "akamai_cloudaccess_key" "TestKeyName" {
   }
   credentials_a = {
     cloud_access_key_id     = "testAccessKey1"
-    cloud_secret_access_key = "PASTE YOUR SECRET HERE WHEN ROTATING A KEY"
+    cloud_secret_access_key = var.credentials_a_cloud_secret_access_key
     primary_key             = false
   }
 }
\ No newline at end of file
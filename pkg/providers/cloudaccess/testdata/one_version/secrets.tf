@@ -0,0 +1,4 @@
+variable "credentials_a_cloud_secret_access_key" {
+  type      = string
+  sensitive = true
+}
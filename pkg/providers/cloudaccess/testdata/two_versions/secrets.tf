@@ -0,0 +1,9 @@
+variable "credentials_a_cloud_secret_access_key" {
+  type      = string
+  sensitive = true
+}
+
+variable "credentials_b_cloud_secret_access_key" {
+  type      = string
+  sensitive = true
+}
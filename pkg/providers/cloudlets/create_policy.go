@@ -6,17 +6,16 @@ import (
 	"embed"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 	"text/template"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudlets"
 	v3 "github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudlets/v3"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
-	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -63,6 +62,7 @@ type (
 		validatePolicy() error
 		populateWithLatestPolicyVersion(ctx context.Context) error
 		getTFPolicyData(ctx context.Context, section string) (*TFPolicyData, error)
+		addToReport(report *templates.Report)
 	}
 
 	v2ActivationStrategy struct {
@@ -78,6 +78,9 @@ type (
 	}
 )
 
+// providerVersion is the minimal version of the akamai provider required by generated configuration
+const providerVersion = "6.2.0"
+
 //go:embed templates/*
 var templateFiles embed.FS
 
@@ -93,89 +96,88 @@ var (
 	errVersionsNotFound = errors.New("no policy versions found for given policy")
 )
 
-// CmdCreatePolicy is an entrypoint to create-policy command
-func CmdCreatePolicy(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(c.Context)
-	clientV2 := cloudlets.Client(sess)
-	clientV3 := v3.Client(sess)
-
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
+// Exporter exports cloudlets policies, see export-cloudlets-policy command
+type Exporter struct{}
+
+// Spec describes export-cloudlets-policy command
+func (Exporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-cloudlets-policy",
+		Aliases:     []string{"create-cloudlets-policy"},
+		Description: "Generates Terraform configuration for Cloudlets Policy resources",
+		Args:        []string{"policy_name"},
+		Complete:    completePolicyNames,
+		Templates: exporter.TemplateSet{
+			FS: templateFiles,
+			Files: map[string]string{
+				"policy.tmpl":        "policy.tf",
+				"match-rules.tmpl":   "match-rules.tf",
+				"load-balancer.tmpl": "load-balancer.tf",
+				"variables.tmpl":     "variables.tf",
+				"imports.tmpl":       "import.sh",
+			},
+			Imports: "imports.tmpl",
+			Funcs: template.FuncMap{
+				"deepequal": reflect.DeepEqual,
+			},
+			Data:            map[string]interface{}{"": TFPolicyData{}},
+			ProviderVersion: providerVersion,
+		},
 	}
+}
 
-	policyPath := filepath.Join(tfWorkPath, "policy.tf")
-	matchRulesPath := filepath.Join(tfWorkPath, "match-rules.tf")
-	loadBalancerPath := filepath.Join(tfWorkPath, "load-balancer.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
+// Export exports the policy with its match rules and load balancers
+func (Exporter) Export(ctx context.Context, e *exporter.Export) error {
+	clientV2 := e.CloudletsClient()
+	clientV3 := e.CloudletsV3Client()
 
-	err := tools.CheckFiles(policyPath, matchRulesPath, loadBalancerPath, variablesPath, importPath)
-	if err != nil {
+	if err := e.Check(); err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
-	templateToFile := map[string]string{
-		"policy.tmpl":        policyPath,
-		"match-rules.tmpl":   matchRulesPath,
-		"load-balancer.tmpl": loadBalancerPath,
-		"variables.tmpl":     variablesPath,
-		"imports.tmpl":       importPath,
-	}
 
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: template.FuncMap{
-			"deepequal": reflect.DeepEqual,
-		},
-	}
-
-	policyName := c.Args().First()
-	section := edgegrid.GetEdgercSection(c)
-	if err = createPolicy(ctx, policyName, section, clientV2, clientV3, processor); err != nil {
+	if err := createPolicy(ctx, e.Arg(0), e.Section, clientV2, clientV3, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting policy HCL: %s", err)), 1)
 	}
 	return nil
 }
 
 func createPolicy(ctx context.Context, policyName, section string, clientV2 cloudlets.Cloudlets, clientV3 v3.Cloudlets, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
+	reporter := progress.Get(ctx)
 
-	fmt.Println("Configuring Policy")
-	term.Spinner().Start("Fetching policy " + policyName)
+	reporter.Writeln("Configuring Policy")
+	reporter.Start("Fetching policy " + policyName)
 
 	strategy, err := initializeStrategyForPolicy(ctx, policyName, clientV2, clientV3)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingPolicy, err)
 	}
 	if err = strategy.validatePolicy(); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
 
 	err = strategy.populateWithLatestPolicyVersion(ctx)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingVersion, err)
 	}
+	strategy.addToReport(templates.GetReport(ctx))
 
 	tfPolicyData, err := strategy.getTFPolicyData(ctx, section)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
 
-	term.Spinner().OK()
-	term.Spinner().Start("Saving TF configurations ")
+	reporter.OK()
+	reporter.Start("Saving TF configurations ")
 	if err := templateProcessor.ProcessTemplates(*tfPolicyData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
-	fmt.Printf("Terraform configuration for policy '%s' was saved successfully\n", policyName)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for policy '%s' was saved successfully\n", policyName)
 
 	return nil
 }
@@ -407,6 +409,22 @@ func (strategy *v2ActivationStrategy) populateWithLatestPolicyVersion(ctx contex
 	return nil
 }
 
+// addToReport records the policy with its latest version and match rules warnings
+func (strategy *v2ActivationStrategy) addToReport(report *templates.Report) {
+	object := templates.ReportObject{
+		Type: "cloudlets_policy",
+		ID:   strconv.FormatInt(strategy.policy.PolicyID, 10),
+		Name: strategy.policy.Name,
+	}
+	if strategy.policyVersion != nil {
+		object.Version = strconv.FormatInt(strategy.policyVersion.Version, 10)
+		for _, warning := range strategy.policyVersion.Warnings {
+			report.AddWarning("policy '%s': %s (%s)", strategy.policy.Name, warning.Detail, warning.JSONPointer)
+		}
+	}
+	report.AddObject(object)
+}
+
 func getActiveVersionAndProperties(policy *cloudlets.Policy, network cloudlets.PolicyActivationNetwork) *TFPolicyActivationData {
 	var version int64
 	var associatedProperties []string
@@ -506,6 +524,22 @@ func (strategy *v3ActivationStrategy) populateWithLatestPolicyVersion(ctx contex
 	return nil
 }
 
+// addToReport records the policy with its latest version and match rules warnings
+func (strategy *v3ActivationStrategy) addToReport(report *templates.Report) {
+	object := templates.ReportObject{
+		Type: "cloudlets_policy",
+		ID:   strconv.FormatInt(strategy.policy.ID, 10),
+		Name: strategy.policy.Name,
+	}
+	if strategy.policyVersion != nil {
+		object.Version = strconv.FormatInt(strategy.policyVersion.PolicyVersion, 10)
+		for _, warning := range strategy.policyVersion.MatchRulesWarnings {
+			report.AddWarning("policy '%s': %s (%s)", strategy.policy.Name, warning.Detail, warning.JSONPointer)
+		}
+	}
+	report.AddObject(object)
+}
+
 func (strategy *v3ActivationStrategy) getTFPolicyData(_ context.Context, section string) (*TFPolicyData, error) {
 	tfPolicyData := TFPolicyData{
 		Section:           section,
@@ -550,3 +584,41 @@ func (strategy *v3ActivationStrategy) getActivationDataForV3(network v3.Network)
 		IsV3:     true,
 	}
 }
+
+// completePolicyNames returns names of the cloudlets v2 and v3 policies available to the credentials
+func completePolicyNames(ctx context.Context, e *exporter.Export) ([]string, error) {
+	var names []string
+	pageSize, offset := 1000, 0
+	for {
+		policies, err := e.CloudletsClient().ListPolicies(ctx, cloudlets.ListPoliciesRequest{
+			Offset:   offset,
+			PageSize: &pageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies {
+			names = append(names, policy.Name)
+		}
+		if len(policies) < pageSize {
+			break
+		}
+		offset += pageSize
+	}
+
+	page, size := 0, 1000
+	for {
+		policies, err := e.CloudletsV3Client().ListPolicies(ctx, v3.ListPoliciesRequest{Page: page, Size: size})
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies.Content {
+			names = append(names, policy.Name)
+		}
+		if len(policies.Content) < size {
+			break
+		}
+		page++
+	}
+	return names, nil
+}
@@ -6,15 +6,14 @@ import (
 	"embed"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cloudwrapper"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -56,6 +55,9 @@ type (
 	}
 )
 
+// providerVersion is the minimal version of the akamai provider required by generated configuration
+const providerVersion = "5.2.0"
+
 //go:embed templates/*
 var templateFiles embed.FS
 
@@ -72,70 +74,77 @@ var (
 	ErrSavingFiles = errors.New("saving terraform project files")
 )
 
-// CmdCreateCloudWrapper is an entrypoint to export-cloudwrapper command
-func CmdCreateCloudWrapper(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(ctx)
-	client := cloudwrapper.Client(sess)
-
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
-	}
-	cloudwrapperPath := filepath.Join(tfWorkPath, "cloudwrapper.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
-	if err := tools.CheckFiles(cloudwrapperPath, variablesPath, importPath); err != nil {
-		return cli.Exit(color.RedString(err.Error()), 1)
+// Exporter exports CloudWrapper configurations, see export-cloudwrapper command
+type Exporter struct{}
+
+// Spec describes export-cloudwrapper command
+func (Exporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-cloudwrapper",
+		Description: "Generates Terraform configuration for CloudWrapper resources",
+		Args:        []string{"config_id"},
+		Templates: exporter.TemplateSet{
+			FS: templateFiles,
+			Files: map[string]string{
+				"cloudwrapper.tmpl": "cloudwrapper.tf",
+				"variables.tmpl":    "variables.tf",
+				"imports.tmpl":      "import.sh",
+			},
+			Imports:         "imports.tmpl",
+			Funcs:           additionalFunctions,
+			Data:            map[string]interface{}{"": TFCloudWrapperData{}},
+			ProviderVersion: providerVersion,
+		},
 	}
+}
 
-	templateToFile := map[string]string{
-		"cloudwrapper.tmpl": cloudwrapperPath,
-		"variables.tmpl":    variablesPath,
-		"imports.tmpl":      importPath,
-	}
+// Export exports the configuration
+func (Exporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.CloudWrapperClient()
 
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: additionalFunctions,
+	if err := e.Check(); err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
 	}
-	configID, err := strconv.ParseInt(c.Args().Get(0), 10, 64)
+
+	configID, err := strconv.ParseInt(e.Arg(0), 10, 64)
 	if err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
-	section := edgegrid.GetEdgercSection(c)
-	if err = createCloudWrapper(ctx, configID, section, client, processor); err != nil {
+	if err = createCloudWrapper(ctx, configID, e.Section, client, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting cloudwraper: %s", err)), 1)
 	}
 	return nil
 }
 
 func createCloudWrapper(ctx context.Context, configID int64, section string, client cloudwrapper.CloudWrapper, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
-	term.Spinner().Start("Fetching configuration " + strconv.Itoa(int(configID)))
+	reporter := progress.Get(ctx)
+	reporter.Start("Fetching configuration " + strconv.Itoa(int(configID)))
 	configuration, err := client.GetConfiguration(ctx, cloudwrapper.GetConfigurationRequest{
 		ConfigID: configID,
 	})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingConfiguration, err)
 	}
 	if configuration.MultiCDNSettings != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%s: %w", ErrExportingCloudWrapper, ErrContainMultiCDNSettings)
 	}
+	templates.GetReport(ctx).AddObject(templates.ReportObject{
+		Type: "cloudwrapper_configuration",
+		ID:   strconv.FormatInt(configID, 10),
+		Name: configuration.ConfigName,
+	})
 	tfCloudWrapperData := populateCloudWrapperData(configID, section, configuration)
 
-	term.Spinner().Start("Saving TF configurations ")
+	reporter.Start("Saving TF configurations ")
 	if err = templateProcessor.ProcessTemplates(tfCloudWrapperData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrSavingFiles, err)
 	}
 
-	term.Spinner().OK()
-	term.Printf("Terraform configuration for CloudWrapper configuration '%d' was saved successfully\n", tfCloudWrapperData.Configuration.ID)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for CloudWrapper configuration '%d' was saved successfully\n", tfCloudWrapperData.Configuration.ID)
 
 	return nil
 }
@@ -149,7 +158,7 @@ func populateCloudWrapperData(configID int64, section string, configuration *clo
 			Name:                      configuration.ConfigName,
 			Comments:                  configuration.Comments,
 			NotificationEmails:        configuration.NotificationEmails,
-			ConfigurationResourceName: strings.ReplaceAll(configuration.ConfigName, "-", "_"),
+			ConfigurationResourceName: tools.TerraformIdentifier(strings.ReplaceAll(configuration.ConfigName, "-", "_")),
 			RetainIdleObjects:         configuration.RetainIdleObjects,
 			CapacityAlertsThreshold:   configuration.CapacityAlertsThreshold,
 		},
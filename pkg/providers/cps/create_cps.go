@@ -6,15 +6,12 @@ import (
 	"embed"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"strconv"
-	"strings"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cps"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
-	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -34,6 +31,9 @@ type (
 	}
 )
 
+// providerVersion is the minimal version of the akamai provider required by generated configuration
+const providerVersion = "3.1.0"
+
 //go:embed templates/*
 var templateFiles embed.FS
 
@@ -46,44 +46,45 @@ var (
 	ErrUnsupportedEnrollmentType = errors.New("supporting export of dv and third-party enrollments but got")
 )
 
-// CmdCreateCPS is an entrypoint to create-cps command
-func CmdCreateCPS(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(ctx)
-	client := cps.Client(sess)
-
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
-	}
-	enrollmentPath := filepath.Join(tfWorkPath, "enrollment.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
-
-	err := tools.CheckFiles(enrollmentPath, variablesPath, importPath)
-	if err != nil {
-		return cli.Exit(color.RedString(err.Error()), 1)
+// Exporter exports CPS enrollments, see export-cps command
+type Exporter struct{}
+
+// Spec describes export-cps command
+func (Exporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-cps",
+		Aliases:     []string{"create-cps"},
+		Description: "Generates Terraform configuration for CPS (Certificate Provisioning System) resources",
+		Args:        []string{"enrollment_id", "contract_id"},
+		Complete:    exporter.CompleteArgs(completeEnrollmentIDs, exporter.CompleteContractIDs),
+		Flags:       []cli.Flag{exporter.SecretsFlag()},
+		Templates: exporter.TemplateSet{
+			FS: templateFiles,
+			Files: map[string]string{
+				"enrollment.tmpl": "enrollment.tf",
+				"variables.tmpl":  "variables.tf",
+				"imports.tmpl":    "import.sh",
+			},
+			Imports:         "imports.tmpl",
+			Data:            map[string]interface{}{"": TFCPSData{}},
+			ProviderVersion: providerVersion,
+		},
 	}
+}
 
-	templateToFile := map[string]string{
-		"enrollment.tmpl": enrollmentPath,
-		"variables.tmpl":  variablesPath,
-		"imports.tmpl":    importPath,
-	}
+// Export exports the enrollment
+func (Exporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.CPSClient()
 
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
+	if err := e.Check(); err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
 	}
 
-	enrollmentID, err := strconv.Atoi(c.Args().Get(0))
+	enrollmentID, err := strconv.Atoi(e.Arg(0))
 	if err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
-	contractID := c.Args().Get(1)
-	section := edgegrid.GetEdgercSection(c)
-	if err = createCPS(ctx, contractID, enrollmentID, section, client, processor); err != nil {
+	if err = createCPS(ctx, e.Arg(1), enrollmentID, e.Section, client, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting enrollment HCL: %s", err)), 1)
 	}
 	return nil
@@ -91,25 +92,33 @@ func CmdCreateCPS(c *cli.Context) error {
 
 func createCPS(ctx context.Context, contractID string, enrollmentID int,
 	section string, client cps.CPS, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
+	reporter := progress.Get(ctx)
 
-	fmt.Println("Exporting CPS configuration")
+	reporter.Writeln("Exporting CPS configuration")
 
-	term.Spinner().Start(fmt.Sprintf("Fetching enrollment for the given id %d", enrollmentID))
+	reporter.Start(fmt.Sprintf("Fetching enrollment for the given id %d", enrollmentID))
 	enrollment, err := client.GetEnrollment(ctx, cps.GetEnrollmentRequest{
 		EnrollmentID: enrollmentID,
 	})
 	if err != nil || enrollment == nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingEnrollment, err)
 	}
 
 	if enrollment.ValidationType != "third-party" && enrollment.ValidationType != "dv" {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrUnsupportedEnrollmentType, enrollment.ValidationType)
 	}
+	reportObject := templates.ReportObject{
+		Type: "cps_enrollment",
+		ID:   strconv.Itoa(enrollmentID),
+	}
+	if enrollment.CSR != nil {
+		reportObject.Name = enrollment.CSR.CN
+	}
+	templates.GetReport(ctx).AddObject(reportObject)
 
-	term.Spinner().OK()
+	reporter.OK()
 
 	tfData := TFCPSData{
 		Enrollment:   *enrollment,
@@ -119,30 +128,31 @@ func createCPS(ctx context.Context, contractID string, enrollmentID int,
 	}
 
 	if enrollment.ValidationType == "third-party" {
-		term.Spinner().Start("Retrieving certificate history ")
+		reporter.Start("Retrieving certificate history ")
 		certHistory, err := client.GetChangeHistory(ctx, cps.GetChangeHistoryRequest{EnrollmentID: enrollmentID})
 		if err != nil {
-			term.Spinner().Fail()
+			reporter.Fail()
 			return fmt.Errorf("%w: %s", ErrFetchingCertificateHistory, err)
 		}
 		certificateECDSA, trustChainECDSA, certificateRSA, trustChainRSA := getCertificatesFromChangeHistory(certHistory)
-		tfData.CertificateECDSA = strings.ReplaceAll(certificateECDSA, "\n", "\\n")
-		tfData.TrustChainECDSA = strings.ReplaceAll(trustChainECDSA, "\n", "\\n")
-		tfData.CertificateRSA = strings.ReplaceAll(certificateRSA, "\n", "\\n")
-		tfData.TrustChainRSA = strings.ReplaceAll(trustChainRSA, "\n", "\\n")
+		// certificates are kept in secret variables, which are escaped when they are written
+		tfData.CertificateECDSA = certificateECDSA
+		tfData.TrustChainECDSA = trustChainECDSA
+		tfData.CertificateRSA = certificateRSA
+		tfData.TrustChainRSA = trustChainRSA
 		if certificateECDSA == "" && certificateRSA == "" {
 			tfData.NoUploadCertificate = true
 		}
-		term.Spinner().OK()
+		reporter.OK()
 	}
 
-	term.Spinner().Start("Saving TF configurations ")
+	reporter.Start("Saving TF configurations ")
 	if err := templateProcessor.ProcessTemplates(tfData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
-	fmt.Printf("Terraform configuration for enrollment '%d' was saved successfully\n", enrollmentID)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for enrollment '%d' was saved successfully\n", enrollmentID)
 
 	return nil
 }
@@ -173,3 +183,25 @@ func getCertificatesFromChangeHistory(changeHistory *cps.GetChangeHistoryRespons
 	}
 	return certificateECDSA, trustChainECDSA, certificateRSA, trustChainRSA
 }
+
+// completeEnrollmentIDs returns IDs of the enrollments with DV or third-party certificates in all contracts
+func completeEnrollmentIDs(ctx context.Context, e *exporter.Export) ([]string, error) {
+	contractIDs, err := exporter.CompleteContractIDs(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	client := e.CPSClient()
+	var ids []string
+	for _, contractID := range contractIDs {
+		enrollments, err := client.ListEnrollments(ctx, cps.ListEnrollmentsRequest{ContractID: contractID})
+		if err != nil {
+			return nil, err
+		}
+		for _, enrollment := range enrollments.Enrollments {
+			if enrollment.ValidationType == "dv" || enrollment.ValidationType == "third-party" {
+				ids = append(ids, strconv.Itoa(enrollment.ID))
+			}
+		}
+	}
+	return ids, nil
+}
@@ -11,7 +11,9 @@ import (
 	"text/template"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/cps"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/tools"
+	"github.com/akamai/cli-terraform/pkg/exporter"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/jinzhu/copier"
@@ -540,7 +542,7 @@ func TestCreateCPS(t *testing.T) {
 			enrollmentID: 1,
 			contractID:   "ctr_1",
 			dataDir:      "third_party_enrollment_all_fields_ecdsa",
-			filesToCheck: []string{"enrollment.tf", "import.sh", "variables.tf"},
+			filesToCheck: []string{"enrollment.tf", "import.sh", "variables.tf", "secrets.tf", "secrets.auto.tfvars"},
 		},
 		"export third party enrollment rsa": {
 			init: func(m *cps.Mock) {
@@ -562,7 +564,7 @@ func TestCreateCPS(t *testing.T) {
 			enrollmentID: 1,
 			contractID:   "ctr_1",
 			dataDir:      "third_party_enrollment_all_fields_rsa",
-			filesToCheck: []string{"enrollment.tf", "import.sh", "variables.tf"},
+			filesToCheck: []string{"enrollment.tf", "import.sh", "variables.tf", "secrets.tf", "secrets.auto.tfvars"},
 		},
 		"export third party enrollment ecdsa+rsa": {
 			init: func(m *cps.Mock) {
@@ -591,7 +593,7 @@ func TestCreateCPS(t *testing.T) {
 			enrollmentID: 1,
 			contractID:   "ctr_1",
 			dataDir:      "third_party_enrollment_all_fields_ecdsa_rsa",
-			filesToCheck: []string{"enrollment.tf", "import.sh", "variables.tf"},
+			filesToCheck: []string{"enrollment.tf", "import.sh", "variables.tf", "secrets.tf", "secrets.auto.tfvars"},
 		},
 		"export third party enrollment renewal": {
 			init: func(m *cps.Mock) {
@@ -626,7 +628,7 @@ func TestCreateCPS(t *testing.T) {
 			enrollmentID: 1,
 			contractID:   "ctr_1",
 			dataDir:      "third_party_enrollment_all_fields_renewal",
-			filesToCheck: []string{"enrollment.tf", "import.sh", "variables.tf"},
+			filesToCheck: []string{"enrollment.tf", "import.sh", "variables.tf", "secrets.tf", "secrets.auto.tfvars"},
 		},
 		"export third party enrollment new certificate": {
 			init: func(m *cps.Mock) {
@@ -754,13 +756,13 @@ func TestProcessEnrollmentTemplates(t *testing.T) {
 				EnrollmentID:     1,
 				ContractID:       "ctr_1",
 				Section:          "test_section",
-				CertificateECDSA: "-----BEGIN CERTIFICATE ECDSA REQUEST-----\\n...\\n-----END CERTIFICATE ECDSA REQUEST-----",
-				CertificateRSA:   "-----BEGIN CERTIFICATE RSA REQUEST-----\\n...\\n-----END CERTIFICATE RSA REQUEST-----",
-				TrustChainECDSA:  "-----BEGIN CERTIFICATE TRUST-CHAIN ECDSA REQUEST-----\\n...\\n-----END CERTIFICATE TRUST-CHAIN ECDSA REQUEST-----",
-				TrustChainRSA:    "-----BEGIN CERTIFICATE TRUST-CHAIN RSA REQUEST-----\\n...\\n-----END CERTIFICATE TRUST-CHAIN RSA REQUEST-----",
+				CertificateECDSA: "-----BEGIN CERTIFICATE ECDSA REQUEST-----\n...\n-----END CERTIFICATE ECDSA REQUEST-----",
+				CertificateRSA:   "-----BEGIN CERTIFICATE RSA REQUEST-----\n...\n-----END CERTIFICATE RSA REQUEST-----",
+				TrustChainECDSA:  "-----BEGIN CERTIFICATE TRUST-CHAIN ECDSA REQUEST-----\n...\n-----END CERTIFICATE TRUST-CHAIN ECDSA REQUEST-----",
+				TrustChainRSA:    "-----BEGIN CERTIFICATE TRUST-CHAIN RSA REQUEST-----\n...\n-----END CERTIFICATE TRUST-CHAIN RSA REQUEST-----",
 			},
 			dir:          "third_party_enrollment_all_fields_ecdsa_rsa",
-			filesToCheck: []string{"enrollment.tf", "variables.tf", "import.sh"},
+			filesToCheck: []string{"enrollment.tf", "variables.tf", "import.sh", "secrets.tf", "secrets.auto.tfvars"},
 		},
 	}
 
@@ -779,3 +781,23 @@ func TestProcessEnrollmentTemplates(t *testing.T) {
 		})
 	}
 }
+
+func TestCompleteEnrollmentIDs(t *testing.T) {
+	mp := &papi.Mock{}
+	mp.On("GetContracts", mock.Anything).Return(&papi.GetContractsResponse{
+		Contracts: papi.ContractsItems{Items: []*papi.Contract{{ContractID: "ctr_C-1"}, {ContractID: "ctr_C-2"}}},
+	}, nil).Once()
+	mc := &cps.Mock{}
+	mc.On("ListEnrollments", mock.Anything, cps.ListEnrollmentsRequest{ContractID: "C-1"}).Return(&cps.ListEnrollmentsResponse{
+		Enrollments: []cps.Enrollment{{ID: 1, ValidationType: "dv"}, {ID: 2, ValidationType: "ev"}},
+	}, nil).Once()
+	mc.On("ListEnrollments", mock.Anything, cps.ListEnrollmentsRequest{ContractID: "C-2"}).Return(&cps.ListEnrollmentsResponse{
+		Enrollments: []cps.Enrollment{{ID: 3, ValidationType: "third-party"}},
+	}, nil).Once()
+
+	ids, err := completeEnrollmentIDs(context.Background(), &exporter.Export{Clients: exporter.Clients{PAPI: mp, CPS: mc}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "3"}, ids)
+	mp.AssertExpectations(t)
+	mc.AssertExpectations(t)
+}
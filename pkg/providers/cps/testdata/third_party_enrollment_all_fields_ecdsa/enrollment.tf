@@ -1,18 +1,3 @@
-terraform {
-  required_providers {
-    akamai = {
-      source  = "akamai/akamai"
-      version = ">= 3.1.0"
-    }
-  }
-  required_version = ">= 1.0"
-}
-
-provider "akamai" {
-  edgerc         = var.edgerc_path
-  config_section = var.config_section
-}
-
 resource "akamai_cps_third_party_enrollment" "enrollment_id_1" {
   common_name                           = "test.akamai.com"
   allow_duplicate_common_name           = false
@@ -93,8 +78,8 @@ data "akamai_cps_csr" "enrollment_id_1" {
 
 resource "akamai_cps_upload_certificate" "enrollment_id_1" {
   enrollment_id                          = 1
-  certificate_ecdsa_pem                  = "-----BEGIN CERTIFICATE ECDSA REQUEST-----\n...\n-----END CERTIFICATE ECDSA REQUEST-----"
-  trust_chain_ecdsa_pem                  = "-----BEGIN CERTIFICATE TRUST-CHAIN ECDSA REQUEST-----\n...\n-----END CERTIFICATE TRUST-CHAIN ECDSA REQUEST-----"
+  certificate_ecdsa_pem                  = var.enrollment_id_1_certificate_ecdsa_pem
+  trust_chain_ecdsa_pem                  = var.enrollment_id_1_trust_chain_ecdsa_pem
   acknowledge_post_verification_warnings = false
   auto_approve_warnings                  = []
   acknowledge_change_management          = false
@@ -0,0 +1,9 @@
+variable "enrollment_id_1_certificate_ecdsa_pem" {
+  type      = string
+  sensitive = true
+}
+
+variable "enrollment_id_1_trust_chain_ecdsa_pem" {
+  type      = string
+  sensitive = true
+}
@@ -16,21 +16,24 @@
 package dns
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/dns"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
+	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
+	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/urfave/cli/v2"
 )
 
@@ -44,12 +47,20 @@ type zoneImportListStruct struct {
 }
 
 type configStruct struct {
+	zoneName               string
 	fetchConfig            fetchConfigStruct
 	tfWorkPath             string
 	shouldCreateImportList bool
 	createConfig           bool
 	recordNames            []string
 	importScript           bool
+	importStyle            templates.ImportStyle
+	versions               templates.Versions
+	backend                templates.Backend
+	output                 templates.OutputSink
+	secrets                *templates.Secrets
+	secretsMode            templates.SecretsMode
+	tmpl                   *template.Template
 }
 
 type fetchConfigStruct struct {
@@ -58,332 +69,385 @@ type fetchConfigStruct struct {
 	NamesOnly  bool
 }
 
-var zoneName string
-var contractID string
+const (
+	// providerVersion is the minimal version of the akamai provider required by generated configuration
+	providerVersion = "1.6.1"
 
-var fullZoneImportList *zoneImportListStruct
-var fullZoneConfigMap map[string]Types
-
-// work defs
-var moduleFolder = "modules"
-
-// text for root module construction
-var zoneTFFileHandle *os.File
-var zoneTFConfig = ""
-
-// CmdCreateZone is an entrypoint to create-zone command
-func CmdCreateZone(c *cli.Context) error {
-	ctx := c.Context
-	log.SetOutput(ioutil.Discard)
+	// work defs
+	moduleFolder = "modules"
+)
 
-	sess := edgegrid.GetSession(ctx)
-	configDNS := dns.Client(sess)
+// Exporter exports DNS zones with their record sets, see export-zone command
+type Exporter struct{}
+
+// Spec describes export-zone command
+func (Exporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-zone",
+		Aliases:     []string{"create-zone"},
+		Description: "Generates Terraform configuration for Zone resources",
+		Args:        []string{"zone"},
+		Complete:    completeZoneNames,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "resources",
+				Usage: "Create json formatted resource import list file, <zone>_resources.json. Used as input by createconfig.",
+			},
+			&cli.BoolFlag{
+				Name:  "createconfig",
+				Usage: "Create Terraform configuration (<zone>.tf), dnsvars.tf from generated resources file. Saves zone config for import.",
+			},
+			&cli.BoolFlag{
+				Name:  "importscript",
+				Usage: "Create import script for generated Terraform configuration script (<zone>_import.script) files",
+			},
+			&cli.BoolFlag{
+				Name:  "segmentconfig",
+				Usage: "Directive for createconfig. Group and segment records by name into separate config files.",
+			},
+			&cli.BoolFlag{
+				Name:  "configonly",
+				Usage: "Directive for createconfig. Create entire Terraform zone and recordsets configuration (<zone>.tf), dnsvars.tf. Saves zone config for importscript. Ignores any existing resource json file.",
+			},
+			&cli.BoolFlag{
+				Name:  "namesonly",
+				Usage: "Directive for both resource gathering and config generation. All record set types assumed.",
+			},
+			&cli.StringSliceFlag{
+				Name:  "recordname",
+				Usage: "Used in resources gathering or with configonly to filter recordsets. Multiple recordname flags may be specified.",
+			},
+			exporter.SecretsFlag(),
+		},
+		Templates: exporter.TemplateSet{
+			FS: templateFiles,
+			Data: map[string]interface{}{
+				"":                          ZoneData{},
+				"dnsvars.tmpl":              nil,
+				"import-script.tmpl":        ImportData{},
+				"module-set.tmpl":           RecordsetData{},
+				"recordset-modsegment.tmpl": RecordsetData{},
+				"resource-set.tmpl":         RecordsetData{},
+			},
+		},
+	}
+}
 
-	// uppercase characters cause issues with TF and the generated config
-	zoneName = strings.ToLower(c.Args().Get(0))
+// Export exports the zone, depending on the flags it gathers its resources, creates the configuration
+// and the import script
+func (Exporter) Export(ctx context.Context, e *exporter.Export) error {
+	configDNS := e.DNSClient()
 
-	configuration := setConfiguration(c)
+	configuration, err := setConfiguration(e)
+	if err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
+	}
 
-	term := terminal.Get(ctx)
-	fmt.Println("Configuring Zone")
-	zoneObject, err := configDNS.GetZone(ctx, zoneName)
+	reporter := progress.Get(ctx)
+	reporter.Writeln("Configuring Zone")
+	zoneObject, err := configDNS.GetZone(ctx, configuration.zoneName)
 	if err != nil {
-		term.Spinner().Fail()
-		fmt.Println("Error: " + err.Error())
+		reporter.Fail()
+		reporter.Writeln("Error: " + err.Error())
 		return cli.Exit(color.RedString("Zone retrieval failed"), 1)
 	}
-	contractID = zoneObject.ContractID // grab for use later
+	templates.GetReport(ctx).AddObject(templates.ReportObject{
+		Type:    "dns_zone",
+		ID:      zoneObject.Zone,
+		Version: zoneObject.VersionID,
+	})
 	// normalize zone name for zone resource name
-	resourceZoneName := normalizeResourceName(zoneName)
+	resourceZoneName := tools.TerraformIdentifier(configuration.zoneName)
+	var zoneImportList *zoneImportListStruct
 	if configuration.shouldCreateImportList {
-		err := createImportList(ctx, term, configDNS, resourceZoneName, configuration)
+		zoneImportList, err = createImportList(ctx, reporter, configDNS, resourceZoneName, configuration)
 		if err != nil {
 			return err
 		}
-		term.Spinner().OK()
+		reporter.OK()
 	}
 
+	var zoneConfigMap map[string]Types
 	if configuration.createConfig {
 		// Read in resources list
-		zoneImportList, err := retrieveZoneImportList(resourceZoneName, configuration)
+		zoneImportList, err = retrieveZoneImportList(resourceZoneName, zoneImportList, configuration)
 		if err != nil {
-			term.Spinner().Fail()
+			reporter.Fail()
 			return cli.Exit(color.RedString("Failed to read json zone resources file"), 1)
 		}
-		// if segmenting record sets by name, make sure module folder exists
-		if configuration.fetchConfig.ModSegment {
-			modulePath := filepath.Join(configuration.tfWorkPath, moduleFolder)
-			if !createDirectory(modulePath) {
-				term.Spinner().Fail()
-				return cli.Exit(color.RedString("Failed to create modules folder."), 1)
-			}
-		}
-		term.Spinner().Start("Creating zone configuration file ")
-		err = createZoneConfigFile(ctx, zoneImportList, resourceZoneName, zoneObject, configDNS, configuration)
+		// generated terraform files are validated once all of them are written
+		validating := templates.NewValidatingSink(configuration.output)
+		configuration.output = validating
+		reporter.Start("Creating zone configuration file ")
+		zoneConfigMap, err = createZoneConfigFile(ctx, zoneImportList, resourceZoneName, zoneObject, configDNS, configuration)
 		if err != nil {
-			term.Spinner().Fail()
+			reporter.Fail()
 			return err
 		}
 
-		err = createDNSVarsConfig(term, configuration.tfWorkPath)
+		err = createDNSVarsConfig(reporter, zoneObject.ContractID, configuration)
 		if err != nil {
 			return err
 		}
-		term.Spinner().OK()
+		if err = configuration.secrets.Write(configuration.output, configuration.secretsMode); err != nil {
+			reporter.Fail()
+			return cli.Exit(color.RedString("Unable to write secrets config file"), 1)
+		}
+		if err = validating.Validate(configuration.secrets.Names()); err != nil {
+			reporter.Fail()
+			reporter.Writeln("Error: " + err.Error())
+			return cli.Exit(color.RedString("Generated zone configuration is invalid"), 1)
+		}
+		reporter.OK()
 	}
 
 	if configuration.importScript {
-		term.Spinner().Start("Creating zone import script file")
-		err := createImportScript(resourceZoneName, term, configuration)
+		reporter.Start("Creating zone import script file")
+		err := createImportScript(resourceZoneName, zoneConfigMap, reporter, configuration)
 		if err != nil {
-			term.Spinner().Fail()
+			reporter.Fail()
+			return err
+		}
+		reporter.OK()
+	}
+
+	if configuration.backend.Type != "" && (configuration.createConfig || configuration.importScript) {
+		reporter.Start("Creating backend configuration")
+		if err := createBackendConfig(resourceZoneName, configuration); err != nil {
+			reporter.Fail()
 			return err
 		}
-		term.Spinner().OK()
+		reporter.OK()
 	}
 
-	fmt.Println("Zone configuration completed")
+	reporter.Writeln("Zone configuration completed")
 
 	return nil
 }
 
-func createImportList(ctx context.Context, term terminal.Terminal, configDNS dns.DNS, resourceZoneName string, configuration configStruct) error {
-	term.Spinner().Start("Inventorying zone and recordsets ")
+func createImportList(ctx context.Context, reporter progress.Reporter, configDNS dns.DNS, resourceZoneName string, configuration configStruct) (*zoneImportListStruct, error) {
+	reporter.Start("Inventorying zone and recordsets ")
 	recordSets, err := inventorZone(ctx, configDNS, configuration)
 	if err != nil {
-		term.Spinner().Fail()
-		fmt.Println("Error: " + err.Error())
-		return err
+		reporter.Fail()
+		reporter.Writeln("Error: " + err.Error())
+		return nil, err
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
-	term.Spinner().Start("Creating Zone Resources list file ")
-	err = createZoneResourceListFile(resourceZoneName, recordSets, configuration.tfWorkPath)
+	reporter.Start("Creating Zone Resources list file ")
+	zoneImportList, err := createZoneResourceListFile(resourceZoneName, recordSets, configuration)
 	if err != nil {
-		term.Spinner().Fail()
-		return err
+		reporter.Fail()
+		return nil, err
 	}
-	return nil
+	return zoneImportList, nil
 }
 
-func setConfiguration(c *cli.Context) configStruct {
+func setConfiguration(e *exporter.Export) (configStruct, error) {
 	var executionConfig = configStruct{
-		tfWorkPath: "./",
+		// uppercase characters cause issues with TF and the generated config
+		zoneName:   strings.ToLower(e.Arg(0)),
+		tfWorkPath: e.WorkPath,
 	}
 
-	if c.IsSet("tfworkpath") {
-		executionConfig.tfWorkPath = c.String("tfworkpath")
-	}
-	executionConfig.tfWorkPath = filepath.FromSlash(executionConfig.tfWorkPath)
-	if c.IsSet("resources") {
+	if e.Flags.IsSet("resources") {
 		executionConfig.shouldCreateImportList = true
 	}
-	if c.IsSet("createconfig") {
+	if e.Flags.IsSet("createconfig") {
 		executionConfig.createConfig = true
 	}
-	if c.IsSet("configonly") {
+	if e.Flags.IsSet("configonly") {
 		executionConfig.fetchConfig.ConfigOnly = true
 	}
-	if c.IsSet("namesonly") {
+	if e.Flags.IsSet("namesonly") {
 		executionConfig.fetchConfig.NamesOnly = true
 	}
-	if c.IsSet("recordname") {
-		executionConfig.recordNames = c.StringSlice("recordname")
+	if e.Flags.IsSet("recordname") {
+		executionConfig.recordNames = e.Flags.StringSlice("recordname")
 	}
-	if c.IsSet("segmentconfig") {
+	if e.Flags.IsSet("segmentconfig") {
 		executionConfig.fetchConfig.ModSegment = true
 	}
-	if c.IsSet("importscript") {
+	if e.Flags.IsSet("importscript") {
 		executionConfig.importScript = true
 	}
+	executionConfig.importStyle = e.ImportStyle
+	executionConfig.versions = e.Versions
+	executionConfig.backend = e.Backend
+	executionConfig.output = e.Output
+	executionConfig.secrets = &templates.Secrets{}
+	executionConfig.secretsMode = e.Secrets
+	var err error
+	if executionConfig.tmpl, err = parseTemplates(e.TemplateDir, e.WorkPath, executionConfig.secrets); err != nil {
+		return configStruct{}, err
+	}
 
-	return executionConfig
+	return executionConfig, nil
 }
 
-func createZoneConfigFile(ctx context.Context, zoneImportList *zoneImportListStruct, resourceZoneName string, zoneObject *dns.ZoneResponse, configDNS dns.DNS, configuration configStruct) (err error) {
+func createZoneConfigFile(ctx context.Context, zoneImportList *zoneImportListStruct, resourceZoneName string, zoneObject *dns.ZoneResponse, configDNS dns.DNS, configuration configStruct) (map[string]Types, error) {
 	// see if configuration file already exists and exclude any resources already represented.
 	var configImportList *zoneImportListStruct
 	var zoneTypeMap map[string]map[string]bool
+	reporter := progress.Get(ctx)
 
-	zoneTFFileHandle, zoneTFConfig, err = openZoneConfigFile(resourceZoneName, configuration.tfWorkPath)
+	tfFilename := tools.CreateTFFilename(resourceZoneName, configuration.tfWorkPath)
+	// text for root module construction
+	zoneTFConfig, err := readZoneConfigFile(reporter, tfFilename)
 	if err != nil {
-		return cli.Exit(color.RedString("Failed to open/create zone config file."), 1)
+		return nil, cli.Exit(color.RedString("Failed to read zone config file."), 1)
 	}
-	configImportList, zoneTypeMap = reconcileZoneResourceTargets(zoneImportList, resourceZoneName, zoneTFConfig)
+	configImportList, zoneTypeMap = reconcileZoneResourceTargets(reporter, zoneImportList, resourceZoneName, zoneTFConfig)
 
-	defer func(zoneTFFileHandle *os.File) {
-		if e := zoneTFFileHandle.Close(); e != nil {
-			err = e
-		}
-	}(zoneTFFileHandle)
-	fileUtils := fileUtilsProcessor{}
+	fileUtils := fileUtilsProcessor{output: configuration.output, rootModule: &bytes.Buffer{}}
 
-	err = calculateTfConfig(ctx, zoneObject, resourceZoneName, fileUtils, configuration)
+	zoneTFConfig, err = calculateTfConfig(ctx, zoneTFConfig, zoneObject, resourceZoneName, fileUtils, configuration)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	err = fileUtils.appendRootModuleTF(zoneTFConfig)
 	if err != nil {
-		fmt.Println(err.Error())
-		return cli.Exit(color.RedString("Failed. Couldn't write to zone config"), 1)
+		reporter.Writeln(err.Error())
+		return nil, cli.Exit(color.RedString("Failed. Couldn't write to zone config"), 1)
 	}
 
 	// process RecordSets.
-	fullZoneConfigMap, err = processRecordSets(ctx, configDNS, configImportList.Zone, resourceZoneName, zoneTypeMap, fileUtils, configuration)
+	zoneConfigMap, err := processRecordSets(ctx, configDNS, configImportList.Zone, resourceZoneName, zoneTypeMap, fileUtils, configuration)
 	if err != nil {
-		return cli.Exit(color.RedString("Failed to process recordsets."), 1)
+		return nil, cli.Exit(color.RedString("Failed to process recordsets."), 1)
+	}
+	if err = configuration.output.WriteFile(tfFilename, fileUtils.rootModule.Bytes()); err != nil {
+		reporter.Writeln(err.Error())
+		return nil, cli.Exit(color.RedString("Failed. Couldn't write to zone config"), 1)
 	}
 	// Save config map for import script generation
 	resourceConfigFilename := createResourceConfigFilename(resourceZoneName, configuration.tfWorkPath)
 
-	return saveResourceConfigFile(resourceConfigFilename)
+	return zoneConfigMap, saveResourceConfigFile(resourceConfigFilename, zoneConfigMap, configuration.output)
 }
 
-func calculateTfConfig(ctx context.Context, zoneObject *dns.ZoneResponse, resourceZoneName string, fileUtils fileUtilsProcessor, config configStruct) error {
+func calculateTfConfig(ctx context.Context, zoneTFConfig string, zoneObject *dns.ZoneResponse, resourceZoneName string, fileUtils fileUtilsProcessor, config configStruct) (string, error) {
 	// build tf file if none
-	var err error
 	if len(zoneTFConfig) > 0 {
 		if strings.Contains(zoneTFConfig, "module") && strings.Contains(zoneTFConfig, "zonename") {
 			if !config.fetchConfig.ModSegment {
 				// already have a top level zone config and its modularized!
-				return cli.Exit(color.RedString("Failed. Existing zone config is modularized"), 1)
+				return "", cli.Exit(color.RedString("Failed. Existing zone config is modularized"), 1)
 			}
 		} else if config.fetchConfig.ModSegment {
 			// already have a top level zone config and its not modularized!
-			return cli.Exit(color.RedString("Failed. Existing zone config is not modularized"), 1)
-		}
-	} else {
-		// if tf pre-existed, zone has to exist by definition
-		zoneTFConfig, err = processZone(ctx, zoneObject, resourceZoneName, config.fetchConfig.ModSegment, fileUtils, config.tfWorkPath)
-		if err != nil {
-			fmt.Println(err.Error())
-			return cli.Exit(color.RedString("Failed. Couldn't initialize zone config"), 1)
+			return "", cli.Exit(color.RedString("Failed. Existing zone config is not modularized"), 1)
 		}
+		return zoneTFConfig, nil
 	}
-	return nil
+	// if tf pre-existed, zone has to exist by definition
+	zoneTFConfig, err := processZone(ctx, zoneObject, resourceZoneName, fileUtils, config)
+	if err != nil {
+		progress.Get(ctx).Writeln(err.Error())
+		return "", cli.Exit(color.RedString("Failed. Couldn't initialize zone config"), 1)
+	}
+	return zoneTFConfig, nil
 }
 
-func saveResourceConfigFile(resourceConfigFilename string) (err error) {
-	resourceConfigJSON, err := json.MarshalIndent(&fullZoneConfigMap, "", "  ")
+func saveResourceConfigFile(resourceConfigFilename string, zoneConfigMap map[string]Types, output templates.OutputSink) error {
+	resourceConfigJSON, err := json.MarshalIndent(&zoneConfigMap, "", "  ")
 	if err != nil {
 		return cli.Exit(color.RedString("Unable to generate json formatted zone config"), 1)
 	}
-	f, err := os.Create(resourceConfigFilename)
-	if err != nil {
-		return cli.Exit(color.RedString("Unable to create resource config file"), 1)
+	if err = output.WriteFile(resourceConfigFilename, resourceConfigJSON); err != nil {
+		return cli.Exit(color.RedString("Unable to write zone resource config file"), 1)
 	}
-	defer func(f *os.File) {
-		if e := f.Close(); e != nil {
-			err = e
-		}
-	}(f)
+	return nil
+}
 
-	_, err = f.WriteString(string(resourceConfigJSON))
-	if err != nil {
-		return cli.Exit(color.RedString("Unable to write zone resource config file"), 1)
+func createDNSVarsConfig(reporter progress.Reporter, contractID string, configuration configStruct) error {
+	// Need to create dnsvars.tf dependency
+	dnsVarsFileName := filepath.Join(configuration.tfWorkPath, "dnsvars.tf")
+	dnsVars := fmt.Sprintf(configuration.useTemplate(nil, "dnsvars.tmpl", true), contractID)
+	if err := configuration.output.WriteFile(dnsVarsFileName, []byte(dnsVars)); err != nil {
+		reporter.Fail()
+		return cli.Exit(color.RedString("Unable to write dnsvars config file"), 1)
 	}
-	err = f.Sync()
-	if err != nil {
-		return err
+	if err := configuration.versions.WriteVersions(configuration.output, configuration.tfWorkPath, providerVersion); err != nil {
+		reporter.Fail()
+		return cli.Exit(color.RedString("Unable to write versions config file"), 1)
 	}
 	return nil
 }
 
-func createDNSVarsConfig(term terminal.Terminal, tfWorkPath string) (err error) {
-	// Need to create dnsvars.tf dependency
-	dnsVarsFileName := filepath.Join(tfWorkPath, "dnsvars.tf")
-	dnsVarsHandle, err := os.Create(dnsVarsFileName)
-	//}
-	if err != nil {
-		term.Spinner().Fail()
-		return cli.Exit(color.RedString("Unable to create dnsvars config file"), 1)
+func createBackendConfig(resourceZoneName string, configuration configStruct) error {
+	var importScriptFilename string
+	if configuration.importScript {
+		importScriptFilename = createImportScriptFilename(resourceZoneName, configuration)
 	}
-	defer func(dnsVarsHandle *os.File) {
-		if e := dnsVarsHandle.Close(); e != nil {
-			err = e
-		}
-	}(dnsVarsHandle)
-	_, err = dnsVarsHandle.WriteString(fmt.Sprintf(useTemplate(nil, "dnsvars.tmpl", true), contractID))
-	if err != nil {
-		term.Spinner().Fail()
-		return cli.Exit(color.RedString("Unable to write dnsvars config file"), 1)
+	if err := configuration.backend.Write(configuration.output, configuration.tfWorkPath, importScriptFilename, configuration.importStyle, configuration.versions.Engine); err != nil {
+		return cli.Exit(color.RedString("Unable to write backend config file"), 1)
 	}
+	return nil
+}
 
-	return dnsVarsHandle.Sync()
+func createImportScriptFilename(resourceZoneName string, configuration configStruct) string {
+	return filepath.Join(configuration.tfWorkPath, configuration.importStyle.FileName(resourceZoneName+"_resource_import.script"))
 }
 
-func createImportScript(resourceZoneName string, term terminal.Terminal, configuration configStruct) (err error) {
-	fullZoneConfigMap, _ = retrieveZoneResourceConfig(resourceZoneName, configuration)
-	importScriptFilename := filepath.Join(configuration.tfWorkPath, resourceZoneName+"_resource_import.script")
+func createImportScript(resourceZoneName string, zoneConfigMap map[string]Types, reporter progress.Reporter, configuration configStruct) error {
+	zoneConfigMap, _ = retrieveZoneResourceConfig(resourceZoneName, zoneConfigMap, configuration)
+	importScriptFilename := createImportScriptFilename(resourceZoneName, configuration)
 	if _, err := os.Stat(importScriptFilename); err == nil {
-		term.Spinner().OK()
+		reporter.OK()
 	}
-	scriptContent, err := buildZoneImportScript(zoneName, fullZoneConfigMap, resourceZoneName)
+	scriptContent, err := buildZoneImportScript(zoneConfigMap, resourceZoneName, configuration)
 
 	if err != nil {
 		return cli.Exit(color.RedString("Import script content generation failed"), 1)
 	}
-	f, err := os.Create(importScriptFilename)
-	if err != nil {
-		return cli.Exit(color.RedString("Unable to create import script file"), 1)
-	}
-	defer func(f *os.File) {
-		if e := f.Close(); e != nil {
-			err = e
+	if configuration.importStyle == templates.ImportStyleBlocks {
+		blocks, err := templates.ImportBlocks([]byte(scriptContent))
+		if err != nil {
+			return cli.Exit(color.RedString("Import blocks generation failed"), 1)
 		}
-	}(f)
-	_, err = f.WriteString(scriptContent)
-	if err != nil {
+		scriptContent = string(hclwrite.Format(blocks))
+	} else {
+		scriptContent = string(configuration.backend.Script(configuration.versions.Engine.Script([]byte(scriptContent)), configuration.versions.Engine))
+	}
+	if err = configuration.output.WriteFile(importScriptFilename, []byte(scriptContent)); err != nil {
 		return cli.Exit(color.RedString("Unable to write import script file"), 1)
 	}
-	err = f.Sync()
-
-	return err
+	return nil
 }
 
-func createZoneResourceListFile(resourceZoneName string, recordSets map[string]Types, tfWorkPath string) error {
-	importListFilename := createImportListFilename(resourceZoneName, tfWorkPath)
-	if _, err := os.Stat(importListFilename); err == nil {
-		return cli.Exit(color.RedString("Resource list file exists. Remove to continue."), 1)
+func createZoneResourceListFile(resourceZoneName string, recordSets map[string]Types, configuration configStruct) (*zoneImportListStruct, error) {
+	importListFilename := createImportListFilename(resourceZoneName, configuration.tfWorkPath)
+	if err := configuration.output.Check(importListFilename); err != nil {
+		return nil, cli.Exit(color.RedString("Resource list file exists. Remove to continue."), 1)
 	}
-	fullZoneImportList = &zoneImportListStruct{}
-	fullZoneImportList.Zone = zoneName
-	fullZoneImportList.RecordSets = recordSets
-	err := saveImportListToFile(importListFilename)
+	zoneImportList := &zoneImportListStruct{}
+	zoneImportList.Zone = configuration.zoneName
+	zoneImportList.RecordSets = recordSets
+	err := saveImportListToFile(importListFilename, zoneImportList, configuration.output)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return zoneImportList, nil
 }
 
-func saveImportListToFile(importListFilename string) (err error) {
-	importListJSON, err := json.MarshalIndent(fullZoneImportList, "", "  ")
+func saveImportListToFile(importListFilename string, zoneImportList *zoneImportListStruct, output templates.OutputSink) error {
+	importListJSON, err := json.MarshalIndent(zoneImportList, "", "  ")
 	if err != nil {
 		return cli.Exit(color.RedString("Unable to generate json formatted zone resource list"), 1)
 	}
-	f, err := os.Create(importListFilename)
-	if err != nil {
-		return cli.Exit(color.RedString("Unable to create zone resources file"), 1)
-	}
-	defer func(f *os.File) {
-		if e := f.Close(); e != nil {
-			err = e
-		}
-	}(f)
-	_, err = f.WriteString(string(importListJSON))
-	if err != nil {
+	if err = output.WriteFile(importListFilename, importListJSON); err != nil {
 		return cli.Exit(color.RedString("Unable to write zone resources file"), 1)
 	}
-	err = f.Sync()
-
-	return err
+	return nil
 }
 
 func inventorZone(ctx context.Context, configDNS dns.DNS, configuration configStruct) (map[string]Types, error) {
 	recordSets := make(map[string]Types)
 	// Retrieve all zone names
 	if len(configuration.recordNames) == 0 {
-		recordsetNames, err := configDNS.GetZoneNames(ctx, zoneName)
+		recordsetNames, err := configDNS.GetZoneNames(ctx, configuration.zoneName)
 		if err != nil {
 			return nil, cli.Exit(color.RedString("Zone Name retrieval failed"), 1)
 		}
@@ -393,7 +457,7 @@ func inventorZone(ctx context.Context, configDNS dns.DNS, configuration configSt
 		if configuration.fetchConfig.NamesOnly {
 			recordSets[zName] = make([]string, 0, 0)
 		} else {
-			nameTypesResp, err := configDNS.GetZoneNameTypes(ctx, zName, zoneName)
+			nameTypesResp, err := configDNS.GetZoneNameTypes(ctx, zName, configuration.zoneName)
 			if err != nil {
 				return nil, cli.Exit(color.RedString("Zone Name types retrieval failed"), 1)
 			}
@@ -410,41 +474,20 @@ func createResourceConfigFilename(resourceName, tfWorkPath string) string {
 
 // Utility function to create named module path
 func createNamedModulePath(modName, tfWorkPath string) string {
-	return filepath.Join(tfWorkPath, moduleFolder, normalizeResourceName(modName))
+	return filepath.Join(tfWorkPath, moduleFolder, tools.TerraformIdentifier(modName))
 }
 
-// Utility func to create a directory
-func createDirectory(dirName string) bool {
-
-	stat, err := os.Stat(dirName)
-	if err == nil && stat.IsDir() {
-		return true
-	}
-	if os.IsNotExist(err) {
-		errDir := os.MkdirAll(dirName, 0755)
-		if errDir != nil {
-			panic(err)
-		}
-		return true
-	}
-	if stat.Mode().IsRegular() {
-		return false
-	}
-
-	return false
-}
-
-func buildZoneImportScript(zone string, zoneConfigMap map[string]Types, resourceName string) (string, error) {
+func buildZoneImportScript(zoneConfigMap map[string]Types, resourceName string, configuration configStruct) (string, error) {
 	data := ImportData{
-		Zone:          zone,
+		Zone:          configuration.zoneName,
 		ZoneConfigMap: zoneConfigMap,
 		ResourceName:  resourceName,
 	}
-	return useTemplate(&data, "import-script.tmpl", true), nil
+	return configuration.useTemplate(&data, "import-script.tmpl", true), nil
 }
 
 // remove any resources already present in existing zone tf configuration
-func reconcileZoneResourceTargets(zoneImportList *zoneImportListStruct, zoneName, tfConfig string) (*zoneImportListStruct, map[string]map[string]bool) {
+func reconcileZoneResourceTargets(reporter progress.Reporter, zoneImportList *zoneImportListStruct, zoneName, tfConfig string) (*zoneImportListStruct, map[string]map[string]bool) {
 
 	zoneTypeMap := make(map[string]map[string]bool)
 	// populate zoneTypeMap
@@ -459,7 +502,7 @@ func reconcileZoneResourceTargets(zoneImportList *zoneImportListStruct, zoneName
 				typeMap[ntype] = true
 				revisedTypeList = append(revisedTypeList, ntype)
 			} else {
-				fmt.Println("Recordset resource " + normalName + " found in existing tf file")
+				reporter.Writeln("Recordset resource " + normalName + " found in existing tf file")
 			}
 		}
 		zoneImportList.RecordSets[zName] = revisedTypeList
@@ -469,47 +512,24 @@ func reconcileZoneResourceTargets(zoneImportList *zoneImportListStruct, zoneName
 	return zoneImportList, zoneTypeMap
 }
 
-func openZoneConfigFile(zoneName string, tfWorkPath string) (*os.File, string, error) {
-	tfFilename := tools.CreateTFFilename(zoneName, tfWorkPath)
-	var tfHandle *os.File
-	tfHandle, err := os.OpenFile(tfFilename, os.O_CREATE|os.O_RDWR, 0644)
-	if err != nil && err != io.EOF {
-		fmt.Println(err.Error())
-		return nil, "", err
-	}
-	tfInfo, err := os.Stat(tfFilename)
-	if err != nil {
-		fmt.Println(err.Error())
-		return nil, "", err
-	}
-	tfScratch := make([]byte, tfInfo.Size())
-	charsRead, err := tfHandle.Read(tfScratch)
-	if err != nil && err != io.EOF {
-		fmt.Println(err.Error())
-		return nil, "", err
+func readZoneConfigFile(reporter progress.Reporter, tfFilename string) (string, error) {
+	tfConfig, err := os.ReadFile(tfFilename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		reporter.Writeln(err.Error())
+		return "", err
 	}
-	_, err = tfHandle.Seek(0, 0)
-	if err != nil {
-		fmt.Println(err.Error())
-		return nil, "", err
-	}
-	tfConfig := ""
-	if charsRead > 0 {
-		tfConfig = fmt.Sprintf("%s", tfScratch[0:charsRead-1])
-	}
-
-	return tfHandle, tfConfig, nil
+	return string(tfConfig), nil
 }
 
-func retrieveZoneImportList(rscName string, configuration configStruct) (*zoneImportListStruct, error) {
+func retrieveZoneImportList(rscName string, zoneImportList *zoneImportListStruct, configuration configStruct) (*zoneImportListStruct, error) {
 	// check if shouldCreateImportList set. If so, already have ....
 	if configuration.shouldCreateImportList {
-		return fullZoneImportList, nil
+		return zoneImportList, nil
 	}
 	if configuration.fetchConfig.ConfigOnly {
-		fullZoneImportList := &zoneImportListStruct{Zone: zoneName}
-		fullZoneImportList.RecordSets = make(map[string]Types)
-		return fullZoneImportList, nil
+		zoneImportList := &zoneImportListStruct{Zone: configuration.zoneName}
+		zoneImportList.RecordSets = make(map[string]Types)
+		return zoneImportList, nil
 	}
 	importListFilename := createImportListFilename(rscName, configuration.tfWorkPath)
 	if _, err := os.Stat(importListFilename); err != nil {
@@ -528,11 +548,11 @@ func retrieveZoneImportList(rscName string, configuration configStruct) (*zoneIm
 	return importList, nil
 }
 
-func retrieveZoneResourceConfig(rscName string, config configStruct) (map[string]Types, error) {
+func retrieveZoneResourceConfig(rscName string, zoneConfigMap map[string]Types, config configStruct) (map[string]Types, error) {
 	configList := make(map[string]Types)
 	// check if createConfig set. If so, already have ....
 	if config.createConfig {
-		return fullZoneConfigMap, nil
+		return zoneConfigMap, nil
 	}
 	resourceConfigFilename := createResourceConfigFilename(rscName, config.tfWorkPath)
 	if _, err := os.Stat(resourceConfigFilename); err != nil {
@@ -549,3 +569,16 @@ func retrieveZoneResourceConfig(rscName string, config configStruct) (map[string
 
 	return configList, nil
 }
+
+// completeZoneNames returns names of the zones available to the credentials
+func completeZoneNames(ctx context.Context, e *exporter.Export) ([]string, error) {
+	zones, err := e.DNSClient().ListZones(ctx, dns.ZoneListQueryArgs{ShowAll: true})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(zones.Zones))
+	for _, zone := range zones.Zones {
+		names = append(names, zone.Zone)
+	}
+	return names, nil
+}
@@ -1,12 +1,14 @@
 package dns
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 
-	"github.com/akamai/cli/pkg/terminal"
+	"github.com/akamai/cli-terraform/pkg/progress"
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/akamai/cli-terraform/pkg/tools"
 )
 
 type fileUtils interface {
@@ -14,50 +16,32 @@ type fileUtils interface {
 	appendRootModuleTF(configText string) error
 }
 
-type fileUtilsProcessor struct{}
+type fileUtilsProcessor struct {
+	output     templates.OutputSink
+	rootModule *bytes.Buffer
+}
 
 // Work routine to create module TF file
-func (fileUtilsProcessor) createModuleTF(ctx context.Context, modName, content, tfWorkPath string) (err error) {
-	term := terminal.Get(ctx)
-	term.Printf("Creating zone name %s module configuration file...", modName)
+func (f fileUtilsProcessor) createModuleTF(ctx context.Context, modName, content, tfWorkPath string) error {
+	reporter := progress.Get(ctx)
+	reporter.Printf("Creating zone name %s module configuration file...", modName)
 	namedModulePath := createNamedModulePath(modName, tfWorkPath)
-	if !createDirectory(namedModulePath) {
-		return fmt.Errorf("failed to create name module folder: %s", namedModulePath)
-	}
-	moduleFilename := filepath.Join(namedModulePath, normalizeResourceName(modName)+".tf")
-	if _, err := os.Stat(moduleFilename); err == nil {
+	moduleFilename := filepath.Join(namedModulePath, tools.TerraformIdentifier(modName)+".tf")
+	if err := f.output.Check(moduleFilename); err != nil {
 		// File exists.
 		return fmt.Errorf("module configuration file already exists: %s", moduleFilename)
 	}
-	f, err := os.Create(moduleFilename)
-	if err != nil {
-		return fmt.Errorf("failed to create name module configuration file: %s", namedModulePath)
-	}
-	defer func(f *os.File) {
-		if e := f.Close(); e != nil {
-			err = e
-		}
-	}(f)
-	_, err = f.WriteString(content)
-	if err != nil {
+	if err := f.output.WriteFile(moduleFilename, []byte(content)); err != nil {
 		return fmt.Errorf("failed to write name module configuration: %s", namedModulePath)
 	}
-	err = f.Sync()
-
-	return err
+	return nil
 }
 
-// Flush string to root module TF file
-func (fileUtilsProcessor) appendRootModuleTF(configText string) error {
+// Append string to root module TF file, which is saved once zone configuration is complete
+func (f fileUtilsProcessor) appendRootModuleTF(configText string) error {
 	// save top level Zone TF config
-	_, err := zoneTFFileHandle.Write([]byte(configText))
-	if err != nil {
+	if _, err := f.rootModule.WriteString(configText); err != nil {
 		return fmt.Errorf("failed to save zone configuration file")
 	}
-	err = zoneTFFileHandle.Sync()
-	if err != nil {
-		return err
-	}
-
 	return nil
 }
@@ -2,13 +2,16 @@ package dns
 
 import (
 	"testing"
+	"text/template"
 
+	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCreatingImportingScript(t *testing.T) {
 	zoneConfigMap := map[string]Types{"a": {"b", "c", "d"}, "e": {"f", "g", "h"}}
-	importScript, err := buildZoneImportScript("some-zone", zoneConfigMap, "resource_name")
+	config := configStruct{zoneName: "some-zone", tmpl: template.Must(parseTemplates("", "./", &templates.Secrets{}))}
+	importScript, err := buildZoneImportScript(zoneConfigMap, "resource_name", config)
 	require.NoError(t, err)
 	assertFileWithContent(t, "./testdata/import_script/import.sh", importScript)
 }
@@ -3,10 +3,13 @@ package dns
 import (
 	"bytes"
 	"embed"
+	"fmt"
+	"path/filepath"
 	"strings"
 	"text/template"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/dns"
+	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
 )
 
@@ -46,17 +49,38 @@ type (
 	}
 )
 
-var funcs = tools.DecorateWithMultilineHandlingFunctions(map[string]any{
-	"namedModulePath":           createNamedModulePath,
-	"checkForResource":          checkForResource,
-	"createUniqueRecordsetName": createUniqueRecordsetName,
-})
-var tmpl = template.Must(template.New("template").Funcs(funcs).ParseFS(templateFiles, "**/*.tmpl"))
+// tfState holds terraform state of the working directory, it is read on the first check of a resource
+type tfState struct {
+	state *tfStateStruct
+}
+
+// parseTemplates parses embedded templates, replacing them with the ones of the same name from templateDir if it is set
+// Templates are parsed for each export, so that the state read by checkForResource is not shared between exports
+// Values passed to 'secret' function are collected in secrets as variables of the root module in tfWorkPath
+func parseTemplates(templateDir, tfWorkPath string, secrets *templates.Secrets) (*template.Template, error) {
+	templatesFS, err := templates.NewOverlayFS(templateFiles, templateDir)
+	if err != nil {
+		return nil, err
+	}
+	funcs := tools.DecorateWithMultilineHandlingFunctions(map[string]any{
+		"namedModulePath":           createNamedModulePath,
+		"checkForResource":          (&tfState{}).checkForResource,
+		"createUniqueRecordsetName": createUniqueRecordsetName,
+		"secret": func(name, value string) string {
+			return secrets.Add(filepath.Clean(tfWorkPath), name, value)
+		},
+	})
+	t, err := template.New("template").Funcs(funcs).ParseFS(templatesFS, "**/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", templates.ErrTemplateParsing, err)
+	}
+	return t, nil
+}
 
-func useTemplate(data interface{}, templateName string, trimBeginning bool) string {
+func (c configStruct) useTemplate(data interface{}, templateName string, trimBeginning bool) string {
 	buf := bytes.Buffer{}
 
-	if err := tmpl.Lookup(templateName).Execute(&buf, data); err != nil {
+	if err := c.tmpl.Lookup(templateName).Execute(&buf, data); err != nil {
 		return ""
 	}
 
@@ -69,15 +93,17 @@ func useTemplate(data interface{}, templateName string, trimBeginning bool) stri
 }
 
 // check if resource present in state
-func checkForResource(rType, name, tfWorkPath string) bool {
+func (s *tfState) checkForResource(rType, name, tfWorkPath string) bool {
 
-	if tfState == nil {
-		if err := readTfState(tfWorkPath); err != nil {
+	if s.state == nil {
+		state, err := readTfState(tfWorkPath)
+		if err != nil {
 			// not differentiating between not exists and file error
 			return false
 		}
+		s.state = state
 	}
-	for _, r := range tfState.Resources {
+	for _, r := range s.state.Resources {
 		if r.Type == rType && r.Name == name {
 			return true
 		}
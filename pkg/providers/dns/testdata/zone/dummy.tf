@@ -1,9 +0,0 @@
-terraform {
-  required_version = ">= 1.0"
-  required_providers {
-    akamai = {
-      source  = "akamai/akamai"
-      version = ">= 1.6.1"
-    }
-  }
-}
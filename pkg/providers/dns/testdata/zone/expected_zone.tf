@@ -1,13 +1,3 @@
-terraform {
-  required_version = ">= 1.0"
-  required_providers {
-    akamai = {
-      source  = "akamai/akamai"
-      version = ">= 1.6.1"
-    }
-  }
-}
-
 locals {
   zone = "0007770b-08a8-4b5f-a46b-081b772ba605-test.com"
 }
@@ -26,7 +16,7 @@ resource "akamai_dns_zone" "_0007770b-08a8-4b5f-a46b-081b772ba605-test_com" {
   tsig_key {
     name      = "some-name"
     algorithm = "some-algorithm"
-    secret    = "some-secret"
+    secret    = var.tsig_key_secret
   }
 }
 
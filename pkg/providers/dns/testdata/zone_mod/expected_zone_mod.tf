@@ -1,13 +1,3 @@
-terraform {
-  required_version = ">= 1.0"
-  required_providers {
-    akamai = {
-      source  = "akamai/akamai"
-      version = ">= 1.6.1"
-    }
-  }
-}
-
 locals {
   zone = "0007770b-08a8-4b5f-a46b-081b772ba605-test.com"
 }
@@ -15,7 +5,8 @@ locals {
 module "_0007770b-08a8-4b5f-a46b-081b772ba605-test_com" {
   source = "modules/_0007770b-08a8-4b5f-a46b-081b772ba605-test_com"
 
-  contract = var.contractid
-  group    = var.groupid
-  name     = local.zone
+  contract        = var.contractid
+  group           = var.groupid
+  name            = local.zone
+  tsig_key_secret = var.tsig_key_secret
 }
@@ -13,6 +13,12 @@ variable "name" {
   description = "zone name"
 }
 
+variable "tsig_key_secret" {
+  type        = string
+  sensitive   = true
+  description = "secret of the TSIG key"
+}
+
 output "zonename" {
   value = akamai_dns_zone._0007770b-08a8-4b5f-a46b-081b772ba605-test_com.name
 }
@@ -35,6 +41,6 @@ resource "akamai_dns_zone" "_0007770b-08a8-4b5f-a46b-081b772ba605-test_com" {
   tsig_key {
     name      = "some-name"
     algorithm = "some-algorithm"
-    secret    = "some-secret"
+    secret    = var.tsig_key_secret
   }
 }
@@ -88,15 +88,15 @@ func processRecordSets(ctx context.Context, client dns.DNS, zone, resourceZoneNa
 			data := RecordsetData{BlockName: modName, ResourceFields: recordMap, TFWorkPath: config.tfWorkPath}
 			if config.fetchConfig.ModSegment {
 				// process as module
-				if err := fileUtils.appendRootModuleTF(useTemplate(&data, "module-set.tmpl", false)); err != nil {
+				if err := fileUtils.appendRootModuleTF(config.useTemplate(&data, "module-set.tmpl", false)); err != nil {
 					return nil, err
 				}
-				if err := fileUtils.createModuleTF(ctx, modName, useTemplate(&data, "recordset-modsegment.tmpl", true), config.tfWorkPath); err != nil {
+				if err := fileUtils.createModuleTF(ctx, modName, config.useTemplate(&data, "recordset-modsegment.tmpl", true), config.tfWorkPath); err != nil {
 					return nil, err
 				}
 			} else {
 				// add to toplevel TF
-				if err := fileUtils.appendRootModuleTF(useTemplate(&data, "resource-set.tmpl", false)); err != nil {
+				if err := fileUtils.appendRootModuleTF(config.useTemplate(&data, "resource-set.tmpl", false)); err != nil {
 					return nil, err
 				}
 			}
@@ -247,7 +247,7 @@ func processString(source string) string {
 // create unique resource record name
 func createUniqueRecordsetName(resourceZoneName, rName, rType string) string {
 	return strings.TrimRight(fmt.Sprintf("%s_%s_%s",
-		normalizeResourceName(resourceZoneName),
-		normalizeResourceName(rName),
+		tools.TerraformIdentifier(resourceZoneName),
+		tools.TerraformIdentifier(rName),
 		rType), "_")
 }
@@ -3,8 +3,10 @@ package dns
 import (
 	"context"
 	"testing"
+	"text/template"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/dns"
+	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -92,7 +94,7 @@ func TestProcessRecordset(t *testing.T) {
 			}
 			zoneTypeMap := make(map[string]map[string]bool)
 			zoneTypeMap["someName"] = map[string]bool{"someType": true}
-			config := configStruct{fetchConfig: fetchConfigStruct{ModSegment: test.mod}}
+			config := configStruct{fetchConfig: fetchConfigStruct{ModSegment: test.mod}, tmpl: template.Must(parseTemplates("", "./", &templates.Secrets{}))}
 			processingResult, _ := processRecordSets(ctx, m, zone, "zoneName", zoneTypeMap, fus, config)
 
 			assert.Equal(t, 1, len(processingResult))
@@ -21,7 +21,7 @@ import (
 )
 
 // process zone
-func processZone(ctx context.Context, zone *dns.ZoneResponse, resourceZoneName string, modSegment bool, fileUtils fileUtils, tfWorkPath string) (string, error) {
+func processZone(ctx context.Context, zone *dns.ZoneResponse, resourceZoneName string, fileUtils fileUtils, config configStruct) (string, error) {
 	data := ZoneData{
 		BlockName:             resourceZoneName,
 		Zone:                  zone.Zone,
@@ -33,17 +33,17 @@ func processZone(ctx context.Context, zone *dns.ZoneResponse, resourceZoneName s
 		TSIGKey:               zone.TSIGKey,
 		Target:                zone.Target,
 		EndCustomerID:         zone.EndCustomerID,
-		TFWorkPath:            tfWorkPath,
+		TFWorkPath:            config.tfWorkPath,
 	}
 	var zoneTF string
-	if modSegment {
-		err := fileUtils.createModuleTF(ctx, resourceZoneName, useTemplate(&data, "config.tmpl", true), tfWorkPath)
+	if config.fetchConfig.ModSegment {
+		err := fileUtils.createModuleTF(ctx, resourceZoneName, config.useTemplate(&data, "config.tmpl", true), config.tfWorkPath)
 		if err != nil {
 			return "", err
 		}
-		zoneTF = useTemplate(&data, "zone.tmpl", true)
+		zoneTF = config.useTemplate(&data, "zone.tmpl", true)
 	} else {
-		zoneTF = useTemplate(&data, "full_zone.tmpl", true)
+		zoneTF = config.useTemplate(&data, "full_zone.tmpl", true)
 	}
 
 	return zoneTF, nil
@@ -3,8 +3,11 @@ package dns
 import (
 	"context"
 	"testing"
+	"text/template"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/dns"
+	"github.com/akamai/cli-terraform/pkg/templates"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -80,9 +83,12 @@ func TestProcessZone(t *testing.T) {
 			if test.modSegment {
 				m.On("createModuleTF", test.modName, mock.Anything, mock.Anything).Return(nil).Once()
 			}
-			zone, err := processZone(context.Background(), &test.zoneResponse, "_0007770b-08a8-4b5f-a46b-081b772ba605-test_com", test.modSegment, m, "./")
+			secrets := &templates.Secrets{}
+			config := configStruct{fetchConfig: fetchConfigStruct{ModSegment: test.modSegment}, tfWorkPath: "./", tmpl: template.Must(parseTemplates("", "./", secrets))}
+			zone, err := processZone(context.Background(), &test.zoneResponse, "_0007770b-08a8-4b5f-a46b-081b772ba605-test_com", m, config)
 			require.NoError(t, err)
 			m.AssertExpectations(t)
+			assert.Equal(t, map[string][]string{".": {"tsig_key_secret"}}, secrets.Names())
 
 			if test.modSegment {
 				assertFileWithContent(t, test.modContentPath, m.createModuleArg)
@@ -39,24 +39,21 @@ type Resource struct {
 	Instances []interface{} `json:"instances"`
 }
 
-var tfState *tfStateStruct
-
 // Utility method to read in tf state content
-func readTfState(tfWorkPath string) error {
-	// TFWorkPath global var
+func readTfState(tfWorkPath string) (*tfStateStruct, error) {
 	tfStateFileName := filepath.Join(tfWorkPath, "terraform.tfstate")
 	if _, err := os.Stat(tfStateFileName); err != nil {
-		return err
+		return nil, err
 	}
 	stateData, err := ioutil.ReadFile(tfStateFileName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	tfState = &tfStateStruct{}
-	err = json.Unmarshal(stateData, tfState)
+	state := &tfStateStruct{}
+	err = json.Unmarshal(stateData, state)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return state, nil
 }
@@ -6,15 +6,14 @@ import (
 	"embed"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"strings"
 	"text/template"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/edgeworkers"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -32,10 +31,25 @@ type (
 	}
 )
 
+const (
+	// edgeKVProviderVersion is the minimal version of the akamai provider required by exported EdgeKV
+	edgeKVProviderVersion = "3.6.0"
+
+	// edgeWorkerProviderVersion is the minimal version of the akamai provider required by exported EdgeWorker
+	edgeWorkerProviderVersion = "2.0.0"
+)
+
 var (
 	//go:embed templates/*
 	templateFiles embed.FS
 
+	additionalFunctions = template.FuncMap{
+		"ToLower": func(network edgeworkers.ActivationNetwork) string {
+			return strings.ToLower(string(network))
+		},
+		"Escape": tools.Escape,
+	}
+
 	// ErrFetchingEdgeKV is returned when fetching edgekv fails
 	ErrFetchingEdgeKV = errors.New("unable to fetch edgekv with given namespace_name and network")
 	// ErrFetchingEdgeKVItems is returned when fetching edgekv items fails
@@ -46,91 +60,86 @@ var (
 	ErrFetchingEdgeKVGroups = errors.New("unable to fetch edgekv groups with given namespace_name and network")
 )
 
-// CmdCreateEdgeKV is an entrypoint to create-edgekv command
-func CmdCreateEdgeKV(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(c.Context)
-	client := edgeworkers.Client(sess)
+// EdgeKVExporter exports EdgeKV namespaces, see export-edgekv command
+type EdgeKVExporter struct{}
 
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
+// Spec describes export-edgekv command
+func (EdgeKVExporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-edgekv",
+		Aliases:     []string{"create-edgekv"},
+		Description: "Generates Terraform configuration for EdgeKV resources",
+		Args:        []string{"namespace_name", "network"},
+		Complete:    exporter.CompleteArgs(completeNamespaceNames, exporter.CompleteValues(string(edgeworkers.NamespaceStagingNetwork), string(edgeworkers.NamespaceProductionNetwork))),
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Maximum number of EdgeKV items fetched at the same time",
+				Value: 4,
+			},
+		},
+		Templates: exporter.TemplateSet{
+			FS: templateFiles,
+			Files: map[string]string{
+				"edgekv.tmpl":           "edgekv.tf",
+				"edgekv-variables.tmpl": "variables.tf",
+				"edgekv-imports.tmpl":   "import.sh",
+			},
+			Imports:         "edgekv-imports.tmpl",
+			Prefix:          "edgekv",
+			Funcs:           additionalFunctions,
+			Data:            map[string]interface{}{"": TFEdgeKVData{}},
+			ProviderVersion: edgeKVProviderVersion,
+		},
 	}
+}
 
-	edgeKVPath := filepath.Join(tfWorkPath, "edgekv.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
+// Export exports the namespace with its groups and items
+func (EdgeKVExporter) Export(ctx context.Context, e *exporter.Export) error {
+	ctx = tools.WithConcurrency(ctx, e.Flags.Int("concurrency"))
+	client := e.EdgeWorkersClient()
 
-	err := tools.CheckFiles(edgeKVPath, variablesPath, importPath)
-	if err != nil {
+	if err := e.Check(); err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
-	templateToFile := map[string]string{
-		"edgekv.tmpl":           edgeKVPath,
-		"edgekv-variables.tmpl": variablesPath,
-		"edgekv-imports.tmpl":   importPath,
-	}
 
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: template.FuncMap{
-			"ToLower": func(network edgeworkers.ActivationNetwork) string {
-				return strings.ToLower(string(network))
-			},
-			"Escape": tools.Escape,
-		},
-	}
-
-	namespace := c.Args().First()
-	network := edgeworkers.NamespaceNetwork(c.Args().Get(1))
-	section := edgegrid.GetEdgercSection(c)
-
-	if err = createEdgeKV(ctx, namespace, network, section, client, processor); err != nil {
+	namespace := e.Arg(0)
+	network := edgeworkers.NamespaceNetwork(e.Arg(1))
+	if err := createEdgeKV(ctx, namespace, network, e.Section, client, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting edgekv HCL: %s", err)), 1)
 	}
 	return nil
 }
 
 func createEdgeKV(ctx context.Context, namespace string, network edgeworkers.NamespaceNetwork, section string, client edgeworkers.Edgeworkers, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
-	fmt.Println("Configuring EdgeKV")
-	term.Spinner().Start("Fetching EdgeKV %s", namespace)
+	reporter := progress.Get(ctx)
+	reporter.Writeln("Configuring EdgeKV")
+	reporter.Start("Fetching EdgeKV %s", namespace)
 
 	edgeKV, err := getEdgeKV(ctx, namespace, network, client)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingEdgeKV, err)
 	}
+	templates.GetReport(ctx).AddObject(templates.ReportObject{
+		Type: "edgekv_namespace",
+		ID:   fmt.Sprintf("%s:%s", edgeKV.Name, network),
+		Name: edgeKV.Name,
+	})
 
-	term.Spinner().OK()
-	term.Spinner().Start("Fetching EdgeKV groups in %s", namespace)
-	groupItems := make(map[string]map[string]edgeworkers.Item, 0)
+	reporter.OK()
+	reporter.Start("Fetching EdgeKV groups in %s", namespace)
 	edgeKVGroups, err := getEdgeKVGroups(ctx, namespace, network, client)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingEdgeKV, err)
 	}
-	term.Spinner().OK()
-	term.Spinner().Start("Fetching EdgeKV items in groups in %s", namespace)
-	for _, group := range edgeKVGroups {
-		edgeKVItems, err := getEdgeKVItems(ctx, namespace, network, group, client)
-		if err != nil {
-			term.Spinner().Fail()
-			return fmt.Errorf("%w: %s", ErrFetchingEdgeKV, err)
-		}
-
-		items := make(map[string]edgeworkers.Item, 0)
-		for _, itemID := range *edgeKVItems {
-			item, err := getEdgeKVItem(ctx, namespace, network, group, itemID, client)
-			if err != nil {
-				term.Spinner().Fail()
-				return fmt.Errorf("%w: %s", ErrFetchingEdgeKV, err)
-			}
-			items[itemID] = *item
-		}
-		groupItems[group] = items
+	reporter.OK()
+	reporter.Start("Fetching EdgeKV items in groups in %s", namespace)
+	groupItems, err := getEdgeKVGroupItems(ctx, namespace, network, edgeKVGroups, client)
+	if err != nil {
+		reporter.Fail()
+		return fmt.Errorf("%w: %s", ErrFetchingEdgeKV, err)
 	}
 	tfEdgeKVData := TFEdgeKVData{
 		Name:        edgeKV.Name,
@@ -146,14 +155,14 @@ func createEdgeKV(ctx context.Context, namespace string, network edgeworkers.Nam
 		tfEdgeKVData.GroupID = *edgeKV.GroupID
 	}
 
-	term.Spinner().OK()
-	term.Spinner().Start("Saving TF configurations ")
+	reporter.OK()
+	reporter.Start("Saving TF configurations ")
 	if err := templateProcessor.ProcessTemplates(tfEdgeKVData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", templates.ErrSavingFiles, err)
 	}
-	term.Spinner().OK()
-	fmt.Printf("Terraform configuration for edgeKV '%s' on network '%s' was saved successfully\n", edgeKV.Name, network)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for edgeKV '%s' on network '%s' was saved successfully\n", edgeKV.Name, network)
 
 	return nil
 }
@@ -173,6 +182,54 @@ func getEdgeKV(ctx context.Context, namespace string, network edgeworkers.Namesp
 	return edgeKV, nil
 }
 
+// getEdgeKVGroupItems fetches items of all groups by the group and the item id, using at most tools.Concurrency(ctx)
+// requests at the same time
+func getEdgeKVGroupItems(ctx context.Context, namespace string, network edgeworkers.NamespaceNetwork, groups []string, client edgeworkers.Edgeworkers) (map[string]map[string]edgeworkers.Item, error) {
+	itemIDs := make([]edgeworkers.ListItemsResponse, len(groups))
+	err := tools.ForEach(ctx, len(groups), func(ctx context.Context, i int) error {
+		edgeKVItems, err := getEdgeKVItems(ctx, namespace, network, groups[i], client)
+		if err != nil {
+			return err
+		}
+		itemIDs[i] = *edgeKVItems
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type groupItem struct {
+		group, itemID string
+	}
+	var groupItems []groupItem
+	for i, group := range groups {
+		for _, itemID := range itemIDs[i] {
+			groupItems = append(groupItems, groupItem{group: group, itemID: itemID})
+		}
+	}
+	items := make([]edgeworkers.Item, len(groupItems))
+	err = tools.ForEach(ctx, len(groupItems), func(ctx context.Context, i int) error {
+		item, err := getEdgeKVItem(ctx, namespace, network, groupItems[i].group, groupItems[i].itemID, client)
+		if err != nil {
+			return err
+		}
+		items[i] = *item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]map[string]edgeworkers.Item, len(groups))
+	for _, group := range groups {
+		res[group] = make(map[string]edgeworkers.Item, 0)
+	}
+	for i, groupItem := range groupItems {
+		res[groupItem.group][groupItem.itemID] = items[i]
+	}
+	return res, nil
+}
+
 func getEdgeKVItems(ctx context.Context, namespace string, network edgeworkers.NamespaceNetwork, groupID string, client edgeworkers.Edgeworkers) (*edgeworkers.ListItemsResponse, error) {
 	items, err := client.ListItems(ctx, edgeworkers.ListItemsRequest{
 		ItemsRequestParams: edgeworkers.ItemsRequestParams{
@@ -215,3 +272,23 @@ func getEdgeKVGroups(ctx context.Context, namespace string, network edgeworkers.
 
 	return items, nil
 }
+
+// completeNamespaceNames returns names of the EdgeKV namespaces on staging and production networks
+func completeNamespaceNames(ctx context.Context, e *exporter.Export) ([]string, error) {
+	client := e.EdgeWorkersClient()
+	var names []string
+	seen := make(map[string]bool)
+	for _, network := range []edgeworkers.NamespaceNetwork{edgeworkers.NamespaceStagingNetwork, edgeworkers.NamespaceProductionNetwork} {
+		namespaces, err := client.ListEdgeKVNamespaces(ctx, edgeworkers.ListEdgeKVNamespacesRequest{Network: network})
+		if err != nil {
+			return nil, err
+		}
+		for _, namespace := range namespaces.Namespaces {
+			if !seen[namespace.Name] {
+				seen[namespace.Name] = true
+				names = append(names, namespace.Name)
+			}
+		}
+	}
+	return names, nil
+}
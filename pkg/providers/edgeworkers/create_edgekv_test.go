@@ -11,6 +11,7 @@ import (
 	"text/template"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/edgeworkers"
+	"github.com/akamai/cli-terraform/pkg/exporter"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
 	"github.com/akamai/cli/pkg/terminal"
@@ -142,8 +143,9 @@ func TestCreateEdgeKV(t *testing.T) {
 	section := "test_section"
 
 	tests := map[string]struct {
-		init      func(*edgeworkers.Mock, *templates.MockProcessor)
-		withError error
+		init        func(*edgeworkers.Mock, *templates.MockProcessor)
+		concurrency int
+		withError   error
 	}{
 		"fetch edgekv based on namespace and network": {
 			init: func(e *edgeworkers.Mock, p *templates.MockProcessor) {
@@ -165,6 +167,32 @@ func TestCreateEdgeKV(t *testing.T) {
 				expectProcessTemplates(p, edgeworkers.NamespaceStagingNetwork, "test_namespace", "EU", 0, intPtr(123), section, items, nil).Once()
 			},
 		},
+		"group items fetched concurrently": {
+			init: func(e *edgeworkers.Mock, p *templates.MockProcessor) {
+				expectGetEdgeKVNamespace(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "EU", intPtr(0), intPtr(123), nil).Once()
+				expectListGroupsWithinNamespace(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", []string{"group1", "group2"}, nil).Once()
+				expectListItems(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "group1", &edgeworkers.ListItemsResponse{"item1.1", "item1.2"}, nil).Once()
+				expectListItems(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "group2", &edgeworkers.ListItemsResponse{"item2.1", "item2.2"}, nil).Once()
+				expectGetItem(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "group1", "item1.1", "value1.1", nil).Once()
+				expectGetItem(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "group1", "item1.2", "value1.2", nil).Once()
+				expectGetItem(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "group2", "item2.1", "value2.1", nil).Once()
+				expectGetItem(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "group2", "item2.2", "value\n2.2", nil).Once()
+				expectProcessTemplates(p, edgeworkers.NamespaceStagingNetwork, "test_namespace", "EU", 0, intPtr(123), section, items, nil).Once()
+			},
+			concurrency: 3,
+		},
+		"error fetching group item concurrently": {
+			init: func(e *edgeworkers.Mock, p *templates.MockProcessor) {
+				expectGetEdgeKVNamespace(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "EU", intPtr(0), intPtr(123), nil).Once()
+				expectListGroupsWithinNamespace(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", []string{"group1"}, nil).Once()
+				expectListItems(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "group1", &edgeworkers.ListItemsResponse{"item1.1", "item1.2", "item1.3"}, nil).Once()
+				expectGetItem(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "group1", "item1.1", "", fmt.Errorf("error")).Once()
+				expectGetItem(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "group1", "item1.2", "value1.2", nil).Maybe()
+				expectGetItem(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "group1", "item1.3", "value1.3", nil).Maybe()
+			},
+			concurrency: 2,
+			withError:   ErrFetchingEdgeKV,
+		},
 		"fetch edgekv based on namespace and network with no group_id returned": {
 			init: func(e *edgeworkers.Mock, p *templates.MockProcessor) {
 				expectGetEdgeKVNamespace(e, edgeworkers.NamespaceStagingNetwork, "test_namespace", "EU", intPtr(0), nil, nil).Once()
@@ -194,6 +222,7 @@ func TestCreateEdgeKV(t *testing.T) {
 			mp := new(templates.MockProcessor)
 			test.init(me, mp)
 			ctx := terminal.Context(context.Background(), terminal.New(terminal.DiscardWriter(), nil, terminal.DiscardWriter()))
+			ctx = tools.WithConcurrency(ctx, test.concurrency)
 			err := createEdgeKV(ctx, "test_namespace", edgeworkers.NamespaceStagingNetwork, section, me, mp)
 			if test.withError != nil {
 				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
@@ -291,3 +320,23 @@ func TestProcessEdgeKVTemplates(t *testing.T) {
 		})
 	}
 }
+
+func TestCompleteNamespaceNames(t *testing.T) {
+	m := &edgeworkers.Mock{}
+	m.On("ListEdgeKVNamespaces", mock.Anything, edgeworkers.ListEdgeKVNamespacesRequest{Network: edgeworkers.NamespaceStagingNetwork}).
+		Return(&edgeworkers.ListEdgeKVNamespacesResponse{Namespaces: []edgeworkers.Namespace{{Name: "a"}, {Name: "b"}}}, nil).Once()
+	m.On("ListEdgeKVNamespaces", mock.Anything, edgeworkers.ListEdgeKVNamespacesRequest{Network: edgeworkers.NamespaceProductionNetwork}).
+		Return(&edgeworkers.ListEdgeKVNamespacesResponse{Namespaces: []edgeworkers.Namespace{{Name: "b"}, {Name: "c"}}}, nil).Once()
+	complete := EdgeKVExporter{}.Spec().Complete
+	e := &exporter.Export{Clients: exporter.Clients{EdgeWorkers: m}}
+
+	names, err := complete(context.Background(), e)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+
+	e.Args = []string{"a"}
+	networks, err := complete(context.Background(), e)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"staging", "production"}, networks)
+	m.AssertExpectations(t)
+}
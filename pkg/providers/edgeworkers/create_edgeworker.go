@@ -9,15 +9,12 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
-	"strings"
-	"text/template"
 	"time"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/edgeworkers"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
-	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -44,74 +41,75 @@ const (
 	activationStatusComplete = "COMPLETE"
 )
 
-// CmdCreateEdgeWorker is an entrypoint to create-edgeworker command
-func CmdCreateEdgeWorker(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(c.Context)
-	client := edgeworkers.Client(sess)
-
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
+// EdgeWorkerExporter exports EdgeWorkers with their code bundles, see export-edgeworker command
+type EdgeWorkerExporter struct{}
+
+// Spec describes export-edgeworker command
+func (EdgeWorkerExporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-edgeworker",
+		Aliases:     []string{"create-edgeworker"},
+		Description: "Generates Terraform configuration for EdgeWorker resources",
+		Args:        []string{"edgeworker_id"},
+		Complete:    completeEdgeWorkerIDs,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "bundlepath",
+				Usage: "Path location for placement of EdgeWorkers tgz code bundle. Default: same value as tfworkpath",
+			},
+		},
+		Templates: exporter.TemplateSet{
+			FS: templateFiles,
+			Files: map[string]string{
+				"edgeworker.tmpl":           "edgeworker.tf",
+				"edgeworker-variables.tmpl": "variables.tf",
+				"edgeworker-imports.tmpl":   "import.sh",
+			},
+			Imports:         "edgeworker-imports.tmpl",
+			Prefix:          "edgeworker",
+			Funcs:           additionalFunctions,
+			Data:            map[string]interface{}{"": TFEdgeWorkerData{}},
+			ProviderVersion: edgeWorkerProviderVersion,
+		},
 	}
+}
 
-	edgeWorkerPath := filepath.Join(tfWorkPath, "edgeworker.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
+// Export exports the EdgeWorker and saves its code bundle into bundlepath
+func (EdgeWorkerExporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.EdgeWorkersClient()
 
-	bundleDir := tfWorkPath
-	if c.IsSet("bundlepath") {
-		bundleDir = c.String("bundlepath")
+	bundleDir := e.WorkPath
+	if e.Flags.IsSet("bundlepath") {
+		bundleDir = filepath.FromSlash(e.Flags.String("bundlepath"))
 	}
-	bundleDir = filepath.FromSlash(bundleDir)
 	if stat, err := os.Stat(bundleDir); err != nil || !stat.IsDir() {
 		return cli.Exit(color.RedString("Bundle path is not accessible"), 1)
 	}
 
-	err := tools.CheckFiles(edgeWorkerPath, variablesPath, importPath)
-	if err != nil {
+	if err := e.Check(); err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
-	templateToFile := map[string]string{
-		"edgeworker.tmpl":           edgeWorkerPath,
-		"edgeworker-variables.tmpl": variablesPath,
-		"edgeworker-imports.tmpl":   importPath,
-	}
-
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: template.FuncMap{
-			"ToLower": func(network edgeworkers.ActivationNetwork) string {
-				return strings.ToLower(string(network))
-			},
-			"Escape": tools.Escape,
-		},
-	}
 
-	edgeWorkerID, err := strconv.Atoi(c.Args().First())
+	edgeWorkerID, err := strconv.Atoi(e.Arg(0))
 	if err != nil {
 		return cli.Exit(color.RedString("edgeworker_id is not a valid integer"), 1)
 	}
-	section := edgegrid.GetEdgercSection(c)
-
-	if err = createEdgeWorker(ctx, edgeWorkerID, bundleDir, section, client, processor); err != nil {
+	if err = createEdgeWorker(ctx, edgeWorkerID, bundleDir, e.Section, client, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting edgeworker HCL: %s", err)), 1)
 	}
 	return nil
 }
 
 func createEdgeWorker(ctx context.Context, edgeWorkerID int, bundleDir, section string, client edgeworkers.Edgeworkers, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
-	fmt.Println("Configuring EdgeWorker")
-	term.Spinner().Start(fmt.Sprintf("Fetching EdgeWorker %d", edgeWorkerID), "")
+	reporter := progress.Get(ctx)
+	reporter.Writeln("Configuring EdgeWorker")
+	reporter.Start(fmt.Sprintf("Fetching EdgeWorker %d", edgeWorkerID), "")
 
 	edgeWorker, err := client.GetEdgeWorkerID(ctx, edgeworkers.GetEdgeWorkerIDRequest{
 		EdgeWorkerID: edgeWorkerID,
 	})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingEdgeWorker, err)
 	}
 
@@ -125,8 +123,8 @@ func createEdgeWorker(ctx context.Context, edgeWorkerID int, bundleDir, section
 	var activation *edgeworkers.Activation
 
 	localBundle := ""
+	var version string
 	if len(versions.EdgeWorkerVersions) != 0 {
-		var version string
 		var createdTime time.Time
 		for _, v := range versions.EdgeWorkerVersions {
 			parsedCreatedTime, err := time.Parse(time.RFC3339, v.CreatedTime)
@@ -145,10 +143,16 @@ func createEdgeWorker(ctx context.Context, edgeWorkerID int, bundleDir, section
 		}
 		localBundle, err = getEdgeWorkerBundle(ctx, edgeWorkerID, version, bundleDir, client)
 		if err != nil {
-			term.Spinner().Fail()
+			reporter.Fail()
 			return fmt.Errorf("%w: %s", ErrFetchingEdgeWorker, err)
 		}
 	}
+	templates.GetReport(ctx).AddObject(templates.ReportObject{
+		Type:    "edgeworker",
+		ID:      strconv.Itoa(edgeWorkerID),
+		Name:    edgeWorker.Name,
+		Version: version,
+	})
 
 	tfEdgeWorkerData := TFEdgeWorkerData{
 		EdgeWorkerID:   edgeWorkerID,
@@ -163,14 +167,14 @@ func createEdgeWorker(ctx context.Context, edgeWorkerID int, bundleDir, section
 		tfEdgeWorkerData.Note = activation.Note
 	}
 
-	term.Spinner().OK()
-	term.Spinner().Start("Saving TF configurations ", "")
+	reporter.OK()
+	reporter.Start("Saving TF configurations ", "")
 	if err := templateProcessor.ProcessTemplates(tfEdgeWorkerData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", templates.ErrSavingFiles, err)
 	}
-	term.Spinner().OK()
-	fmt.Printf("Terraform configuration for edgeworker '%s' with edgeworker_id '%d' was saved successfully\n", edgeWorker.Name, edgeWorkerID)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for edgeworker '%s' with edgeworker_id '%d' was saved successfully\n", edgeWorker.Name, edgeWorkerID)
 
 	return nil
 }
@@ -185,15 +189,12 @@ func getEdgeWorkerBundle(ctx context.Context, edgeWorkerID int, version, bundleP
 		return "", err
 	}
 
-	localBundle := filepath.Join(bundlePath, version+".tgz")
-	f, err := os.OpenFile(localBundle, os.O_CREATE|os.O_WRONLY, 0755)
+	content, err := io.ReadAll(bundleContent)
 	if err != nil {
 		return "", err
 	}
-	if _, err := io.Copy(f, bundleContent); err != nil {
-		return "", err
-	}
-	if err := f.Close(); err != nil {
+	localBundle := filepath.Join(bundlePath, version+".tgz")
+	if err := templates.GetOutput(ctx).WriteFile(localBundle, content); err != nil {
 		return "", err
 	}
 
@@ -254,3 +255,16 @@ func sortActivationsByDate(activations []edgeworkers.Activation) []edgeworkers.A
 	})
 	return activations
 }
+
+// completeEdgeWorkerIDs returns IDs of the EdgeWorkers available to the credentials
+func completeEdgeWorkerIDs(ctx context.Context, e *exporter.Export) ([]string, error) {
+	edgeWorkers, err := e.EdgeWorkersClient().ListEdgeWorkersID(ctx, edgeworkers.ListEdgeWorkersIDRequest{})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(edgeWorkers.EdgeWorkers))
+	for _, edgeWorker := range edgeWorkers.EdgeWorkers {
+		ids = append(ids, strconv.Itoa(edgeWorker.EdgeWorkerID))
+	}
+	return ids, nil
+}
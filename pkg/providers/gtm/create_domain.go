@@ -20,15 +20,13 @@ import (
 	"embed"
 	"errors"
 	"fmt"
-	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/gtm"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -78,91 +76,92 @@ type (
 	}
 )
 
+// providerVersion is the minimal version of the akamai provider required by generated configuration
+const providerVersion = "6.0.0"
+
 //go:embed templates/*
 var templateFiles embed.FS
 
 var defaultDCs = map[int]struct{}{5400: {}, 5401: {}, 5402: {}}
 
 var additionalFunctions = tools.DecorateWithMultilineHandlingFunctions(map[string]any{
-	"normalize":    normalizeResourceName,
+	"normalize":    tools.TerraformIdentifier,
 	"toUpper":      strings.ToUpper,
 	"isDefaultDC":  isDefaultDatacenter,
 	"escapeString": tools.EscapeQuotedStringLit,
 })
 
 var (
-	subWithUnderscoreRegexp               = regexp.MustCompile(`[^\w-_]`)
-	mustStartWithLetterOrUnderscoreRegexp = regexp.MustCompile("^[^a-zA-Z_]")
 	// ErrFetchingDomain is returned when fetching domain fails
 	ErrFetchingDomain = errors.New("unable to fetch domain with given name")
 )
 
-// CmdCreateDomain is an entrypoint to create-domain command
-func CmdCreateDomain(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(ctx)
-	client := gtm.Client(sess)
+// Exporter exports GTM domains, see export-domain command
+type Exporter struct{}
 
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
+// Spec describes export-domain command
+func (Exporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-domain",
+		Aliases:     []string{"create-domain"},
+		Description: "Generates Terraform configuration for Domain resources",
+		Args:        []string{"domain"},
+		Complete:    completeDomainNames,
+		Flags:       []cli.Flag{exporter.SecretsFlag()},
+		Templates: exporter.TemplateSet{
+			FS: templateFiles,
+			Files: map[string]string{
+				"datacenters.tmpl": "datacenters.tf",
+				"domain.tmpl":      "domain.tf",
+				"imports.tmpl":     "import.sh",
+				"maps.tmpl":        "maps.tf",
+				"properties.tmpl":  "properties.tf",
+				"resources.tmpl":   "resources.tf",
+				"variables.tmpl":   "variables.tf",
+			},
+			Imports:         "imports.tmpl",
+			Funcs:           additionalFunctions,
+			Data:            map[string]interface{}{"": TFDomainData{}},
+			ProviderVersion: providerVersion,
+		},
 	}
+}
 
-	datacentersPath := filepath.Join(tfWorkPath, "datacenters.tf")
-	domainPath := filepath.Join(tfWorkPath, "domain.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
-	mapsPath := filepath.Join(tfWorkPath, "maps.tf")
-	propertiesPath := filepath.Join(tfWorkPath, "properties.tf")
-	resourcesPath := filepath.Join(tfWorkPath, "resources.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-
-	templateToFile := map[string]string{
-		"datacenters.tmpl": datacentersPath,
-		"domain.tmpl":      domainPath,
-		"imports.tmpl":     importPath,
-		"maps.tmpl":        mapsPath,
-		"properties.tmpl":  propertiesPath,
-		"resources.tmpl":   resourcesPath,
-		"variables.tmpl":   variablesPath,
-	}
+// Export exports the domain with its datacenters, properties, resources and maps
+func (Exporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.GTMClient()
 
-	err := tools.CheckFiles(datacentersPath, domainPath, importPath, mapsPath, propertiesPath, resourcesPath, variablesPath)
-	if err != nil {
+	if err := e.Check(); err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
 
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: additionalFunctions,
-	}
-
-	domainName := c.Args().First()
-	section := edgegrid.GetEdgercSection(c)
-	if err := createDomain(ctx, client, domainName, section, processor); err != nil {
+	if err := createDomain(ctx, client, e.Arg(0), e.Section, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting domain HCL: %s", err)), 1)
 	}
 	return nil
 }
 
 func createDomain(ctx context.Context, client gtm.GTM, domainName, section string, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
+	reporter := progress.Get(ctx)
 
-	if _, err := term.Writeln("Configuring Domain"); err != nil {
+	if _, err := reporter.Writeln("Configuring Domain"); err != nil {
 		return err
 	}
 
-	term.Spinner().Start(fmt.Sprintf("Fetching domain %s", domainName))
+	reporter.Start(fmt.Sprintf("Fetching domain %s", domainName))
 	domain, err := client.GetDomain(ctx, domainName)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingDomain, err)
 	}
+	templates.GetReport(ctx).AddObject(templates.ReportObject{
+		Type: "gtm_domain",
+		ID:   domain.Name,
+	})
 
 	tfDomainData := TFDomainData{
 		Section:                     section,
-		NormalizedName:              normalizeResourceName(strings.TrimSuffix(domain.Name, ".akadns.net")),
+		NormalizedName:              tools.TerraformIdentifier(strings.TrimSuffix(domain.Name, ".akadns.net")),
 		Name:                        domain.Name,
 		Type:                        domain.Type,
 		Comment:                     domain.ModificationComments,
@@ -188,16 +187,16 @@ func createDomain(ctx context.Context, client gtm.GTM, domainName, section strin
 	}
 
 	tfDomainData.getDatacenters(domain)
-	term.Spinner().OK()
+	reporter.OK()
 
-	term.Spinner().Start("Saving TF configurations")
+	reporter.Start("Saving TF configurations")
 	if err := templateProcessor.ProcessTemplates(tfDomainData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
-	if _, err = term.Writeln(fmt.Sprintf("Terraform configuration for policy '%s' was saved successfully\n", domain.Name)); err != nil {
+	if _, err = reporter.Writeln(fmt.Sprintf("Terraform configuration for policy '%s' was saved successfully\n", domain.Name)); err != nil {
 		return err
 	}
 
@@ -229,22 +228,11 @@ func (d *TFDomainData) getDatacenters(domain *gtm.Domain) {
 	}
 }
 
-// normalizeResourceName is a utility function to normalize resource names.
-// A name must start with a letter or underscore and may contain only letters, digits, underscores, and dashes.
-func normalizeResourceName(key string) string {
-	key = subWithUnderscoreRegexp.ReplaceAllString(key, "_")
-
-	if mustStartWithLetterOrUnderscoreRegexp.MatchString(key) {
-		key = fmt.Sprintf("_%s", key)
-	}
-	return key
-}
-
 // FindDatacenterResourceName finds and returns datacenter resource name with given id
 func (d TFDomainData) FindDatacenterResourceName(id int) (string, error) {
 	for _, dc := range d.Datacenters {
 		if dc.ID == id {
-			return normalizeResourceName(dc.Nickname), nil
+			return tools.TerraformIdentifier(dc.Nickname), nil
 		}
 	}
 	return "", fmt.Errorf("cannot find datacenter resource with ID: %d", id)
@@ -254,3 +242,16 @@ func isDefaultDatacenter(id int) bool {
 	_, ok := defaultDCs[id]
 	return ok
 }
+
+// completeDomainNames returns names of the GTM domains available to the credentials
+func completeDomainNames(ctx context.Context, e *exporter.Export) ([]string, error) {
+	domains, err := e.GTMClient().ListDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		names = append(names, domain.Name)
+	}
+	return names, nil
+}
@@ -7,10 +7,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/iam"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
+	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/urfave/cli/v2"
 )
 
@@ -69,6 +72,9 @@ type (
 	}
 )
 
+// providerVersion is the minimal version of the akamai provider required by generated configuration
+const providerVersion = "2.0.0"
+
 var (
 	//go:embed templates/*
 	templateFiles embed.FS
@@ -79,22 +85,68 @@ var (
 	ErrFetchingUsers = errors.New("unable to fetch users under this account")
 )
 
-// CmdCreateIAM is an entrypoint to create-iam command. This is only for action validation purpose
-func CmdCreateIAM(_ *cli.Context) error {
+// Exporter exports IAM users, groups and roles using one of its subcommands, see export-iam command
+type Exporter struct{}
+
+// Spec describes export-iam command
+func (Exporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-iam",
+		Aliases:     []string{"create-iam"},
+		Description: "Generates Terraform configuration for Identity and Access Management resources",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Maximum number of users fetched at the same time",
+				Value: 4,
+			},
+		},
+		Subcommands: []exporter.Exporter{AllExporter{}, GroupExporter{}, RoleExporter{}, UserExporter{}},
+		Templates:   templateSet(nil),
+	}
+}
+
+// Export does nothing, objects are exported by the subcommands
+func (Exporter) Export(_ context.Context, _ *exporter.Export) error {
 	return nil
 }
 
-func getTFUsers(ctx context.Context, client iam.IAM, users []iam.UserListItem, term terminal.Terminal) ([]*TFUser, error) {
-	res := make([]*TFUser, 0)
-	for _, v := range users {
-		user, err := client.GetUser(ctx, iam.GetUserRequest{
-			IdentityID:    v.IdentityID,
+// templateSet returns templates of the package generating the given files
+func templateSet(files map[string]string) exporter.TemplateSet {
+	return exporter.TemplateSet{
+		FS:              templateFiles,
+		Files:           files,
+		Imports:         "imports.tmpl",
+		Funcs:           additionalFunctions,
+		Data:            map[string]interface{}{"": TFData{}},
+		ProviderVersion: providerVersion,
+	}
+}
+
+func getTFUsers(ctx context.Context, client iam.IAM, users []iam.UserListItem, reporter progress.Reporter) ([]*TFUser, error) {
+	// users are fetched using at most tools.Concurrency(ctx) requests at the same time
+	// and processed in the original order once all of them are fetched
+	fetched := make([]*iam.User, len(users))
+	errs := make([]error, len(users))
+	err := tools.ForEach(ctx, len(users), func(ctx context.Context, i int) error {
+		fetched[i], errs[i] = client.GetUser(ctx, iam.GetUserRequest{
+			IdentityID:    users[i].IdentityID,
 			Actions:       true,
 			AuthGrants:    true,
 			Notifications: true,
 		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*TFUser, 0)
+	for i, v := range users {
+		user, err := fetched[i], errs[i]
 		if err != nil {
-			_, err := term.Writeln(fmt.Sprintf("[WARN] Unable to fetch user of ID '%s' - skipping:\n%s", v.IdentityID, err))
+			templates.GetReport(ctx).AddWarning("unable to fetch user of ID '%s', skipped: %s", v.IdentityID, err)
+			_, err := reporter.Writeln(fmt.Sprintf("[WARN] Unable to fetch user of ID '%s' - skipping:\n%s", v.IdentityID, err))
 			if err != nil {
 				return nil, err
 			}
@@ -202,3 +254,16 @@ func getGrantedRolesID(grantedRoles []iam.RoleGrantedRole) []int {
 	}
 	return rolesIDs
 }
+
+// addToReport records exported users, roles and groups
+func (d TFData) addToReport(report *templates.Report) {
+	for _, user := range d.TFUsers {
+		report.AddObject(templates.ReportObject{Type: "iam_user", ID: user.ID, Name: user.Email})
+	}
+	for _, role := range d.TFRoles {
+		report.AddObject(templates.ReportObject{Type: "iam_role", ID: strconv.FormatInt(role.RoleID, 10), Name: role.RoleName})
+	}
+	for _, group := range d.TFGroups {
+		report.AddObject(templates.ReportObject{Type: "iam_group", ID: strconv.Itoa(group.GroupID), Name: group.GroupName})
+	}
+}
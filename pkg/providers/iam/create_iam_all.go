@@ -4,13 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"path/filepath"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/iam"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -22,75 +21,63 @@ var (
 	ErrFetchingRoles = errors.New("unable to fetch roles under this account")
 )
 
-// CmdCreateIAMAll is an entrypoint to create-iam all command
-func CmdCreateIAMAll(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(ctx)
-	client := iam.Client(sess)
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
+// AllExporter exports all users, groups and roles, see export-iam all command
+type AllExporter struct{}
+
+// Spec describes export-iam all command
+func (AllExporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "all",
+		Description: "Exports all available Terraform Users, Groups and Roles",
+		Templates: templateSet(map[string]string{
+			"groups.tmpl":    "groups.tf",
+			"imports.tmpl":   "import.sh",
+			"roles.tmpl":     "roles.tf",
+			"users.tmpl":     "users.tf",
+			"variables.tmpl": "variables.tf",
+		}),
 	}
-	tfWorkPath = filepath.FromSlash(tfWorkPath)
+}
 
-	groupsPath := filepath.Join(tfWorkPath, "groups.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
-	rolesPath := filepath.Join(tfWorkPath, "roles.tf")
-	usersPath := filepath.Join(tfWorkPath, "users.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
+// Export exports all users, groups and roles
+func (AllExporter) Export(ctx context.Context, e *exporter.Export) error {
+	ctx = tools.WithConcurrency(ctx, e.Flags.Int("concurrency"))
+	client := e.IAMClient()
 
-	err := tools.CheckFiles(groupsPath, importPath, rolesPath, usersPath, variablesPath)
-	if err != nil {
+	if err := e.Check(); err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
 
-	templateToFile := map[string]string{
-		"groups.tmpl":    groupsPath,
-		"imports.tmpl":   importPath,
-		"roles.tmpl":     rolesPath,
-		"users.tmpl":     usersPath,
-		"variables.tmpl": variablesPath,
-	}
-
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: additionalFunctions,
-	}
-
-	section := edgegrid.GetEdgercSection(c)
-
-	if err := createIAMAll(ctx, section, client, processor); err != nil {
+	if err := createIAMAll(ctx, e.Section, client, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting HCL for IAM: %s", err)), 1)
 	}
 	return nil
 }
 
 func createIAMAll(ctx context.Context, section string, client iam.IAM, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
-	_, err := term.Writeln("Exporting all accessible Identity and Access Management configuration")
+	reporter := progress.Get(ctx)
+	_, err := reporter.Writeln("Exporting all accessible Identity and Access Management configuration")
 	if err != nil {
 		return err
 	}
 
-	term.Spinner().Start("Fetching all available users")
+	reporter.Start("Fetching all available users")
 	users, err := client.ListUsers(ctx, iam.ListUsersRequest{Actions: true})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingUsers, err)
 	}
-	tfUsers, err := getTFUsers(ctx, client, filterUsers(users), term)
+	tfUsers, err := getTFUsers(ctx, client, filterUsers(users), reporter)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
-	term.Spinner().Start("Fetching all available groups")
+	reporter.Start("Fetching all available groups")
 	groups, err := client.ListGroups(ctx, iam.ListGroupsRequest{Actions: true})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingGroups, err)
 	}
 	tfGroups := make([]TFGroup, 0)
@@ -99,24 +86,24 @@ func createIAMAll(ctx context.Context, section string, client iam.IAM, templateP
 			tfGroups = append(tfGroups, getTFGroup(&innerGroup))
 		}
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
-	term.Spinner().Start("Fetching all available roles")
+	reporter.Start("Fetching all available roles")
 	roles, err := client.ListRoles(ctx, iam.ListRolesRequest{
 		Actions:       true,
 		IgnoreContext: true,
 		Users:         true,
 	})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingRoles, err)
 	}
 	tfRoles, err := getTFRoles(ctx, client, roles)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingRoles, err)
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
 	tfData := TFData{
 		TFUsers:    tfUsers,
@@ -126,14 +113,15 @@ func createIAMAll(ctx context.Context, section string, client iam.IAM, templateP
 		Subcommand: "all",
 	}
 
-	term.Spinner().Start("Saving TF configurations ")
+	tfData.addToReport(templates.GetReport(ctx))
+	reporter.Start("Saving TF configurations ")
 	if err = templateProcessor.ProcessTemplates(tfData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
-	_, err = term.Writeln("Terraform configuration was saved successfully")
+	_, err = reporter.Writeln("Terraform configuration was saved successfully")
 	if err != nil {
 		return nil
 	}
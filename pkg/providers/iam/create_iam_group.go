@@ -4,14 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"strconv"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/iam"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -23,88 +22,78 @@ var (
 	ErrFetchingRolesWithinGroup = errors.New("unable to fetch roles within group")
 )
 
-// CmdCreateIAMGroup is an entrypoint to create-iam group command
-func CmdCreateIAMGroup(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(ctx)
-	client := iam.Client(sess)
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
+// GroupExporter exports a group with its users and roles, see export-iam group command
+type GroupExporter struct{}
+
+// Spec describes export-iam group command
+func (GroupExporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "group",
+		Description: "Exports Terraform Group resource with relevant users and roles resources",
+		Args:        []string{"group_id"},
+		Templates: templateSet(map[string]string{
+			"groups.tmpl":    "group.tf",
+			"imports.tmpl":   "import.sh",
+			"roles.tmpl":     "roles.tf",
+			"users.tmpl":     "users.tf",
+			"variables.tmpl": "variables.tf",
+		}),
 	}
-	tfWorkPath = filepath.FromSlash(tfWorkPath)
+}
 
-	groupPath := filepath.Join(tfWorkPath, "group.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
-	rolesPath := filepath.Join(tfWorkPath, "roles.tf")
-	usersPath := filepath.Join(tfWorkPath, "users.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
+// Export exports the group with its users and roles
+func (GroupExporter) Export(ctx context.Context, e *exporter.Export) error {
+	ctx = tools.WithConcurrency(ctx, e.Flags.Int("concurrency"))
+	client := e.IAMClient()
 
-	err := tools.CheckFiles(groupPath, usersPath, rolesPath, variablesPath, importPath)
-	if err != nil {
+	if err := e.Check(); err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
 
-	templateToFile := map[string]string{
-		"groups.tmpl":    groupPath,
-		"imports.tmpl":   importPath,
-		"roles.tmpl":     rolesPath,
-		"users.tmpl":     usersPath,
-		"variables.tmpl": variablesPath,
-	}
-
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: additionalFunctions,
-	}
-
-	section := edgegrid.GetEdgercSection(c)
-	groupID, err := strconv.ParseInt(c.Args().First(), 10, 64)
+	groupID, err := strconv.ParseInt(e.Arg(0), 10, 64)
 	if err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Wrong format of group id %v must be a number: %s", groupID, err)), 1)
 	}
-	if err = createIAMGroupByID(ctx, groupID, section, client, processor); err != nil {
+	if err = createIAMGroupByID(ctx, groupID, e.Section, client, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting HCL for IAM: %s", err)), 1)
 	}
 	return nil
 }
 
 func createIAMGroupByID(ctx context.Context, groupID int64, section string, client iam.IAM, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
-	_, err := term.Writeln("Exporting Identity and Access Management group configuration with related users and groups")
+	reporter := progress.Get(ctx)
+	_, err := reporter.Writeln("Exporting Identity and Access Management group configuration with related users and groups")
 	if err != nil {
 		return err
 	}
 
-	term.Spinner().Start("Fetching group by id " + strconv.FormatInt(groupID, 10))
+	reporter.Start("Fetching group by id " + strconv.FormatInt(groupID, 10))
 	group, err := client.GetGroup(ctx, iam.GetGroupRequest{
 		GroupID: groupID,
 	})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("could not get group with ID '%v': %w", groupID, err)
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
 	tfGroup := getTFGroup(group)
 
-	term.Spinner().Start("Fetching users within group with id " + strconv.FormatInt(groupID, 10))
-	tfUsers, err := getUsersWithinGroup(ctx, client, groupID, term)
+	reporter.Start("Fetching users within group with id " + strconv.FormatInt(groupID, 10))
+	tfUsers, err := getUsersWithinGroup(ctx, client, groupID, reporter)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
-	term.Spinner().Start("Fetching user's relative roles within group " + strconv.FormatInt(groupID, 10))
+	reporter.Start("Fetching user's relative roles within group " + strconv.FormatInt(groupID, 10))
 	tfRoles, err := getRolesWithinGroup(ctx, client, groupID)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
 	tfData := TFData{
 		TFUsers: tfUsers,
@@ -116,13 +105,14 @@ func createIAMGroupByID(ctx context.Context, groupID int64, section string, clie
 		Subcommand: "group",
 	}
 
-	term.Spinner().Start("Saving TF configurations ")
+	tfData.addToReport(templates.GetReport(ctx))
+	reporter.Start("Saving TF configurations ")
 	if err = templateProcessor.ProcessTemplates(tfData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
-	_, err = term.Writeln(fmt.Sprintf("Terraform configuration for group with id '%v' was saved successfully", groupID))
+	reporter.OK()
+	_, err = reporter.Writeln(fmt.Sprintf("Terraform configuration for group with id '%v' was saved successfully", groupID))
 	if err != nil {
 		return nil
 	}
@@ -130,7 +120,7 @@ func createIAMGroupByID(ctx context.Context, groupID int64, section string, clie
 	return nil
 }
 
-func getUsersWithinGroup(ctx context.Context, client iam.IAM, groupID int64, term terminal.Terminal) ([]*TFUser, error) {
+func getUsersWithinGroup(ctx context.Context, client iam.IAM, groupID int64, reporter progress.Reporter) ([]*TFUser, error) {
 	users, err := client.ListUsers(ctx, iam.ListUsersRequest{
 		Actions: true,
 		GroupID: tools.Int64Ptr(groupID),
@@ -139,7 +129,7 @@ func getUsersWithinGroup(ctx context.Context, client iam.IAM, groupID int64, ter
 		return nil, fmt.Errorf("%w: %v with error %s", ErrFetchingUsersWithinGroup, groupID, err)
 	}
 
-	return getTFUsers(ctx, client, filterUsers(users), term)
+	return getTFUsers(ctx, client, filterUsers(users), reporter)
 }
 
 func getRolesWithinGroup(ctx context.Context, client iam.IAM, groupID int64) ([]TFRole, error) {
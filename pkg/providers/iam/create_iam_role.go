@@ -4,14 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"strconv"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/iam"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
-	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -21,62 +19,50 @@ var (
 	ErrFetchingRole = errors.New("unable to fetch role by role_id")
 )
 
-// CmdCreateIAMRole is an entrypoint to create-iam role command
-func CmdCreateIAMRole(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(ctx)
-	client := iam.Client(sess)
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
+// RoleExporter exports a role with its users and groups, see export-iam role command
+type RoleExporter struct{}
+
+// Spec describes export-iam role command
+func (RoleExporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "role",
+		Description: "Exports Terraform Role resource with relevant users and groups resources",
+		Args:        []string{"role_id"},
+		Templates: templateSet(map[string]string{
+			"groups.tmpl":    "groups.tf",
+			"imports.tmpl":   "import.sh",
+			"roles.tmpl":     "role.tf",
+			"users.tmpl":     "users.tf",
+			"variables.tmpl": "variables.tf",
+		}),
 	}
-	tfWorkPath = filepath.FromSlash(tfWorkPath)
+}
 
-	groupPath := filepath.Join(tfWorkPath, "groups.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
-	rolesPath := filepath.Join(tfWorkPath, "role.tf")
-	userPath := filepath.Join(tfWorkPath, "users.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
+// Export exports the role with its users and groups
+func (RoleExporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.IAMClient()
 
-	err := tools.CheckFiles(userPath, groupPath, rolesPath, variablesPath, importPath)
-	if err != nil {
+	if err := e.Check(); err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
 
-	templateToFile := map[string]string{
-		"groups.tmpl":    groupPath,
-		"imports.tmpl":   importPath,
-		"roles.tmpl":     rolesPath,
-		"users.tmpl":     userPath,
-		"variables.tmpl": variablesPath,
-	}
-
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: additionalFunctions,
-	}
-
-	section := edgegrid.GetEdgercSection(c)
-	roleID, err := strconv.ParseInt(c.Args().First(), 10, 64)
+	roleID, err := strconv.ParseInt(e.Arg(0), 10, 64)
 	if err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Wrong format of role id %v must be a number: %s", roleID, err)), 1)
 	}
-
-	if err = createIAMRoleByID(ctx, roleID, section, client, processor); err != nil {
+	if err = createIAMRoleByID(ctx, roleID, e.Section, client, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting HCL for IAM: %s", err)), 1)
 	}
 	return nil
 }
 
 func createIAMRoleByID(ctx context.Context, roleID int64, section string, client iam.IAM, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
-	_, err := term.Writeln("Exporting Identity and Access Management role configuration with related users and groups")
+	reporter := progress.Get(ctx)
+	_, err := reporter.Writeln("Exporting Identity and Access Management role configuration with related users and groups")
 	if err != nil {
 		return err
 	}
-	term.Spinner().Start(fmt.Sprintf("Fetching role by role_id %d", roleID))
+	reporter.Start(fmt.Sprintf("Fetching role by role_id %d", roleID))
 
 	role, err := client.GetRole(ctx, iam.GetRoleRequest{
 		ID:           roleID,
@@ -84,10 +70,10 @@ func createIAMRoleByID(ctx context.Context, roleID int64, section string, client
 		Users:        true,
 	})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: could not fetch role with roleID '%v': %s", ErrFetchingRole, roleID, err)
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
 	tfRole := TFRole{
 		RoleID:          role.RoleID,
@@ -96,22 +82,22 @@ func createIAMRoleByID(ctx context.Context, roleID int64, section string, client
 		GrantedRoles:    getGrantedRolesID(role.GrantedRoles),
 	}
 
-	term.Spinner().Start(fmt.Sprintf("Fetching users with the given role %d", roleID))
-	users, err := getUsersByRole(ctx, term, role.Users, client)
+	reporter.Start(fmt.Sprintf("Fetching users with the given role %d", roleID))
+	users, err := getUsersByRole(ctx, reporter, role.Users, client)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
 	tfUsers := make([]*TFUser, 0)
 	tfGroups := make([]TFGroup, 0)
 
-	term.Spinner().Start(fmt.Sprintf("Fetching groups for users related within role %d", roleID))
+	reporter.Start(fmt.Sprintf("Fetching groups for users related within role %d", roleID))
 	for _, user := range users {
 		userData, err := getTFUser(user)
 		if err != nil {
-			term.Spinner().Fail()
+			reporter.Fail()
 			return err
 		}
 
@@ -121,14 +107,14 @@ func createIAMRoleByID(ctx context.Context, roleID int64, section string, client
 		if len(authGrantsList) > 0 {
 			groupsData, err := getTFUserGroups(ctx, client, authGrantsList)
 			if err != nil {
-				term.Spinner().Fail()
+				reporter.Fail()
 				return err
 			}
 
 			tfGroups = appendUniqueGroups(tfGroups, groupsData)
 		}
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
 	tfData := TFData{
 		TFUsers:  tfUsers,
@@ -141,13 +127,14 @@ func createIAMRoleByID(ctx context.Context, roleID int64, section string, client
 		Subcommand: "role",
 	}
 
-	term.Spinner().Start("Saving TF configurations ")
+	tfData.addToReport(templates.GetReport(ctx))
+	reporter.Start("Saving TF configurations ")
 	if err = templateProcessor.ProcessTemplates(tfData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
-	_, err = term.Writeln(fmt.Sprintf("Terraform configuration for role with id '%d' was saved successfully", tfRole.RoleID))
+	reporter.OK()
+	_, err = reporter.Writeln(fmt.Sprintf("Terraform configuration for role with id '%d' was saved successfully", tfRole.RoleID))
 	if err != nil {
 		return nil
 	}
@@ -172,7 +159,7 @@ func appendUniqueGroups(tfGroups []TFGroup, groupsData []TFGroup) []TFGroup {
 	return tfGroups
 }
 
-func getUsersByRole(ctx context.Context, term terminal.Terminal, roleUsers []iam.RoleUser, client iam.IAM) ([]*iam.User, error) {
+func getUsersByRole(ctx context.Context, reporter progress.Reporter, roleUsers []iam.RoleUser, client iam.IAM) ([]*iam.User, error) {
 	users := make([]*iam.User, 0)
 
 	for _, roleUser := range roleUsers {
@@ -183,7 +170,8 @@ func getUsersByRole(ctx context.Context, term terminal.Terminal, roleUsers []iam
 			Notifications: true,
 		})
 		if err != nil {
-			_, err := term.Writeln(fmt.Sprintf("[WARN] Unable to fetch user of ID '%s' - skipping:\n%s", roleUser.UIIdentityID, err))
+			templates.GetReport(ctx).AddWarning("unable to fetch user of ID '%s', skipped: %s", roleUser.UIIdentityID, err)
+			_, err := reporter.Writeln(fmt.Sprintf("[WARN] Unable to fetch user of ID '%s' - skipping:\n%s", roleUser.UIIdentityID, err))
 			if err != nil {
 				return nil, err
 			}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/iam"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
 	"github.com/akamai/cli/pkg/terminal"
@@ -346,7 +347,7 @@ func TestGetUsersByRole(t *testing.T) {
 			term := terminal.Mock{}
 			test.init(&client, &term)
 
-			result, err := getUsersByRole(context.Background(), &term, test.roleUsers, &client)
+			result, err := getUsersByRole(context.Background(), progress.New(progress.ModeSpinner, &term, nil, progress.Options{}), test.roleUsers, &client)
 			if test.withError != nil {
 				require.Error(t, err)
 				assert.True(t, errors.Is(err, test.withError))
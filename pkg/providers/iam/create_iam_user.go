@@ -4,13 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"path/filepath"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/iam"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
-	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -24,69 +22,57 @@ var (
 	ErrMarshalUserAuthGrants = errors.New("unable to marshal AuthGrants ")
 )
 
-// CmdCreateIAMUser is an entrypoint to create-iam user command
-func CmdCreateIAMUser(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(ctx)
-	client := iam.Client(sess)
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
-	}
-	tfWorkPath = filepath.FromSlash(tfWorkPath)
-
-	groupPath := filepath.Join(tfWorkPath, "groups.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
-	rolesPath := filepath.Join(tfWorkPath, "roles.tf")
-	userPath := filepath.Join(tfWorkPath, "user.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-
-	err := tools.CheckFiles(userPath, groupPath, rolesPath, variablesPath, importPath)
-	if err != nil {
-		return cli.Exit(color.RedString(err.Error()), 1)
+// UserExporter exports a user with its groups and roles, see export-iam user command
+type UserExporter struct{}
+
+// Spec describes export-iam user command
+func (UserExporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "user",
+		Description: "Exports Terraform User resource with relevant groups and roles resources",
+		Args:        []string{"user_email"},
+		Templates: templateSet(map[string]string{
+			"groups.tmpl":    "groups.tf",
+			"imports.tmpl":   "import.sh",
+			"roles.tmpl":     "roles.tf",
+			"users.tmpl":     "user.tf",
+			"variables.tmpl": "variables.tf",
+		}),
 	}
+}
 
-	templateToFile := map[string]string{
-		"groups.tmpl":    groupPath,
-		"imports.tmpl":   importPath,
-		"roles.tmpl":     rolesPath,
-		"users.tmpl":     userPath,
-		"variables.tmpl": variablesPath,
-	}
+// Export exports the user with its groups and roles
+func (UserExporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.IAMClient()
 
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: additionalFunctions,
+	if err := e.Check(); err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
 	}
 
-	section := edgegrid.GetEdgercSection(c)
-	email := c.Args().First()
-	if err = createIAMUserByEmail(ctx, email, section, client, processor); err != nil {
+	if err := createIAMUserByEmail(ctx, e.Arg(0), e.Section, client, e.Processor()); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting HCL for IAM: %s", err)), 1)
 	}
 	return nil
 }
 
 func createIAMUserByEmail(ctx context.Context, userEmail, section string, client iam.IAM, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
-	_, err := term.Writeln("Exporting Identity and Access Management user configuration with relevant roles and groups")
+	reporter := progress.Get(ctx)
+	_, err := reporter.Writeln("Exporting Identity and Access Management user configuration with relevant roles and groups")
 	if err != nil {
 		return err
 	}
-	term.Spinner().Start("Fetching user by email " + userEmail)
+	reporter.Start("Fetching user by email " + userEmail)
 
 	user, err := getUserByEmail(ctx, client, userEmail)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
 	tfUserData, err := getTFUser(user)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
 
@@ -101,30 +87,31 @@ func createIAMUserByEmail(ctx context.Context, userEmail, section string, client
 	}
 
 	if len(authGrantsList) > 0 {
-		term.Spinner().Start("Fetching roles for user " + userEmail)
+		reporter.Start("Fetching roles for user " + userEmail)
 		tfData.TFRoles, err = getTFUserRoles(ctx, client, authGrantsList)
 		if err != nil {
-			term.Spinner().Fail()
+			reporter.Fail()
 			return err
 		}
-		term.Spinner().OK()
+		reporter.OK()
 
-		term.Spinner().Start("Fetching groups for user " + userEmail)
+		reporter.Start("Fetching groups for user " + userEmail)
 		tfData.TFGroups, err = getTFUserGroups(ctx, client, authGrantsList)
 		if err != nil {
-			term.Spinner().Fail()
+			reporter.Fail()
 			return err
 		}
-		term.Spinner().OK()
+		reporter.OK()
 	}
 
-	term.Spinner().Start("Saving TF configurations ")
+	tfData.addToReport(templates.GetReport(ctx))
+	reporter.Start("Saving TF configurations ")
 	if err = templateProcessor.ProcessTemplates(tfData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
-	_, err = term.Writeln(fmt.Sprintf("Terraform configuration for user with email '%s' was saved successfully", tfUserData.Email))
+	reporter.OK()
+	_, err = reporter.Writeln(fmt.Sprintf("Terraform configuration for user with email '%s' was saved successfully", tfUserData.Email))
 	if err != nil {
 		return nil
 	}
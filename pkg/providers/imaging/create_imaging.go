@@ -7,10 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
-	"os"
-	"path"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -18,10 +15,9 @@ import (
 	"text/template"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/imaging"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
-	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -52,6 +48,9 @@ type (
 	}
 )
 
+// providerVersion is the minimal version of the akamai provider required by generated configuration
+const providerVersion = "6.1.0"
+
 //go:embed templates/*
 var templateFiles embed.FS
 
@@ -69,85 +68,95 @@ var (
 // maxDepth value has to match the MaxPolicyDepth value in terraform imaging subprovider
 const maxDepth = 7
 
-// CmdCreateImaging is an entrypoint to create-imaging command
-func CmdCreateImaging(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(ctx)
-	client := imaging.Client(sess)
-
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
+// Exporter exports image and video policy sets, see export-imaging command
+type Exporter struct{}
+
+// Spec describes export-imaging command
+func (Exporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-imaging",
+		Aliases:     []string{"create-imaging"},
+		Description: "Generates Terraform configuration for Image and Video Manager resources",
+		Args:        []string{"contract_id", "policy_set_id"},
+		Complete:    exporter.CompleteArgs(exporter.CompleteContractIDs, completePolicySetIDs),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "policy-json-dir",
+				Usage: "Path location for placement of policy jsons. Default: same value as tfworkpath",
+			},
+			&cli.BoolFlag{
+				Name:    "policy-as-hcl",
+				Aliases: []string{"schema"},
+				Usage:   "Generate content of the policy using HCL instead of JSON file",
+			},
+		},
+		Templates: exporter.TemplateSet{
+			FS: templateFiles,
+			Files: map[string]string{
+				"imaging.tmpl":   "imaging.tf",
+				"variables.tmpl": "variables.tf",
+				"imports.tmpl":   "import.sh",
+			},
+			Imports: "imports.tmpl",
+			Funcs: template.FuncMap{
+				"ToLower": func(val string) string {
+					return strings.ToLower(val)
+				},
+				"RemoveSymbols": func(val string) string {
+					return RemoveSymbols.ReplaceAllString(val, "_")
+				},
+			},
+			Data:            map[string]interface{}{"": TFImagingData{}},
+			ProviderVersion: providerVersion,
+		},
 	}
-	imagingPath := filepath.Join(tfWorkPath, "imaging.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
+}
 
-	err := tools.CheckFiles(imagingPath, variablesPath, importPath)
-	if err != nil {
+// Export exports the policy set with its policies
+func (Exporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.ImagingClient()
+
+	if err := e.Check(); err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
 
 	jsonDir := "."
-	if c.IsSet("policy-json-dir") {
-		jsonDir = c.String("policy-json-dir")
-	}
-	if !c.IsSet("policies-as-hcl") {
-		jsonDirPath := path.Join(tfWorkPath, jsonDir)
-		err = ensureDirExists(jsonDirPath)
-		if err != nil {
-			return cli.Exit(color.RedString(err.Error()), 1)
-		}
+	if e.Flags.IsSet("policy-json-dir") {
+		jsonDir = e.Flags.String("policy-json-dir")
 	}
 
-	templateToFile := map[string]string{
-		"imaging.tmpl":   imagingPath,
-		"variables.tmpl": variablesPath,
-		"imports.tmpl":   importPath,
-	}
-
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: template.FuncMap{
-			"ToLower": func(val string) string {
-				return strings.ToLower(val)
-			},
-			"RemoveSymbols": func(val string) string {
-				return RemoveSymbols.ReplaceAllString(val, "_")
-			},
-		},
-	}
-
-	contractID, policySetID := c.Args().Get(0), c.Args().Get(1)
-	section := edgegrid.GetEdgercSection(c)
-	if err = createImaging(ctx, contractID, policySetID, tfWorkPath, jsonDir, section, client, processor, tools.PolicyAsHCL); err != nil {
+	contractID, policySetID := e.Arg(0), e.Arg(1)
+	if err := createImaging(ctx, contractID, policySetID, e.WorkPath, jsonDir, e.Section, client, e.Processor(), e.Flags.Bool("policy-as-hcl")); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting policy HCL: %s", err)), 1)
 	}
 	return nil
 }
 
 func createImaging(ctx context.Context, contractID, policySetID, tfWorkPath, jsonDir, section string, client imaging.Imaging, templateProcessor templates.TemplateProcessor, policyAsHCL bool) error {
-	term := terminal.Get(ctx)
+	reporter := progress.Get(ctx)
 
-	fmt.Println("Exporting Image and Video Manager configuration")
-	term.Spinner().Start("Fetching policy set " + policySetID)
+	reporter.Writeln("Exporting Image and Video Manager configuration")
+	reporter.Start("Fetching policy set " + policySetID)
 
 	policySet, err := client.GetPolicySet(ctx, imaging.GetPolicySetRequest{
 		PolicySetID: policySetID,
 		ContractID:  contractID,
 	})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingPolicySet, err)
 	}
-	term.Spinner().OK()
+	templates.GetReport(ctx).AddObject(templates.ReportObject{
+		Type: "imaging_policy_set",
+		ID:   policySet.ID,
+		Name: policySet.Name,
+	})
+	reporter.OK()
 
-	term.Spinner().Start("Fetching policies for the given policy set " + policySetID)
+	reporter.Start("Fetching policies for the given policy set " + policySetID)
 	policies, err := getPolicies(ctx, policySetID, contractID, client)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingPolicy, err)
 	}
 
@@ -159,11 +168,11 @@ func createImaging(ctx context.Context, contractID, policySetID, tfWorkPath, jso
 		tfPoliciesData, err = getPoliciesVideoData(ctx, policies, policySetID, contractID, tfWorkPath, jsonDir, client, policyAsHCL)
 	}
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingPolicy, err)
 	}
 
-	term.Spinner().OK()
+	reporter.OK()
 
 	tfData := TFImagingData{
 		PolicySet: TFPolicySet{
@@ -181,27 +190,17 @@ func createImaging(ctx context.Context, contractID, policySetID, tfWorkPath, jso
 		tfData.Policies = tfPoliciesData
 	}
 
-	term.Spinner().Start("Saving TF configurations ")
+	reporter.Start("Saving TF configurations ")
 	if err := templateProcessor.ProcessTemplates(tfData); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return err
 	}
-	term.Spinner().OK()
-	fmt.Printf("Terraform configuration for policy set '%s' was saved successfully\n", policySet.ID)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for policy set '%s' was saved successfully\n", policySet.ID)
 
 	return nil
 }
 
-func ensureDirExists(dirPath string) error {
-	dirPath = filepath.FromSlash(dirPath)
-
-	err := os.MkdirAll(dirPath, 0755)
-	if err != nil {
-		return fmt.Errorf("%w: %s", ErrCreateDir, err)
-	}
-	return nil
-}
-
 func getPolicies(ctx context.Context, policySetID, contractID string, client imaging.Imaging) ([]imaging.PolicyOutput, error) {
 	stagingPolicies, err := client.ListPolicies(ctx, imaging.ListPoliciesRequest{
 		Network:     imaging.PolicyNetworkStaging,
@@ -273,7 +272,7 @@ func getPoliciesImageData(ctx context.Context, policies []imaging.PolicyOutput,
 			})
 		} else {
 			jsonPath := filepath.Join(jsonDir, RemoveSymbols.ReplaceAllString(policy.ID, "_")+".json")
-			err = ioutil.WriteFile(filepath.Join(tfWorkPath, jsonPath), []byte(policyJSON), 0644)
+			err = templates.GetOutput(ctx).WriteFile(filepath.Join(tfWorkPath, jsonPath), []byte(policyJSON))
 			if err != nil {
 				return nil, err
 			}
@@ -344,7 +343,7 @@ func getPoliciesVideoData(ctx context.Context, policies []imaging.PolicyOutput,
 			})
 		} else {
 			jsonPath := filepath.Join(jsonDir, RemoveSymbols.ReplaceAllString(policy.ID, "_")+".json")
-			err = ioutil.WriteFile(filepath.Join(tfWorkPath, jsonPath), []byte(policyJSON), 0644)
+			err = templates.GetOutput(ctx).WriteFile(filepath.Join(tfWorkPath, jsonPath), []byte(policyJSON))
 			if err != nil {
 				return nil, err
 			}
@@ -475,3 +474,19 @@ func getDepth(policy interface{}, depth int) int {
 	}
 	return newDepth
 }
+
+// completePolicySetIDs returns IDs of the policy sets of the contract given by the first argument
+func completePolicySetIDs(ctx context.Context, e *exporter.Export) ([]string, error) {
+	policySets, err := e.ImagingClient().ListPolicySets(ctx, imaging.ListPolicySetsRequest{
+		ContractID: e.Arg(0),
+		Network:    imaging.NetworkBoth,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(policySets))
+	for _, policySet := range policySets {
+		ids = append(ids, policySet.ID)
+	}
+	return ids, nil
+}
@@ -8,7 +8,6 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
-	"path"
 	"strings"
 	"testing"
 	"text/template"
@@ -1186,64 +1185,3 @@ func convertPolicyInputImage(policy imaging.PolicyInput) (*imaging.PolicyOutputI
 	}
 	return &policyInput, nil
 }
-
-func TestEnsureDirExists(t *testing.T) {
-	t.Run("no json dir specified", func(t *testing.T) {
-		tfDir, err := ioutil.TempDir("", "tfworkpath")
-		assert.NoError(t, err)
-		defer func() { assert.NoError(t, os.RemoveAll(tfDir)) }()
-		jsonDirPath := path.Join(tfDir, ".")
-
-		err = ensureDirExists(jsonDirPath)
-
-		assert.NoError(t, err)
-		assert.DirExists(t, jsonDirPath)
-	})
-
-	t.Run("json dir already exists", func(t *testing.T) {
-		tfDir, err := ioutil.TempDir("", "tfworkpath")
-		assert.NoError(t, err)
-		defer func() { assert.NoError(t, os.RemoveAll(tfDir)) }()
-		jsonDirPath := path.Join(tfDir, "jsondir")
-
-		// create a dir in path where json dir is expected
-		err = os.MkdirAll(jsonDirPath, 0755)
-		assert.NoError(t, err)
-
-		err = ensureDirExists(jsonDirPath)
-
-		assert.NoError(t, err)
-		assert.DirExists(t, jsonDirPath)
-	})
-
-	t.Run("json dir does not exists", func(t *testing.T) {
-		tfDir, err := ioutil.TempDir("", "tfworkpath")
-		assert.NoError(t, err)
-		defer func() { assert.NoError(t, os.RemoveAll(tfDir)) }()
-		jsonDirPath := path.Join(tfDir, "jsondir")
-
-		err = ensureDirExists(jsonDirPath)
-
-		assert.NoError(t, err)
-		assert.DirExists(t, jsonDirPath)
-	})
-
-	t.Run("path exists but is not a dir", func(t *testing.T) {
-		tfDir, err := ioutil.TempDir("", "tfworkpath")
-		assert.NoError(t, err)
-		defer func() { assert.NoError(t, os.RemoveAll(tfDir)) }()
-		jsonDirPath := path.Join(tfDir, "jsondir")
-
-		// create a file in path where json dir is expected
-		err = os.MkdirAll(tfDir, 0755)
-		assert.NoError(t, err)
-		f, err := os.Create(jsonDirPath)
-		assert.NoError(t, err)
-		assert.NoError(t, f.Close())
-
-		err = ensureDirExists(jsonDirPath)
-
-		assert.ErrorIs(t, err, ErrCreateDir)
-		assert.NoDirExists(t, jsonDirPath)
-	})
-}
@@ -6,12 +6,12 @@ import (
 	"fmt"
 	"path/filepath"
 	"sort"
+	"strconv"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
-	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -27,56 +27,59 @@ var (
 	ErrIncludeRulesNotFound = errors.New("include rules not found")
 )
 
-// CmdCreateInclude is an entrypoint to export-include include sub-command
-func CmdCreateInclude(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(c.Context)
-	client := papi.Client(sess)
-
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
+// IncludeExporter exports includes, see export-property-include command
+type IncludeExporter struct{}
+
+// Spec describes export-property-include command
+func (IncludeExporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-property-include",
+		Description: "Generates Terraform configuration for Include resources",
+		Args:        []string{"contract_id", "include_name"},
+		Complete:    exporter.CompleteArgs(exporter.CompleteContractIDs, completeIncludeNames),
+		Flags:       []cli.Flag{rulesAsHCLFlag(), exporter.SecretsFlag()},
+		Templates: templateSet(map[string]string{
+			"includes.tmpl":  "includes.tf",
+			"variables.tmpl": "variables.tf",
+			"imports.tmpl":   "import.sh",
+		}, includeProviderVersion),
 	}
+}
 
-	includePath := filepath.Join(tfWorkPath, "includes.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
+// Export exports the include
+func (IncludeExporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.PAPIClient()
 
-	err := tools.CheckFiles(includePath, variablesPath, importPath)
-	if err != nil {
+	if err := e.Check(); err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
-	templateToFile := map[string]string{
-		"includes.tmpl":  includePath,
-		"variables.tmpl": variablesPath,
-		"imports.tmpl":   importPath,
-	}
-
-	var rulesAsHCL bool
-	if c.IsSet("rules-as-hcl") {
-		rulesAsHCL = c.Bool("rules-as-hcl")
-	}
 
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: additionalFuncs,
-	}
-
-	contractID := c.Args().First()
-	includeName := c.Args().Get(1)
-	section := edgegrid.GetEdgercSection(c)
-
-	if err = createInclude(ctx, contractID, includeName, section, "property-snippets", tfWorkPath, rulesAsHCL, client, processor); err != nil {
+	contractID, includeName := e.Arg(0), e.Arg(1)
+	processor, _ := newProcessor(e)
+	rulesAsHCL := e.Flags.Bool("rules-as-hcl")
+	if err := createInclude(ctx, contractID, includeName, e.Section, "property-snippets", e.WorkPath, rulesAsHCL, client, processor); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting include: %s", err)), 1)
 	}
 
 	return nil
 }
 
+// deprecatedIncludeExporter runs IncludeExporter as include subcommand of export-property command
+type deprecatedIncludeExporter struct {
+	IncludeExporter
+}
+
+// Spec describes export-property include command
+func (d deprecatedIncludeExporter) Spec() exporter.Spec {
+	spec := d.IncludeExporter.Spec()
+	spec.Name = "include"
+	spec.Description = "Generates Terraform configuration for Include resources. Deprecated, use `export-property-include` command instead"
+	spec.Flags = nil
+	return spec
+}
+
 func createInclude(ctx context.Context, contractID, includeName, section, jsonDir, tfWorkPath string, rulesAsHCL bool, client papi.PAPI, processor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
+	reporter := progress.Get(ctx)
 
 	tfData := TFData{
 		Includes:   make([]TFIncludeData, 0),
@@ -85,28 +88,32 @@ func createInclude(ctx context.Context, contractID, includeName, section, jsonDi
 	}
 
 	// Get Include
-	term.Spinner().Start("Fetching include " + includeName)
+	reporter.Start("Fetching include " + includeName)
 	include, err := findIncludeByName(ctx, client, contractID, includeName)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrIncludeNotFound, err)
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
+	reporter.Start("Fetching the latest version, rules and activations of include ")
 	includeData, rules, err := getIncludeData(ctx, include, client)
 	if err != nil {
+		reporter.Fail()
 		return err
 	}
+	reporter.OK()
+	reportInclude(ctx, include)
 
 	// Save snippets
 	if !rulesAsHCL {
-		term.Spinner().Start("Saving snippets ")
+		reporter.Start("Saving snippets ")
 		ruleTemplate, rulesTemplate := setIncludeRuleTemplates(rules)
-		if err = saveSnippets(rules.Rules, ruleTemplate, rulesTemplate, filepath.Join(tfWorkPath, jsonDir), fmt.Sprintf("%s.json", include.IncludeName)); err != nil {
-			term.Spinner().Fail()
+		if includeData.RulesVariables, err = saveSnippets(templates.GetOutput(ctx), rules.Rules, ruleTemplate, rulesTemplate, filepath.Join(tfWorkPath, jsonDir), fmt.Sprintf("%s.json", include.IncludeName)); err != nil {
+			reporter.Fail()
 			return fmt.Errorf("%w: %s", ErrSavingSnippets, err)
 		}
-		term.Spinner().OK()
+		reporter.OK()
 	} else {
 		includeData.Rules = flattenRules(includeData.IncludeName, rules.Rules)
 	}
@@ -122,23 +129,22 @@ func createInclude(ctx context.Context, contractID, includeName, section, jsonDi
 		processor.AddTemplateTarget("includes_rules.tmpl", filepath.Join(tfWorkPath, "includes_rules.tf"))
 		filterFuncs = append(filterFuncs, useThisOnlyRuleFormat(rules.RuleFormat))
 	}
-	term.Spinner().Start("Saving TF configurations ")
+	reporter.Start("Saving TF configurations ")
 	if err = processor.ProcessTemplates(tfData, filterFuncs...); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrSavingFiles, err)
 	}
 
-	term.Spinner().OK()
-	term.Printf("Terraform configuration for include '%s' was saved successfully\n", includeData.IncludeName)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for include '%s' was saved successfully\n", includeData.IncludeName)
 
 	return nil
 }
 
+// getIncludeData fetches the latest version, rules and activations of the include
+// It does not use the spinner, so that includes of a property can be fetched at the same time
 func getIncludeData(ctx context.Context, include *papi.Include, client papi.PAPI) (*TFIncludeData, *papi.GetIncludeRuleTreeResponse, error) {
-	term := terminal.Get(ctx)
-
 	// Get the latest version of include
-	term.Spinner().Start("Fetching the latest version of include ")
 	latestVersion, err := client.GetIncludeVersion(ctx, papi.GetIncludeVersionRequest{
 		ContractID: include.ContractID,
 		GroupID:    include.GroupID,
@@ -146,12 +152,10 @@ func getIncludeData(ctx context.Context, include *papi.Include, client papi.PAPI
 		Version:    include.LatestVersion,
 	})
 	if err != nil {
-		term.Spinner().Fail()
 		return nil, nil, fmt.Errorf("%w: %s", ErrFetchingLatestIncludeVersion, err)
 	}
 
 	// Get include rules
-	term.Spinner().Start("Fetching include rules ")
 	rules, err := client.GetIncludeRuleTree(ctx, papi.GetIncludeRuleTreeRequest{
 		ContractID:     include.ContractID,
 		GroupID:        include.GroupID,
@@ -160,24 +164,19 @@ func getIncludeData(ctx context.Context, include *papi.Include, client papi.PAPI
 		RuleFormat:     latestVersion.IncludeVersion.RuleFormat,
 	})
 	if err != nil {
-		term.Spinner().Fail()
 		return nil, nil, fmt.Errorf("%w: %s", ErrIncludeRulesNotFound, err)
 	}
-	term.Spinner().OK()
 
 	// Get activations
-	term.Spinner().Start("Fetching include activations ")
 	results, err := client.ListIncludeActivations(ctx, papi.ListIncludeActivationsRequest{
 		IncludeID:  include.IncludeID,
 		ContractID: include.ContractID,
 		GroupID:    include.GroupID,
 	})
 	if err != nil {
-		term.Spinner().Fail()
 		return nil, nil, fmt.Errorf("%w: %s", ErrFetchingActivations, err)
 	}
 	activations := results.Activations.Items
-	term.Spinner().OK()
 
 	var stagingActivations, prodActivations []papi.IncludeActivation
 	var latestStagingActivation, latestProdActivation *papi.IncludeActivation
@@ -214,11 +213,19 @@ func getIncludeData(ctx context.Context, include *papi.Include, client papi.PAPI
 		includeData.ProductionInfo.IsActiveOnLatestVersion = latestProdActivation.IncludeVersion == include.LatestVersion
 	}
 
-	term.Spinner().OK()
-
 	return &includeData, rules, nil
 }
 
+// reportInclude records the include in the export report
+func reportInclude(ctx context.Context, include *papi.Include) {
+	templates.GetReport(ctx).AddObject(templates.ReportObject{
+		Type:    "include",
+		ID:      include.IncludeID,
+		Name:    include.IncludeName,
+		Version: strconv.Itoa(include.LatestVersion),
+	})
+}
+
 // findLatestIncludeActivation finds the latest activation of type `ACTIVATE` with status `ACTIVE` or `PENDING`.
 // If it encounters activation of type `DEACTIVATE` with status `ACTIVE` first or does not find any activation of type
 // `ACTIVATE` with `ACTIVE` status, it returns nil
@@ -324,3 +331,16 @@ func getActivatedNetworks(include *papi.Include) []string {
 
 	return result
 }
+
+// completeIncludeNames returns names of the includes of the contract given by the first argument
+func completeIncludeNames(ctx context.Context, e *exporter.Export) ([]string, error) {
+	includes, err := e.PAPIClient().ListIncludes(ctx, papi.ListIncludesRequest{ContractID: e.Arg(0)})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(includes.Includes.Items))
+	for _, include := range includes.Includes.Items {
+		names = append(names, include.IncludeName)
+	}
+	return names, nil
+}
@@ -5,12 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"strconv"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
-	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -20,44 +20,35 @@ var (
 	ErrIncludeRuleNotFound = errors.New("include rule not found")
 )
 
-// CmdCreateIncludeRule is an entrypoint to export-include-rule sub-command
-func CmdCreateIncludeRule(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(c.Context)
-	client := papi.Client(sess)
-
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
-	}
-
-	includePath := filepath.Join(tfWorkPath, "includes.tf")
-	err := tools.CheckFiles(includePath)
-	if err != nil {
-		return cli.Exit(color.RedString(err.Error()), 1)
-	}
-	templateToFile := map[string]string{
-		"includes.tmpl": includePath,
+// IncludeRuleExporter exports single rules of includes, see export-property-include-rule command
+type IncludeRuleExporter struct{}
+
+// Spec describes export-property-include-rule command
+func (IncludeRuleExporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-property-include-rule",
+		Description: "Generates Terraform configuration for a single Include rule",
+		Args:        []string{"contract_id", "include_name", "rule_name"},
+		Complete:    exporter.CompleteArgs(exporter.CompleteContractIDs, completeIncludeNames),
+		Flags:       []cli.Flag{rulesAsHCLFlag(), exporter.SecretsFlag()},
+		Templates: templateSet(map[string]string{
+			"includes.tmpl": "includes.tf",
+		}, includeProviderVersion),
 	}
+}
 
-	var rulesAsHCL bool
-	if c.IsSet("rules-as-hcl") {
-		rulesAsHCL = c.Bool("rules-as-hcl")
-	}
+// Export exports the rule of the include
+func (IncludeRuleExporter) Export(ctx context.Context, e *exporter.Export) error {
+	client := e.PAPIClient()
 
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: additionalFuncs,
+	if err := e.Check(); err != nil {
+		return cli.Exit(color.RedString(err.Error()), 1)
 	}
 
-	contractID := c.Args().First()
-	includeName := c.Args().Get(1)
-	ruleName := c.Args().Get(2)
-	section := edgegrid.GetEdgercSection(c)
-
-	if err = createIncludeRule(ctx, contractID, includeName, ruleName, section, "property-snippets", tfWorkPath, rulesAsHCL, client, processor); err != nil {
+	contractID, includeName, ruleName := e.Arg(0), e.Arg(1), e.Arg(2)
+	processor, _ := newProcessor(e)
+	rulesAsHCL := e.Flags.Bool("rules-as-hcl")
+	if err := createIncludeRule(ctx, contractID, includeName, ruleName, e.Section, "property-snippets", e.WorkPath, rulesAsHCL, client, processor); err != nil {
 		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting include: %s", err)), 1)
 	}
 
@@ -65,7 +56,7 @@ func CmdCreateIncludeRule(c *cli.Context) error {
 }
 
 func createIncludeRule(ctx context.Context, contractID, includeName, ruleName, section, jsonDir, tfWorkPath string, rulesAsHCL bool, client papi.PAPI, processor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
+	reporter := progress.Get(ctx)
 
 	var includeData TFIncludeData
 	tfData := TFData{
@@ -75,13 +66,13 @@ func createIncludeRule(ctx context.Context, contractID, includeName, ruleName, s
 	}
 
 	// Get Include
-	term.Spinner().Start("Fetching include " + includeName)
+	reporter.Start("Fetching include " + includeName)
 	include, err := findIncludeByName(ctx, client, contractID, includeName)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrIncludeNotFound, err)
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
 	rules, err := getIncludeRuleData(ctx, include, ruleName, client)
 	if err != nil {
@@ -90,13 +81,13 @@ func createIncludeRule(ctx context.Context, contractID, includeName, ruleName, s
 
 	// Save snippets
 	if !rulesAsHCL {
-		term.Spinner().Start("Saving snippets ")
+		reporter.Start("Saving snippets ")
 		ruleTemplate, rulesTemplate := setIncludeRuleTemplates(rules)
-		if err = saveSnippets(rules.Rules, ruleTemplate, rulesTemplate, filepath.Join(tfWorkPath, jsonDir), fmt.Sprintf("%s.json", include.IncludeName)); err != nil {
-			term.Spinner().Fail()
+		if includeData.RulesVariables, err = saveSnippets(templates.GetOutput(ctx), rules.Rules, ruleTemplate, rulesTemplate, filepath.Join(tfWorkPath, jsonDir), fmt.Sprintf("%s.json", include.IncludeName)); err != nil {
+			reporter.Fail()
 			return fmt.Errorf("%w: %s", ErrSavingSnippets, err)
 		}
-		term.Spinner().OK()
+		reporter.OK()
 	} else {
 		dummyDefaultRule := papi.Rules{
 			Children: []papi.Rules{
@@ -117,23 +108,23 @@ func createIncludeRule(ctx context.Context, contractID, includeName, ruleName, s
 		processor.AddTemplateTarget("includes_rules.tmpl", filepath.Join(tfWorkPath, "includes_rules.tf"))
 		filterFuncs = append(filterFuncs, useThisOnlyRuleFormat(rules.RuleFormat))
 	}
-	term.Spinner().Start("Saving TF configurations ")
+	reporter.Start("Saving TF configurations ")
 	if err = processor.ProcessTemplates(tfData, filterFuncs...); err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrSavingFiles, err)
 	}
 
-	term.Spinner().OK()
-	term.Printf("Terraform configuration for include rule '%s' was saved successfully\n", ruleName)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for include rule '%s' was saved successfully\n", ruleName)
 
 	return nil
 }
 
 func getIncludeRuleData(ctx context.Context, include *papi.Include, ruleName string, client papi.PAPI) (*papi.GetIncludeRuleTreeResponse, error) {
-	term := terminal.Get(ctx)
+	reporter := progress.Get(ctx)
 
 	// Get the latest version of include
-	term.Spinner().Start("Fetching the latest version of include ")
+	reporter.Start("Fetching the latest version of include ")
 	latestVersion, err := client.GetIncludeVersion(ctx, papi.GetIncludeVersionRequest{
 		ContractID: include.ContractID,
 		GroupID:    include.GroupID,
@@ -141,12 +132,19 @@ func getIncludeRuleData(ctx context.Context, include *papi.Include, ruleName str
 		Version:    include.LatestVersion,
 	})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return nil, fmt.Errorf("%w: %s", ErrFetchingLatestIncludeVersion, err)
 	}
 
+	templates.GetReport(ctx).AddObject(templates.ReportObject{
+		Type:    "include",
+		ID:      include.IncludeID,
+		Name:    include.IncludeName,
+		Version: strconv.Itoa(include.LatestVersion),
+	})
+
 	// Get include rules
-	term.Spinner().Start("Fetching include rules ")
+	reporter.Start("Fetching include rules ")
 	rules, err := client.GetIncludeRuleTree(ctx, papi.GetIncludeRuleTreeRequest{
 		ContractID:     include.ContractID,
 		GroupID:        include.GroupID,
@@ -155,10 +153,10 @@ func getIncludeRuleData(ctx context.Context, include *papi.Include, ruleName str
 		RuleFormat:     latestVersion.IncludeVersion.RuleFormat,
 	})
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return nil, fmt.Errorf("%w: %s", ErrIncludeRuleNotFound, err)
 	}
-	term.Spinner().OK()
+	reporter.OK()
 
 	singleRule, err := findSingleRule(ctx, ruleName, rules.Rules)
 	if err != nil {
@@ -166,7 +164,7 @@ func getIncludeRuleData(ctx context.Context, include *papi.Include, ruleName str
 	}
 	rules.Rules = singleRule
 
-	term.Spinner().OK()
+	reporter.OK()
 
 	return rules, nil
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/tools"
+	"github.com/akamai/cli-terraform/pkg/exporter"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli/pkg/terminal"
 	"github.com/stretchr/testify/assert"
@@ -564,7 +565,7 @@ func TestProcessIncludeTemplates(t *testing.T) {
 			processor := templates.FSTemplateProcessor{
 				TemplatesFS:     templateFiles,
 				TemplateTargets: templateToFile,
-				AdditionalFuncs: additionalFuncs,
+				AdditionalFuncs: templateFuncs(&templateErrors{}),
 			}
 			require.NoError(t, processor.ProcessTemplates(test.givenData, test.filterFuncs...))
 
@@ -739,3 +740,16 @@ func getTestData(key string) TFData {
 
 	return TFDataMap[key]
 }
+
+func TestCompleteIncludeNames(t *testing.T) {
+	m := &papi.Mock{}
+	m.On("ListIncludes", mock.Anything, papi.ListIncludesRequest{ContractID: "ctr_1"}).Return(&papi.ListIncludesResponse{
+		Includes: papi.IncludeItems{Items: []papi.Include{{IncludeName: "include-a"}, {IncludeName: "include-b"}}},
+	}, nil).Once()
+	complete := IncludeExporter{}.Spec().Complete
+
+	names, err := complete(context.Background(), &exporter.Export{Args: []string{"ctr_1"}, Clients: exporter.Clients{PAPI: m}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"include-a", "include-b"}, names)
+	m.AssertExpectations(t)
+}
@@ -23,19 +23,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/hapi"
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
-	"github.com/akamai/cli-terraform/pkg/edgegrid"
+	"github.com/akamai/cli-terraform/pkg/exporter"
+	"github.com/akamai/cli-terraform/pkg/progress"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
-	"github.com/akamai/cli/pkg/terminal"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -93,6 +93,7 @@ type TFIncludeData struct {
 	Rules          []*WrappedRules
 	ProductionInfo NetworkInfo
 	StagingInfo    NetworkInfo
+	RulesVariables []RulesVariable
 }
 
 // TFPropertyData holds template data for property
@@ -112,6 +113,13 @@ type TFPropertyData struct {
 	ReadVersion          string
 	ProductionInfo       NetworkInfo
 	StagingInfo          NetworkInfo
+	RulesVariables       []RulesVariable
+}
+
+// RulesVariable is a variable of rules exported as JSON snippets, which holds the value of a secret taken out of them
+type RulesVariable struct {
+	Name  string
+	Value string
 }
 
 // NetworkInfo holds details for specific network
@@ -161,15 +169,24 @@ type RuleTemplate struct {
 }
 
 type propertyOptions struct {
-	propertyName  string
-	section       string
-	tfWorkPath    string
-	version       string
-	withIncludes  bool
-	rulesAsHCL    bool
-	withBootstrap bool
+	propertyName   string
+	section        string
+	tfWorkPath     string
+	version        string
+	withIncludes   bool
+	rulesAsHCL     bool
+	withBootstrap  bool
+	templateErrors *templateErrors
 }
 
+const (
+	// propertyProviderVersion is the minimal version of the akamai provider required by exported property
+	propertyProviderVersion = "6.4.0"
+
+	// includeProviderVersion is the minimal version of the akamai provider required by exported include
+	includeProviderVersion = "5.6.0"
+)
+
 //go:embed templates/*
 var templateFiles embed.FS
 
@@ -205,90 +222,142 @@ var (
 	ErrUnsupportedRuleFormat = errors.New("unsupported rule format")
 )
 
+// snippetSecrets maps options of behaviors holding secrets to names of variables replacing them in JSON snippets,
+// which are the same as names of the secrets of rules exported as data sources
+var snippetSecrets = map[string]map[string]string{
+	"g2oheader": {
+		"secretKey": "g2oheader_secret_key",
+	},
+	"originCharacteristics": {
+		"secretKey":          "origin_characteristics_secret_key",
+		"gcsHmacKeySecret":   "origin_characteristics_gcs_hmac_key_secret",
+		"awsSecretAccessKey": "origin_characteristics_aws_secret_access_key",
+	},
+	"watermarking": {
+		"decryptionPassword1": "watermarking_decryption_password1",
+		"decryptionPassword2": "watermarking_decryption_password2",
+	},
+}
+
 var additionalFuncs = tools.DecorateWithMultilineHandlingFunctions(
 	map[string]any{
 		"TerraformName": tools.TerraformName,
 		"AsInt":         AsInt,
-		"ReportError":   ReportError,
-		"CheckErrors":   CheckErrors,
 	})
 
-// CmdCreateProperty is an entrypoint to create-property command
-func CmdCreateProperty(c *cli.Context) error {
-	ctx := c.Context
-	sess := edgegrid.GetSession(c.Context)
-	client := papi.Client(sess)
-	clientHapi := hapi.Client(sess)
-
-	// tfWorkPath is a target directory for generated terraform resources
-	var tfWorkPath = "./"
-	if c.IsSet("tfworkpath") {
-		tfWorkPath = c.String("tfworkpath")
-	}
-	var version string
-	if c.IsSet("version") {
-		version = c.String("version")
+// PropertyExporter exports properties, see export-property command
+type PropertyExporter struct{}
+
+// Spec describes export-property command
+func (PropertyExporter) Spec() exporter.Spec {
+	return exporter.Spec{
+		Name:        "export-property",
+		Aliases:     []string{"create-property"},
+		Description: "Generates Terraform configuration for Property resources",
+		Args:        []string{"property name"},
+		Complete:    completePropertyNames,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "version",
+				Usage:       "Property version to import",
+				DefaultText: "LATEST",
+			},
+			&cli.BoolFlag{
+				Name:  "with-includes",
+				Usage: "Referenced includes will also be exported along with property. Deprecated.",
+			},
+			rulesAsHCLFlag(),
+			exporter.SecretsFlag(),
+			&cli.BoolFlag{
+				Name:  "akamai-property-bootstrap",
+				Usage: "Referenced property will be exported using combination of 'akamai-property-bootstrap' and 'akamai-property' resources",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Maximum number of referenced includes fetched at the same time",
+				Value: 4,
+			},
+		},
+		Subcommands: []exporter.Exporter{deprecatedIncludeExporter{}},
+		Templates: templateSet(map[string]string{
+			"property.tmpl":  "property.tf",
+			"variables.tmpl": "variables.tf",
+			"imports.tmpl":   "import.sh",
+		}, propertyProviderVersion),
 	}
+}
 
-	propertyPath := filepath.Join(tfWorkPath, "property.tf")
-	variablesPath := filepath.Join(tfWorkPath, "variables.tf")
-	importPath := filepath.Join(tfWorkPath, "import.sh")
+// Export exports the property, with its includes if requested
+func (PropertyExporter) Export(ctx context.Context, e *exporter.Export) error {
+	ctx = tools.WithConcurrency(ctx, e.Flags.Int("concurrency"))
+	client := e.PAPIClient()
+	clientHapi := e.HAPIClient()
 
-	err := tools.CheckFiles(propertyPath, variablesPath, importPath)
-	if err != nil {
+	if err := e.Check(); err != nil {
 		return cli.Exit(color.RedString(err.Error()), 1)
 	}
-	templateToFile := map[string]string{
-		"property.tmpl":  propertyPath,
-		"variables.tmpl": variablesPath,
-		"imports.tmpl":   importPath,
-	}
 
-	var withIncludes bool
-	if c.IsSet("with-includes") {
-		withIncludes = c.Bool("with-includes")
-		if withIncludes {
-			templateToFile["includes.tmpl"] = filepath.Join(tfWorkPath, "includes.tf")
-		}
+	processor, templateErrors := newProcessor(e)
+	withIncludes := e.Flags.Bool("with-includes")
+	rulesAsHCL := e.Flags.Bool("rules-as-hcl")
+	if withIncludes {
+		processor.TemplateTargets["includes.tmpl"] = e.Path("includes.tf")
 	}
-
-	var rulesAsHCL bool
-	if c.IsSet("rules-as-hcl") {
-		rulesAsHCL = c.Bool("rules-as-hcl")
+	if withIncludes && rulesAsHCL {
+		processor.TemplateTargets["includes_rules.tmpl"] = e.Path("includes_rules.tf")
 	}
 
-	var isBootstrap bool
-	if c.IsSet("akamai-property-bootstrap") {
-		isBootstrap = c.Bool("akamai-property-bootstrap")
+	options := propertyOptions{
+		propertyName:   e.Arg(0),
+		section:        e.Section,
+		tfWorkPath:     e.WorkPath,
+		version:        e.Flags.String("version"),
+		withIncludes:   withIncludes,
+		rulesAsHCL:     rulesAsHCL,
+		withBootstrap:  e.Flags.Bool("akamai-property-bootstrap"),
+		templateErrors: templateErrors,
+	}
+	if err := createProperty(ctx, options, "property-snippets", client, clientHapi, processor); err != nil {
+		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting property: %s", err)), 1)
 	}
+	return nil
+}
 
-	if withIncludes && rulesAsHCL {
-		templateToFile["includes_rules.tmpl"] = filepath.Join(tfWorkPath, "includes_rules.tf")
+// rulesAsHCLFlag returns the flag of the commands exporting rules either as JSON snippets or as data sources
+func rulesAsHCLFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:    "rules-as-hcl",
+		Aliases: []string{"schema"},
+		Usage:   "Referenced rules will be exported as data source",
 	}
+}
 
-	processor := templates.FSTemplateProcessor{
-		TemplatesFS:     templateFiles,
-		TemplateTargets: templateToFile,
-		AdditionalFuncs: additionalFuncs,
+// templateSet returns templates of the package generating the given files
+func templateSet(files map[string]string, providerVersion string) exporter.TemplateSet {
+	imports := "imports.tmpl"
+	if _, ok := files[imports]; !ok {
+		imports = ""
 	}
-
-	options := propertyOptions{
-		propertyName:  c.Args().First(),
-		section:       edgegrid.GetEdgercSection(c),
-		tfWorkPath:    tfWorkPath,
-		version:       version,
-		withIncludes:  withIncludes,
-		rulesAsHCL:    rulesAsHCL,
-		withBootstrap: isBootstrap,
-	}
-	if err = createProperty(ctx, options, "property-snippets", client, clientHapi, processor); err != nil {
-		return cli.Exit(color.RedString(fmt.Sprintf("Error exporting property: %s", err)), 1)
+	return exporter.TemplateSet{
+		FS:              templateFiles,
+		Files:           files,
+		Imports:         imports,
+		Funcs:           templateFuncs(&templateErrors{}),
+		Data:            map[string]interface{}{"": TFData{}},
+		ProviderVersion: providerVersion,
 	}
-	return nil
+}
+
+// newProcessor returns template processor of the export with template functions reporting errors into returned templateErrors
+func newProcessor(e *exporter.Export) (templates.FSTemplateProcessor, *templateErrors) {
+	errs := &templateErrors{}
+	processor := e.Processor()
+	processor.AdditionalFuncs = templateFuncs(errs)
+	return processor, errs
 }
 
 func createProperty(ctx context.Context, options propertyOptions, jsonDir string, client papi.PAPI, clientHapi hapi.HAPI, templateProcessor templates.TemplateProcessor) error {
-	term := terminal.Get(ctx)
+	reporter := progress.Get(ctx)
 
 	tfData := TFData{
 		Property: TFPropertyData{
@@ -301,53 +370,61 @@ func createProperty(ctx context.Context, options propertyOptions, jsonDir string
 	}
 
 	// Get Property
-	term.Spinner().Start("Fetching property " + options.propertyName)
+	reporter.Start("Fetching property " + options.propertyName)
 	property, err := findProperty(ctx, client, options.propertyName)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrPropertyNotFound, err)
 	}
 
 	tfData.Property.ContractID = property.ContractID
 	tfData.Property.PropertyName = property.PropertyName
 	tfData.Property.PropertyID = property.PropertyID
-	tfData.Property.PropertyResourceName = strings.Replace(property.PropertyName, ".", "-", -1)
+	// dots of the property name are kept in the resource name as dashes, e.g. www-example-com
+	tfData.Property.PropertyResourceName = tools.TerraformIdentifier(strings.Replace(property.PropertyName, ".", "-", -1))
 
-	term.Spinner().OK()
+	reporter.OK()
 
 	// Get Group
-	term.Spinner().Start("Fetching group ")
+	reporter.Start("Fetching group ")
 	group, err := getGroup(ctx, client, property.GroupID)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrGroupNotFound, err)
 	}
 
 	tfData.Property.GroupName = group.GroupName
 	tfData.Property.GroupID = group.GroupID
 
-	term.Spinner().OK()
+	reporter.OK()
 
 	if options.version == "" {
 		options.version = "LATEST"
 	}
 
 	// Get Version
-	term.Spinner().Start("Fetching property version ")
+	reporter.Start("Fetching property version ")
 	version, latestVersion, err := getVersion(ctx, client, property, options.version)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrPropertyVersionNotFound, err)
 	}
 
 	tfData.Property.ProductID = version.Version.ProductID
 	tfData.Property.ReadVersion = options.version
 
-	term.Spinner().OK()
+	templates.GetReport(ctx).AddObject(templates.ReportObject{
+		Type:    "property",
+		ID:      property.PropertyID,
+		Name:    property.PropertyName,
+		Version: strconv.Itoa(version.Version.PropertyVersion),
+	})
+
+	reporter.OK()
 
 	// Get Includes if withIncludes is set
 	if options.withIncludes {
-		term.Spinner().Start("Fetching referenced includes with property " + options.propertyName)
+		reporter.Start("Fetching referenced includes with property " + options.propertyName)
 		includes, err := client.ListReferencedIncludes(ctx, papi.ListReferencedIncludesRequest{
 			PropertyID:      property.PropertyID,
 			ContractID:      property.ContractID,
@@ -355,27 +432,41 @@ func createProperty(ctx context.Context, options propertyOptions, jsonDir string
 			PropertyVersion: version.Version.PropertyVersion,
 		})
 		if err != nil {
-			term.Spinner().Fail()
+			reporter.Fail()
 			return fmt.Errorf("%w: %s", ErrFetchingReferencedIncludes, err)
 		}
-		term.Spinner().OK()
+		reporter.OK()
+
+		// includes are fetched using at most tools.Concurrency(ctx) goroutines and processed in the original order
+		items := includes.Includes.Items
+		includesData := make([]*TFIncludeData, len(items))
+		includesRules := make([]*papi.GetIncludeRuleTreeResponse, len(items))
+		reporter.Start(fmt.Sprintf("Fetching %d referenced includes ", len(items)))
+		err = tools.ForEach(ctx, len(items), func(ctx context.Context, i int) error {
+			var err error
+			includesData[i], includesRules[i], err = getIncludeData(ctx, &items[i], client)
+			return err
+		})
+		if err != nil {
+			reporter.Fail()
+			return err
+		}
+		reporter.OK()
 
 		tfData.Includes = make([]TFIncludeData, 0)
-		for _, include := range includes.Includes.Items {
-			includeData, rules, err := getIncludeData(ctx, &include, client)
-			if err != nil {
-				return err
-			}
+		for i, include := range items {
+			includeData, rules := includesData[i], includesRules[i]
+			reportInclude(ctx, &items[i])
 
 			// Save snippets
 			if !options.rulesAsHCL {
-				term.Spinner().Start("Saving snippets ")
+				reporter.Start("Saving snippets ")
 				ruleTemplate, rulesTemplate := setIncludeRuleTemplates(rules)
-				if err = saveSnippets(rules.Rules, ruleTemplate, rulesTemplate, filepath.Join(options.tfWorkPath, jsonDir), fmt.Sprintf("%s.json", include.IncludeName)); err != nil {
-					term.Spinner().Fail()
+				if includeData.RulesVariables, err = saveSnippets(templates.GetOutput(ctx), rules.Rules, ruleTemplate, rulesTemplate, filepath.Join(options.tfWorkPath, jsonDir), fmt.Sprintf("%s.json", include.IncludeName)); err != nil {
+					reporter.Fail()
 					return fmt.Errorf("%w: %s", ErrSavingSnippets, err)
 				}
-				term.Spinner().OK()
+				reporter.OK()
 			} else {
 				includeData.Rules = flattenRules(includeData.IncludeName, rules.Rules)
 			}
@@ -385,10 +476,10 @@ func createProperty(ctx context.Context, options propertyOptions, jsonDir string
 	}
 
 	// Get Property Rules
-	term.Spinner().Start("Fetching property rules ")
+	reporter.Start("Fetching property rules ")
 	rules, err := getPropertyRules(ctx, client, version)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrPropertyRulesNotFound, err)
 	}
 
@@ -400,42 +491,42 @@ func createProperty(ctx context.Context, options propertyOptions, jsonDir string
 	// Get Rule Format
 	tfData.Property.RuleFormat = rules.RuleFormat
 
-	term.Spinner().OK()
+	reporter.OK()
 
 	// Get Product
-	term.Spinner().Start("Fetching product name ")
+	reporter.Start("Fetching product name ")
 	product, err := getProduct(ctx, client, tfData.Property.ProductID, property.ContractID)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrProductNameNotFound, err)
 	}
 
 	tfData.Property.ProductName = product.ProductName
 
-	term.Spinner().OK()
+	reporter.OK()
 
 	// Get Hostnames
-	term.Spinner().Start("Fetching hostnames ")
+	reporter.Start("Fetching hostnames ")
 	hostnames, err := getPropertyVersionHostnames(ctx, client, property, version)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrHostnamesNotFound, err)
 	}
 
 	tfData.Property.Hostnames, tfData.Property.EdgeHostnames, err =
 		getEdgeHostnameDetail(ctx, client, clientHapi, hostnames, property)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingHostnameDetails, err)
 	}
 
-	term.Spinner().OK()
+	reporter.OK()
 
-	term.Spinner().Start("Fetching activation details ")
+	reporter.Start("Fetching activation details ")
 
 	activeStagingActivation, err := fetchActiveActivationForNetwork(ctx, client, property, papi.ActivationNetworkStaging)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingActivationDetails, err)
 	}
 	if activeStagingActivation != nil {
@@ -447,7 +538,7 @@ func createProperty(ctx context.Context, options propertyOptions, jsonDir string
 	}
 	activeProductionActivation, err := fetchActiveActivationForNetwork(ctx, client, property, papi.ActivationNetworkProduction)
 	if err != nil {
-		term.Spinner().Fail()
+		reporter.Fail()
 		return fmt.Errorf("%w: %s", ErrFetchingActivationDetails, err)
 	}
 	if activeProductionActivation != nil {
@@ -458,7 +549,7 @@ func createProperty(ctx context.Context, options propertyOptions, jsonDir string
 		tfData.Property.ProductionInfo.IsActiveOnLatestVersion = activeProductionActivation.PropertyVersion == latestVersion.Version.PropertyVersion
 	}
 
-	term.Spinner().OK()
+	reporter.OK()
 
 	filterFuncs := make([]func([]string) ([]string, error), 0)
 	if options.rulesAsHCL {
@@ -470,25 +561,25 @@ func createProperty(ctx context.Context, options propertyOptions, jsonDir string
 		tfData.Rules = flattenRules(tfData.Property.PropertyName, rules.Rules)
 		filterFuncs = append(filterFuncs, useThisOnlyRuleFormat(rules.RuleFormat))
 	}
-	term.Spinner().Start("Saving TF configurations ")
-	if err = templateProcessor.ProcessTemplates(tfData, filterFuncs...); err != nil {
-		term.Spinner().Fail()
-		if _, err := CheckErrors(); err != nil {
-			return fmt.Errorf("%w", err)
-		}
-		return fmt.Errorf("%w: %s", ErrSavingFiles, err)
-	}
+	reporter.Start("Saving TF configurations ")
 	if !options.rulesAsHCL {
-		// Save snippets
+		// Save snippets, secrets taken out of them are variables of the rules template
 		ruleTemplate, rulesTemplate := setPropertyRuleTemplates(rules)
-		if err = saveSnippets(rules.Rules, ruleTemplate, rulesTemplate, filepath.Join(options.tfWorkPath, jsonDir), "main.json"); err != nil {
-			term.Spinner().Fail()
+		if tfData.Property.RulesVariables, err = saveSnippets(templates.GetOutput(ctx), rules.Rules, ruleTemplate, rulesTemplate, filepath.Join(options.tfWorkPath, jsonDir), "main.json"); err != nil {
+			reporter.Fail()
 			return fmt.Errorf("%w: %s", ErrSavingSnippets, err)
 		}
 	}
+	if err = templateProcessor.ProcessTemplates(tfData, filterFuncs...); err != nil {
+		reporter.Fail()
+		if _, err := options.templateErrors.checkErrors(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+		return fmt.Errorf("%w: %s", ErrSavingFiles, err)
+	}
 
-	term.Spinner().OK()
-	term.Printf("Terraform configuration for property '%s' was saved successfully\n", property.PropertyName)
+	reporter.OK()
+	reporter.Printf("Terraform configuration for property '%s' was saved successfully\n", property.PropertyName)
 
 	return nil
 }
@@ -593,7 +684,7 @@ func getEdgeHostnameDetail(ctx context.Context, clientPAPI papi.PAPI, clientHAPI
 	for _, hostname := range hostnames.Items {
 		cnameTo := hostname.CnameTo
 		cnameFrom := hostname.CnameFrom
-		cnameToResource := strings.Replace(cnameTo, ".", "-", -1)
+		cnameToResource := tools.TerraformIdentifier(strings.Replace(cnameTo, ".", "-", -1))
 
 		if hostname.EdgeHostnameID != "" {
 			// Get slot details
@@ -725,23 +816,23 @@ func setPropertyRuleTemplates(rules *papi.GetRuleTreeResponse) (RuleTemplate, Ru
 }
 
 // saveSnippets saves given property rules into files under jsonDir directory
-func saveSnippets(rules papi.Rules, ruleTemplate RuleTemplate, rulesTemplate RulesTemplate, snippetsPath, templateFileName string) error {
-	err := os.MkdirAll(snippetsPath, 0755)
-	if err != nil {
-		return fmt.Errorf("can't create directory for rule snippets: %s", err)
-	}
-
+// Values of secrets are replaced with references to variables of the rules template, e.g. "${env.g2oheader_secret_key}",
+// and the variables holding them are returned
+func saveSnippets(output templates.OutputSink, rules papi.Rules, ruleTemplate RuleTemplate, rulesTemplate RulesTemplate, snippetsPath, templateFileName string) ([]RulesVariable, error) {
+	secrets := make(map[string]string)
+	replaceSecrets(secrets, ruleTemplate.Behaviors, nil)
 	nameNormalizer := ruleNameNormalizer()
 	for _, rule := range rules.Children {
+		replaceSecrets(secrets, rule.Behaviors, rule.Children)
 		jsonBody, err := json.MarshalIndent(rule, "", "  ")
 		if err != nil {
-			return fmt.Errorf("can't marshall property rule snippets: %s", err)
+			return nil, fmt.Errorf("can't marshall property rule snippets: %s", err)
 		}
 		name := nameNormalizer(rule.Name)
 		rulesNamePath := filepath.Join(snippetsPath, fmt.Sprintf("%s.json", name))
-		err = os.WriteFile(rulesNamePath, jsonBody, 0644)
+		err = output.WriteFile(rulesNamePath, jsonBody)
 		if err != nil {
-			return fmt.Errorf("can't write property rule snippets: %s", err)
+			return nil, fmt.Errorf("can't write property rule snippets: %s", err)
 		}
 		ruleTemplate.Children = append(ruleTemplate.Children, fmt.Sprintf("#include:%s.json", name))
 	}
@@ -750,15 +841,56 @@ func saveSnippets(rules papi.Rules, ruleTemplate RuleTemplate, rulesTemplate Rul
 
 	jsonBody, err := json.MarshalIndent(rulesTemplate, "", "  ")
 	if err != nil {
-		return fmt.Errorf("can't marshall rule template: %s", err)
+		return nil, fmt.Errorf("can't marshall rule template: %s", err)
 	}
 	templatePath := filepath.Join(snippetsPath, templateFileName)
-	err = os.WriteFile(templatePath, jsonBody, 0644)
+	err = output.WriteFile(templatePath, jsonBody)
 	if err != nil {
-		return fmt.Errorf("can't write property rule template: %s", err)
+		return nil, fmt.Errorf("can't write property rule template: %s", err)
 	}
 
-	return nil
+	var variables []RulesVariable
+	for name, value := range secrets {
+		variables = append(variables, RulesVariable{Name: name, Value: value})
+	}
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+	return variables, nil
+}
+
+// replaceSecrets replaces values of secrets of the behaviors and of the behaviors of the child rules
+// with references to variables, which are added to secrets, see snippetSecrets
+// The same value is referenced by one variable, different values of the same option get variables with a numeric suffix
+func replaceSecrets(secrets map[string]string, behaviors []papi.RuleBehavior, children []papi.Rules) {
+	for _, behavior := range behaviors {
+		options := make([]string, 0, len(snippetSecrets[behavior.Name]))
+		for option := range snippetSecrets[behavior.Name] {
+			options = append(options, option)
+		}
+		sort.Strings(options)
+
+		for _, option := range options {
+			value, ok := behavior.Options[option].(string)
+			if !ok {
+				continue
+			}
+			base := snippetSecrets[behavior.Name][option]
+			name := base
+			for i := 2; ; i++ {
+				existing, ok := secrets[name]
+				if !ok || existing == value {
+					break
+				}
+				name = base + "_" + strconv.Itoa(i)
+			}
+			secrets[name] = value
+			behavior.Options[option] = fmt.Sprintf("${env.%s}", name)
+		}
+	}
+	for _, child := range children {
+		replaceSecrets(secrets, child.Behaviors, child.Children)
+	}
 }
 
 // getUseCases finds UseCases for given edgeHostnameID
@@ -990,21 +1122,64 @@ func AsInt(f any) int64 {
 	return int64(f.(float64))
 }
 
-// as go templates do not support well pointers in receivers and function arguments, global variable seems to be the only
-// solution to accumulate all issues
-var reportedErrors []string
+// templateErrors accumulates issues reported by the templates of a single export
+// As go templates do not support well pointers in receivers and function arguments, ReportError and CheckErrors
+// functions of the templates are bound to it, see templateFuncs
+type templateErrors struct {
+	messages []string
+}
+
+// templateFuncs returns additional functions of the templates, reporting errors into errs
+func templateFuncs(errs *templateErrors) template.FuncMap {
+	funcs := make(template.FuncMap, len(additionalFuncs)+2)
+	for name, fn := range additionalFuncs {
+		funcs[name] = fn
+	}
+	funcs["ReportError"] = errs.reportError
+	funcs["CheckErrors"] = errs.checkErrors
+	return funcs
+}
 
-// ReportError is used to report unknown behaviors or criteria during processing the template
-func ReportError(format string, a ...any) string {
+// reportError is used to report unknown behaviors or criteria during processing the template
+func (e *templateErrors) reportError(format string, a ...any) string {
 	message := fmt.Sprintf(format, a...)
-	reportedErrors = append(reportedErrors, message)
+	e.messages = append(e.messages, message)
 	return message
 }
 
-// CheckErrors is used to fail the processing of the template in case of any unknown behaviors or criteria
-func CheckErrors() (string, error) {
-	if len(reportedErrors) > 0 {
-		return "", fmt.Errorf("there were errors reported: %v", strings.Join(reportedErrors, ", "))
+// checkErrors is used to fail the processing of the template in case of any unknown behaviors or criteria
+func (e *templateErrors) checkErrors() (string, error) {
+	if e != nil && len(e.messages) > 0 {
+		return "", fmt.Errorf("there were errors reported: %v", strings.Join(e.messages, ", "))
 	}
 	return "", nil
 }
+
+// completePropertyNames returns names of the properties available to the credentials, in all contracts and groups
+func completePropertyNames(ctx context.Context, e *exporter.Export) ([]string, error) {
+	client := e.PAPIClient()
+	groups, err := client.GetGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, group := range groups.Groups.Items {
+		for _, contractID := range group.ContractIDs {
+			properties, err := client.GetProperties(ctx, papi.GetPropertiesRequest{
+				ContractID: contractID,
+				GroupID:    group.GroupID,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, property := range properties.Properties.Items {
+				if !seen[property.PropertyName] {
+					seen[property.PropertyName] = true
+					names = append(names, property.PropertyName)
+				}
+			}
+		}
+	}
+	return names, nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/hapi"
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/v8/pkg/papi"
+	"github.com/akamai/cli-terraform/pkg/exporter"
 	"github.com/akamai/cli-terraform/pkg/templates"
 	"github.com/akamai/cli-terraform/pkg/tools"
 	"github.com/akamai/cli/pkg/terminal"
@@ -2731,6 +2732,52 @@ func TestProcessPolicyTemplates(t *testing.T) {
 			filesToCheck: []string{"property.tf", "rules.tf", "variables.tf", "import.sh"},
 			filterFuncs:  []func([]string) ([]string, error){useThisOnlyRuleFormat("v2024-08-13")},
 		},
+		"property with rules as datasource with secrets": {
+			givenData: TFData{
+				Property: TFPropertyData{
+					GroupName:            "test_group",
+					GroupID:              "grp_12345",
+					ContractID:           "test_contract",
+					PropertyResourceName: "test-edgesuite-net",
+					PropertyName:         "test.edgesuite.net",
+					PropertyID:           "prp_12345",
+					ProductID:            "prd_HTTP_Content_Del",
+					ProductName:          "HTTP_Content_Del",
+					RuleFormat:           "v2024-08-13",
+					IsSecure:             "false",
+					ReadVersion:          "LATEST",
+				},
+				Section: "test_section",
+			},
+			dir:          "basic-rules-datasource-secrets",
+			rulesAsHCL:   true,
+			filesToCheck: []string{"property.tf", "rules.tf", "variables.tf", "import.sh", "secrets.tf", "secrets.auto.tfvars", ".gitignore"},
+			filterFuncs:  []func([]string) ([]string, error){useThisOnlyRuleFormat("v2024-08-13")},
+		},
+		"property with secrets in snippets": {
+			givenData: TFData{
+				Property: TFPropertyData{
+					GroupName:            "test_group",
+					GroupID:              "grp_12345",
+					ContractID:           "test_contract",
+					PropertyResourceName: "test-edgesuite-net",
+					PropertyName:         "test.edgesuite.net",
+					PropertyID:           "prp_12345",
+					ProductID:            "prd_HTTP_Content_Del",
+					ProductName:          "HTTP_Content_Del",
+					RuleFormat:           "latest",
+					IsSecure:             "false",
+					ReadVersion:          "LATEST",
+					RulesVariables: []RulesVariable{
+						{Name: "g2oheader_secret_key", Value: "g2o-secret"},
+						{Name: "origin_characteristics_aws_secret_access_key", Value: "aws-secret"},
+					},
+				},
+				Section: "test_section",
+			},
+			dir:          "basic-snippets-secrets",
+			filesToCheck: []string{"property.tf", "variables.tf", "import.sh", "secrets.tf", "secrets.auto.tfvars", ".gitignore"},
+		},
 		"property with bootstrap": {
 			givenData: TFData{
 				Property: TFPropertyData{
@@ -2811,10 +2858,9 @@ func TestProcessPolicyTemplates(t *testing.T) {
 			processor := templates.FSTemplateProcessor{
 				TemplatesFS:     templateFiles,
 				TemplateTargets: templateToFile,
-				AdditionalFuncs: additionalFuncs,
+				AdditionalFuncs: templateFuncs(&templateErrors{}),
 			}
 			err := processor.ProcessTemplates(test.givenData, test.filterFuncs...)
-			reportedErrors = []string{}
 			if test.withError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), test.withError)
@@ -3237,3 +3283,74 @@ func newTimeFromString(t *testing.T, s string) *time.Time {
 	require.NoError(t, err)
 	return &parsedTime
 }
+
+func TestCompletePropertyNames(t *testing.T) {
+	m := &papi.Mock{}
+	m.On("GetGroups", mock.Anything).Return(&papi.GetGroupsResponse{
+		Groups: papi.GroupItems{Items: []*papi.Group{
+			{GroupID: "grp_1", ContractIDs: []string{"ctr_1", "ctr_2"}},
+			{GroupID: "grp_2", ContractIDs: []string{"ctr_1"}},
+		}},
+	}, nil).Once()
+	for _, req := range []papi.GetPropertiesRequest{{ContractID: "ctr_1", GroupID: "grp_1"}, {ContractID: "ctr_2", GroupID: "grp_1"}, {ContractID: "ctr_1", GroupID: "grp_2"}} {
+		m.On("GetProperties", mock.Anything, req).Return(&papi.GetPropertiesResponse{
+			Properties: papi.PropertiesItems{Items: []*papi.Property{{PropertyName: "property-" + req.ContractID}, {PropertyName: "shared"}}},
+		}, nil).Once()
+	}
+
+	names, err := completePropertyNames(context.Background(), &exporter.Export{Clients: exporter.Clients{PAPI: m}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"property-ctr_1", "shared", "property-ctr_2"}, names)
+	m.AssertExpectations(t)
+}
+
+func TestSaveSnippetsSecrets(t *testing.T) {
+	rules := papi.Rules{
+		Name: "default",
+		Behaviors: []papi.RuleBehavior{
+			{Name: "g2oheader", Options: papi.RuleOptionsMap{"enabled": true, "secretKey": "g2o-secret"}},
+		},
+		Children: []papi.Rules{
+			{
+				Name: "Origin",
+				Behaviors: []papi.RuleBehavior{
+					{Name: "originCharacteristics", Options: papi.RuleOptionsMap{"awsSecretAccessKey": "aws-secret", "awsRegion": "us-east-1"}},
+				},
+				Children: []papi.Rules{
+					{
+						Name: "Other origin",
+						Behaviors: []papi.RuleBehavior{
+							{Name: "g2oheader", Options: papi.RuleOptionsMap{"secretKey": "other-g2o-secret"}},
+							{Name: "originCharacteristics", Options: papi.RuleOptionsMap{"awsSecretAccessKey": "aws-secret"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	ruleTemplate, rulesTemplate := setPropertyRuleTemplates(&papi.GetRuleTreeResponse{Rules: rules})
+	output := templates.NewMemorySink()
+
+	variables, err := saveSnippets(output, rules, ruleTemplate, rulesTemplate, "property-snippets", "main.json")
+	require.NoError(t, err)
+	assert.Equal(t, []RulesVariable{
+		{Name: "g2oheader_secret_key", Value: "g2o-secret"},
+		{Name: "g2oheader_secret_key_2", Value: "other-g2o-secret"},
+		{Name: "origin_characteristics_aws_secret_access_key", Value: "aws-secret"},
+	}, variables)
+
+	for _, path := range output.Files() {
+		content, _ := output.File(path)
+		assert.NotContains(t, string(content), "secret\"", path)
+	}
+	content, _ := output.File("property-snippets/main.json")
+	assert.Contains(t, string(content), `"secretKey": "${env.g2oheader_secret_key}"`)
+	content, _ = output.File("property-snippets/Origin.json")
+	assert.Contains(t, string(content), `"awsSecretAccessKey": "${env.origin_characteristics_aws_secret_access_key}"`)
+	assert.Contains(t, string(content), `"secretKey": "${env.g2oheader_secret_key_2}"`)
+	assert.Contains(t, string(content), `"awsRegion": "us-east-1"`)
+
+	variables, err = saveSnippets(templates.NewMemorySink(), papi.Rules{Name: "default"}, RuleTemplate{}, RulesTemplate{}, "property-snippets", "main.json")
+	require.NoError(t, err)
+	assert.Nil(t, variables)
+}
@@ -0,0 +1,2 @@
+terraform init
+terraform import akamai_property.test-edgesuite-net prp_12345,test_contract,grp_12345,LATEST
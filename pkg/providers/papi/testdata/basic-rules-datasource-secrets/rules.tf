@@ -0,0 +1,32 @@
+
+data "akamai_property_rules_builder" "test-edgesuite-net_rule_default" {
+  rules_v2024_08_13 {
+    name      = "default"
+    is_secure = false
+    uuid      = "default"
+    behavior {
+      g2oheader {
+        data_header            = "X-Akamai-G2O-Auth-Data"
+        enabled                = true
+        encoding_version       = 5
+        nonce                  = "nonce"
+        secret_key             = var.g2oheader_secret_key
+        signed_header          = "X-Akamai-G2O-Auth-Sign"
+        use_custom_sign_string = false
+      }
+    }
+    behavior {
+      origin_characteristics {
+        authentication_method       = "AWS"
+        authentication_method_title = ""
+        aws_access_key_id           = "access-key-id"
+        aws_access_key_version_guid = ""
+        aws_host                    = ""
+        aws_region                  = "us-east-1"
+        aws_secret_access_key       = var.origin_characteristics_aws_secret_access_key
+        aws_service                 = "s3"
+        country                     = "UNKNOWN"
+      }
+    }
+  }
+}
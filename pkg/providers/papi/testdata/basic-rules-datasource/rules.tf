@@ -189,7 +189,7 @@ EOT
 
 data "akamai_property_rules_builder" "test-edgesuite-net_rule_strange_characters--a-------------ą" {
   rules_v2023_01_05 {
-    name                  = "Strange Characters${a}\"\\||$%&*@#|!ą"
+    name                  = "Strange Characters$${a}\"\\||$%&*@#|!ą"
     criteria_must_satisfy = "all"
     criterion {
       content_type {
@@ -326,7 +326,7 @@ data "akamai_property_rules_builder" "test-edgesuite-net_rule_new_rule3" {
 
 data "akamai_property_rules_builder" "test-edgesuite-net_rule_strange_characters--a-------------ą1" {
   rules_v2023_01_05 {
-    name                  = "Strange Characters${a}\"\\&&$%&*@#|!ą"
+    name                  = "Strange Characters$${a}\"\\&&$%&*@#|!ą"
     criteria_must_satisfy = "all"
   }
 }
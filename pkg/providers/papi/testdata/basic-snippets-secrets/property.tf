@@ -0,0 +1,40 @@
+data "akamai_property_rules_template" "rules" {
+  template_file = abspath("${path.module}/property-snippets/main.json")
+  variables {
+    name  = "g2oheader_secret_key"
+    type  = "string"
+    value = var.g2oheader_secret_key
+  }
+  variables {
+    name  = "origin_characteristics_aws_secret_access_key"
+    type  = "string"
+    value = var.origin_characteristics_aws_secret_access_key
+  }
+}
+
+resource "akamai_property" "test-edgesuite-net" {
+  name        = "test.edgesuite.net"
+  contract_id = var.contract_id
+  group_id    = var.group_id
+  product_id  = "prd_HTTP_Content_Del"
+  rule_format = "latest"
+  rules       = data.akamai_property_rules_template.rules.json
+}
+
+# NOTE: Be careful when removing this resource as you can disable traffic
+#resource "akamai_property_activation" "test-edgesuite-net-staging" {
+#  property_id                    = akamai_property.test-edgesuite-net.id
+#  contact                        = []
+#  version                        = var.activate_latest_on_staging ? akamai_property.test-edgesuite-net.latest_version : akamai_property.test-edgesuite-net.staging_version
+#  network                        = "STAGING"
+#  auto_acknowledge_rule_warnings = false
+#}
+
+# NOTE: Be careful when removing this resource as you can disable traffic
+#resource "akamai_property_activation" "test-edgesuite-net-production" {
+#  property_id                    = akamai_property.test-edgesuite-net.id
+#  contact                        = []
+#  version                        = var.activate_latest_on_production ? akamai_property.test-edgesuite-net.latest_version : akamai_property.test-edgesuite-net.production_version
+#  network                        = "PRODUCTION"
+#  auto_acknowledge_rule_warnings = false
+#}
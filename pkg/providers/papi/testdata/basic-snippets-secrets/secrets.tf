@@ -0,0 +1,9 @@
+variable "g2oheader_secret_key" {
+  type      = string
+  sensitive = true
+}
+
+variable "origin_characteristics_aws_secret_access_key" {
+  type      = string
+  sensitive = true
+}
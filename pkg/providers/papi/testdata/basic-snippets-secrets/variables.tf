@@ -0,0 +1,29 @@
+variable "edgerc_path" {
+  type    = string
+  default = "~/.edgerc"
+}
+
+variable "config_section" {
+  type    = string
+  default = "test_section"
+}
+
+variable "contract_id" {
+  type    = string
+  default = "test_contract"
+}
+
+variable "group_id" {
+  type    = string
+  default = "grp_12345"
+}
+
+#variable "activate_latest_on_staging" {
+#  type    = bool
+#  default = true
+#}
+
+#variable "activate_latest_on_production" {
+#  type    = bool
+#  default = true
+#}
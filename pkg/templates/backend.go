@@ -0,0 +1,233 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/akamai/cli-terraform/pkg/tools"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type (
+	// BackendType is the type of terraform backend in which the state of exported configuration is kept
+	BackendType string
+
+	// Backend decides the backend written into backend.tf, nothing is written if Type is empty
+	Backend struct {
+		// Type is the type of the backend
+		Type BackendType
+		// Config contains arguments of the backend block, e.g. bucket of s3 backend
+		Config map[string]string
+	}
+
+	backendContextKey struct{}
+)
+
+const (
+	// BackendLocal keeps the state in a local file
+	BackendLocal BackendType = "local"
+	// BackendS3 keeps the state in Amazon S3
+	BackendS3 BackendType = "s3"
+	// BackendGCS keeps the state in Google Cloud Storage
+	BackendGCS BackendType = "gcs"
+	// BackendAzureRM keeps the state in Azure Blob Storage
+	BackendAzureRM BackendType = "azurerm"
+	// BackendHTTP keeps the state behind a REST endpoint
+	BackendHTTP BackendType = "http"
+	// BackendPG keeps the state in a Postgres database
+	BackendPG BackendType = "pg"
+
+	// BackendFileName is the name of the file with the backend block
+	BackendFileName = "backend.tf"
+	// BootstrapFileName is the name of the script initializing the backend and importing exported resources
+	BootstrapFileName = "bootstrap.sh"
+	// BackendConfigFileName is the name of the git-ignored file with credentials of the backend, which is passed
+	// to init as partial backend configuration
+	BackendConfigFileName = "backend.hcl"
+)
+
+var (
+	// ErrInvalidBackend is returned when unknown backend is requested
+	ErrInvalidBackend = errors.New("invalid backend")
+	// ErrInvalidBackendConfig is returned when backend config is not a key=value pair
+	ErrInvalidBackendConfig = errors.New("invalid backend config")
+
+	backendTypes = []BackendType{BackendLocal, BackendS3, BackendGCS, BackendAzureRM, BackendHTTP, BackendPG}
+
+	// backendCredentials are arguments of the backends which are written into backend.hcl instead of backend.tf
+	backendCredentials = map[BackendType][]string{
+		BackendS3:      {"access_key", "secret_key", "token", "sse_customer_key"},
+		BackendGCS:     {"credentials", "access_token", "encryption_key"},
+		BackendAzureRM: {"access_key", "sas_token", "client_secret", "client_certificate_password", "oidc_token"},
+		BackendHTTP:    {"password"},
+		BackendPG:      {"conn_str"},
+	}
+)
+
+// ParseBackend returns Backend of the given type configured with key=value pairs
+// Empty name results in Backend without type, config cannot be used without the type
+func ParseBackend(name string, config []string) (Backend, error) {
+	if name == "" {
+		if len(config) > 0 {
+			return Backend{}, fmt.Errorf("%w: backend config requires backend type", ErrInvalidBackendConfig)
+		}
+		return Backend{}, nil
+	}
+	backendType, err := tools.ParseEnum(name, "", ErrInvalidBackend, backendTypes...)
+	if err != nil {
+		return Backend{}, err
+	}
+	backend := Backend{Type: backendType}
+
+	for _, pair := range config {
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || !hclsyntax.ValidIdentifier(key) {
+			return Backend{}, fmt.Errorf("%w: '%s', expected key=value", ErrInvalidBackendConfig, pair)
+		}
+		if backend.Config == nil {
+			backend.Config = make(map[string]string)
+		}
+		backend.Config[key] = value
+	}
+	return backend, nil
+}
+
+// credential returns true if the argument of the backend is its credential
+func (t BackendType) credential(key string) bool {
+	for _, credential := range backendCredentials[t] {
+		if key == credential {
+			return true
+		}
+	}
+	return false
+}
+
+// WithBackend returns context with the given backend
+func WithBackend(ctx context.Context, backend Backend) context.Context {
+	return context.WithValue(ctx, backendContextKey{}, backend)
+}
+
+// GetBackend returns backend stored in the context, if there is none Backend without type is returned
+func GetBackend(ctx context.Context) Backend {
+	if backend, ok := ctx.Value(backendContextKey{}).(Backend); ok {
+		return backend
+	}
+	return Backend{}
+}
+
+// Write writes backend.tf and bootstrap.sh into dir, if the backend type is set
+// Credentials of the backend, e.g. secret_key of s3, are written into git-ignored backend.hcl instead of backend.tf
+// and passed to init as partial configuration
+// bootstrap.sh initializes the working directory with the engine and then runs the import script located in importPath
+// or, for ImportStyleBlocks, plans the import, importPath may be empty if the export does not generate imports
+// The import script initializes the working directory in the same way, see Script
+func (b Backend) Write(output OutputSink, dir, importPath string, importStyle ImportStyle, engine Engine) error {
+	if b.Type == "" {
+		return nil
+	}
+
+	file := hclwrite.NewEmptyFile()
+	backend := file.Body().AppendNewBlock("terraform", nil).Body().AppendNewBlock("backend", []string{string(b.Type)}).Body()
+	credentials := hclwrite.NewEmptyFile()
+	keys := make([]string, 0, len(b.Config))
+	for key := range b.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if b.Type.credential(key) {
+			credentials.Body().SetAttributeValue(key, backendValue(b.Config[key]))
+			continue
+		}
+		backend.SetAttributeValue(key, backendValue(b.Config[key]))
+	}
+	path := filepath.Join(dir, BackendFileName)
+	if err := output.WriteFile(path, hclwrite.Format(file.Bytes())); err != nil {
+		return fmt.Errorf("%w: '%s': %s", ErrSavingFiles, path, err)
+	}
+
+	if len(credentials.Body().Attributes()) > 0 {
+		path = filepath.Join(dir, BackendConfigFileName)
+		if err := output.WriteFile(path, hclwrite.Format(credentials.Bytes())); err != nil {
+			return fmt.Errorf("%w: '%s': %s", ErrSavingFiles, path, err)
+		}
+		if err := gitIgnore(output, dir, BackendConfigFileName); err != nil {
+			return err
+		}
+	}
+
+	script := []string{
+		"#!/bin/sh",
+		fmt.Sprintf("# Initializes %s backend and imports exported resources", b.Type),
+		"set -e",
+		`cd "$(dirname "$0")"`,
+		b.initCommand(engine),
+	}
+	if importPath != "" {
+		command := engine.Command()
+		importName, err := filepath.Rel(dir, importPath)
+		if err != nil {
+			importName = filepath.Base(importPath)
+		}
+		importName = filepath.ToSlash(importName)
+		if importStyle == ImportStyleBlocks {
+			script = append(script, fmt.Sprintf("# review import blocks of %s and apply them with '%s apply'", importName, command))
+			script = append(script, command+" plan -input=false")
+		} else {
+			script = append(script, "sh ./"+importName)
+		}
+	}
+	path = filepath.Join(dir, BootstrapFileName)
+	if err := output.WriteFile(path, []byte(strings.Join(script, "\n")+"\n")); err != nil {
+		return fmt.Errorf("%w: '%s': %s", ErrSavingFiles, path, err)
+	}
+	return nil
+}
+
+// Script replaces the init command of the given import script, e.g. `terraform init`, with the one run by bootstrap.sh,
+// so that the script initializes the working directory with the partial configuration of the backend as well
+// The script is returned unchanged if the backend type is not set
+func (b Backend) Script(script []byte, engine Engine) []byte {
+	if b.Type == "" {
+		return script
+	}
+	lines := strings.Split(string(script), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == engine.Command()+" init" {
+			lines[i] = line[:strings.Index(line, trimmed)] + b.initCommand(engine)
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// initCommand returns the command initializing the working directory, which passes backend.hcl as partial
+// configuration if any of the arguments of the backend is a credential
+func (b Backend) initCommand(engine Engine) string {
+	init := engine.Command() + " init -input=false"
+	for key := range b.Config {
+		if b.Type.credential(key) {
+			return init + " -backend-config=" + BackendConfigFileName
+		}
+	}
+	return init
+}
+
+// backendValue returns boolean and numeric values, e.g. encrypt = true of s3 backend, without quotes
+func backendValue(value string) cty.Value {
+	if value == "true" || value == "false" {
+		return cty.BoolVal(value == "true")
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil && strconv.FormatInt(n, 10) == value {
+		return cty.NumberIntVal(n)
+	}
+	return cty.StringVal(value)
+}
@@ -0,0 +1,365 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli-terraform/pkg/tools"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// ConflictPolicy defines what happens when a generated file already exists
+type ConflictPolicy string
+
+const (
+	// ConflictFail aborts the export if any of the generated files already exists
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictOverwrite replaces existing files
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictBackup renames existing files by adding a timestamp suffix before writing new ones
+	ConflictBackup ConflictPolicy = "backup"
+	// ConflictMerge merges generated content into existing files
+	ConflictMerge ConflictPolicy = "merge"
+
+	backupTimeFormat = "20060102150405"
+)
+
+var (
+	// ErrInvalidConflictPolicy is returned when unknown conflict policy is requested
+	ErrInvalidConflictPolicy = errors.New("invalid conflict policy")
+	// ErrMerge is returned when generated file cannot be merged with the existing one
+	ErrMerge = errors.New("merging with existing file")
+
+	// jsonBlockLabels is the number of labels of blocks of the given type, which are keys of nested objects
+	// in terraform JSON syntax, other blocks, e.g. terraform, locals or import, have no labels
+	jsonBlockLabels = map[string]int{
+		"resource": 2,
+		"data":     2,
+		"variable": 1,
+		"output":   1,
+		"module":   1,
+		"provider": 1,
+	}
+)
+
+// conflictResolver is implemented by sinks writing files according to a conflict policy
+type conflictResolver interface {
+	ConflictPolicy() ConflictPolicy
+}
+
+// ParseConflictPolicy returns ConflictPolicy for the given name, empty name results in ConflictFail
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	return tools.ParseEnum(name, ConflictFail, ErrInvalidConflictPolicy, ConflictFail, ConflictOverwrite, ConflictBackup, ConflictMerge)
+}
+
+// outputConflictPolicy returns the conflict policy of the sink or of the first sink wrapped by it which has one,
+// output which never meets existing files, e.g. streamed one, results in the empty policy
+func outputConflictPolicy(sink OutputSink) ConflictPolicy {
+	for sink != nil {
+		if r, ok := sink.(conflictResolver); ok {
+			return r.ConflictPolicy()
+		}
+		u, ok := sink.(unwrapper)
+		if !ok {
+			return ""
+		}
+		sink = u.Unwrap()
+	}
+	return ""
+}
+
+// BackupName returns the name under which the existing file is preserved, e.g. variables.tf -> variables.tf.20240904153000.bak
+func BackupName(path string, t time.Time) string {
+	return fmt.Sprintf("%s.%s.bak", path, t.Format(backupTimeFormat))
+}
+
+// MergeFiles merges generated content into the existing content of the file with the given path
+// Terraform files are merged block by block: blocks which already exist are kept, new ones are appended
+// and a warning is reported for each existing block which differs from the generated one, e.g. duplicated variable
+// Terraform files in JSON syntax, e.g. variables.tf.json, are merged in the same way
+// Values of variables, e.g. secrets.auto.tfvars, are merged attribute by attribute, keeping the existing values
+// Lines of shell scripts are merged in the same way, content of other files is replaced with the generated one
+// versions.tf is replaced as well, it is generated from the existing one keeping the highest provider version
+func MergeFiles(path string, existing, generated []byte, warn func(string)) ([]byte, error) {
+	if strings.TrimSuffix(filepath.Base(path), ".json") == VersionsFileName {
+		return generated, nil
+	}
+	warnBlock := func(msg string) {
+		warn(fmt.Sprintf("%s already exists in '%s', keeping existing definition", msg, path))
+	}
+	switch {
+	case strings.HasSuffix(path, ".tf.json"):
+		merged, err := mergeJSON(existing, generated, warnBlock)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s': %s", ErrMerge, path, err)
+		}
+		return merged, nil
+	case filepath.Ext(path) == ".tf", filepath.Ext(path) == ".tfvars":
+		merged, err := mergeHCL(existing, generated, warnBlock)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s': %s", ErrMerge, path, err)
+		}
+		return merged, nil
+	case filepath.Ext(path) == ".sh", filepath.Ext(path) == ".script":
+		return mergeLines(existing, generated), nil
+	}
+	return generated, nil
+}
+
+func mergeHCL(existing, generated []byte, warn func(string)) ([]byte, error) {
+	existingFile, diags := hclwrite.ParseConfig(existing, "existing", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	generatedFile, diags := hclwrite.ParseConfig(generated, "generated", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	body := existingFile.Body()
+	blocks := make(map[string]*hclwrite.Block)
+	for _, block := range body.Blocks() {
+		blocks[blockKey(block)] = block
+	}
+	attributes := generatedFile.Body().Attributes()
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if body.GetAttribute(name) == nil {
+			body.SetAttributeRaw(name, attributes[name].Expr().BuildTokens(nil))
+		}
+	}
+	for _, block := range generatedFile.Body().Blocks() {
+		key := blockKey(block)
+		if existingBlock, ok := blocks[key]; ok {
+			if !bytes.Equal(hclwrite.Format(existingBlock.BuildTokens(nil).Bytes()), hclwrite.Format(block.BuildTokens(nil).Bytes())) {
+				warn(blockName(block))
+			}
+			continue
+		}
+		blocks[key] = block
+		body.AppendNewline()
+		body.AppendBlock(block)
+	}
+
+	return hclwrite.Format(existingFile.Bytes()), nil
+}
+
+// blockKey identifies the block by its type and labels
+// import blocks are identified by the target address and other blocks without labels by their content
+func blockKey(block *hclwrite.Block) string {
+	key := block.Type() + " " + strings.Join(block.Labels(), " ")
+	if len(block.Labels()) > 0 {
+		return key
+	}
+	if to := block.Body().GetAttribute("to"); block.Type() == "import" && to != nil {
+		return key + strings.TrimSpace(string(to.Expr().BuildTokens(nil).Bytes()))
+	}
+	return key + string(hclwrite.Format(block.Body().BuildTokens(nil).Bytes()))
+}
+
+func blockName(block *hclwrite.Block) string {
+	name := block.Type()
+	for _, label := range block.Labels() {
+		name += fmt.Sprintf(" %q", label)
+	}
+	return name
+}
+
+// mergeJSON merges terraform files in JSON syntax like mergeHCL, labels of blocks are the keys of nested objects,
+// e.g. {"variable": {"contract_id": {...}}}, and blocks without labels are identified by their content
+func mergeJSON(existing, generated []byte, warn func(string)) ([]byte, error) {
+	existingFile, err := decodeJSONObject(existing)
+	if err != nil {
+		return nil, err
+	}
+	generatedFile, err := decodeJSONObject(generated)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range generatedFile.fields {
+		merged, err := mergeJSONBlocks(existingFile.get(f.key), f.value.(json.RawMessage), f.key, jsonBlockLabels[f.key], warn)
+		if err != nil {
+			return nil, err
+		}
+		existingFile.set(f.key, merged)
+	}
+
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(existingFile); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeJSONBlocks merges blocks of the given type with the given number of labels, name is the type followed
+// by the labels of the enclosing objects, e.g. resource "akamai_property"
+func mergeJSONBlocks(existing interface{}, generated json.RawMessage, name string, labels int, warn func(string)) (json.RawMessage, error) {
+	existingValue, ok := existing.(json.RawMessage)
+	if !ok {
+		return generated, nil
+	}
+	if labels == 0 {
+		return mergeJSONUnlabeled(existingValue, generated, name, warn)
+	}
+
+	existingBlocks, err := decodeJSONObject(existingValue)
+	if err != nil {
+		return nil, err
+	}
+	generatedBlocks, err := decodeJSONObject(generated)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range generatedBlocks.fields {
+		value := f.value.(json.RawMessage)
+		blockName := fmt.Sprintf("%s %q", name, f.key)
+		current, ok := existingBlocks.get(f.key).(json.RawMessage)
+		switch {
+		case !ok:
+			existingBlocks.set(f.key, value)
+		case labels > 1:
+			merged, err := mergeJSONBlocks(current, value, blockName, labels-1, warn)
+			if err != nil {
+				return nil, err
+			}
+			existingBlocks.set(f.key, merged)
+		case !jsonEqual(current, value):
+			warn(blockName)
+		}
+	}
+	return marshalJSON(existingBlocks)
+}
+
+// mergeJSONUnlabeled merges blocks without labels, e.g. import blocks, which are a single object or an array of them
+func mergeJSONUnlabeled(existing, generated json.RawMessage, name string, warn func(string)) (json.RawMessage, error) {
+	merged, err := jsonItems(existing)
+	if err != nil {
+		return nil, err
+	}
+	generatedItems, err := jsonItems(generated)
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[string]json.RawMessage, len(merged))
+	for _, item := range merged {
+		items[jsonBlockKey(name, item)] = item
+	}
+	for _, item := range generatedItems {
+		key := jsonBlockKey(name, item)
+		if current, ok := items[key]; ok {
+			if !jsonEqual(current, item) {
+				warn(name)
+			}
+			continue
+		}
+		items[key] = item
+		merged = append(merged, item)
+	}
+	if len(merged) == 1 {
+		return merged[0], nil
+	}
+	return marshalJSON(merged)
+}
+
+// jsonBlockKey identifies the block without labels like blockKey, import blocks by the target address
+// and other blocks by their content
+func jsonBlockKey(name string, item json.RawMessage) string {
+	if name == "import" {
+		var block struct {
+			To string `json:"to"`
+		}
+		if err := json.Unmarshal(item, &block); err == nil && block.To != "" {
+			return block.To
+		}
+	}
+	return string(compactJSON(item))
+}
+
+// jsonItems returns the items of the array or the value itself, if it is not an array
+func jsonItems(value json.RawMessage) ([]json.RawMessage, error) {
+	if trimmed := bytes.TrimSpace(value); len(trimmed) == 0 || trimmed[0] != '[' {
+		return []json.RawMessage{value}, nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(value, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// decodeJSONObject decodes the JSON object keeping the order of its fields, values of the fields are left undecoded
+func decodeJSONObject(data []byte) (*jsonObject, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if token != json.Delim('{') {
+		return nil, fmt.Errorf("expected JSON object, got '%v'", token)
+	}
+	res := &jsonObject{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected key of JSON object, got '%v'", token)
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		res.set(key, value)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	return bytes.Equal(compactJSON(a), compactJSON(b))
+}
+
+func compactJSON(value json.RawMessage) []byte {
+	buf := bytes.Buffer{}
+	if err := json.Compact(&buf, value); err != nil {
+		return value
+	}
+	return buf.Bytes()
+}
+
+// mergeLines appends lines of the generated file missing in the existing one, lines are not limited in length
+func mergeLines(existing, generated []byte) []byte {
+	merged := append([]byte(nil), bytes.TrimRight(existing, "\n")...)
+	lines := make(map[string]bool)
+	for _, line := range strings.Split(string(existing), "\n") {
+		lines[strings.TrimSpace(line)] = true
+	}
+	for _, line := range strings.Split(string(generated), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || lines[trimmed] {
+			continue
+		}
+		lines[trimmed] = true
+		merged = append(merged, '\n')
+		merged = append(merged, line...)
+	}
+	return append(merged, '\n')
+}
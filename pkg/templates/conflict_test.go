@@ -0,0 +1,293 @@
+package templates
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConflictPolicy(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  ConflictPolicy
+		withError error
+	}{
+		"default": {
+			given:    "",
+			expected: ConflictFail,
+		},
+		"backup": {
+			given:    "backup",
+			expected: ConflictBackup,
+		},
+		"merge": {
+			given:    "merge",
+			expected: ConflictMerge,
+		},
+		"invalid": {
+			given:     "foo",
+			withError: ErrInvalidConflictPolicy,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy, err := ParseConflictPolicy(test.given)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, policy)
+		})
+	}
+}
+
+func TestBackupName(t *testing.T) {
+	assert.Equal(t, "variables.tf.20240904153000.bak", BackupName("variables.tf", time.Date(2024, 9, 4, 15, 30, 0, 0, time.UTC)))
+}
+
+func TestMergeFiles(t *testing.T) {
+	tests := map[string]struct {
+		path      string
+		existing  string
+		generated string
+		expected  string
+		warnings  []string
+		withError error
+	}{
+		"union of variables": {
+			path: "variables.tf",
+			existing: `variable "edgerc_path" {
+  type    = string
+  default = "~/.edgerc"
+}
+
+variable "config_section" {
+  type    = string
+  default = "default"
+}
+`,
+			generated: `variable "edgerc_path" {
+  type    = string
+  default = "~/.edgerc"
+}
+
+variable "config_section" {
+  type    = string
+  default = "test"
+}
+
+variable "contract_id" {
+  type    = string
+  default = "ctr_1"
+}
+`,
+			expected: `variable "edgerc_path" {
+  type    = string
+  default = "~/.edgerc"
+}
+
+variable "config_section" {
+  type    = string
+  default = "default"
+}
+
+variable "contract_id" {
+  type    = string
+  default = "ctr_1"
+}
+`,
+			warnings: []string{`variable "config_section" already exists in 'variables.tf', keeping existing definition`},
+		},
+		"import blocks": {
+			path: "imports.tf",
+			existing: `import {
+  to = akamai_property.a
+  id = "prp_1"
+}
+`,
+			generated: `import {
+  to = akamai_property.a
+  id = "prp_1"
+}
+
+import {
+  to = akamai_property.b
+  id = "prp_2"
+}
+`,
+			expected: `import {
+  to = akamai_property.a
+  id = "prp_1"
+}
+
+import {
+  to = akamai_property.b
+  id = "prp_2"
+}
+`,
+		},
+		"union of variables in JSON syntax": {
+			path: "variables.tf.json",
+			existing: `{
+  "variable": {
+    "config_section": {"type": "string", "default": "default"}
+  }
+}
+`,
+			generated: `{
+  "variable": {
+    "config_section": {"type": "string", "default": "test"},
+    "contract_id": {"type": "string", "default": "ctr_1"}
+  }
+}
+`,
+			expected: `{
+  "variable": {
+    "config_section": {
+      "type": "string",
+      "default": "default"
+    },
+    "contract_id": {
+      "type": "string",
+      "default": "ctr_1"
+    }
+  }
+}
+`,
+			warnings: []string{`variable "config_section" already exists in 'variables.tf.json', keeping existing definition`},
+		},
+		"resources and import blocks in JSON syntax": {
+			path: "main.tf.json",
+			existing: `{
+  "resource": {"akamai_property": {"a": {"name": "a"}}},
+  "import": {"to": "akamai_property.a", "id": "prp_1"}
+}
+`,
+			generated: `{
+  "resource": {"akamai_property": {"b": {"name": "b"}}, "akamai_iam_role": {"c": {"role_name": "c"}}},
+  "import": [{"to": "akamai_property.a", "id": "prp_1"}, {"to": "akamai_property.b", "id": "prp_2"}]
+}
+`,
+			expected: `{
+  "resource": {
+    "akamai_property": {
+      "a": {
+        "name": "a"
+      },
+      "b": {
+        "name": "b"
+      }
+    },
+    "akamai_iam_role": {
+      "c": {
+        "role_name": "c"
+      }
+    }
+  },
+  "import": [
+    {
+      "to": "akamai_property.a",
+      "id": "prp_1"
+    },
+    {
+      "to": "akamai_property.b",
+      "id": "prp_2"
+    }
+  ]
+}
+`,
+		},
+		"invalid existing file in JSON syntax": {
+			path:      "variables.tf.json",
+			existing:  `{"variable": `,
+			generated: `{"variable": {"b": {}}}`,
+			withError: ErrMerge,
+		},
+		"values of variables": {
+			path:      SecretsVarsFileName,
+			existing:  "a = \"1\"\nb = \"2\"\n",
+			generated: "b = \"3\"\nc = \"4\"\n",
+			expected:  "a = \"1\"\nb = \"2\"\nc = \"4\"\n",
+		},
+		"script lines": {
+			path:      "import.sh",
+			existing:  "terraform init\nterraform import akamai_property.a prp_1\n",
+			generated: "terraform init\nterraform import akamai_iam_role.b 1\n",
+			expected:  "terraform init\nterraform import akamai_property.a prp_1\nterraform import akamai_iam_role.b 1\n",
+		},
+		"long script lines": {
+			path:      "import.sh",
+			existing:  "terraform init\necho " + strings.Repeat("a", 100*1024) + "\n",
+			generated: "terraform init\necho " + strings.Repeat("b", 100*1024) + "\n",
+			expected:  "terraform init\necho " + strings.Repeat("a", 100*1024) + "\necho " + strings.Repeat("b", 100*1024) + "\n",
+		},
+		"versions are replaced": {
+			path: filepath.Join("property", VersionsFileName),
+			existing: `terraform {
+  required_providers {
+    akamai = {
+      source  = "akamai/akamai"
+      version = ">= 3.1.0"
+    }
+  }
+  required_version = ">= 1.0"
+}
+`,
+			generated: `terraform {
+  required_providers {
+    akamai = {
+      source  = "akamai/akamai"
+      version = ">= 6.4.0"
+    }
+  }
+  required_version = ">= 1.0"
+}
+`,
+			expected: `terraform {
+  required_providers {
+    akamai = {
+      source  = "akamai/akamai"
+      version = ">= 6.4.0"
+    }
+  }
+  required_version = ">= 1.0"
+}
+`,
+		},
+		"other files are replaced": {
+			path:      "rules.json",
+			existing:  `{"a": 1}`,
+			generated: `{"b": 2}`,
+			expected:  `{"b": 2}`,
+		},
+		"invalid existing file": {
+			path:      "variables.tf",
+			existing:  `variable "a" {`,
+			generated: `variable "b" {}`,
+			withError: ErrMerge,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var warnings []string
+			res, err := MergeFiles(test.path, []byte(test.existing), []byte(test.generated), func(msg string) {
+				warnings = append(warnings, msg)
+			})
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(res))
+			assert.Equal(t, test.warnings, warnings)
+		})
+	}
+}
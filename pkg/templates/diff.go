@@ -0,0 +1,214 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// ChangeType describes how generated content differs from the existing one
+type ChangeType string
+
+const (
+	// ChangeAdded marks a file, block or attribute which exists only in generated content
+	ChangeAdded ChangeType = "added"
+	// ChangeRemoved marks a file, block or attribute which exists only in existing content
+	ChangeRemoved ChangeType = "removed"
+	// ChangeModified marks a file or attribute with different existing and generated content
+	ChangeModified ChangeType = "modified"
+)
+
+type (
+	// Change is a single difference between existing and generated file
+	// Block and Attribute are empty for changes of the whole file, Attribute is a dot separated path of nested blocks,
+	// e.g. rules.behavior[1].options, and is empty for changes of the whole block
+	Change struct {
+		Type      ChangeType `json:"type"`
+		File      string     `json:"file"`
+		Block     string     `json:"block,omitempty"`
+		Attribute string     `json:"attribute,omitempty"`
+		Existing  string     `json:"existing,omitempty"`
+		Generated string     `json:"generated,omitempty"`
+	}
+
+	keyedBlock struct {
+		key   string
+		block *hclwrite.Block
+	}
+)
+
+// ErrDiff is returned when generated file cannot be compared with the existing one
+var ErrDiff = errors.New("comparing with existing file")
+
+// DiffFiles compares existing content of the file with the given path with the generated one
+// Terraform files are compared block by block and attribute by attribute, JSON files by their values
+// and other files line by line, ignoring surrounding white spaces
+func DiffFiles(path string, existing, generated []byte) ([]Change, error) {
+	switch filepath.Ext(path) {
+	case ".tf":
+		changes, err := diffHCL(path, existing, generated)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s': %s", ErrDiff, path, err)
+		}
+		return changes, nil
+	case ".json":
+		var existingValue, generatedValue interface{}
+		if json.Unmarshal(existing, &existingValue) == nil && json.Unmarshal(generated, &generatedValue) == nil {
+			if reflect.DeepEqual(existingValue, generatedValue) {
+				return nil, nil
+			}
+			return []Change{{Type: ChangeModified, File: path}}, nil
+		}
+	}
+	if bytes.Equal(bytes.TrimSpace(existing), bytes.TrimSpace(generated)) {
+		return nil, nil
+	}
+	return []Change{{Type: ChangeModified, File: path}}, nil
+}
+
+func diffHCL(path string, existing, generated []byte) ([]Change, error) {
+	existingFile, diags := hclwrite.ParseConfig(hclwrite.Format(existing), "existing", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	generatedFile, diags := hclwrite.ParseConfig(hclwrite.Format(generated), "generated", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	changes := diffAttributes(path, "", "", existingFile.Body(), generatedFile.Body())
+	existingBlocks, generatedBlocks := rootBlocks(existingFile.Body()), rootBlocks(generatedFile.Body())
+	generatedByKey := make(map[string]*hclwrite.Block, len(generatedBlocks))
+	for _, b := range generatedBlocks {
+		generatedByKey[b.key] = b.block
+	}
+	existingByKey := make(map[string]bool, len(existingBlocks))
+	for _, b := range existingBlocks {
+		existingByKey[b.key] = true
+		generatedBlock, ok := generatedByKey[b.key]
+		if !ok {
+			changes = append(changes, Change{Type: ChangeRemoved, File: path, Block: b.key})
+			continue
+		}
+		changes = append(changes, diffBody(path, b.key, "", b.block.Body(), generatedBlock.Body())...)
+	}
+	for _, b := range generatedBlocks {
+		if !existingByKey[b.key] {
+			changes = append(changes, Change{Type: ChangeAdded, File: path, Block: b.key})
+		}
+	}
+	return changes, nil
+}
+
+// rootBlocks returns top level blocks identified by their type and labels
+// import blocks are identified by the target address and other blocks without labels by their position
+func rootBlocks(body *hclwrite.Body) []keyedBlock {
+	var res []keyedBlock
+	counts := make(map[string]int)
+	for _, block := range body.Blocks() {
+		key := blockName(block)
+		if to := block.Body().GetAttribute("to"); block.Type() == "import" && to != nil {
+			key = fmt.Sprintf("import %s", expressionString(to))
+		} else if len(block.Labels()) == 0 {
+			key, counts[key] = fmt.Sprintf("%s[%d]", key, counts[key]), counts[key]+1
+		}
+		res = append(res, keyedBlock{key: key, block: block})
+	}
+	return res
+}
+
+func diffBody(path, block, prefix string, existing, generated *hclwrite.Body) []Change {
+	changes := diffAttributes(path, block, prefix, existing, generated)
+
+	existingBlocks, generatedBlocks := nestedBlocks(existing, generated), nestedBlocks(generated, existing)
+	generatedByKey := make(map[string]*hclwrite.Block, len(generatedBlocks))
+	for _, b := range generatedBlocks {
+		generatedByKey[b.key] = b.block
+	}
+	existingByKey := make(map[string]bool, len(existingBlocks))
+	for _, b := range existingBlocks {
+		existingByKey[b.key] = true
+		generatedBlock, ok := generatedByKey[b.key]
+		if !ok {
+			changes = append(changes, Change{Type: ChangeRemoved, File: path, Block: block, Attribute: prefix + b.key})
+			continue
+		}
+		changes = append(changes, diffBody(path, block, prefix+b.key+".", b.block.Body(), generatedBlock.Body())...)
+	}
+	for _, b := range generatedBlocks {
+		if !existingByKey[b.key] {
+			changes = append(changes, Change{Type: ChangeAdded, File: path, Block: block, Attribute: prefix + b.key})
+		}
+	}
+	return changes
+}
+
+// nestedBlocks returns blocks of the body identified by their type, labels and, if there are several of them
+// in any of the compared bodies, their position
+func nestedBlocks(body, other *hclwrite.Body) []keyedBlock {
+	names := func(body *hclwrite.Body) map[string]int {
+		res := make(map[string]int)
+		for _, block := range body.Blocks() {
+			res[strings.Join(append([]string{block.Type()}, block.Labels()...), ".")]++
+		}
+		return res
+	}
+	bodyNames, otherNames := names(body), names(other)
+
+	var res []keyedBlock
+	counts := make(map[string]int)
+	for _, block := range body.Blocks() {
+		key := strings.Join(append([]string{block.Type()}, block.Labels()...), ".")
+		if bodyNames[key] > 1 || otherNames[key] > 1 {
+			key, counts[key] = fmt.Sprintf("%s[%d]", key, counts[key]), counts[key]+1
+		}
+		res = append(res, keyedBlock{key: key, block: block})
+	}
+	return res
+}
+
+func diffAttributes(path, block, prefix string, existing, generated *hclwrite.Body) []Change {
+	existingAttributes, generatedAttributes := existing.Attributes(), generated.Attributes()
+	names := make([]string, 0, len(existingAttributes)+len(generatedAttributes))
+	for name := range existingAttributes {
+		names = append(names, name)
+	}
+	for name := range generatedAttributes {
+		if _, ok := existingAttributes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []Change
+	for _, name := range names {
+		change := Change{File: path, Block: block, Attribute: prefix + name}
+		existingAttribute, inExisting := existingAttributes[name]
+		generatedAttribute, inGenerated := generatedAttributes[name]
+		switch {
+		case !inGenerated:
+			change.Type, change.Existing = ChangeRemoved, expressionString(existingAttribute)
+		case !inExisting:
+			change.Type, change.Generated = ChangeAdded, expressionString(generatedAttribute)
+		default:
+			change.Type, change.Existing, change.Generated = ChangeModified, expressionString(existingAttribute), expressionString(generatedAttribute)
+			if change.Existing == change.Generated {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func expressionString(attribute *hclwrite.Attribute) string {
+	return strings.TrimSpace(string(attribute.Expr().BuildTokens(nil).Bytes()))
+}
@@ -0,0 +1,118 @@
+package templates
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffFiles(t *testing.T) {
+	tests := map[string]struct {
+		path      string
+		existing  string
+		generated string
+		expected  []Change
+		withError error
+	}{
+		"no changes in tf file": {
+			path:      "property.tf",
+			existing:  "resource \"akamai_property\" \"p\" {\n  name = \"p\"\n  rule_format = \"v2024-01-01\"\n}\n",
+			generated: "resource \"akamai_property\" \"p\" {\n  rule_format   = \"v2024-01-01\"\n  name  = \"p\"\n}\n",
+		},
+		"changed attributes": {
+			path: "property.tf",
+			existing: `
+terraform {
+  required_version = ">= 1.0"
+}
+
+resource "akamai_property" "p" {
+  name        = "p"
+  rule_format = "v2023-01-05"
+  old         = true
+  hostnames {
+    cname_from = "a.com"
+  }
+}
+`,
+			generated: `
+terraform {
+  required_version = ">= 1.0"
+}
+
+resource "akamai_property" "p" {
+  name        = "p"
+  rule_format = "v2024-01-01"
+  new         = 1
+  hostnames {
+    cname_from = "a.com"
+  }
+  hostnames {
+    cname_from = "b.com"
+  }
+}
+`,
+			expected: []Change{
+				{Type: ChangeAdded, File: "property.tf", Block: `resource "akamai_property" "p"`, Attribute: "new", Generated: "1"},
+				{Type: ChangeRemoved, File: "property.tf", Block: `resource "akamai_property" "p"`, Attribute: "old", Existing: "true"},
+				{Type: ChangeModified, File: "property.tf", Block: `resource "akamai_property" "p"`, Attribute: "rule_format", Existing: `"v2023-01-05"`, Generated: `"v2024-01-01"`},
+				{Type: ChangeAdded, File: "property.tf", Block: `resource "akamai_property" "p"`, Attribute: "hostnames[1]"},
+			},
+		},
+		"nested blocks": {
+			path:      "rules.tf",
+			existing:  "data \"rules\" \"r\" {\n  rule {\n    behavior {\n      name = \"a\"\n    }\n    behavior {\n      name = \"b\"\n    }\n  }\n}\n",
+			generated: "data \"rules\" \"r\" {\n  rule {\n    behavior {\n      name = \"a\"\n    }\n    behavior {\n      name = \"c\"\n    }\n  }\n}\n",
+			expected: []Change{
+				{Type: ChangeModified, File: "rules.tf", Block: `data "rules" "r"`, Attribute: "rule.behavior[1].name", Existing: `"b"`, Generated: `"c"`},
+			},
+		},
+		"added and removed blocks": {
+			path:      "imports.tf",
+			existing:  "import {\n  id = \"1\"\n  to = akamai_a.a\n}\n\nvariable \"old\" {}\n",
+			generated: "import {\n  id = \"2\"\n  to = akamai_a.a\n}\n\nimport {\n  id = \"3\"\n  to = akamai_b.b\n}\n",
+			expected: []Change{
+				{Type: ChangeModified, File: "imports.tf", Block: "import akamai_a.a", Attribute: "id", Existing: `"1"`, Generated: `"2"`},
+				{Type: ChangeRemoved, File: "imports.tf", Block: `variable "old"`},
+				{Type: ChangeAdded, File: "imports.tf", Block: "import akamai_b.b"},
+			},
+		},
+		"same json": {
+			path:      "rules/main.json",
+			existing:  `{"a": 1, "b": [1, 2]}`,
+			generated: "{\n  \"b\": [1, 2],\n  \"a\": 1\n}\n",
+		},
+		"changed json": {
+			path:      "rules/main.json",
+			existing:  `{"a": 1}`,
+			generated: `{"a": 2}`,
+			expected:  []Change{{Type: ChangeModified, File: "rules/main.json"}},
+		},
+		"changed script": {
+			path:      "import.sh",
+			existing:  "terraform import a.b 1\n",
+			generated: "terraform import a.b 2\n",
+			expected:  []Change{{Type: ChangeModified, File: "import.sh"}},
+		},
+		"invalid tf file": {
+			path:      "property.tf",
+			existing:  "resource {",
+			generated: "",
+			withError: ErrDiff,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			changes, err := DiffFiles(test.path, []byte(test.existing), []byte(test.generated))
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, changes)
+		})
+	}
+}
@@ -0,0 +1,479 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli-terraform/pkg/tools"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Format is the syntax of generated terraform configuration
+type Format string
+
+const (
+	// FormatHCL generates configuration in terraform native syntax, e.g. main.tf
+	FormatHCL Format = "hcl"
+	// FormatJSON generates configuration in terraform JSON syntax, e.g. main.tf.json
+	FormatJSON Format = "json"
+)
+
+type (
+	// JSONSink converts terraform files written to it into terraform JSON syntax and passes them to the wrapped sink
+	// Terraform files get .json suffix, e.g. main.tf is written as main.tf.json, other files are passed unchanged
+	// Existing terraform files in JSON syntax are read back as terraform files in native syntax, see ReadFile
+	JSONSink struct {
+		OutputSink
+	}
+
+	// jsonObject is a JSON object keeping the order of its fields
+	jsonObject struct {
+		fields []jsonField
+	}
+
+	jsonField struct {
+		key   string
+		value interface{}
+	}
+)
+
+var (
+	// ErrInvalidFormat is returned when unknown format is requested
+	ErrInvalidFormat = errors.New("invalid format")
+	// ErrConvert is returned when terraform configuration cannot be converted into JSON syntax
+	ErrConvert = errors.New("converting to terraform JSON syntax")
+	// ErrConvertFromJSON is returned when existing terraform configuration in JSON syntax cannot be read
+	ErrConvertFromJSON = errors.New("converting from terraform JSON syntax")
+
+	_ OutputSink = JSONSink{}
+	_ reader     = JSONSink{}
+
+	// referenceAttributes hold references, lists of references or type constraints, which are written in JSON syntax
+	// without interpolation, grouped by the type of the block in which they appear
+	referenceAttributes = map[string]map[string]bool{
+		"resource": {"depends_on": true, "provider": true},
+		"data":     {"depends_on": true, "provider": true},
+		"module":   {"depends_on": true},
+		"output":   {"depends_on": true},
+		"variable": {"type": true},
+		"import":   {"to": true, "provider": true},
+		"moved":    {"from": true, "to": true},
+		"lifecycle": {
+			"ignore_changes":       true,
+			"replace_triggered_by": true,
+		},
+	}
+)
+
+// ParseFormat returns Format for the given name, empty name results in FormatHCL
+func ParseFormat(name string) (Format, error) {
+	return tools.ParseEnum(name, FormatHCL, ErrInvalidFormat, FormatHCL, FormatJSON)
+}
+
+// Check checks target paths of the files
+func (s JSONSink) Check(paths ...string) error {
+	targets := make([]string, 0, len(paths))
+	for _, path := range paths {
+		targets = append(targets, jsonPath(path))
+	}
+	return s.OutputSink.Check(targets...)
+}
+
+// WriteFile converts terraform file into JSON syntax and writes it
+func (s JSONSink) WriteFile(path string, content []byte) error {
+	if filepath.Ext(path) != ".tf" {
+		return s.OutputSink.WriteFile(path, content)
+	}
+	converted, err := ConvertToJSON(content)
+	if err != nil {
+		return fmt.Errorf("%w: '%s': %s", ErrConvert, path, err)
+	}
+	return s.OutputSink.WriteFile(jsonPath(path), converted)
+}
+
+// Unwrap returns the wrapped sink
+func (s JSONSink) Unwrap() OutputSink {
+	return s.OutputSink
+}
+
+// ReadFile returns content of the file in the wrapped sink, terraform file is read from its counterpart in JSON syntax,
+// if it exists, converted into native syntax, e.g. main.tf from main.tf.json, so that it is read in the same way
+// as with HCL format, e.g. to keep the higher provider version of versions.tf
+func (s JSONSink) ReadFile(path string) ([]byte, error) {
+	if filepath.Ext(path) == ".tf" {
+		content, err := ReadOutput(s.OutputSink, jsonPath(path))
+		if err == nil {
+			converted, err := convertFromJSON(content)
+			if err != nil {
+				return nil, fmt.Errorf("%w: '%s': %s", ErrConvertFromJSON, jsonPath(path), err)
+			}
+			return converted, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return ReadOutput(s.OutputSink, path)
+}
+
+// Glob returns files of the wrapped sink matching the pattern, terraform files in JSON syntax match patterns
+// of terraform files under the name in native syntax, e.g. main.tf.json is returned as main.tf for *.tf
+func (s JSONSink) Glob(pattern string) ([]string, error) {
+	matches, err := GlobOutput(s.OutputSink, pattern)
+	if err != nil || filepath.Ext(pattern) != ".tf" {
+		return matches, err
+	}
+	jsonMatches, err := GlobOutput(s.OutputSink, jsonPath(pattern))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range jsonMatches {
+		matches = append(matches, strings.TrimSuffix(path, ".json"))
+	}
+	return globUnion(pattern, matches, nil)
+}
+
+func jsonPath(path string) string {
+	if filepath.Ext(path) == ".tf" {
+		return path + ".json"
+	}
+	return path
+}
+
+// ConvertToJSON converts terraform configuration in native syntax into JSON syntax
+// Blocks become nested objects keyed by block type and labels, constant values are written as JSON values
+// and other expressions as strings with interpolation sequence, e.g. "${var.contract_id}"
+func ConvertToJSON(src []byte) ([]byte, error) {
+	file, diags := hclsyntax.ParseConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unexpected body type %T", file.Body)
+	}
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(convertBody(body, "", src)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func convertBody(body *hclsyntax.Body, blockType string, src []byte) *jsonObject {
+	res := &jsonObject{}
+
+	attributes := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attribute := range body.Attributes {
+		attributes = append(attributes, attribute)
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].SrcRange.Start.Byte < attributes[j].SrcRange.Start.Byte
+	})
+	for _, attribute := range attributes {
+		res.set(attribute.Name, convertExpression(attribute.Expr, src, referenceAttributes[blockType][attribute.Name]))
+	}
+
+	for _, block := range body.Blocks {
+		parent, key := res, block.Type
+		for _, label := range block.Labels {
+			parent, key = parent.object(key), label
+		}
+		value := convertBody(block.Body, block.Type, src)
+		switch existing := parent.get(key).(type) {
+		case nil:
+			parent.set(key, value)
+		case *jsonObject:
+			parent.set(key, []interface{}{existing, value})
+		case []interface{}:
+			parent.set(key, append(existing, value))
+		}
+	}
+	return res
+}
+
+func convertExpression(expr hclsyntax.Expression, src []byte, reference bool) interface{} {
+	switch e := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		res := make([]interface{}, 0, len(e.Exprs))
+		for _, item := range e.Exprs {
+			res = append(res, convertExpression(item, src, reference))
+		}
+		return res
+	case *hclsyntax.ObjectConsExpr:
+		res := &jsonObject{}
+		for _, item := range e.Items {
+			key := hcl.ExprAsKeyword(item.KeyExpr)
+			if key == "" {
+				value, diags := item.KeyExpr.Value(nil)
+				if diags.HasErrors() || !value.Type().Equals(cty.String) || !value.IsKnown() || value.IsNull() {
+					return interpolation(expr, src)
+				}
+				key = value.AsString()
+			}
+			res.set(key, convertExpression(item.ValueExpr, src, reference))
+		}
+		return res
+	}
+
+	if len(expr.Variables()) == 0 {
+		if value, diags := expr.Value(nil); !diags.HasErrors() && value.IsWhollyKnown() {
+			return convertValue(value)
+		}
+	}
+	if reference {
+		return string(bytes.TrimSpace(expr.Range().SliceBytes(src)))
+	}
+
+	switch e := expr.(type) {
+	case *hclsyntax.TemplateWrapExpr:
+		return interpolation(e.Wrapped, src)
+	case *hclsyntax.TemplateExpr:
+		var sb strings.Builder
+		for _, part := range e.Parts {
+			if value, diags := part.Value(nil); len(part.Variables()) == 0 && !diags.HasErrors() && value.Type().Equals(cty.String) && value.IsKnown() && !value.IsNull() {
+				sb.WriteString(escapeTemplate(value.AsString()))
+				continue
+			}
+			sb.WriteString(interpolation(part, src))
+		}
+		return sb.String()
+	}
+	return interpolation(expr, src)
+}
+
+func interpolation(expr hclsyntax.Expression, src []byte) string {
+	return fmt.Sprintf("${%s}", bytes.TrimSpace(expr.Range().SliceBytes(src)))
+}
+
+func convertValue(value cty.Value) interface{} {
+	if value.IsNull() {
+		return nil
+	}
+	switch t := value.Type(); {
+	case t.Equals(cty.String):
+		return escapeTemplate(value.AsString())
+	case t.Equals(cty.Number):
+		return json.Number(value.AsBigFloat().Text('f', -1))
+	case t.Equals(cty.Bool):
+		return value.True()
+	case t.IsListType() || t.IsSetType() || t.IsTupleType():
+		res := make([]interface{}, 0, value.LengthInt())
+		for it := value.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			res = append(res, convertValue(v))
+		}
+		return res
+	case t.IsMapType() || t.IsObjectType():
+		res := &jsonObject{}
+		for it := value.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			res.set(k.AsString(), convertValue(v))
+		}
+		return res
+	}
+	return nil
+}
+
+// escapeTemplate escapes template sequences, as all strings in terraform JSON syntax are templates
+func escapeTemplate(s string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(s)
+}
+
+func (o *jsonObject) get(key string) interface{} {
+	for _, f := range o.fields {
+		if f.key == key {
+			return f.value
+		}
+	}
+	return nil
+}
+
+func (o *jsonObject) set(key string, value interface{}) {
+	for i, f := range o.fields {
+		if f.key == key {
+			o.fields[i].value = value
+			return
+		}
+	}
+	o.fields = append(o.fields, jsonField{key: key, value: value})
+}
+
+// object returns nested object stored under the key, creating it if needed
+func (o *jsonObject) object(key string) *jsonObject {
+	if obj, ok := o.get(key).(*jsonObject); ok {
+		return obj
+	}
+	obj := &jsonObject{}
+	o.set(key, obj)
+	return obj
+}
+
+// MarshalJSON writes fields of the object in the order they were added
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, f := range o.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := marshalJSON(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshalJSON(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// convertFromJSON converts terraform configuration in JSON syntax into native syntax
+// Top level blocks keep their types and labels, see jsonBlockLabels, while nested blocks become attributes with object
+// values, which is enough to read declarations and settings of the configuration, but not to apply it
+func convertFromJSON(src []byte) ([]byte, error) {
+	root, err := decodeJSONObject(src)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, f := range root.fields {
+		if f.key == "//" {
+			continue
+		}
+		if err := writeJSONBlocks(&buf, f.key, nil, f.value.(json.RawMessage)); err != nil {
+			return nil, err
+		}
+	}
+	return hclwrite.Format(buf.Bytes()), nil
+}
+
+// writeJSONBlocks writes blocks of the type in native syntax, value holds objects keyed by the remaining labels
+func writeJSONBlocks(buf *bytes.Buffer, blockType string, labels []string, value json.RawMessage) error {
+	items, err := jsonItems(value)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		obj, err := decodeJSONObject(item)
+		if err != nil {
+			return err
+		}
+		if len(labels) < jsonBlockLabels[blockType] {
+			for _, f := range obj.fields {
+				if err := writeJSONBlocks(buf, blockType, append(labels[:len(labels):len(labels)], f.key), f.value.(json.RawMessage)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		buf.WriteString(blockType)
+		for _, label := range labels {
+			quoted, err := marshalJSON(label)
+			if err != nil {
+				return err
+			}
+			buf.WriteByte(' ')
+			buf.Write(quoted)
+		}
+		buf.WriteString(" {\n")
+		for _, f := range obj.fields {
+			if !hclsyntax.ValidIdentifier(f.key) {
+				continue
+			}
+			expr, err := jsonExpression(f.value.(json.RawMessage))
+			if err != nil {
+				return err
+			}
+			buf.WriteString(f.key + " = " + expr + "\n")
+		}
+		buf.WriteString("}\n\n")
+	}
+	return nil
+}
+
+// jsonExpression returns JSON value as an expression in native syntax, strings keep template sequences,
+// e.g. "${var.contract_id}", which are interpreted the same way in both syntaxes
+func jsonExpression(value json.RawMessage) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return "", err
+	}
+	return nativeExpression(decoded)
+}
+
+func nativeExpression(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			expr, err := nativeExpression(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, expr)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var sb strings.Builder
+		sb.WriteString("{\n")
+		for _, key := range keys {
+			name := key
+			if !hclsyntax.ValidIdentifier(key) {
+				quoted, err := marshalJSON(key)
+				if err != nil {
+					return "", err
+				}
+				name = string(quoted)
+			}
+			expr, err := nativeExpression(v[key])
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(name + " = " + expr + "\n")
+		}
+		sb.WriteString("}")
+		return sb.String(), nil
+	case string:
+		quoted, err := marshalJSON(v)
+		return string(quoted), err
+	}
+	return fmt.Sprint(value), nil
+}
+
+// marshalJSON works like json.Marshal, but does not escape HTML characters, e.g. in version constraints
+func marshalJSON(v interface{}) ([]byte, error) {
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
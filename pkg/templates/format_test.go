@@ -0,0 +1,248 @@
+package templates
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  Format
+		withError error
+	}{
+		"default": {
+			given:    "",
+			expected: FormatHCL,
+		},
+		"json": {
+			given:    "json",
+			expected: FormatJSON,
+		},
+		"invalid": {
+			given:     "yaml",
+			withError: ErrInvalidFormat,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			format, err := ParseFormat(test.given)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, format)
+		})
+	}
+}
+
+func TestConvertToJSON(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  string
+		withError bool
+	}{
+		"blocks and attributes": {
+			given: `
+terraform {
+  required_providers {
+    akamai = {
+      source  = "akamai/akamai"
+      version = ">= 6.4.0"
+    }
+  }
+}
+
+resource "akamai_property" "my-property" {
+  name        = "my-property"
+  contract_id = var.contract_id
+  product_id  = "prd_${var.product}"
+  rules       = data.akamai_property_rules_template.rules.json
+  hostnames {
+    cname_from = "a.com"
+  }
+  hostnames {
+    cname_from = "b.com"
+  }
+  depends_on = [akamai_cp_code.cp_code]
+}
+
+variable "contract_id" {
+  type    = string
+  default = null
+}
+`,
+			expected: `{
+  "terraform": {
+    "required_providers": {
+      "akamai": {
+        "source": "akamai/akamai",
+        "version": ">= 6.4.0"
+      }
+    }
+  },
+  "resource": {
+    "akamai_property": {
+      "my-property": {
+        "name": "my-property",
+        "contract_id": "${var.contract_id}",
+        "product_id": "prd_${var.product}",
+        "rules": "${data.akamai_property_rules_template.rules.json}",
+        "depends_on": [
+          "akamai_cp_code.cp_code"
+        ],
+        "hostnames": [
+          {
+            "cname_from": "a.com"
+          },
+          {
+            "cname_from": "b.com"
+          }
+        ]
+      }
+    }
+  },
+  "variable": {
+    "contract_id": {
+      "type": "string",
+      "default": null
+    }
+  }
+}
+`,
+		},
+		"values": {
+			given: `
+locals {
+  number   = 1.5
+  flag     = true
+  list     = ["a", var.b]
+  escaped  = "$${not_interpolated}"
+  heredoc  = <<-EOT
+    line
+  EOT
+  function = jsonencode({ a = 1 })
+  other    = "b"
+}
+
+import {
+  id = "1"
+  to = akamai_a.a
+}
+
+import {
+  id = "2"
+  to = akamai_a.b
+}
+`,
+			expected: `{
+  "locals": {
+    "number": 1.5,
+    "flag": true,
+    "list": [
+      "a",
+      "${var.b}"
+    ],
+    "escaped": "$${not_interpolated}",
+    "heredoc": "line\n",
+    "function": "${jsonencode({ a = 1 })}",
+    "other": "b"
+  },
+  "import": [
+    {
+      "id": "1",
+      "to": "akamai_a.a"
+    },
+    {
+      "id": "2",
+      "to": "akamai_a.b"
+    }
+  ]
+}
+`,
+		},
+		"invalid configuration": {
+			given:     "resource {",
+			withError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := ConvertToJSON([]byte(test.given))
+			if test.withError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(res))
+		})
+	}
+}
+
+func TestJSONSink(t *testing.T) {
+	memory := NewMemorySink()
+	sink := JSONSink{OutputSink: memory}
+
+	require.NoError(t, sink.Check("main.tf", "import.sh"))
+	require.NoError(t, sink.WriteFile("main.tf", []byte("variable \"a\" {}\n")))
+	require.NoError(t, sink.WriteFile("import.sh", []byte("terraform import a.b 1\n")))
+	assert.True(t, errors.Is(sink.WriteFile("invalid.tf", []byte("variable {")), ErrConvert))
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, []string{"import.sh", "main.tf.json"}, memory.Files())
+	content, _ := memory.File("main.tf.json")
+	assert.Equal(t, "{\n  \"variable\": {\n    \"a\": {}\n  }\n}\n", string(content))
+}
+
+func TestJSONSinkRead(t *testing.T) {
+	memory := NewMemorySink()
+	sink := JSONSink{OutputSink: memory}
+	require.NoError(t, memory.WriteFile("main.tf.json", []byte(`{
+  "//": "comment",
+  "resource": {"akamai_property": {"a": {"name": "${var.name}", "rules": {"b": [1, true, null]}}}},
+  "variable": {"name": {}},
+  "import": [{"to": "akamai_property.a", "id": "prp_1"}]
+}`)))
+	require.NoError(t, memory.WriteFile("other.tf", []byte("locals {}\n")))
+	require.NoError(t, memory.WriteFile("invalid.tf.json", []byte("{")))
+
+	content, err := sink.ReadFile("main.tf")
+	require.NoError(t, err)
+	assert.Equal(t, `resource "akamai_property" "a" {
+  name = "${var.name}"
+  rules = {
+    b = [1, true, null]
+  }
+}
+
+variable "name" {
+}
+
+import {
+  to = "akamai_property.a"
+  id = "prp_1"
+}
+
+`, string(content))
+	content, err = sink.ReadFile("other.tf")
+	require.NoError(t, err)
+	assert.Equal(t, "locals {}\n", string(content))
+	_, err = sink.ReadFile("invalid.tf")
+	assert.True(t, errors.Is(err, ErrConvertFromJSON), err)
+
+	paths, err := sink.Glob("*.tf")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"invalid.tf", "main.tf", "other.tf"}, paths)
+
+	// the higher provider version of existing versions.tf.json is kept
+	require.NoError(t, Versions{}.WriteVersions(sink, "", "9.0.0"))
+	require.NoError(t, Versions{}.WriteVersions(sink, "", "6.0.0"))
+	content, _ = memory.File(VersionsFileName + ".json")
+	assert.Contains(t, string(content), `">= 9.0.0"`)
+}
@@ -0,0 +1,122 @@
+package templates
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/akamai/cli-terraform/pkg/tools"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ImportStyle defines the way in which import of exported resources is generated
+type ImportStyle string
+
+const (
+	// ImportStyleScript generates a shell script with `terraform import` commands
+	ImportStyleScript ImportStyle = "script"
+	// ImportStyleBlocks generates a terraform file with `import` blocks, which requires terraform 1.5 or later
+	ImportStyleBlocks ImportStyle = "blocks"
+
+	importCommand = "terraform import "
+)
+
+// importCommands are import commands of the supported engines
+var importCommands = []string{importCommand, EngineOpenTofu.Command() + " import "}
+
+var (
+	// ErrInvalidImportStyle is returned when unknown import style is requested
+	ErrInvalidImportStyle = errors.New("invalid import style")
+	// ErrImportBlocks is returned when import script cannot be converted into import blocks
+	ErrImportBlocks = errors.New("converting import script to import blocks")
+)
+
+// ParseImportStyle returns ImportStyle for the given name, empty name results in ImportStyleScript
+func ParseImportStyle(name string) (ImportStyle, error) {
+	return tools.ParseEnum(name, ImportStyleScript, ErrInvalidImportStyle, ImportStyleScript, ImportStyleBlocks)
+}
+
+// FileName returns name of the file with imports for the given import script name
+// For ImportStyleBlocks the extension is replaced with .tf and the name is pluralized, e.g. import.sh -> imports.tf
+func (s ImportStyle) FileName(scriptName string) string {
+	if s != ImportStyleBlocks {
+		return scriptName
+	}
+	name := strings.TrimSuffix(scriptName, filepath.Ext(scriptName))
+	if !strings.HasSuffix(name, "s") {
+		name += "s"
+	}
+	return name + ".tf"
+}
+
+// ImportBlocks converts `terraform import` commands from the given script into terraform import blocks
+// Lines other than import commands, such as `terraform init`, are skipped
+func ImportBlocks(script []byte) ([]byte, error) {
+	file := hclwrite.NewEmptyFile()
+	body := file.Body()
+
+	scanner := bufio.NewScanner(bytes.NewReader(script))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		_, args, found := cutImportCommand(strings.TrimSpace(scanner.Text()))
+		if !found {
+			continue
+		}
+		address, id, err := parseImportCommand(args)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrImportBlocks, lineNum, err)
+		}
+		traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("%w: line %d: invalid resource address '%s': %s", ErrImportBlocks, lineNum, address, diags.Error())
+		}
+
+		if len(body.Blocks()) > 0 {
+			body.AppendNewline()
+		}
+		block := body.AppendNewBlock("import", nil).Body()
+		block.SetAttributeTraversal("to", traversal)
+		block.SetAttributeValue("id", cty.StringVal(id))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrImportBlocks, err)
+	}
+
+	return file.Bytes(), nil
+}
+
+// cutImportCommand returns import command the line starts with, e.g. `terraform import `, and its arguments
+func cutImportCommand(line string) (string, string, bool) {
+	for _, command := range importCommands {
+		if args, found := strings.CutPrefix(line, command); found {
+			return command, args, true
+		}
+	}
+	return "", "", false
+}
+
+func parseImportCommand(args string) (string, string, error) {
+	address, id, found := strings.Cut(strings.TrimSpace(args), " ")
+	id = strings.TrimSpace(id)
+	if !found || id == "" {
+		return "", "", fmt.Errorf("missing import id in '%s'", args)
+	}
+	if strings.HasPrefix(id, `"`) {
+		unquoted, err := strconv.Unquote(id)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid import id %s: %s", id, err)
+		}
+		id = unquoted
+	}
+	return address, id, nil
+}
+
+func isImportsTemplate(templateName string) bool {
+	return strings.HasSuffix(templateName, "imports.tmpl")
+}
@@ -0,0 +1,143 @@
+package templates
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImportStyle(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  ImportStyle
+		withError error
+	}{
+		"default": {
+			given:    "",
+			expected: ImportStyleScript,
+		},
+		"script": {
+			given:    "script",
+			expected: ImportStyleScript,
+		},
+		"blocks": {
+			given:    "blocks",
+			expected: ImportStyleBlocks,
+		},
+		"invalid": {
+			given:     "foo",
+			withError: ErrInvalidImportStyle,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			style, err := ParseImportStyle(test.given)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, style)
+		})
+	}
+}
+
+func TestImportStyleFileName(t *testing.T) {
+	tests := map[string]struct {
+		style    ImportStyle
+		given    string
+		expected string
+	}{
+		"script keeps name": {
+			style:    ImportStyleScript,
+			given:    "import.sh",
+			expected: "import.sh",
+		},
+		"blocks": {
+			style:    ImportStyleBlocks,
+			given:    "import.sh",
+			expected: "imports.tf",
+		},
+		"blocks already plural": {
+			style:    ImportStyleBlocks,
+			given:    "imports.sh",
+			expected: "imports.tf",
+		},
+		"blocks with prefix": {
+			style:    ImportStyleBlocks,
+			given:    "appsec-import.sh",
+			expected: "appsec-imports.tf",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.style.FileName(test.given))
+		})
+	}
+}
+
+func TestImportBlocks(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  string
+		withError error
+	}{
+		"multiple imports": {
+			given: `terraform init
+terraform import akamai_property.test prp_1,ctr_1,grp_1,LATEST
+    terraform import akamai_iam_role.role_id_1 1
+terraform import module.security.akamai_appsec_configuration.config 123`,
+			expected: `import {
+  to = akamai_property.test
+  id = "prp_1,ctr_1,grp_1,LATEST"
+}
+
+import {
+  to = akamai_iam_role.role_id_1
+  id = "1"
+}
+
+import {
+  to = module.security.akamai_appsec_configuration.config
+  id = "123"
+}
+`,
+		},
+		"quoted id": {
+			given: `terraform import akamai_gtm_property.test "test.akadns.net:prop name"`,
+			expected: `import {
+  to = akamai_gtm_property.test
+  id = "test.akadns.net:prop name"
+}
+`,
+		},
+		"only init": {
+			given:    "terraform init\n",
+			expected: "",
+		},
+		"missing id": {
+			given:     "terraform import akamai_property.test",
+			withError: ErrImportBlocks,
+		},
+		"invalid address": {
+			given:     "terraform import akamai_property.1test 1",
+			withError: ErrImportBlocks,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := ImportBlocks([]byte(test.given))
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(res))
+		})
+	}
+}
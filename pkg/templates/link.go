@@ -0,0 +1,485 @@
+package templates
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type (
+	// Link is a literal ID of an object in a terraform file replaced with a reference to the resource
+	// managing the object, which is declared in the same module
+	Link struct {
+		// File is the slash separated path of the changed file
+		File string `json:"file"`
+		// Block is the address of the top level block with the attribute, e.g. akamai_edge_hostname.www
+		Block string `json:"block"`
+		// Attribute is the path of the attribute within the block, e.g. rules_v2024_08_13.behavior.edge_worker.edge_worker_id
+		Attribute string `json:"attribute"`
+		// Value is the replaced literal
+		Value string `json:"value"`
+		// Reference is the expression which replaced the literal, e.g. akamai_edgeworker.edgeworker.id
+		Reference string `json:"reference"`
+	}
+
+	// linkKind is the kind of objects which are linked by their IDs
+	linkKind string
+
+	// linkRule describes an attribute holding a literal ID of an object which may be managed by a resource
+	// declared in the same module
+	linkRule struct {
+		// block is the type of the block with the attribute, for top level blocks the type of the resource,
+		// empty block matches any block
+		block     string
+		attribute string
+		// key is the attribute of the same block whose literal value identifies the object, the attribute itself if empty
+		key  string
+		kind linkKind
+	}
+
+	// linkTarget describes resources managing objects of the kind, which are identified by import IDs
+	linkTarget struct {
+		kind linkKind
+		// id returns ID of the object from the import ID of the resource and the address of the resource managing
+		// the object, if it is other than the imported one, e.g. policy of a policy activation
+		id func(importID string, body *hclwrite.Body) (string, string)
+	}
+
+	// linkKey identifies an object of the kind
+	linkKey struct {
+		kind linkKind
+		id   string
+	}
+
+	// linker rewrites literal IDs within a single module
+	linker struct {
+		// index holds addresses of the resources managing objects, an empty address marks objects managed by
+		// more than one resource, which are not linked
+		index map[linkKey]string
+		// properties are hostnames of the declared akamai_property resources
+		properties []propertyHostnames
+		// defaults are default values of the declared variables by their names
+		defaults map[string]*hclwrite.Attribute
+		links    []Link
+	}
+
+	// propertyHostnames are cname_from values of hostnames blocks of akamai_property resource with the address
+	propertyHostnames struct {
+		address   string
+		hostnames []string
+	}
+)
+
+const (
+	linkEnrollment          linkKind = "enrollment"
+	linkCloudletsPolicy     linkKind = "cloudlets policy"
+	linkCloudletsPolicyName linkKind = "cloudlets policy name"
+	linkEdgeWorker          linkKind = "edgeworker"
+	linkPolicySet           linkKind = "policy set"
+)
+
+var (
+	// linkRules lists attributes rewritten by LinkFiles, the first rule resolving the attribute is used
+	linkRules = []linkRule{
+		{block: "akamai_edge_hostname", attribute: "certificate", kind: linkEnrollment},
+		{block: "cloudlet_policy", attribute: "id", kind: linkCloudletsPolicy},
+		{block: "cloudlet_policy", attribute: "id", key: "name", kind: linkCloudletsPolicyName},
+		{attribute: "cloudlet_shared_policy", kind: linkCloudletsPolicy},
+		{block: "edge_worker", attribute: "edge_worker_id", kind: linkEdgeWorker},
+		{block: "image_manager", attribute: "policy_set", kind: linkPolicySet},
+		{block: "image_manager_video", attribute: "policy_set", kind: linkPolicySet},
+	}
+
+	// linkTargets are resources which can be referenced by linked attributes, by resource type
+	linkTargets = map[string]linkTarget{
+		"akamai_cps_dv_enrollment":           {kind: linkEnrollment, id: importIDField(",")},
+		"akamai_cps_third_party_enrollment":  {kind: linkEnrollment, id: importIDField(",")},
+		"akamai_cloudlets_policy":            {kind: linkCloudletsPolicyName, id: importIDField("")},
+		"akamai_cloudlets_policy_activation": {kind: linkCloudletsPolicy, id: activatedPolicy},
+		"akamai_edgeworker":                  {kind: linkEdgeWorker, id: importIDField("")},
+		"akamai_imaging_policy_set":          {kind: linkPolicySet, id: importIDField(":")},
+	}
+)
+
+// LinkFiles replaces literal IDs in terraform files with references to resources managing the identified objects,
+// when they are declared in the same module, e.g. certificate of an edge hostname with the ID of the CPS enrollment
+// exported into the same directory
+// Hostnames passed to modules, e.g. the security module of exported security configuration, are replaced with
+// hostnames of the properties declared in the same module, see linker.linkHostnames
+// Resources are identified by the import IDs found in import blocks and import scripts of the module. Files are given
+// and returned by slash separated paths, only changed files are returned together with all replaced literals
+func LinkFiles(files map[string][]byte) (map[string][]byte, []Link) {
+	modules := make(map[string][]string)
+	for name := range files {
+		dir := path.Dir(name)
+		modules[dir] = append(modules[dir], name)
+	}
+	dirs := make([]string, 0, len(modules))
+	for dir, names := range modules {
+		sort.Strings(names)
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	changed := make(map[string][]byte)
+	var links []Link
+	for _, dir := range dirs {
+		parsed := make(map[string]*hclwrite.File)
+		bodies := make(map[string]*hclwrite.Body)
+		imports := make(map[string]string)
+		defaults := make(map[string]*hclwrite.Attribute)
+		for _, name := range modules[dir] {
+			declarations := reportFile{ReportFile: ReportFile{Path: name}}
+			switch path.Ext(name) {
+			case ".tf":
+				file, diags := hclwrite.ParseConfig(files[name], name, hcl.InitialPos)
+				if diags.HasErrors() {
+					continue
+				}
+				parsed[name] = file
+				for _, block := range file.Body().Blocks() {
+					switch {
+					case block.Type() == "resource" && len(block.Labels()) == 2:
+						bodies[strings.Join(block.Labels(), ".")] = block.Body()
+					case block.Type() == "variable" && len(block.Labels()) == 1:
+						if attribute := block.Body().GetAttribute("default"); attribute != nil {
+							defaults[block.Labels()[0]] = attribute
+						}
+					}
+				}
+				declarations.collectDeclarations(files[name])
+			case ".sh", ".script":
+				declarations.collectImportCommands(files[name])
+			}
+			for address, id := range declarations.imports {
+				imports[address] = id
+			}
+		}
+
+		l := linker{index: linkIndex(bodies, imports), properties: linkProperties(bodies), defaults: defaults}
+		for _, name := range modules[dir] {
+			file, ok := parsed[name]
+			if !ok {
+				continue
+			}
+			count := len(l.links)
+			for _, block := range file.Body().Blocks() {
+				if n, ok := addressBlockLabels[block.Type()]; !ok || len(block.Labels()) != n {
+					continue
+				}
+				if block.Type() == "module" {
+					l.linkHostnames(name, addressKey(block.Type(), block.Labels()), block.Body())
+					continue
+				}
+				l.linkBody(name, addressKey(block.Type(), block.Labels()), nil, block.Labels()[0], block.Body())
+			}
+			if len(l.links) > count {
+				changed[name] = hclwrite.Format(file.Bytes())
+			}
+		}
+		links = append(links, l.links...)
+	}
+	return changed, links
+}
+
+// linkIndex returns addresses of the declared resources by the objects they manage
+func linkIndex(bodies map[string]*hclwrite.Body, imports map[string]string) map[linkKey]string {
+	index := make(map[linkKey]string)
+	for address, importID := range imports {
+		body, ok := bodies[address]
+		if !ok {
+			continue
+		}
+		resourceType, _, _ := strings.Cut(address, ".")
+		target, ok := linkTargets[resourceType]
+		if !ok {
+			continue
+		}
+		id, targetAddress := target.id(importID, body)
+		if targetAddress == "" {
+			targetAddress = address
+		}
+		if _, declared := bodies[targetAddress]; id == "" || !declared {
+			continue
+		}
+		key := linkKey{kind: target.kind, id: id}
+		if existing, ok := index[key]; ok && existing != targetAddress {
+			index[key] = ""
+			continue
+		}
+		index[key] = targetAddress
+	}
+	return index
+}
+
+// linkProperties returns hostnames of akamai_property resources sorted by their addresses, hostnames given by other
+// than literal values are skipped
+func linkProperties(bodies map[string]*hclwrite.Body) []propertyHostnames {
+	var properties []propertyHostnames
+	for address, body := range bodies {
+		if !strings.HasPrefix(address, "akamai_property.") {
+			continue
+		}
+		property := propertyHostnames{address: address}
+		for _, block := range body.Blocks() {
+			if block.Type() != "hostnames" {
+				continue
+			}
+			if hostname, ok := literalValue(block.Body().GetAttribute("cname_from")); ok {
+				property.hostnames = append(property.hostnames, hostname)
+			}
+		}
+		if len(property.hostnames) > 0 {
+			properties = append(properties, property)
+		}
+	}
+	sort.Slice(properties, func(i, j int) bool {
+		return properties[i].address < properties[j].address
+	})
+	return properties
+}
+
+// importIDField returns function taking ID of the object from the first field of the import ID,
+// whole import ID is the object ID if the separator is empty
+func importIDField(separator string) func(string, *hclwrite.Body) (string, string) {
+	return func(importID string, _ *hclwrite.Body) (string, string) {
+		if separator == "" {
+			return importID, ""
+		}
+		id, _, _ := strings.Cut(importID, separator)
+		return id, ""
+	}
+}
+
+// activatedPolicy returns ID of the activated policy, given by the import ID of policy activation, e.g. 1234:prod,
+// and the address of the policy resource referenced by policy_id of the activation
+func activatedPolicy(importID string, body *hclwrite.Body) (string, string) {
+	id, _, _ := strings.Cut(importID, ":")
+	policyID := body.GetAttribute("policy_id")
+	if policyID == nil {
+		return "", ""
+	}
+	for _, traversal := range policyID.Expr().Variables() {
+		var names []string
+		for _, token := range traversal.BuildTokens(nil) {
+			if token.Type == hclsyntax.TokenIdent {
+				names = append(names, string(token.Bytes))
+			} else if token.Type != hclsyntax.TokenDot {
+				break
+			}
+		}
+		if len(names) >= 2 && names[0] == "akamai_cloudlets_policy" {
+			return id, names[0] + "." + names[1]
+		}
+	}
+	return "", ""
+}
+
+// linkBody replaces literal IDs in attributes of the body and its nested blocks
+func (l *linker) linkBody(file, address string, attributePath []string, blockType string, body *hclwrite.Body) {
+	// nested paths must not share the array of the parent path
+	attributePath = attributePath[:len(attributePath):len(attributePath)]
+	names := make([]string, 0, len(body.Attributes()))
+	for name := range body.Attributes() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, rule := range linkRules {
+			if rule.attribute != name || rule.block != "" && rule.block != blockType {
+				continue
+			}
+			if l.linkAttribute(file, address, append(attributePath, name), body, rule) {
+				break
+			}
+		}
+	}
+	for _, block := range body.Blocks() {
+		l.linkBody(file, address, append(attributePath, block.Type()), block.Type(), block.Body())
+	}
+}
+
+// linkAttribute replaces literal value of the attribute with the reference to the resource found by the rule
+func (l *linker) linkAttribute(file, address string, attributePath []string, body *hclwrite.Body, rule linkRule) bool {
+	attribute := body.GetAttribute(rule.attribute)
+	value, ok := literalValue(attribute)
+	if !ok {
+		return false
+	}
+	key := value
+	if rule.key != "" {
+		if key, ok = literalValue(body.GetAttribute(rule.key)); !ok {
+			return false
+		}
+	}
+	target := l.index[linkKey{kind: rule.kind, id: key}]
+	if target == "" {
+		return false
+	}
+
+	resourceType, name, _ := strings.Cut(target, ".")
+	body.SetAttributeTraversal(rule.attribute, hcl.Traversal{
+		hcl.TraverseRoot{Name: resourceType},
+		hcl.TraverseAttr{Name: name},
+		hcl.TraverseAttr{Name: "id"},
+	})
+	l.links = append(l.links, Link{
+		File:      file,
+		Block:     address,
+		Attribute: strings.Join(attributePath, "."),
+		Value:     value,
+		Reference: target + ".id",
+	})
+	return true
+}
+
+// linkHostnames replaces hostnames argument of the module call, given by a list literal or by a variable with such
+// a default value, with hostnames of the declared properties, e.g.
+// [for hostname in akamai_property.www.hostnames : hostname.cname_from]
+// A property is used only if all its hostnames are listed, the remaining hostnames are kept as literals
+func (l *linker) linkHostnames(file, address string, body *hclwrite.Body) {
+	attribute := body.GetAttribute("hostnames")
+	if attribute == nil {
+		return
+	}
+	hostnames, ok := literalStrings(attribute)
+	if !ok {
+		name, isVariable := variableName(attribute)
+		if hostnames, ok = literalStrings(l.defaults[name]); !isVariable || !ok {
+			return
+		}
+	}
+
+	remaining := make(map[string]bool, len(hostnames))
+	for _, hostname := range hostnames {
+		remaining[hostname] = true
+	}
+	var parts []string
+	for _, property := range l.properties {
+		listed := true
+		for _, hostname := range property.hostnames {
+			listed = listed && remaining[hostname]
+		}
+		if !listed {
+			continue
+		}
+		for _, hostname := range property.hostnames {
+			delete(remaining, hostname)
+		}
+		parts = append(parts, fmt.Sprintf("[for hostname in %s.hostnames : hostname.cname_from]", property.address))
+	}
+	if len(parts) == 0 {
+		return
+	}
+	var literals []string
+	for _, hostname := range hostnames {
+		if remaining[hostname] {
+			literals = append(literals, hostname)
+			delete(remaining, hostname)
+		}
+	}
+	if len(literals) > 0 {
+		parts = append(parts, stringList(literals))
+	}
+	reference := parts[0]
+	if len(parts) > 1 {
+		reference = fmt.Sprintf("concat(%s)", strings.Join(parts, ", "))
+	}
+
+	parsed, diags := hclwrite.ParseConfig([]byte("hostnames = "+reference+"\n"), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return
+	}
+	body.SetAttributeRaw("hostnames", parsed.Body().GetAttribute("hostnames").Expr().BuildTokens(nil))
+	l.links = append(l.links, Link{
+		File:      file,
+		Block:     address,
+		Attribute: "hostnames",
+		Value:     stringList(hostnames),
+		Reference: reference,
+	})
+}
+
+// variableName returns name of the variable if the attribute is a reference to it, e.g. var.hostnames
+func variableName(attribute *hclwrite.Attribute) (string, bool) {
+	expr, diags := hclsyntax.ParseExpression(attribute.Expr().BuildTokens(nil).Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", false
+	}
+	traversal, ok := expr.(*hclsyntax.ScopeTraversalExpr)
+	if !ok || len(traversal.Traversal) != 2 || traversal.Traversal.RootName() != "var" {
+		return "", false
+	}
+	name, ok := traversal.Traversal[1].(hcl.TraverseAttr)
+	return name.Name, ok
+}
+
+// literalStrings returns values of the attribute if it is a non-empty list literal of strings
+func literalStrings(attribute *hclwrite.Attribute) ([]string, bool) {
+	if attribute == nil {
+		return nil, false
+	}
+	expr, diags := hclsyntax.ParseExpression(attribute.Expr().BuildTokens(nil).Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() || len(expr.Variables()) > 0 {
+		return nil, false
+	}
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || value.IsNull() || !value.IsWhollyKnown() || !value.CanIterateElements() || value.LengthInt() == 0 {
+		return nil, false
+	}
+	var values []string
+	for it := value.ElementIterator(); it.Next(); {
+		_, element := it.Element()
+		if element.IsNull() || element.Type() != cty.String {
+			return nil, false
+		}
+		values = append(values, element.AsString())
+	}
+	return values, true
+}
+
+// stringList returns HCL list literal of the values, e.g. ["www.example.com"]
+func stringList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, strconv.Quote(value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// literalValue returns value of the attribute if it is a string or a number literal
+func literalValue(attribute *hclwrite.Attribute) (string, bool) {
+	if attribute == nil {
+		return "", false
+	}
+	expr, diags := hclsyntax.ParseExpression(attribute.Expr().BuildTokens(nil).Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() || len(expr.Variables()) > 0 {
+		return "", false
+	}
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || value.IsNull() || !value.IsWhollyKnown() {
+		return "", false
+	}
+	switch value.Type() {
+	case cty.String:
+		if value.AsString() == "" {
+			return "", false
+		}
+		return value.AsString(), true
+	case cty.Number:
+		return value.AsBigFloat().Text('f', -1), true
+	}
+	return "", false
+}
+
+// String returns description of the link, e.g. for the summary of the linked files
+func (l Link) String() string {
+	return fmt.Sprintf("%s: %s.%s = %s -> %s", l.File, l.Block, l.Attribute, l.Value, l.Reference)
+}
@@ -0,0 +1,361 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkFiles(t *testing.T) {
+	enrollment := `resource "akamai_cps_dv_enrollment" "enrollment_id_1234" {
+  common_name = "www.example.com"
+}
+`
+	cloudlets := `resource "akamai_cloudlets_policy" "policy" {
+  name          = "redirects"
+  cloudlet_code = "ER"
+}
+
+resource "akamai_cloudlets_policy_activation" "policy_activation" {
+  policy_id = tonumber(akamai_cloudlets_policy.policy.id)
+  network   = var.env
+}
+`
+	edgeWorker := `resource "akamai_edgeworker" "edgeworker" {
+  name = "worker"
+}
+
+import {
+  to = akamai_edgeworker.edgeworker
+  id = "42"
+}
+`
+	rules := `data "akamai_property_rules_builder" "www_rule_default" {
+  rules_v2024_08_13 {
+    name = "default"
+    behavior {
+      edge_redirector {
+        enabled = true
+        cloudlet_policy {
+          id   = 5678
+          name = "redirects"
+        }
+      }
+    }
+    behavior {
+      edge_worker {
+        enabled        = true
+        edge_worker_id = "42"
+      }
+    }
+  }
+}
+`
+	property := `resource "akamai_property" "www" {
+  name = "www.example.com"
+  hostnames {
+    cname_from = "www.example.com"
+  }
+  hostnames {
+    cname_from = "example.com"
+  }
+}
+`
+	security := `module "security" {
+  source    = "./modules/security"
+  hostnames = var.hostnames
+  name      = var.name
+}
+`
+
+	tests := map[string]struct {
+		files         map[string][]byte
+		expectedFiles map[string][]byte
+		expectedLinks []Link
+	}{
+		"edge hostname certificate linked to enrollment": {
+			files: map[string][]byte{
+				"property.tf": []byte(`resource "akamai_edge_hostname" "www" {
+  edge_hostname = "www.example.com.edgekey.net"
+  certificate   = 1234
+}
+`),
+				"enrollment.tf": []byte(enrollment),
+				"import.sh":     []byte("terraform init\nterraform import akamai_cps_dv_enrollment.enrollment_id_1234 1234,ctr_C-1\n"),
+			},
+			expectedFiles: map[string][]byte{
+				"property.tf": []byte(`resource "akamai_edge_hostname" "www" {
+  edge_hostname = "www.example.com.edgekey.net"
+  certificate   = akamai_cps_dv_enrollment.enrollment_id_1234.id
+}
+`),
+			},
+			expectedLinks: []Link{{
+				File:      "property.tf",
+				Block:     "akamai_edge_hostname.www",
+				Attribute: "certificate",
+				Value:     "1234",
+				Reference: "akamai_cps_dv_enrollment.enrollment_id_1234.id",
+			}},
+		},
+		"rules linked to cloudlets policy by activation and to edgeworker": {
+			files: map[string][]byte{
+				"rules.tf":      []byte(rules),
+				"cloudlets.tf":  []byte(cloudlets),
+				"edgeworker.tf": []byte(edgeWorker),
+				"import.sh": []byte(`terraform import akamai_cloudlets_policy.policy redirects
+terraform import akamai_cloudlets_policy_activation.policy_activation 5678:prod
+`),
+			},
+			expectedFiles: map[string][]byte{
+				"rules.tf": []byte(`data "akamai_property_rules_builder" "www_rule_default" {
+  rules_v2024_08_13 {
+    name = "default"
+    behavior {
+      edge_redirector {
+        enabled = true
+        cloudlet_policy {
+          id   = akamai_cloudlets_policy.policy.id
+          name = "redirects"
+        }
+      }
+    }
+    behavior {
+      edge_worker {
+        enabled        = true
+        edge_worker_id = akamai_edgeworker.edgeworker.id
+      }
+    }
+  }
+}
+`),
+			},
+			expectedLinks: []Link{
+				{
+					File:      "rules.tf",
+					Block:     "data.akamai_property_rules_builder.www_rule_default",
+					Attribute: "rules_v2024_08_13.behavior.edge_redirector.cloudlet_policy.id",
+					Value:     "5678",
+					Reference: "akamai_cloudlets_policy.policy.id",
+				},
+				{
+					File:      "rules.tf",
+					Block:     "data.akamai_property_rules_builder.www_rule_default",
+					Attribute: "rules_v2024_08_13.behavior.edge_worker.edge_worker_id",
+					Value:     "42",
+					Reference: "akamai_edgeworker.edgeworker.id",
+				},
+			},
+		},
+		"cloudlets policy linked by name": {
+			files: map[string][]byte{
+				"rules.tf":     []byte(rules),
+				"cloudlets.tf": []byte(cloudlets),
+				"import.sh":    []byte("terraform import akamai_cloudlets_policy.policy redirects\n"),
+			},
+			expectedFiles: map[string][]byte{
+				"rules.tf": []byte(`data "akamai_property_rules_builder" "www_rule_default" {
+  rules_v2024_08_13 {
+    name = "default"
+    behavior {
+      edge_redirector {
+        enabled = true
+        cloudlet_policy {
+          id   = akamai_cloudlets_policy.policy.id
+          name = "redirects"
+        }
+      }
+    }
+    behavior {
+      edge_worker {
+        enabled        = true
+        edge_worker_id = "42"
+      }
+    }
+  }
+}
+`),
+			},
+			expectedLinks: []Link{{
+				File:      "rules.tf",
+				Block:     "data.akamai_property_rules_builder.www_rule_default",
+				Attribute: "rules_v2024_08_13.behavior.edge_redirector.cloudlet_policy.id",
+				Value:     "5678",
+				Reference: "akamai_cloudlets_policy.policy.id",
+			}},
+		},
+		"shared cloudlets policy": {
+			files: map[string][]byte{
+				"rules.tf": []byte(`data "akamai_property_rules_builder" "rule" {
+  rules_v2024_08_13 {
+    behavior {
+      phased_release {
+        cloudlet_shared_policy = 5678
+      }
+    }
+  }
+}
+`),
+				"cloudlets.tf": []byte(cloudlets),
+				"import.sh":    []byte("terraform import akamai_cloudlets_policy_activation.policy_activation 5678:staging\n"),
+			},
+			expectedFiles: map[string][]byte{
+				"rules.tf": []byte(`data "akamai_property_rules_builder" "rule" {
+  rules_v2024_08_13 {
+    behavior {
+      phased_release {
+        cloudlet_shared_policy = akamai_cloudlets_policy.policy.id
+      }
+    }
+  }
+}
+`),
+			},
+			expectedLinks: []Link{{
+				File:      "rules.tf",
+				Block:     "data.akamai_property_rules_builder.rule",
+				Attribute: "rules_v2024_08_13.behavior.phased_release.cloudlet_shared_policy",
+				Value:     "5678",
+				Reference: "akamai_cloudlets_policy.policy.id",
+			}},
+		},
+		"target in other module": {
+			files: map[string][]byte{
+				"property/property.tf": []byte(`resource "akamai_edge_hostname" "www" {
+  certificate = 1234
+}
+`),
+				"cps/enrollment.tf": []byte(enrollment),
+				"cps/import.sh":     []byte("terraform import akamai_cps_dv_enrollment.enrollment_id_1234 1234,ctr_C-1\n"),
+			},
+			expectedFiles: map[string][]byte{},
+		},
+		"target not declared": {
+			files: map[string][]byte{
+				"property.tf": []byte(`resource "akamai_edge_hostname" "www" {
+  certificate = 1234
+}
+`),
+				"import.sh": []byte("terraform import akamai_cps_dv_enrollment.enrollment_id_1234 1234,ctr_C-1\n"),
+			},
+			expectedFiles: map[string][]byte{},
+		},
+		"object managed by several resources": {
+			files: map[string][]byte{
+				"property.tf": []byte(`resource "akamai_edge_hostname" "www" {
+  certificate = 1234
+}
+
+resource "akamai_cps_dv_enrollment" "first" {
+}
+
+resource "akamai_cps_dv_enrollment" "second" {
+}
+`),
+				"import.sh": []byte(`terraform import akamai_cps_dv_enrollment.first 1234,ctr_C-1
+terraform import akamai_cps_dv_enrollment.second 1234,ctr_C-2
+`),
+			},
+			expectedFiles: map[string][]byte{},
+		},
+		"security hostnames linked to property": {
+			files: map[string][]byte{
+				"property.tf": []byte(property),
+				"appsec.tf":   []byte(security),
+				"appsec-variables.tf": []byte(`variable "hostnames" {
+  type    = list(string)
+  default = ["www.example.com", "example.com"]
+}
+`),
+			},
+			expectedFiles: map[string][]byte{
+				"appsec.tf": []byte(`module "security" {
+  source    = "./modules/security"
+  hostnames = [for hostname in akamai_property.www.hostnames : hostname.cname_from]
+  name      = var.name
+}
+`),
+			},
+			expectedLinks: []Link{{
+				File:      "appsec.tf",
+				Block:     "module.security",
+				Attribute: "hostnames",
+				Value:     `["www.example.com", "example.com"]`,
+				Reference: "[for hostname in akamai_property.www.hostnames : hostname.cname_from]",
+			}},
+		},
+		"security hostnames partially linked": {
+			files: map[string][]byte{
+				"property.tf": []byte(property + `
+resource "akamai_property" "api" {
+  hostnames {
+    cname_from = "api.example.com"
+  }
+  hostnames {
+    cname_from = "static.example.com"
+  }
+}
+`),
+				"appsec.tf": []byte(`module "security" {
+  source    = "./modules/security"
+  hostnames = ["shop.example.com", "example.com", "api.example.com", "www.example.com"]
+}
+`),
+			},
+			expectedFiles: map[string][]byte{
+				"appsec.tf": []byte(`module "security" {
+  source    = "./modules/security"
+  hostnames = concat([for hostname in akamai_property.www.hostnames : hostname.cname_from], ["shop.example.com", "api.example.com"])
+}
+`),
+			},
+			expectedLinks: []Link{{
+				File:      "appsec.tf",
+				Block:     "module.security",
+				Attribute: "hostnames",
+				Value:     `["shop.example.com", "example.com", "api.example.com", "www.example.com"]`,
+				Reference: `concat([for hostname in akamai_property.www.hostnames : hostname.cname_from], ["shop.example.com", "api.example.com"])`,
+			}},
+		},
+		"security hostnames without property": {
+			files: map[string][]byte{
+				"appsec.tf": []byte(security),
+				"appsec-variables.tf": []byte(`variable "hostnames" {
+  default = ["www.example.com"]
+}
+`),
+			},
+			expectedFiles: map[string][]byte{},
+		},
+		"value is not a literal": {
+			files: map[string][]byte{
+				"property.tf": []byte(`resource "akamai_edge_hostname" "www" {
+  certificate = var.certificate
+}
+`),
+				"enrollment.tf": []byte(enrollment),
+				"import.sh":     []byte("terraform import akamai_cps_dv_enrollment.enrollment_id_1234 1234,ctr_C-1\n"),
+			},
+			expectedFiles: map[string][]byte{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			files, links := LinkFiles(test.files)
+			assert.Equal(t, test.expectedFiles, files)
+			assert.Equal(t, test.expectedLinks, links)
+		})
+	}
+}
+
+func TestLinkString(t *testing.T) {
+	link := Link{
+		File:      "property.tf",
+		Block:     "akamai_edge_hostname.www",
+		Attribute: "certificate",
+		Value:     "1234",
+		Reference: "akamai_cps_dv_enrollment.enrollment_id_1234.id",
+	}
+	assert.Equal(t, "property.tf: akamai_edge_hostname.www.certificate = 1234 -> akamai_cps_dv_enrollment.enrollment_id_1234.id", link.String())
+}
@@ -0,0 +1,438 @@
+package templates
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/akamai/cli-terraform/pkg/tools"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+type (
+	// NamingStyle defines how names of generated resources, data sources and modules are formatted
+	NamingStyle string
+
+	// NameCollisionPolicy defines what happens when two blocks get the same name within a module
+	NameCollisionPolicy string
+
+	// Naming is the strategy of naming generated resources, data sources and modules
+	// Names are always valid terraform identifiers, unique within the module
+	Naming struct {
+		// Style formats the names, NamingDefault keeps names generated by exporters
+		Style NamingStyle
+		// Prefix is added to every name
+		Prefix string
+		// Suffix is added to every name
+		Suffix string
+		// Collision resolves names used by more than one block of the same type within the module
+		Collision NameCollisionPolicy
+	}
+
+	// NamingSink renames resources, data sources and modules in terraform files written to it according to Naming
+	// References, import blocks and import scripts are updated accordingly
+	// Terraform files and scripts are kept until Close, as references can span several files, other files are passed
+	// to the wrapped sink unchanged
+	NamingSink struct {
+		OutputSink
+		Naming Naming
+
+		mu    sync.Mutex
+		files []namedFile
+	}
+
+	namedFile struct {
+		path    string
+		content []byte
+	}
+
+	// renamer holds new names of blocks and module calls by the slash separated directory of the module
+	renamer struct {
+		naming  Naming
+		renames map[string]map[string]string
+		taken   map[string]map[string]bool
+		calls   map[string]map[string]string
+	}
+)
+
+const (
+	// NamingDefault keeps names generated by exporters
+	NamingDefault NamingStyle = "default"
+	// NamingSnake formats names in snake case, e.g. my_property
+	NamingSnake NamingStyle = "snake"
+	// NamingKebab formats names in kebab case, e.g. my-property
+	NamingKebab NamingStyle = "kebab"
+
+	// NameCollisionSuffix adds a number to the colliding name, e.g. my_property_2
+	NameCollisionSuffix NameCollisionPolicy = "suffix"
+	// NameCollisionHash adds a short hash of the original name to the colliding name, e.g. my_property_5d41a4
+	NameCollisionHash NameCollisionPolicy = "hash"
+	// NameCollisionFail fails the export
+	NameCollisionFail NameCollisionPolicy = "fail"
+)
+
+var (
+	// ErrInvalidNamingStyle is returned when unknown naming style is requested
+	ErrInvalidNamingStyle = errors.New("invalid naming style")
+	// ErrInvalidNameCollisionPolicy is returned when unknown name collision policy is requested
+	ErrInvalidNameCollisionPolicy = errors.New("invalid name collision policy")
+	// ErrNameCollision is returned when two blocks get the same name and NameCollisionFail is used
+	ErrNameCollision = errors.New("name collision")
+
+	_ OutputSink = &NamingSink{}
+	_ reader     = &NamingSink{}
+
+	camelCaseRegexp    = regexp.MustCompile(`([\p{Ll}\d])(\p{Lu})`)
+	upperCaseRegexp    = regexp.MustCompile(`(\p{Lu})(\p{Lu}\p{Ll})`)
+	addressBlockLabels = map[string]int{"resource": 2, "data": 2, "module": 1}
+)
+
+// ParseNamingStyle returns NamingStyle for the given name, empty name results in NamingDefault
+func ParseNamingStyle(name string) (NamingStyle, error) {
+	return tools.ParseEnum(name, NamingDefault, ErrInvalidNamingStyle, NamingDefault, NamingSnake, NamingKebab)
+}
+
+// ParseNameCollisionPolicy returns NameCollisionPolicy for the given name, empty name results in NameCollisionSuffix
+func ParseNameCollisionPolicy(name string) (NameCollisionPolicy, error) {
+	return tools.ParseEnum(name, NameCollisionSuffix, ErrInvalidNameCollisionPolicy, NameCollisionSuffix, NameCollisionHash, NameCollisionFail)
+}
+
+// Enabled returns true if names generated by exporters are changed
+func (n Naming) Enabled() bool {
+	return n.Style != "" && n.Style != NamingDefault || n.Prefix != "" || n.Suffix != ""
+}
+
+// Name returns the name formatted according to the style, with prefix and suffix, as a valid terraform identifier
+// made by tools.TerraformIdentifier, the same as exporters use for the names they generate
+func (n Naming) Name(name string) string {
+	switch n.Style {
+	case NamingSnake, NamingKebab:
+		words := append(append(nameWords(n.Prefix), nameWords(name)...), nameWords(n.Suffix)...)
+		name = strings.Join(words, n.separator())
+	default:
+		name = n.Prefix + name + n.Suffix
+	}
+	return tools.TerraformIdentifier(name)
+}
+
+func (n Naming) separator() string {
+	if n.Style == NamingKebab {
+		return "-"
+	}
+	return "_"
+}
+
+// nameWords splits the name into lower case words on non-alphanumeric characters and camel case boundaries
+func nameWords(name string) []string {
+	name = upperCaseRegexp.ReplaceAllString(name, "${1} ${2}")
+	name = camelCaseRegexp.ReplaceAllString(name, "${1} ${2}")
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NewNamingSink returns NamingSink renaming blocks in files written to sink
+func NewNamingSink(sink OutputSink, naming Naming) *NamingSink {
+	return &NamingSink{OutputSink: sink, Naming: naming}
+}
+
+// WriteFile keeps terraform files and scripts until Close, other files are written immediately
+func (s *NamingSink) WriteFile(path string, content []byte) error {
+	if ext := filepath.Ext(path); ext != ".tf" && ext != ".sh" && ext != ".script" {
+		return s.OutputSink.WriteFile(path, content)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.files {
+		if f.path == path {
+			s.files[i].content = append([]byte(nil), content...)
+			return nil
+		}
+	}
+	s.files = append(s.files, namedFile{path: path, content: append([]byte(nil), content...)})
+	return nil
+}
+
+// Close renames blocks in kept files, writes them and closes the wrapped sink
+func (s *NamingSink) Close() error {
+	s.mu.Lock()
+	files := s.files
+	s.files = nil
+	s.mu.Unlock()
+
+	renamed, err := s.Naming.rename(files)
+	if err != nil {
+		return err
+	}
+	for _, f := range renamed {
+		if err := s.OutputSink.WriteFile(f.path, f.content); err != nil {
+			return err
+		}
+	}
+	return s.OutputSink.Close()
+}
+
+// Unwrap returns the wrapped sink
+func (s *NamingSink) Unwrap() OutputSink {
+	return s.OutputSink
+}
+
+// ReadFile returns content of the kept file, before it is renamed, or of the file in the wrapped sink
+func (s *NamingSink) ReadFile(path string) ([]byte, error) {
+	s.mu.Lock()
+	for _, f := range s.files {
+		if f.path == path {
+			s.mu.Unlock()
+			return append([]byte(nil), f.content...), nil
+		}
+	}
+	s.mu.Unlock()
+	return ReadOutput(s.OutputSink, path)
+}
+
+// Glob returns kept files and files of the wrapped sink matching the pattern
+func (s *NamingSink) Glob(pattern string) ([]string, error) {
+	s.mu.Lock()
+	paths := make([]string, 0, len(s.files))
+	for _, f := range s.files {
+		paths = append(paths, f.path)
+	}
+	s.mu.Unlock()
+	return globUnion(pattern, paths, func(pattern string) ([]string, error) {
+		return GlobOutput(s.OutputSink, pattern)
+	})
+}
+
+// rename renames resources, data sources and modules declared in terraform files and updates references to them
+// in terraform files and addresses in import scripts
+func (n Naming) rename(files []namedFile) ([]namedFile, error) {
+	r := renamer{
+		naming:  n,
+		renames: make(map[string]map[string]string),
+		taken:   make(map[string]map[string]bool),
+		calls:   make(map[string]map[string]string),
+	}
+
+	parsed := make([]*hclwrite.File, len(files))
+	for i, f := range files {
+		if filepath.Ext(f.path) != ".tf" {
+			continue
+		}
+		file, diags := hclwrite.ParseConfig(f.content, f.path, hcl.InitialPos)
+		if diags.HasErrors() {
+			continue
+		}
+		parsed[i] = file
+
+		dir := path.Dir(filepath.ToSlash(f.path))
+		for _, block := range file.Body().Blocks() {
+			if n, ok := addressBlockLabels[block.Type()]; !ok || len(block.Labels()) != n {
+				continue
+			}
+			if err := r.declare(dir, block.Type(), block.Labels()); err != nil {
+				return nil, fmt.Errorf("'%s': %w", f.path, err)
+			}
+		}
+		declarations := reportFile{ReportFile: ReportFile{Path: f.path}}
+		declarations.collectDeclarations(f.content)
+		for name, source := range declarations.modules {
+			if r.calls[dir] == nil {
+				r.calls[dir] = make(map[string]string)
+			}
+			r.calls[dir][name] = path.Join(dir, source)
+		}
+	}
+
+	res := make([]namedFile, 0, len(files))
+	for i, f := range files {
+		dir := path.Dir(filepath.ToSlash(f.path))
+		content := f.content
+		switch {
+		case parsed[i] != nil:
+			r.renameBody(dir, parsed[i].Body(), true)
+			content = hclwrite.Format(parsed[i].Bytes())
+		case filepath.Ext(f.path) == ".sh", filepath.Ext(f.path) == ".script":
+			content = r.renameImportCommands(dir, content)
+		}
+		res = append(res, namedFile{path: f.path, content: content})
+	}
+	return res, nil
+}
+
+// declare assigns new name to the block with the given labels, resolving collisions within the directory
+func (r renamer) declare(dir, blockType string, labels []string) error {
+	key, kind := addressKey(blockType, labels), blockType
+	if blockType != "module" {
+		kind = blockType + "." + labels[0]
+	}
+	if r.renames[dir] == nil {
+		r.renames[dir] = make(map[string]string)
+		r.taken[dir] = make(map[string]bool)
+	}
+	if _, ok := r.renames[dir][key]; ok {
+		return nil
+	}
+
+	original := labels[len(labels)-1]
+	name := r.naming.Name(original)
+	if r.taken[dir][kind+"."+name] {
+		switch r.naming.Collision {
+		case NameCollisionFail:
+			return fmt.Errorf("%w: '%s' is renamed to '%s', which is already used", ErrNameCollision, key, name)
+		case NameCollisionHash:
+			sum := sha256.Sum256([]byte(original))
+			name = fmt.Sprintf("%s%s%x", name, r.naming.separator(), sum[:3])
+		}
+		for i, base := 2, name; r.taken[dir][kind+"."+name]; i++ {
+			name = fmt.Sprintf("%s%s%d", base, r.naming.separator(), i)
+		}
+	}
+	r.taken[dir][kind+"."+name] = true
+	r.renames[dir][key] = name
+	return nil
+}
+
+func addressKey(blockType string, labels []string) string {
+	switch blockType {
+	case "resource":
+		return strings.Join(labels, ".")
+	case "data":
+		return "data." + strings.Join(labels, ".")
+	}
+	return "module." + labels[0]
+}
+
+// renameBody renames labels of blocks declared in the body and all references in its expressions
+func (r renamer) renameBody(dir string, body *hclwrite.Body, topLevel bool) {
+	for _, attribute := range body.Attributes() {
+		r.renameReferences(dir, attribute.Expr())
+	}
+	for _, block := range body.Blocks() {
+		if n, ok := addressBlockLabels[block.Type()]; topLevel && ok && len(block.Labels()) == n {
+			labels := block.Labels()
+			if name, ok := r.renames[dir][addressKey(block.Type(), labels)]; ok {
+				labels[len(labels)-1] = name
+				block.SetLabels(labels)
+			}
+		}
+		r.renameBody(dir, block.Body(), false)
+	}
+}
+
+func (r renamer) renameReferences(dir string, expr *hclwrite.Expression) {
+	for _, traversal := range expr.Variables() {
+		var names []string
+		for _, token := range traversal.BuildTokens(nil) {
+			if token.Type == hclsyntax.TokenIdent {
+				names = append(names, string(token.Bytes))
+			} else if token.Type != hclsyntax.TokenDot {
+				break
+			}
+		}
+		search := referencePrefix(names)
+		if search == nil {
+			continue
+		}
+		name, ok := r.renames[dir][strings.Join(search, ".")]
+		if !ok || name == search[len(search)-1] {
+			continue
+		}
+		replacement := append(append([]string(nil), search[:len(search)-1]...), name)
+		expr.RenameVariablePrefix(search, replacement)
+	}
+}
+
+// referencePrefix returns the part of the reference which addresses the block, e.g. data.akamai_group.group
+func referencePrefix(names []string) []string {
+	switch {
+	case len(names) >= 2 && names[0] == "module":
+		return names[:2]
+	case len(names) >= 3 && names[0] == "data":
+		return names[:3]
+	case len(names) >= 2:
+		return names[:2]
+	}
+	return nil
+}
+
+// renameImportCommands updates addresses in `terraform import` or `tofu import` commands of the script located in dir
+func (r renamer) renameImportCommands(dir string, content []byte) []byte {
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimLeft(line, " \t")
+		if command, args, found := cutImportCommand(trimmed); found {
+			if address, rest, found := strings.Cut(strings.TrimLeft(args, " "), " "); found {
+				line = line[:len(line)-len(trimmed)] + command + r.renameAddress(dir, address) + " " + rest
+			}
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	if !bytes.HasSuffix(content, []byte("\n")) {
+		buf.Truncate(buf.Len() - 1)
+	}
+	return buf.Bytes()
+}
+
+// renameAddress returns resource address with module and resource names replaced, e.g.
+// module.security.akamai_appsec_configuration.config, modules are resolved starting from dir
+func (r renamer) renameAddress(dir, address string) string {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return address
+	}
+	steps := make([]string, 0, len(traversal))
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			steps = append(steps, s.Name)
+		case hcl.TraverseAttr:
+			steps = append(steps, s.Name)
+		}
+	}
+
+	i := 0
+	for ; i+1 < len(steps) && steps[i] == "module"; i += 2 {
+		module := steps[i+1]
+		if name, ok := r.renames[dir]["module."+module]; ok {
+			steps[i+1] = name
+		}
+		dir = r.calls[dir][module]
+	}
+	if search := referencePrefix(steps[i:]); search != nil && (len(search) > 2 || search[0] != "module") {
+		if name, ok := r.renames[dir][strings.Join(search, ".")]; ok {
+			steps[i+len(search)-1] = name
+		}
+	}
+
+	res := make(hcl.Traversal, 0, len(traversal))
+	j := 0
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			s.Name = steps[j]
+			j++
+			res = append(res, s)
+		case hcl.TraverseAttr:
+			s.Name = steps[j]
+			j++
+			res = append(res, s)
+		default:
+			res = append(res, step)
+		}
+	}
+	return traversalString(res)
+}
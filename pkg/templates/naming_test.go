@@ -0,0 +1,308 @@
+package templates
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNamingStyle(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  NamingStyle
+		withError error
+	}{
+		"default": {
+			given:    "",
+			expected: NamingDefault,
+		},
+		"kebab": {
+			given:    "kebab",
+			expected: NamingKebab,
+		},
+		"invalid": {
+			given:     "camel",
+			withError: ErrInvalidNamingStyle,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			style, err := ParseNamingStyle(test.given)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, style)
+		})
+	}
+}
+
+func TestNamingName(t *testing.T) {
+	tests := map[string]struct {
+		naming   Naming
+		given    string
+		expected string
+	}{
+		"default keeps the name": {
+			naming:   Naming{Style: NamingDefault},
+			given:    "my-property_1",
+			expected: "my-property_1",
+		},
+		"default with prefix and suffix": {
+			naming:   Naming{Style: NamingDefault, Prefix: "prod_", Suffix: "_v2"},
+			given:    "my-property",
+			expected: "prod_my-property_v2",
+		},
+		"snake": {
+			naming:   Naming{Style: NamingSnake},
+			given:    "My-Property.example.com",
+			expected: "my_property_example_com",
+		},
+		"snake splits camel case": {
+			naming:   Naming{Style: NamingSnake},
+			given:    "originCDNHostname",
+			expected: "origin_cdn_hostname",
+		},
+		"kebab with prefix and suffix": {
+			naming:   Naming{Style: NamingKebab, Prefix: "prod", Suffix: "V2"},
+			given:    "my_property",
+			expected: "prod-my-property-v2",
+		},
+		"leading digit": {
+			naming:   Naming{Style: NamingSnake},
+			given:    "123 zone",
+			expected: "_123_zone",
+		},
+		"invalid characters": {
+			naming:   Naming{Style: NamingDefault, Prefix: "a"},
+			given:    "b.c/d",
+			expected: "ab_c_d",
+		},
+		"only symbols": {
+			naming:   Naming{Style: NamingSnake},
+			given:    "...",
+			expected: "_",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.naming.Name(test.given))
+		})
+	}
+}
+
+func TestNamingSink(t *testing.T) {
+	mainTF := `resource "akamai_property" "MyProperty" {
+  name     = "MyProperty"
+  group_id = data.akamai_group.MyGroup.id
+  rules    = data.akamai_property_rules_template.Rules.json
+}
+
+data "akamai_group" "MyGroup" {
+  group_name = "group"
+}
+
+module "SecurityConfig" {
+  source  = "./modules/security"
+  name    = akamai_property.MyProperty.name
+  enabled = var.enabled
+}
+
+output "config_id" {
+  value = module.SecurityConfig.config_id
+}
+`
+	rulesTF := `data "akamai_property_rules_template" "Rules" {
+  template_file = abspath("${path.module}/property-snippets/main.json")
+  depends_on    = [data.akamai_group.MyGroup]
+}
+`
+	moduleTF := `resource "akamai_appsec_configuration" "Config" {
+  name = "config"
+}
+
+output "config_id" {
+  value = akamai_appsec_configuration.Config.config_id
+}
+`
+	importTF := `import {
+  id = "prp_1,ctr_1,grp_1"
+  to = akamai_property.MyProperty
+}
+`
+	importSH := `terraform init
+terraform import akamai_property.MyProperty prp_1,ctr_1,grp_1
+terraform import module.SecurityConfig.akamai_appsec_configuration.Config 123
+`
+
+	tests := map[string]struct {
+		naming    Naming
+		files     map[string]string
+		expected  map[string]string
+		withError error
+	}{
+		"names and references renamed": {
+			naming: Naming{Style: NamingSnake},
+			files: map[string]string{
+				"out/main.tf":                     mainTF,
+				"out/rules.tf":                    rulesTF,
+				"out/modules/security/config.tf":  moduleTF,
+				"out/import.sh":                   importSH,
+				"out/property-snippets/main.json": `{"name": "MyProperty"}`,
+			},
+			expected: map[string]string{
+				"out/main.tf": `resource "akamai_property" "my_property" {
+  name     = "MyProperty"
+  group_id = data.akamai_group.my_group.id
+  rules    = data.akamai_property_rules_template.rules.json
+}
+
+data "akamai_group" "my_group" {
+  group_name = "group"
+}
+
+module "security_config" {
+  source  = "./modules/security"
+  name    = akamai_property.my_property.name
+  enabled = var.enabled
+}
+
+output "config_id" {
+  value = module.security_config.config_id
+}
+`,
+				"out/rules.tf": `data "akamai_property_rules_template" "rules" {
+  template_file = abspath("${path.module}/property-snippets/main.json")
+  depends_on    = [data.akamai_group.my_group]
+}
+`,
+				"out/modules/security/config.tf": `resource "akamai_appsec_configuration" "config" {
+  name = "config"
+}
+
+output "config_id" {
+  value = akamai_appsec_configuration.config.config_id
+}
+`,
+				"out/import.sh": `terraform init
+terraform import akamai_property.my_property prp_1,ctr_1,grp_1
+terraform import module.security_config.akamai_appsec_configuration.config 123
+`,
+				"out/property-snippets/main.json": `{"name": "MyProperty"}`,
+			},
+		},
+		"import blocks renamed": {
+			naming: Naming{Style: NamingDefault, Prefix: "prod_"},
+			files: map[string]string{
+				"main.tf":   `resource "akamai_property" "MyProperty" {}`,
+				"import.tf": importTF,
+			},
+			expected: map[string]string{
+				"main.tf": `resource "akamai_property" "prod_MyProperty" {}`,
+				"import.tf": `import {
+  id = "prp_1,ctr_1,grp_1"
+  to = akamai_property.prod_MyProperty
+}
+`,
+			},
+		},
+		"dns import script renamed": {
+			naming: Naming{Style: NamingKebab},
+			files: map[string]string{
+				"dns.tf": `resource "akamai_dns_zone" "example_com" {}
+resource "akamai_dns_record" "example_com_www_A" {}
+`,
+				"example_com_resource_import.script": `terraform init
+terraform import akamai_dns_zone.example_com example.com
+terraform import akamai_dns_record.example_com_www_A example.com#www.example.com#A
+`,
+			},
+			expected: map[string]string{
+				"dns.tf": `resource "akamai_dns_zone" "example-com" {}
+resource "akamai_dns_record" "example-com-www-a" {}
+`,
+				"example_com_resource_import.script": `terraform init
+terraform import akamai_dns_zone.example-com example.com
+terraform import akamai_dns_record.example-com-www-a example.com#www.example.com#A
+`,
+			},
+		},
+		"collision with numeric suffix": {
+			naming: Naming{Style: NamingKebab, Collision: NameCollisionSuffix},
+			files: map[string]string{
+				"main.tf": `resource "akamai_dns_record" "www_example" {}
+resource "akamai_dns_record" "www-example" {}
+resource "akamai_dns_zone" "www_example" {}
+`,
+			},
+			expected: map[string]string{
+				"main.tf": `resource "akamai_dns_record" "www-example" {}
+resource "akamai_dns_record" "www-example-2" {}
+resource "akamai_dns_zone" "www-example" {}
+`,
+			},
+		},
+		"collision with hash": {
+			naming: Naming{Style: NamingSnake, Collision: NameCollisionHash},
+			files: map[string]string{
+				"main.tf": `resource "akamai_dns_record" "www_example" {}
+resource "akamai_dns_record" "www-example" {}
+`,
+			},
+			expected: map[string]string{
+				"main.tf": `resource "akamai_dns_record" "www_example" {}
+resource "akamai_dns_record" "www_example_d5367f" {}
+`,
+			},
+		},
+		"collision fails": {
+			naming: Naming{Style: NamingSnake, Collision: NameCollisionFail},
+			files: map[string]string{
+				"main.tf": `resource "akamai_dns_record" "www_example" {}
+resource "akamai_dns_record" "www-example" {}
+`,
+			},
+			withError: ErrNameCollision,
+		},
+		"same names in different modules": {
+			naming: Naming{Style: NamingSnake, Collision: NameCollisionFail},
+			files: map[string]string{
+				"main.tf":            `resource "akamai_dns_record" "www_example" {}`,
+				"modules/a/main.tf":  `resource "akamai_dns_record" "www-example" {}`,
+				"modules/b/other.tf": `resource "akamai_dns_record" "wwwExample" {}`,
+			},
+			expected: map[string]string{
+				"main.tf":            `resource "akamai_dns_record" "www_example" {}`,
+				"modules/a/main.tf":  `resource "akamai_dns_record" "www_example" {}`,
+				"modules/b/other.tf": `resource "akamai_dns_record" "www_example" {}`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			memory := NewMemorySink()
+			sink := NewNamingSink(memory, test.naming)
+			for path, content := range test.files {
+				require.NoError(t, sink.WriteFile(path, []byte(content)))
+			}
+			err := sink.Close()
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, memory.Files(), len(test.expected))
+			for path, expected := range test.expected {
+				content, ok := memory.File(path)
+				require.True(t, ok, "file %s was not written", path)
+				assert.Equal(t, expected, string(content), path)
+			}
+		})
+	}
+}
@@ -0,0 +1,549 @@
+package templates
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akamai/cli-terraform/pkg/tools"
+)
+
+type (
+	// OutputSink is a destination for files generated during export
+	OutputSink interface {
+		// Check verifies that the given files can be written by the sink
+		Check(paths ...string) error
+		// WriteFile stores content under the given path
+		WriteFile(path string, content []byte) error
+		// Close flushes all pending data, after Close no more files can be written
+		Close() error
+	}
+
+	// reader is implemented by sinks which are able to return files of their destination, including the ones
+	// written into the sink so far, see ReadOutput
+	reader interface {
+		ReadFile(path string) ([]byte, error)
+		Glob(pattern string) ([]string, error)
+	}
+
+	// OutputMode defines where generated files are written
+	OutputMode string
+
+	// OutputOptions contains configuration of the output sink
+	OutputOptions struct {
+		// Mode decides where generated files are written
+		Mode OutputMode
+		// Root is the working directory, paths of files in stdout and archive output are relative to it
+		Root string
+		// ArchivePath is the path of the archive, if empty the archive is written to Stdout
+		ArchivePath string
+		// DryRun prevents writing any files, the list of files is reported to Stdout on Close instead
+		DryRun bool
+		// Conflict decides what happens with files which already exist on disk
+		Conflict ConflictPolicy
+		// Stdout is the writer used for streamed output
+		Stdout io.Writer
+		// Warnings is the writer to which warnings are reported, they are discarded if it is not set
+		Warnings io.Writer
+		// Format is the syntax of written terraform files, FormatJSON wraps the sink in JSONSink
+		Format Format
+		// Report, if set, records written files and is written into Root on Close, see ReportSink
+		Report *Report
+		// Naming, if enabled, renames generated resources, data sources and modules, see NamingSink
+		Naming Naming
+		// Sink, if set, receives generated files instead of the sink selected by Mode and DryRun
+		Sink OutputSink
+	}
+
+	// DiskSink writes files directly into the file system
+	// Conflict decides what happens with files which already exist, the zero value fails the export
+	DiskSink struct {
+		Conflict ConflictPolicy
+		Warnings io.Writer
+	}
+
+	// MemorySink keeps generated files in memory
+	MemorySink struct {
+		mu    sync.Mutex
+		files map[string][]byte
+	}
+
+	// StreamSink writes all files into a single stream, each of them preceded by a header with the file path
+	StreamSink struct {
+		mu      sync.Mutex
+		w       io.Writer
+		root    string
+		written bool
+	}
+
+	// ArchiveSink writes files into tar or zip archive
+	ArchiveSink struct {
+		mu     sync.Mutex
+		root   string
+		tar    *tar.Writer
+		zip    *zip.Writer
+		closer io.Closer
+	}
+
+	// ReadThroughSink keeps generated files in memory, but reads the existing files which were not written into it,
+	// so that the export makes the same decisions as when writing into the file system, e.g. to compare the files
+	ReadThroughSink struct {
+		MemorySink
+	}
+
+	// DryRunSink does not write anything, but reports which files would be written on Close
+	DryRunSink struct {
+		ReadThroughSink
+		w        io.Writer
+		root     string
+		conflict ConflictPolicy
+	}
+
+	outputContextKey struct{}
+)
+
+const (
+	// OutputModeDisk writes generated files into the working directory
+	OutputModeDisk OutputMode = "disk"
+	// OutputModeStdout prints generated files to standard output
+	OutputModeStdout OutputMode = "stdout"
+	// OutputModeTar writes generated files into a tar archive
+	OutputModeTar OutputMode = "tar"
+	// OutputModeZip writes generated files into a zip archive
+	OutputModeZip OutputMode = "zip"
+)
+
+var (
+	// ErrInvalidOutputMode is returned when unknown output mode is requested
+	ErrInvalidOutputMode = errors.New("invalid output mode")
+	// ErrOutput is returned when output sink cannot be created
+	ErrOutput = errors.New("creating output")
+
+	_ OutputSink = DiskSink{}
+	_ OutputSink = &MemorySink{}
+	_ OutputSink = &StreamSink{}
+	_ OutputSink = &ArchiveSink{}
+	_ OutputSink = &ReadThroughSink{}
+	_ OutputSink = &DryRunSink{}
+
+	_ reader = DiskSink{}
+	_ reader = &MemorySink{}
+	_ reader = &ReadThroughSink{}
+	_ reader = &DryRunSink{}
+
+	_ conflictResolver = DiskSink{}
+	_ conflictResolver = &DryRunSink{}
+)
+
+// ParseOutputMode returns OutputMode for the given name, empty name results in OutputModeDisk
+func ParseOutputMode(name string) (OutputMode, error) {
+	return tools.ParseEnum(name, OutputModeDisk, ErrInvalidOutputMode, OutputModeDisk, OutputModeStdout, OutputModeTar, OutputModeZip)
+}
+
+// IsArchive returns true if the mode produces an archive
+func (m OutputMode) IsArchive() bool {
+	return m == OutputModeTar || m == OutputModeZip
+}
+
+// NewOutputSink creates OutputSink for the given options
+func NewOutputSink(opts OutputOptions) (OutputSink, error) {
+	sink := opts.Sink
+	if sink == nil {
+		var err error
+		if sink, err = newOutputSink(opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Report != nil {
+		sink = NewReportSink(sink, opts.Report, opts.Root)
+	}
+	if opts.Format == FormatJSON {
+		sink = JSONSink{OutputSink: sink}
+	}
+	if opts.Naming.Enabled() {
+		sink = NewNamingSink(sink, opts.Naming)
+	}
+	return sink, nil
+}
+
+func newOutputSink(opts OutputOptions) (OutputSink, error) {
+	if opts.DryRun {
+		sink := NewDryRunSink(opts.Stdout, opts.Root)
+		sink.conflict = opts.Conflict
+		return sink, nil
+	}
+	switch opts.Mode {
+	case OutputModeStdout:
+		return NewStreamSink(opts.Stdout, opts.Root), nil
+	case OutputModeTar, OutputModeZip:
+		if opts.ArchivePath == "" {
+			return NewArchiveSink(opts.Mode, opts.Stdout, opts.Root), nil
+		}
+		f, err := os.Create(opts.ArchivePath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrOutput, err)
+		}
+		sink := NewArchiveSink(opts.Mode, f, opts.Root)
+		sink.closer = f
+		return sink, nil
+	}
+	return NewStagingSink(DiskSink{Conflict: opts.Conflict, Warnings: opts.Warnings}, opts.Root), nil
+}
+
+// WithOutput returns context with the given output sink
+func WithOutput(ctx context.Context, sink OutputSink) context.Context {
+	return context.WithValue(ctx, outputContextKey{}, sink)
+}
+
+// GetOutput returns output sink stored in the context, DiskSink is returned if there is none
+func GetOutput(ctx context.Context) OutputSink {
+	if sink, ok := ctx.Value(outputContextKey{}).(OutputSink); ok {
+		return sink
+	}
+	return DiskSink{}
+}
+
+// ReadOutput returns content of the file in the destination of the sink, e.g. to update a file shared by several
+// exports, the content written into the sink or any sink wrapped by it takes precedence over the existing file
+// An error wrapping os.ErrNotExist is returned if there is no such file, which is always the case for sinks
+// which do not write into the file system, such as stdout or archive output
+func ReadOutput(sink OutputSink, path string) ([]byte, error) {
+	if r := outputReader(sink); r != nil {
+		return r.ReadFile(path)
+	}
+	return nil, &fs.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+}
+
+// GlobOutput returns sorted paths of files in the destination of the sink matching the pattern, see ReadOutput
+func GlobOutput(sink OutputSink, pattern string) ([]string, error) {
+	if r := outputReader(sink); r != nil {
+		return r.Glob(pattern)
+	}
+	return nil, nil
+}
+
+// outputReader returns the sink or the first sink wrapped by it which is able to read files
+func outputReader(sink OutputSink) reader {
+	for sink != nil {
+		if r, ok := sink.(reader); ok {
+			return r
+		}
+		u, ok := sink.(unwrapper)
+		if !ok {
+			return nil
+		}
+		sink = u.Unwrap()
+	}
+	return nil
+}
+
+// globUnion returns sorted union of paths matching the pattern among the given ones and the ones returned by glob,
+// if it is set
+func globUnion(pattern string, paths []string, glob func(string) ([]string, error)) ([]string, error) {
+	var matches []string
+	if glob != nil {
+		var err error
+		if matches, err = glob(pattern); err != nil {
+			return nil, err
+		}
+	}
+	seen := make(map[string]struct{}, len(matches))
+	for _, path := range matches {
+		seen[path] = struct{}{}
+	}
+	for _, path := range paths {
+		if _, ok := seen[path]; ok {
+			continue
+		}
+		if ok, err := filepath.Match(pattern, path); err != nil {
+			return nil, err
+		} else if ok {
+			seen[path] = struct{}{}
+			matches = append(matches, path)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// Check returns an error if any of the given files already exists and conflict policy is ConflictFail
+func (s DiskSink) Check(paths ...string) error {
+	return checkConflicts(s.Conflict, paths...)
+}
+
+// ConflictPolicy returns Conflict
+func (s DiskSink) ConflictPolicy() ConflictPolicy {
+	return s.Conflict
+}
+
+// WriteFile writes the file creating its parent directories if needed
+// Existing file is backed up or merged with the content according to the conflict policy
+func (s DiskSink) WriteFile(path string, content []byte) error {
+	if _, err := os.Stat(path); err == nil {
+		switch s.Conflict {
+		case ConflictBackup:
+			if err := os.Rename(path, BackupName(path, time.Now())); err != nil {
+				return err
+			}
+		case ConflictMerge:
+			existing, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if content, err = MergeFiles(path, existing, content, s.warn); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// ReadFile reads the file from the file system
+func (DiskSink) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// Glob returns files of the file system matching the pattern
+func (DiskSink) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+func (s DiskSink) warn(msg string) {
+	if s.Warnings != nil {
+		_, _ = fmt.Fprintf(s.Warnings, "Warning: %s\n", msg)
+	}
+}
+
+// Close does nothing for DiskSink
+func (DiskSink) Close() error {
+	return nil
+}
+
+// NewMemorySink returns empty MemorySink
+func NewMemorySink() *MemorySink {
+	return &MemorySink{files: make(map[string][]byte)}
+}
+
+// Check does nothing for MemorySink
+func (*MemorySink) Check(...string) error {
+	return nil
+}
+
+// WriteFile stores a copy of the content, content of the file written before under the same path is replaced
+func (s *MemorySink) WriteFile(path string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.files == nil {
+		s.files = make(map[string][]byte)
+	}
+	s.files[path] = append([]byte(nil), content...)
+	return nil
+}
+
+// Close does nothing for MemorySink
+func (*MemorySink) Close() error {
+	return nil
+}
+
+// ReadFile returns content of the file written under the given path
+func (s *MemorySink) ReadFile(path string) ([]byte, error) {
+	if content, ok := s.File(path); ok {
+		return append([]byte(nil), content...), nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+}
+
+// Glob returns written files matching the pattern
+func (s *MemorySink) Glob(pattern string) ([]string, error) {
+	return globUnion(pattern, s.Files(), nil)
+}
+
+// Files returns paths of all written files in sorted order
+func (s *MemorySink) Files() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.files))
+	for path := range s.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// File returns content of the file written under the given path
+func (s *MemorySink) File(path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.files[path]
+	return content, ok
+}
+
+// NewStreamSink returns StreamSink writing to w
+func NewStreamSink(w io.Writer, root string) *StreamSink {
+	return &StreamSink{w: w, root: root}
+}
+
+// Check does nothing for StreamSink
+func (*StreamSink) Check(...string) error {
+	return nil
+}
+
+// WriteFile writes the header with the file path followed by the content
+func (s *StreamSink) WriteFile(path string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var b strings.Builder
+	if s.written {
+		b.WriteString("\n")
+	}
+	b.WriteString(fmt.Sprintf("# ==> %s <==\n", relativePath(s.root, path)))
+	b.Write(content)
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		b.WriteString("\n")
+	}
+	s.written = true
+	_, err := io.WriteString(s.w, b.String())
+	return err
+}
+
+// Close does nothing for StreamSink
+func (*StreamSink) Close() error {
+	return nil
+}
+
+// NewArchiveSink returns ArchiveSink writing archive of the given mode (OutputModeTar or OutputModeZip) to w
+func NewArchiveSink(mode OutputMode, w io.Writer, root string) *ArchiveSink {
+	sink := &ArchiveSink{root: root}
+	if mode == OutputModeZip {
+		sink.zip = zip.NewWriter(w)
+	} else {
+		sink.tar = tar.NewWriter(w)
+	}
+	return sink
+}
+
+// Check does nothing for ArchiveSink
+func (*ArchiveSink) Check(...string) error {
+	return nil
+}
+
+// WriteFile adds the file to the archive
+func (s *ArchiveSink) WriteFile(path string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := relativePath(s.root, path)
+	if s.zip != nil {
+		w, err := s.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	}
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}
+	if err := s.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := s.tar.Write(content)
+	return err
+}
+
+// Close finalizes the archive
+func (s *ArchiveSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	if s.zip != nil {
+		err = s.zip.Close()
+	} else {
+		err = s.tar.Close()
+	}
+	if s.closer != nil {
+		if closeErr := s.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// NewReadThroughSink returns ReadThroughSink
+func NewReadThroughSink() *ReadThroughSink {
+	return &ReadThroughSink{MemorySink: MemorySink{files: make(map[string][]byte)}}
+}
+
+// ReadFile returns content of the file written into the sink or, if there is none, of the existing file
+func (s *ReadThroughSink) ReadFile(path string) ([]byte, error) {
+	if content, ok := s.File(path); ok {
+		return append([]byte(nil), content...), nil
+	}
+	return os.ReadFile(path)
+}
+
+// Glob returns files written into the sink and existing files matching the pattern
+func (s *ReadThroughSink) Glob(pattern string) ([]string, error) {
+	return globUnion(pattern, s.Files(), filepath.Glob)
+}
+
+// NewDryRunSink returns DryRunSink reporting to w
+// It reads existing files like ReadThroughSink, so that the export makes the same decisions as without dry run
+func NewDryRunSink(w io.Writer, root string) *DryRunSink {
+	return &DryRunSink{ReadThroughSink: ReadThroughSink{MemorySink: MemorySink{files: make(map[string][]byte)}}, w: w, root: root}
+}
+
+// Check returns an error if any of the given files already exists and conflict policy is ConflictFail,
+// the same as the export without dry run would
+func (s *DryRunSink) Check(paths ...string) error {
+	return checkConflicts(s.conflict, paths...)
+}
+
+// ConflictPolicy returns the conflict policy of the export without dry run
+func (s *DryRunSink) ConflictPolicy() ConflictPolicy {
+	return s.conflict
+}
+
+// Close reports files which would be written
+func (s *DryRunSink) Close() error {
+	files := s.Files()
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Dry run, %d file(s) would be written:\n", len(files)))
+	for _, path := range files {
+		content, _ := s.File(path)
+		b.WriteString(fmt.Sprintf("  %s (%d bytes)\n", relativePath(s.root, path), len(content)))
+	}
+	_, err := io.WriteString(s.w, b.String())
+	return err
+}
+
+func checkConflicts(policy ConflictPolicy, paths ...string) error {
+	if policy != "" && policy != ConflictFail {
+		return nil
+	}
+	return tools.CheckFiles(paths...)
+}
+
+func relativePath(root, path string) string {
+	if root != "" {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
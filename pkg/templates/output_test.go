@@ -0,0 +1,330 @@
+package templates
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputMode(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  OutputMode
+		withError error
+	}{
+		"default": {
+			given:    "",
+			expected: OutputModeDisk,
+		},
+		"stdout": {
+			given:    "stdout",
+			expected: OutputModeStdout,
+		},
+		"zip": {
+			given:    "zip",
+			expected: OutputModeZip,
+		},
+		"invalid": {
+			given:     "foo",
+			withError: ErrInvalidOutputMode,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mode, err := ParseOutputMode(test.given)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, mode)
+		})
+	}
+}
+
+func TestNewOutputSink(t *testing.T) {
+	tests := map[string]struct {
+		mode     OutputMode
+		dryRun   bool
+		expected OutputSink
+	}{
+		"disk": {
+			mode:     OutputModeDisk,
+			expected: &StagingSink{},
+		},
+		"stdout": {
+			mode:     OutputModeStdout,
+			expected: &StreamSink{},
+		},
+		"tar": {
+			mode:     OutputModeTar,
+			expected: &ArchiveSink{},
+		},
+		"dry run takes precedence": {
+			mode:     OutputModeZip,
+			dryRun:   true,
+			expected: &DryRunSink{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sink, err := NewOutputSink(OutputOptions{Mode: test.mode, Root: "./", DryRun: test.dryRun, Stdout: &bytes.Buffer{}})
+			require.NoError(t, err)
+			assert.IsType(t, test.expected, sink)
+		})
+	}
+}
+
+func TestOutputContext(t *testing.T) {
+	assert.Equal(t, DiskSink{}, GetOutput(context.Background()))
+
+	sink := NewMemorySink()
+	assert.Same(t, sink, GetOutput(WithOutput(context.Background(), sink)))
+}
+
+func TestDiskSink(t *testing.T) {
+	path := filepath.Join("testdata", "res", "disk", "nested", "file.tf")
+	sink := DiskSink{}
+
+	require.NoError(t, sink.Check(path))
+	require.NoError(t, sink.WriteFile(path, []byte("content")))
+	require.NoError(t, sink.Close())
+
+	res, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(res))
+	assert.Error(t, sink.Check(path))
+}
+
+func TestDiskSinkConflicts(t *testing.T) {
+	tests := map[string]struct {
+		conflict     ConflictPolicy
+		withCheckErr bool
+		expected     string
+		backup       bool
+		warnings     string
+	}{
+		"fail": {
+			conflict:     ConflictFail,
+			withCheckErr: true,
+			expected:     "variable \"b\" {}\n",
+		},
+		"overwrite": {
+			conflict: ConflictOverwrite,
+			expected: "variable \"b\" {}\n",
+		},
+		"backup": {
+			conflict: ConflictBackup,
+			expected: "variable \"b\" {}\n",
+			backup:   true,
+		},
+		"merge": {
+			conflict: ConflictMerge,
+			expected: "variable \"a\" {}\n\nvariable \"b\" {}\n",
+		},
+		"merge with duplicate": {
+			conflict: ConflictMerge,
+			expected: "variable \"a\" {}\n\nvariable \"b\" {}\n",
+			warnings: "Warning: variable \"a\" already exists in 'testdata/res/conflicts/variables.tf', keeping existing definition\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join("testdata", "res", "conflicts")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+			path := filepath.Join(dir, "variables.tf")
+			require.NoError(t, os.WriteFile(path, []byte("variable \"a\" {}\n"), 0644))
+
+			generated := "variable \"b\" {}\n"
+			if test.warnings != "" {
+				generated = "variable \"a\" {\n  default = 1\n}\n" + generated
+			}
+			warnings := bytes.Buffer{}
+			sink := DiskSink{Conflict: test.conflict, Warnings: &warnings}
+			if test.withCheckErr {
+				assert.Error(t, sink.Check(path))
+			} else {
+				assert.NoError(t, sink.Check(path))
+			}
+			require.NoError(t, sink.WriteFile(path, []byte(generated)))
+
+			res, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(res))
+			assert.Equal(t, test.warnings, warnings.String())
+			backups, err := filepath.Glob(path + ".*.bak")
+			require.NoError(t, err)
+			assert.Equal(t, test.backup, len(backups) == 1)
+		})
+	}
+}
+
+func TestMemorySink(t *testing.T) {
+	sink := NewMemorySink()
+	require.NoError(t, sink.WriteFile("b.tf", []byte("b")))
+	require.NoError(t, sink.WriteFile("a.tf", []byte("a")))
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, []string{"a.tf", "b.tf"}, sink.Files())
+	content, ok := sink.File("b.tf")
+	assert.True(t, ok)
+	assert.Equal(t, "b", string(content))
+	_, ok = sink.File("c.tf")
+	assert.False(t, ok)
+}
+
+func TestStreamSink(t *testing.T) {
+	buf := bytes.Buffer{}
+	sink := NewStreamSink(&buf, "work")
+	require.NoError(t, sink.WriteFile(filepath.Join("work", "main.tf"), []byte("resource \"a\" \"b\" {}\n")))
+	require.NoError(t, sink.WriteFile(filepath.Join("work", "rules", "main.json"), []byte("{}")))
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, `# ==> main.tf <==
+resource "a" "b" {}
+
+# ==> rules/main.json <==
+{}
+`, buf.String())
+}
+
+func TestArchiveSink(t *testing.T) {
+	files := map[string]string{
+		"main.tf":         "resource",
+		"rules/main.json": "{}",
+	}
+
+	t.Run("tar", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		sink := NewArchiveSink(OutputModeTar, &buf, "work")
+		for name, content := range files {
+			require.NoError(t, sink.WriteFile(filepath.Join("work", name), []byte(content)))
+		}
+		require.NoError(t, sink.Close())
+
+		res := map[string]string{}
+		r := tar.NewReader(&buf)
+		for {
+			header, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			content, err := io.ReadAll(r)
+			require.NoError(t, err)
+			res[header.Name] = string(content)
+		}
+		assert.Equal(t, files, res)
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		sink := NewArchiveSink(OutputModeZip, &buf, "work")
+		for name, content := range files {
+			require.NoError(t, sink.WriteFile(filepath.Join("work", name), []byte(content)))
+		}
+		require.NoError(t, sink.Close())
+
+		res := map[string]string{}
+		r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		for _, f := range r.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			res[f.Name] = string(content)
+		}
+		assert.Equal(t, files, res)
+	})
+}
+
+func TestDryRunSink(t *testing.T) {
+	buf := bytes.Buffer{}
+	path := filepath.Join("testdata", "res", "dry-run.tf")
+	sink := NewDryRunSink(&buf, "testdata")
+	require.NoError(t, sink.Check(path))
+	require.NoError(t, sink.WriteFile(path, []byte("content")))
+	require.NoError(t, sink.Close())
+
+	_, err := os.Stat(path)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	assert.Equal(t, "Dry run, 1 file(s) would be written:\n  res/dry-run.tf (7 bytes)\n", buf.String())
+}
+
+func TestReadOutput(t *testing.T) {
+	tests := map[string]struct {
+		sink     func(dir string) OutputSink
+		expected map[string]string
+	}{
+		"disk": {
+			sink:     func(string) OutputSink { return DiskSink{} },
+			expected: map[string]string{"existing.tf": "existing", "written.tf": "written"},
+		},
+		"memory": {
+			sink:     func(string) OutputSink { return NewMemorySink() },
+			expected: map[string]string{"written.tf": "written"},
+		},
+		"read through": {
+			sink:     func(string) OutputSink { return NewReadThroughSink() },
+			expected: map[string]string{"existing.tf": "existing", "written.tf": "written"},
+		},
+		"dry run": {
+			sink:     func(dir string) OutputSink { return NewDryRunSink(io.Discard, dir) },
+			expected: map[string]string{"existing.tf": "existing", "written.tf": "written"},
+		},
+		"staging": {
+			sink:     func(dir string) OutputSink { return NewStagingSink(DiskSink{}, dir) },
+			expected: map[string]string{"existing.tf": "existing", "written.tf": "written"},
+		},
+		"naming and report wrapping staging": {
+			sink: func(dir string) OutputSink {
+				return NewNamingSink(NewReportSink(NewStagingSink(DiskSink{}, dir), &Report{}, dir), Naming{Style: NamingSnake})
+			},
+			expected: map[string]string{"existing.tf": "existing", "written.tf": "written"},
+		},
+		"stdout": {
+			sink:     func(string) OutputSink { return NewStreamSink(io.Discard, "") },
+			expected: map[string]string{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.tf"), []byte("existing"), 0644))
+			sink := test.sink(dir)
+			require.NoError(t, sink.WriteFile(filepath.Join(dir, "written.tf"), []byte("written")))
+
+			var expectedPaths []string
+			for _, file := range []string{"existing.tf", "written.tf"} {
+				content, err := ReadOutput(sink, filepath.Join(dir, file))
+				expected, ok := test.expected[file]
+				if !ok {
+					assert.True(t, errors.Is(err, os.ErrNotExist), "expected: %s; got: %s", os.ErrNotExist, err)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, expected, string(content))
+				expectedPaths = append(expectedPaths, filepath.Join(dir, file))
+			}
+			paths, err := GlobOutput(sink, filepath.Join(dir, "*.tf"))
+			require.NoError(t, err)
+			assert.Equal(t, expectedPaths, paths)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// TemplateDirEnv is the environment variable with the directory of user templates, it can be used instead of --template-dir flag
+const TemplateDirEnv = "AKAMAI_TERRAFORM_TEMPLATE_DIR"
+
+// OverlayFS serves templates from Base, replacing every template with the file of the same name from Overrides,
+// e.g. templates/property.tmpl is replaced by property.tmpl from Overrides if it exists
+// Files in Overrides which do not replace any template are ignored
+type OverlayFS struct {
+	Base      fs.FS
+	Overrides fs.FS
+}
+
+var (
+	// ErrTemplateDir is returned when the directory of user templates cannot be used
+	ErrTemplateDir = errors.New("invalid template directory")
+	// ErrTemplateParsing is returned when templates cannot be parsed, e.g. because of syntax errors in user templates
+	ErrTemplateParsing = errors.New("parsing templates")
+
+	_ fs.FS = OverlayFS{}
+)
+
+// NewOverlayFS returns templates from base overridden by templates from dir, base is returned as is if dir is empty
+func NewOverlayFS(base fs.FS, dir string) (fs.FS, error) {
+	if dir == "" {
+		return base, nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%w: '%s' is not a directory", ErrTemplateDir, dir)
+	}
+	return OverlayFS{Base: base, Overrides: os.DirFS(dir)}, nil
+}
+
+// Open opens the named file from Overrides if it replaces a template from Base, otherwise from Base
+func (o OverlayFS) Open(name string) (fs.File, error) {
+	file, err := o.Base.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if path.Ext(name) != ".tmpl" {
+		return file, nil
+	}
+	info, err := fs.Stat(o.Overrides, path.Base(name))
+	if errors.Is(err, fs.ErrNotExist) || err == nil && info.IsDir() {
+		return file, nil
+	}
+	_ = file.Close()
+	if err != nil {
+		return nil, err
+	}
+	return o.Overrides.Open(path.Base(name))
+}
+
+// DescribeData returns Go definitions of the type of data passed to templates and of all struct types it refers to, e.g.
+//
+//	type papi.TFData struct {
+//		Section string
+//		Property papi.TFPropertyData
+//	}
+func DescribeData(data interface{}) string {
+	var sb strings.Builder
+	seen := make(map[reflect.Type]bool)
+	queue := []reflect.Type{reflect.TypeOf(data)}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			if t.Kind() == reflect.Map {
+				queue = append(queue, t.Key())
+			}
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || t.Name() == "" || seen[t] || t.PkgPath() == "time" {
+			continue
+		}
+		seen[t] = true
+
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("type %s struct {\n", t.String()))
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Anonymous {
+				sb.WriteString(fmt.Sprintf("\t%s\n", field.Type.String()))
+			} else {
+				sb.WriteString(fmt.Sprintf("\t%s %s\n", field.Name, field.Type.String()))
+			}
+			queue = append(queue, field.Type)
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+package templates
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlayFS(t *testing.T) {
+	base := fstest.MapFS{
+		"templates/main.tmpl":      {Data: []byte("embedded main")},
+		"templates/maps/geo.tmpl":  {Data: []byte("embedded geo")},
+		"templates/variables.tmpl": {Data: []byte("embedded variables")},
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tmpl"), []byte("custom main"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "geo.tmpl"), []byte("custom geo"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.tmpl"), []byte("other"), 0644))
+	file := filepath.Join(dir, "main.tmpl")
+
+	tests := map[string]struct {
+		dir       string
+		expected  map[string]string
+		withError error
+	}{
+		"no template directory": {
+			expected: map[string]string{
+				"templates/main.tmpl":      "embedded main",
+				"templates/maps/geo.tmpl":  "embedded geo",
+				"templates/variables.tmpl": "embedded variables",
+			},
+		},
+		"templates replaced by name": {
+			dir: dir,
+			expected: map[string]string{
+				"templates/main.tmpl":      "custom main",
+				"templates/maps/geo.tmpl":  "custom geo",
+				"templates/variables.tmpl": "embedded variables",
+			},
+		},
+		"template directory does not exist": {
+			dir:       filepath.Join(dir, "missing"),
+			withError: ErrTemplateDir,
+		},
+		"template directory is a file": {
+			dir:       file,
+			withError: ErrTemplateDir,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fsys, err := NewOverlayFS(base, test.dir)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "want: %s; got: %s", test.withError, err)
+				return
+			}
+			require.NoError(t, err)
+			files, err := findTemplateFiles(fsys)
+			require.NoError(t, err)
+			assert.Len(t, files, len(test.expected))
+			for path, expected := range test.expected {
+				content, err := fs.ReadFile(fsys, path)
+				require.NoError(t, err)
+				assert.Equal(t, expected, string(content))
+			}
+		})
+	}
+}
+
+func TestDescribeData(t *testing.T) {
+	type (
+		item struct {
+			Name  string
+			Items []*item
+		}
+		data struct {
+			Section string
+			Items   map[string]item
+			hidden  bool
+		}
+	)
+
+	assert.Equal(t, `type templates.data struct {
+	Section string
+	Items map[string]templates.item
+}
+
+type templates.item struct {
+	Name string
+	Items []*templates.item
+}
+`, DescribeData(data{hidden: true}))
+}
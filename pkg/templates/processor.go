@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
-	"os"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -34,10 +33,28 @@ type (
 	// as well as a map which stores template names with target files to which the result should be written
 	// All templates within TemplatesFS should have .tmpl extension
 	// AdditionalFuncs can be used to add custom template functions
+	// ImportStyle decides if templates named *imports.tmpl produce a script or terraform import blocks
+	// Output is the sink to which results are written, DiskSink is used if it is not set
+	// TemplateDir, if set, is a directory with user templates replacing the ones from TemplatesFS with the same file name
+	// Secrets decides where values of secrets passed to 'secret' template function are kept, see SecretsMode
+	// Versions decides the engine and versions required by generated configuration
+	// MinProviderVersion, if set, is the minimal version of the akamai provider required by the templates,
+	// versions.tf and provider.tf are then written into the top directory of TemplateTargets
+	// SkipProvider prevents writing provider.tf, if the templates configure the provider themselves
+	// Backend, if its type is set, is written into backend.tf in the top directory of TemplateTargets
+	// together with bootstrap.sh running the generated imports
 	FSTemplateProcessor struct {
-		TemplatesFS     fs.FS
-		TemplateTargets map[string]string
-		AdditionalFuncs template.FuncMap
+		TemplatesFS        fs.FS
+		TemplateTargets    map[string]string
+		AdditionalFuncs    template.FuncMap
+		ImportStyle        ImportStyle
+		Output             OutputSink
+		TemplateDir        string
+		Secrets            SecretsMode
+		Versions           Versions
+		MinProviderVersion string
+		SkipProvider       bool
+		Backend            Backend
 	}
 )
 
@@ -52,15 +69,32 @@ var (
 
 // ProcessTemplates parses templates located in fs.FS and executes them using the provided data
 // result of each template execution is persisted in location provided in FSTemplateProcessor.TemplateTargets
+// using FSTemplateProcessor.Output
+// Secrets used by the templates are declared as sensitive variables in secrets.tf next to the files using them
+// and, if FSTemplateProcessor.MinProviderVersion is set, versions.tf and provider.tf are written as well
+// as backend.tf and bootstrap.sh, if FSTemplateProcessor.Backend is set
+// Generated .tf files are validated before any of them is written, see ErrInvalidConfiguration
 func (t FSTemplateProcessor) ProcessTemplates(data interface{}, filterFuncs ...func([]string) ([]string, error)) error {
+	var usedSecrets Secrets
+	var targetDir, importPath string
 	funcs := template.FuncMap{
 		"escape":        tools.EscapeQuotedStringLit,
 		"formatIntList": formatIntList,
 		"toJSON":        tools.ToJSON,
 		"escapeName":    tools.EscapeName,
 		"toList":        tools.ToList,
+		"secret": func(name, value string) string {
+			return usedSecrets.Add(targetDir, name, value)
+		},
+		"providerSource":  t.Versions.Engine.ProviderSource,
+		"providerVersion": t.Versions.ProviderConstraint,
+		"requiredVersion": t.Versions.EngineConstraint,
 	}
-	files, err := findTemplateFiles(t.TemplatesFS)
+	templatesFS, err := NewOverlayFS(t.TemplatesFS, t.TemplateDir)
+	if err != nil {
+		return err
+	}
+	files, err := findTemplateFiles(templatesFS)
 	if err != nil {
 		return fmt.Errorf("%s: %s", "error filtering template files", err)
 	}
@@ -72,27 +106,70 @@ func (t FSTemplateProcessor) ProcessTemplates(data interface{}, filterFuncs ...f
 		}
 	}
 
-	tmpl := template.Must(template.New("templates").Funcs(funcs).Funcs(t.AdditionalFuncs).
-		ParseFS(t.TemplatesFS, files...))
+	output := t.Output
+	if output == nil {
+		output = DiskSink{}
+	}
+
+	tmpl, err := template.New("templates").Funcs(funcs).Funcs(t.AdditionalFuncs).ParseFS(templatesFS, files...)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrTemplateParsing, err)
+	}
 
+	rendered := make(map[string][]byte, len(t.TemplateTargets))
 	for templateName, targetPath := range t.TemplateTargets {
 		buf := bytes.Buffer{}
+		targetDir = filepath.Dir(targetPath)
 
 		if err := tmpl.Lookup(templateName).Execute(&buf, data); err != nil {
 			return fmt.Errorf("%w: %s: %s", ErrTemplateExecution, templateName, err)
 		}
 		out := buf.Bytes()
+		if isImportsTemplate(templateName) {
+			if t.ImportStyle == ImportStyleBlocks {
+				if out, err = ImportBlocks(out); err != nil {
+					return fmt.Errorf("%s: %w", templateName, err)
+				}
+			} else {
+				out = t.Backend.Script(t.Versions.Engine.Script(out), t.Versions.Engine)
+			}
+		}
 		if len(bytes.TrimSpace(out)) == 0 {
 			continue
 		}
+		if isImportsTemplate(templateName) {
+			importPath = targetPath
+		}
 		if filepath.Ext(targetPath) == ".tf" {
 			out = hclwrite.Format(out)
 		}
-		if err := os.WriteFile(targetPath, out, 0644); err != nil {
+		rendered[targetPath] = out
+	}
+	if err := validateConfiguration(output, rendered, usedSecrets.Names()); err != nil {
+		return err
+	}
+	for targetPath, out := range rendered {
+		if err := output.WriteFile(targetPath, out); err != nil {
 			return fmt.Errorf("%w: '%s': %s", ErrSavingFiles, targetPath, err)
 		}
 	}
-	return nil
+	if err := usedSecrets.Write(output, t.Secrets); err != nil {
+		return err
+	}
+	dir := topDir(t.TemplateTargets)
+	if err := t.Backend.Write(output, dir, importPath, t.ImportStyle, t.Versions.Engine); err != nil {
+		return err
+	}
+	if t.MinProviderVersion == "" {
+		return nil
+	}
+	if err := t.Versions.WriteVersions(output, dir, t.MinProviderVersion); err != nil {
+		return err
+	}
+	if t.SkipProvider {
+		return nil
+	}
+	return WriteProvider(output, dir)
 }
 
 // AddTemplateTarget provides ability to specify additional template target after the processor was created
@@ -100,9 +177,14 @@ func (t FSTemplateProcessor) AddTemplateTarget(templateName, targetPath string)
 	t.TemplateTargets[templateName] = targetPath
 }
 
-// TemplateExists returns information if given template exists
+// TemplateExists returns information if given template exists in templates used by ProcessTemplates,
+// i.e. FSTemplateProcessor.TemplatesFS overridden by templates from FSTemplateProcessor.TemplateDir
 func (t FSTemplateProcessor) TemplateExists(fileName string) bool {
-	files, err := findTemplateFiles(t.TemplatesFS)
+	templatesFS, err := NewOverlayFS(t.TemplatesFS, t.TemplateDir)
+	if err != nil {
+		return false
+	}
+	files, err := findTemplateFiles(templatesFS)
 	if err != nil {
 		return false
 	}
@@ -115,6 +197,18 @@ func (t FSTemplateProcessor) TemplateExists(fileName string) bool {
 	return false
 }
 
+// topDir returns the directory of the target closest to the root, e.g. the root module of generated configuration
+func topDir(targets map[string]string) string {
+	var dir string
+	for _, target := range targets {
+		targetDir := filepath.Dir(target)
+		if dir == "" || len(targetDir) < len(dir) || len(targetDir) == len(dir) && targetDir < dir {
+			dir = targetDir
+		}
+	}
+	return dir
+}
+
 func formatIntList(items []int) string {
 	if len(items) == 0 {
 		return "[]"
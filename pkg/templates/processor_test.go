@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,6 +31,8 @@ func TestProcessTemplates(t *testing.T) {
 	tests := map[string]struct {
 		templateDir     string
 		templateTargets map[string]string
+		importStyle     ImportStyle
+		userTemplates   map[string]string
 		data            TestData
 		withError       error
 		expected        map[string]string
@@ -75,6 +78,78 @@ func TestProcessTemplates(t *testing.T) {
 				"./testdata/res/res.txt": "This nests template 1: Hello",
 			},
 		},
+		"imports as script": {
+			templateDir: "./testdata",
+			templateTargets: map[string]string{
+				"imports.tmpl": "./testdata/res/import.sh",
+			},
+			importStyle: ImportStyleScript,
+			data: TestData{
+				A: "test",
+				B: "ID:1",
+			},
+			expected: map[string]string{
+				"./testdata/res/import.sh": "terraform init\nterraform import akamai_resource.test \"ID:1\"",
+			},
+		},
+		"imports as blocks": {
+			templateDir: "./testdata",
+			templateTargets: map[string]string{
+				"imports.tmpl":  "./testdata/res/imports.tf",
+				"resource.tmpl": "./testdata/res/resource.tf",
+			},
+			importStyle: ImportStyleBlocks,
+			data: TestData{
+				A: "test",
+				B: "ID:1",
+			},
+			expected: map[string]string{
+				"./testdata/res/imports.tf": "import {\n  to = akamai_resource.test\n  id = \"ID:1\"\n}\n",
+			},
+		},
+		"user templates replace the ones with the same name": {
+			templateDir: "./testdata",
+			templateTargets: map[string]string{
+				"2.tmpl":            "./testdata/res/2.txt",
+				"with_nesting.tmpl": "./testdata/res/res.txt",
+			},
+			userTemplates: map[string]string{
+				"1.tmpl":     "Custom {{.A}}",
+				"other.tmpl": "{{.Unknown}}",
+			},
+			data: TestData{
+				A: "Hello",
+				B: "World",
+			},
+			expected: map[string]string{
+				"./testdata/res/2.txt":   "World",
+				"./testdata/res/res.txt": "This nests template 1: Custom Hello",
+			},
+		},
+		"error parsing user template": {
+			templateDir: "./testdata",
+			templateTargets: map[string]string{
+				"1.tmpl": "./testdata/res/1.txt",
+			},
+			userTemplates: map[string]string{
+				"1.tmpl": "{{.A",
+			},
+			withError: ErrTemplateParsing,
+		},
+		"invalid generated configuration": {
+			templateDir: "./testdata",
+			templateTargets: map[string]string{
+				"resource.tmpl": "./testdata/res/invalid.tf",
+			},
+			data: TestData{
+				A: "test",
+				B: `"quoted"`,
+			},
+			expected: map[string]string{
+				"./testdata/res/invalid.tf": "",
+			},
+			withError: ErrInvalidConfiguration,
+		},
 		"error executing template": {
 			templateDir: "./testdata",
 			templateTargets: map[string]string{
@@ -93,13 +168,20 @@ func TestProcessTemplates(t *testing.T) {
 			processor := FSTemplateProcessor{
 				TemplatesFS:     templateFS,
 				TemplateTargets: test.templateTargets,
+				ImportStyle:     test.importStyle,
+			}
+			if test.userTemplates != nil {
+				processor.TemplateDir = t.TempDir()
+				for name, content := range test.userTemplates {
+					require.NoError(t, os.WriteFile(filepath.Join(processor.TemplateDir, name), []byte(content), 0644))
+				}
 			}
 			err := processor.ProcessTemplates(test.data)
 			if test.withError != nil {
 				assert.True(t, errors.Is(err, test.withError), "expected: %s; got: %s", test.withError, err)
-				return
+			} else {
+				require.NoError(t, err)
 			}
-			require.NoError(t, err)
 			for path, val := range test.expected {
 				if val == "" {
 					_, err = os.Stat(path)
@@ -113,9 +195,30 @@ func TestProcessTemplates(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessTemplatesOutput(t *testing.T) {
+	sink := NewMemorySink()
+	processor := FSTemplateProcessor{
+		TemplatesFS: os.DirFS("./testdata"),
+		TemplateTargets: map[string]string{
+			"1.tmpl":     "./testdata/res/output/1.txt",
+			"empty.tmpl": "./testdata/res/output/empty.txt",
+		},
+		Output: sink,
+	}
+	require.NoError(t, processor.ProcessTemplates(TestData{A: "Hello"}))
+
+	assert.Equal(t, []string{"./testdata/res/output/1.txt"}, sink.Files())
+	content, _ := sink.File("./testdata/res/output/1.txt")
+	assert.Equal(t, "Hello", string(content))
+	_, err := os.Stat("./testdata/res/output")
+	assert.True(t, errors.Is(err, os.ErrNotExist), "expected nothing written to disk")
+}
+
 func TestCheckTemplate(t *testing.T) {
 	tests := map[string]struct {
 		templateDir     string
+		overridesDir    string
 		templateTargets map[string]string
 		data            string
 		exists          bool
@@ -138,6 +241,24 @@ func TestCheckTemplate(t *testing.T) {
 			data:   "3.tmpl",
 			exists: false,
 		},
+		"check existing template with user templates": {
+			templateDir:  "./testdata",
+			overridesDir: "./testdata/templates",
+			templateTargets: map[string]string{
+				"1.tmpl": "./testdata/res/1.tf",
+			},
+			data:   "1.tmpl",
+			exists: true,
+		},
+		"check template with invalid directory of user templates": {
+			templateDir:  "./testdata",
+			overridesDir: "./testdata/missing",
+			templateTargets: map[string]string{
+				"1.tmpl": "./testdata/res/1.tf",
+			},
+			data:   "1.tmpl",
+			exists: false,
+		},
 	}
 
 	for name, test := range tests {
@@ -145,6 +266,7 @@ func TestCheckTemplate(t *testing.T) {
 			templateFS := os.DirFS(test.templateDir)
 			processor := FSTemplateProcessor{
 				TemplatesFS:     templateFS,
+				TemplateDir:     test.overridesDir,
 				TemplateTargets: test.templateTargets,
 			}
 			ok := processor.TemplateExists(test.data)
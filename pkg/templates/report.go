@@ -0,0 +1,357 @@
+package templates
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ReportFileName is the name of the file with the export report written into the working directory
+const ReportFileName = "export-report.json"
+
+type (
+	// Report collects information about a single export: fetched API objects, generated files,
+	// terraform resources with their import IDs and warnings
+	Report struct {
+		mu        sync.Mutex
+		version   string
+		command   string
+		arguments []string
+		flags     map[string]string
+		objects   []ReportObject
+		files     []reportFile
+		warnings  []string
+	}
+
+	// ReportObject is an API object fetched during export
+	ReportObject struct {
+		Type    string `json:"type"`
+		ID      string `json:"id"`
+		Name    string `json:"name,omitempty"`
+		Version string `json:"version,omitempty"`
+	}
+
+	// ReportFile is a generated file with SHA-256 checksum of its content
+	ReportFile struct {
+		Path   string `json:"path"`
+		SHA256 string `json:"sha256"`
+	}
+
+	// ReportResource is a terraform resource created by the export
+	ReportResource struct {
+		Address  string `json:"address"`
+		File     string `json:"file,omitempty"`
+		ImportID string `json:"import_id,omitempty"`
+	}
+
+	reportJSON struct {
+		Version   string            `json:"version"`
+		Command   string            `json:"command"`
+		Arguments []string          `json:"arguments"`
+		Flags     map[string]string `json:"flags,omitempty"`
+		Objects   []ReportObject    `json:"objects"`
+		Files     []ReportFile      `json:"files"`
+		Resources []ReportResource  `json:"resources"`
+		Warnings  []string          `json:"warnings"`
+	}
+
+	// reportFile keeps declarations found in the generated file, which are resolved into resources
+	// once all files are written
+	reportFile struct {
+		ReportFile
+		resources []string
+		modules   map[string]string
+		imports   map[string]string
+	}
+
+	// ReportSink records files written to the wrapped sink in the report, the report is written on Close
+	ReportSink struct {
+		OutputSink
+		Report *Report
+		Root   string
+	}
+
+	reportContextKey struct{}
+)
+
+var (
+	_ OutputSink = &ReportSink{}
+
+	reportSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "resource", LabelNames: []string{"type", "name"}},
+			{Type: "module", LabelNames: []string{"name"}},
+			{Type: "import"},
+		},
+	}
+	moduleSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "source"}},
+	}
+	importSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "to"}, {Name: "id"}},
+	}
+)
+
+// NewReport returns empty report of the command run with the given arguments and flags
+func NewReport(version, command string, arguments []string, flags map[string]string) *Report {
+	if arguments == nil {
+		arguments = []string{}
+	}
+	return &Report{version: version, command: command, arguments: arguments, flags: flags}
+}
+
+// WithReport returns context with the given report
+func WithReport(ctx context.Context, report *Report) context.Context {
+	return context.WithValue(ctx, reportContextKey{}, report)
+}
+
+// GetReport returns report stored in the context, if there is none an empty report is returned,
+// so that exporters can record objects and warnings regardless of whether the report is written
+func GetReport(ctx context.Context) *Report {
+	if report, ok := ctx.Value(reportContextKey{}).(*Report); ok {
+		return report
+	}
+	return &Report{}
+}
+
+// AddObject records API object fetched during export
+func (r *Report) AddObject(object ReportObject) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.objects = append(r.objects, object)
+}
+
+// AddWarning records a warning, e.g. about skipped configuration
+func (r *Report) AddWarning(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warnings = append(r.warnings, fmt.Sprintf(format, args...))
+}
+
+// AddFile records generated file with the given slash separated path relative to the working directory
+// Resources, modules and imports declared in terraform files and import scripts are collected from the content
+func (r *Report) AddFile(name string, content []byte) {
+	sum := sha256.Sum256(content)
+	file := reportFile{ReportFile: ReportFile{Path: name, SHA256: hex.EncodeToString(sum[:])}}
+	switch {
+	case strings.HasSuffix(name, ".tf"), strings.HasSuffix(name, ".tf.json"):
+		file.collectDeclarations(content)
+	case strings.HasSuffix(name, ".sh"), strings.HasSuffix(name, ".script"):
+		file.collectImportCommands(content)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, f := range r.files {
+		if f.Path == name {
+			r.files[i] = file
+			return
+		}
+	}
+	r.files = append(r.files, file)
+}
+
+// MarshalJSON writes the report with resources resolved from all recorded files
+func (r *Report) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := reportJSON{
+		Version:   r.version,
+		Command:   r.command,
+		Arguments: r.arguments,
+		Flags:     r.flags,
+		Objects:   append([]ReportObject{}, r.objects...),
+		Files:     make([]ReportFile, 0, len(r.files)),
+		Resources: r.resources(),
+		Warnings:  append([]string{}, r.warnings...),
+	}
+	for _, f := range r.files {
+		res.Files = append(res.Files, f.ReportFile)
+	}
+	sort.Slice(res.Files, func(i, j int) bool {
+		return res.Files[i].Path < res.Files[j].Path
+	})
+	return marshalJSON(res)
+}
+
+// resources returns resources declared in terraform files with addresses prefixed with the calling modules
+// and import IDs found in import scripts or import blocks, imported resources which are not declared
+// in any of the files are returned without the file
+func (r *Report) resources() []ReportResource {
+	// module calls by the directory of the module
+	calls := make(map[string]struct{ dir, name string })
+	imports := make(map[string]string)
+	for _, f := range r.files {
+		dir := path.Dir(f.Path)
+		for name, source := range f.modules {
+			calls[path.Join(dir, source)] = struct{ dir, name string }{dir: dir, name: name}
+		}
+		for address, id := range f.imports {
+			imports[address] = id
+		}
+	}
+	var prefix func(dir string, depth int) string
+	prefix = func(dir string, depth int) string {
+		call, ok := calls[dir]
+		if !ok || depth > len(calls) {
+			return ""
+		}
+		return prefix(call.dir, depth+1) + "module." + call.name + "."
+	}
+
+	res := make([]ReportResource, 0)
+	declared := make(map[string]bool)
+	for _, f := range r.files {
+		for _, address := range f.resources {
+			address = prefix(path.Dir(f.Path), 0) + address
+			declared[address] = true
+			res = append(res, ReportResource{Address: address, File: f.Path, ImportID: imports[address]})
+		}
+	}
+	for address, id := range imports {
+		if !declared[address] {
+			res = append(res, ReportResource{Address: address, ImportID: id})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].File != res[j].File {
+			return res[i].File < res[j].File
+		}
+		return res[i].Address < res[j].Address
+	})
+	return res
+}
+
+func (f *reportFile) collectDeclarations(content []byte) {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(f.Path, ".json") {
+		file, diags = hcljson.Parse(content, f.Path)
+	} else {
+		file, diags = hclsyntax.ParseConfig(content, f.Path, hcl.InitialPos)
+	}
+	if diags.HasErrors() {
+		return
+	}
+	body, _, _ := file.Body.PartialContent(reportSchema)
+	for _, block := range body.Blocks {
+		switch block.Type {
+		case "resource":
+			f.resources = append(f.resources, strings.Join(block.Labels, "."))
+		case "module":
+			module, _, _ := block.Body.PartialContent(moduleSchema)
+			if source, ok := module.Attributes["source"]; ok {
+				if value, diags := source.Expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String && !value.IsNull() {
+					if f.modules == nil {
+						f.modules = make(map[string]string)
+					}
+					f.modules[block.Labels[0]] = value.AsString()
+				}
+			}
+		case "import":
+			attributes, _, _ := block.Body.PartialContent(importSchema)
+			to, id := attributes.Attributes["to"], attributes.Attributes["id"]
+			if to == nil || id == nil {
+				continue
+			}
+			traversal, diags := hcl.AbsTraversalForExpr(to.Expr)
+			if diags.HasErrors() {
+				continue
+			}
+			value, diags := id.Expr.Value(nil)
+			if diags.HasErrors() || value.Type() != cty.String || value.IsNull() {
+				continue
+			}
+			f.addImport(traversalString(traversal), value.AsString())
+		}
+	}
+}
+
+func (f *reportFile) collectImportCommands(content []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		_, args, found := cutImportCommand(strings.TrimSpace(scanner.Text()))
+		if !found {
+			continue
+		}
+		if address, id, err := parseImportCommand(args); err == nil {
+			f.addImport(address, id)
+		}
+	}
+}
+
+func (f *reportFile) addImport(address, id string) {
+	if f.imports == nil {
+		f.imports = make(map[string]string)
+	}
+	f.imports[address] = id
+}
+
+func traversalString(traversal hcl.Traversal) string {
+	var sb strings.Builder
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			sb.WriteString(s.Name)
+		case hcl.TraverseAttr:
+			sb.WriteString("." + s.Name)
+		case hcl.TraverseIndex:
+			switch {
+			case s.Key.Type() == cty.String:
+				sb.WriteString(fmt.Sprintf("[%q]", s.Key.AsString()))
+			case s.Key.Type() == cty.Number:
+				sb.WriteString(fmt.Sprintf("[%s]", s.Key.AsBigFloat().Text('f', -1)))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// NewReportSink returns ReportSink recording files written to sink in the report
+func NewReportSink(sink OutputSink, report *Report, root string) *ReportSink {
+	return &ReportSink{OutputSink: sink, Report: report, Root: root}
+}
+
+// WriteFile writes the file and records it in the report
+func (s *ReportSink) WriteFile(path string, content []byte) error {
+	if err := s.OutputSink.WriteFile(path, content); err != nil {
+		return err
+	}
+	s.Report.AddFile(relativePath(s.Root, path), content)
+	return nil
+}
+
+// Close writes the report into the working directory and closes the wrapped sink
+func (s *ReportSink) Close() error {
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s.Report); err != nil {
+		return err
+	}
+	if err := s.OutputSink.WriteFile(filepath.Join(s.Root, ReportFileName), buf.Bytes()); err != nil {
+		return err
+	}
+	return s.OutputSink.Close()
+}
+
+// Unwrap returns the wrapped sink
+func (s *ReportSink) Unwrap() OutputSink {
+	return s.OutputSink
+}
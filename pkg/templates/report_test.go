@@ -0,0 +1,169 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportResources(t *testing.T) {
+	tests := map[string]struct {
+		files    map[string]string
+		expected []ReportResource
+	}{
+		"resources with import script": {
+			files: map[string]string{
+				"property.tf": `
+resource "akamai_property" "test" {
+  name = "test"
+}
+
+data "akamai_group" "group" {}
+`,
+				"import.sh": `terraform init
+terraform import akamai_property.test prp_1,ctr_1,grp_1,LATEST
+`,
+			},
+			expected: []ReportResource{
+				{Address: "akamai_property.test", File: "property.tf", ImportID: "prp_1,ctr_1,grp_1,LATEST"},
+			},
+		},
+		"resources with dns import script": {
+			files: map[string]string{
+				"example.com.tf": `
+resource "akamai_dns_zone" "example_com" {}
+resource "akamai_dns_record" "example_com_www_A" {}
+`,
+				"example_com_resource_import.script": `terraform init
+terraform import akamai_dns_zone.example_com example.com
+terraform import akamai_dns_record.example_com_www_A example.com#www.example.com#A
+`,
+			},
+			expected: []ReportResource{
+				{Address: "akamai_dns_record.example_com_www_A", File: "example.com.tf", ImportID: "example.com#www.example.com#A"},
+				{Address: "akamai_dns_zone.example_com", File: "example.com.tf", ImportID: "example.com"},
+			},
+		},
+		"resources in modules": {
+			files: map[string]string{
+				"appsec.tf": `
+module "security" {
+  source = "./modules/security"
+}
+`,
+				"modules/security/main.tf": `
+resource "akamai_appsec_configuration" "config" {}
+`,
+				"modules/security/nested.tf": `
+module "nested" {
+  source = "./nested"
+}
+`,
+				"modules/security/nested/main.tf": `
+resource "akamai_appsec_rule" "rule" {}
+`,
+				"import.sh": `terraform import module.security.akamai_appsec_configuration.config 1
+terraform import module.security.module.nested.akamai_appsec_rule.rule 1:2
+`,
+			},
+			expected: []ReportResource{
+				{Address: "module.security.akamai_appsec_configuration.config", File: "modules/security/main.tf", ImportID: "1"},
+				{Address: "module.security.module.nested.akamai_appsec_rule.rule", File: "modules/security/nested/main.tf", ImportID: "1:2"},
+			},
+		},
+		"import blocks": {
+			files: map[string]string{
+				"main.tf": `
+resource "akamai_dns_zone" "zone" {}
+resource "akamai_dns_record" "record" {}
+`,
+				"imports.tf": `
+import {
+  to = akamai_dns_zone.zone
+  id = "example.com"
+}
+
+import {
+  to = akamai_dns_record.other["a"]
+  id = "example.com#a#A"
+}
+`,
+			},
+			expected: []ReportResource{
+				{Address: `akamai_dns_record.other["a"]`, ImportID: "example.com#a#A"},
+				{Address: "akamai_dns_record.record", File: "main.tf"},
+				{Address: "akamai_dns_zone.zone", File: "main.tf", ImportID: "example.com"},
+			},
+		},
+		"terraform JSON syntax": {
+			files: map[string]string{
+				"main.tf.json":    `{"resource": {"akamai_edgeworker": {"ew": {"name": "ew"}}}}`,
+				"imports.tf.json": `{"import": [{"to": "akamai_edgeworker.ew", "id": "123"}]}`,
+			},
+			expected: []ReportResource{
+				{Address: "akamai_edgeworker.ew", File: "main.tf.json", ImportID: "123"},
+			},
+		},
+		"invalid file": {
+			files: map[string]string{
+				"main.tf": `resource {`,
+			},
+			expected: []ReportResource{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			report := NewReport("1.0.0", "export-test", nil, nil)
+			for path, content := range test.files {
+				report.AddFile(path, []byte(content))
+			}
+			assert.Equal(t, test.expected, report.resources())
+		})
+	}
+}
+
+func TestReportContext(t *testing.T) {
+	assert.NotNil(t, GetReport(context.Background()))
+
+	report := NewReport("1.0.0", "export-test", nil, nil)
+	assert.Same(t, report, GetReport(WithReport(context.Background(), report)))
+}
+
+func TestReportSink(t *testing.T) {
+	memory := NewMemorySink()
+	report := NewReport("1.0.0", "export-property", []string{"test"}, map[string]string{"tfworkpath": "out"})
+	sink := NewReportSink(memory, report, "out")
+
+	report.AddObject(ReportObject{Type: "property", ID: "prp_1", Name: "test", Version: "3"})
+	report.AddWarning("skipped %s", "something")
+	require.NoError(t, sink.WriteFile(filepath.Join("out", "property.tf"), []byte("resource \"akamai_property\" \"test\" {}\n")))
+	require.NoError(t, sink.WriteFile(filepath.Join("out", "import.sh"), []byte("terraform import akamai_property.test prp_1\n")))
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, []string{filepath.Join("out", ReportFileName), filepath.Join("out", "import.sh"), filepath.Join("out", "property.tf")}, memory.Files())
+	content, _ := memory.File(filepath.Join("out", ReportFileName))
+	var res map[string]interface{}
+	require.NoError(t, json.Unmarshal(content, &res))
+	assert.Equal(t, map[string]interface{}{
+		"version":   "1.0.0",
+		"command":   "export-property",
+		"arguments": []interface{}{"test"},
+		"flags":     map[string]interface{}{"tfworkpath": "out"},
+		"objects": []interface{}{
+			map[string]interface{}{"type": "property", "id": "prp_1", "name": "test", "version": "3"},
+		},
+		"files": []interface{}{
+			map[string]interface{}{"path": "import.sh", "sha256": "ed6a5d4d0b79059d1c12a0c3e61c5d24a509e7ecc297dde8654ce3093f32b3d1"},
+			map[string]interface{}{"path": "property.tf", "sha256": "5183ecdab151f6a511823e3570ad5f2840e0a27b93ec420f4141898470a1a85f"},
+		},
+		"resources": []interface{}{
+			map[string]interface{}{"address": "akamai_property.test", "file": "property.tf", "import_id": "prp_1"},
+		},
+		"warnings": []interface{}{"skipped something"},
+	}, res)
+}
@@ -0,0 +1,159 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/akamai/cli-terraform/pkg/tools"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// SecretsMode decides where values of secrets, such as private keys, used in generated configuration are kept
+// Secrets are never written into generated configuration, each of them becomes a sensitive variable instead
+type SecretsMode string
+
+const (
+	// SecretsTFVars writes values of secrets into secrets.auto.tfvars, which is added to .gitignore
+	SecretsTFVars SecretsMode = "tfvars"
+	// SecretsEnv does not write values of secrets, they have to be set with TF_VAR_<name> environment variables
+	SecretsEnv SecretsMode = "env"
+
+	// SecretsFileName is the name of the file with declarations of secret variables
+	SecretsFileName = "secrets.tf"
+	// SecretsVarsFileName is the name of the file with values of secret variables
+	SecretsVarsFileName = "secrets.auto.tfvars"
+)
+
+// ErrInvalidSecretsMode is returned when unknown secrets mode is requested
+var ErrInvalidSecretsMode = errors.New("invalid secrets mode")
+
+// Secrets collects secrets used by generated configuration, grouped by the directory of the file in which they are used
+// It is used by the 'secret' template function of FSTemplateProcessor and by exporters generating files without it
+type Secrets struct {
+	dirs map[string]map[string]string
+}
+
+// ParseSecretsMode returns SecretsMode for the given name, empty name results in SecretsTFVars
+func ParseSecretsMode(name string) (SecretsMode, error) {
+	return tools.ParseEnum(name, SecretsTFVars, ErrInvalidSecretsMode, SecretsTFVars, SecretsEnv)
+}
+
+// Add records the secret used in a file in dir and returns the reference to its variable
+// The name is converted into snake case identifier, empty value means that the secret is not known,
+// e.g. it is not returned by the API, and has to be provided by the user
+func (s *Secrets) Add(dir, name, value string) string {
+	if s.dirs == nil {
+		s.dirs = make(map[string]map[string]string)
+	}
+	if s.dirs[dir] == nil {
+		s.dirs[dir] = make(map[string]string)
+	}
+	vars := s.dirs[dir]
+
+	base := tools.TerraformIdentifier(strings.Join(nameWords(name), "_"))
+	name = base
+	for i := 2; ; i++ {
+		existing, ok := vars[name]
+		if !ok || existing == value {
+			break
+		}
+		name = base + "_" + strconv.Itoa(i)
+	}
+	vars[name] = value
+	return "var." + name
+}
+
+// Names returns names of variables of the collected secrets, grouped by directory
+func (s *Secrets) Names() map[string][]string {
+	names := make(map[string][]string, len(s.dirs))
+	for dir, vars := range s.dirs {
+		for name := range vars {
+			names[dir] = append(names[dir], name)
+		}
+	}
+	return names
+}
+
+// Write writes declarations of the collected secrets into secrets.tf in the directory in which they are used
+// and, with SecretsTFVars mode, their values into secrets.auto.tfvars ignored by git
+func (s *Secrets) Write(output OutputSink, mode SecretsMode) error {
+	dirs := make([]string, 0, len(s.dirs))
+	for dir := range s.dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		vars := s.dirs[dir]
+		names := make([]string, 0, len(vars))
+		for name := range vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		declarations := hclwrite.NewEmptyFile()
+		values := hclwrite.NewEmptyFile()
+		for i, name := range names {
+			if i > 0 {
+				declarations.Body().AppendNewline()
+			}
+			variable := declarations.Body().AppendNewBlock("variable", []string{name}).Body()
+			variable.SetAttributeTraversal("type", hcl.Traversal{hcl.TraverseRoot{Name: "string"}})
+			variable.SetAttributeValue("sensitive", cty.True)
+
+			if vars[name] == "" {
+				values.Body().AppendUnstructuredTokens(hclwrite.Tokens{{
+					Type:  hclsyntax.TokenComment,
+					Bytes: []byte("# value is not returned by the API, provide it before applying changes\n"),
+				}})
+			}
+			values.Body().SetAttributeValue(name, cty.StringVal(vars[name]))
+		}
+
+		path := filepath.Join(dir, SecretsFileName)
+		if err := output.WriteFile(path, hclwrite.Format(declarations.Bytes())); err != nil {
+			return fmt.Errorf("%w: '%s': %s", ErrSavingFiles, path, err)
+		}
+		if mode == SecretsEnv {
+			continue
+		}
+		path = filepath.Join(dir, SecretsVarsFileName)
+		if err := output.WriteFile(path, hclwrite.Format(values.Bytes())); err != nil {
+			return fmt.Errorf("%w: '%s': %s", ErrSavingFiles, path, err)
+		}
+		if err := gitIgnore(output, dir, SecretsVarsFileName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gitIgnore adds the file name, e.g. secrets.auto.tfvars, to .gitignore in dir, keeping the existing entries
+func gitIgnore(output OutputSink, dir, name string) error {
+	path := filepath.Join(dir, ".gitignore")
+	existing, err := ReadOutput(output, path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: '%s': %s", ErrSavingFiles, path, err)
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == name {
+			return nil
+		}
+	}
+	content := []byte(name + "\n")
+	if len(existing) > 0 {
+		content = mergeLines(existing, content)
+	}
+	if err := output.WriteFile(path, content); err != nil {
+		return fmt.Errorf("%w: '%s': %s", ErrSavingFiles, path, err)
+	}
+	return nil
+}
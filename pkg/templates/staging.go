@@ -0,0 +1,185 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type (
+	// StagingSink writes files into a temporary staging directory and moves them to their destination on Close,
+	// so that an export which fails and is discarded leaves the destination untouched
+	// Files are moved with Disk, which applies its conflict policy to files already existing in the destination
+	StagingSink struct {
+		Disk DiskSink
+
+		mu        sync.Mutex
+		root      string
+		dir       string
+		files     map[string]string
+		discarded bool
+	}
+
+	// unwrapper is implemented by sinks which wrap another sink
+	unwrapper interface {
+		Unwrap() OutputSink
+	}
+
+	// discarder is implemented by sinks which are able to drop files written so far
+	discarder interface {
+		Discard() error
+	}
+)
+
+// StagingDirPattern is the pattern of names of staging directories created in the root directory
+const StagingDirPattern = ".akamai-terraform-staging-*"
+
+var (
+	// ErrStaging is returned when staged files cannot be written or moved into their destination
+	ErrStaging = errors.New("staging generated files")
+
+	_ OutputSink       = &StagingSink{}
+	_ reader           = &StagingSink{}
+	_ conflictResolver = &StagingSink{}
+)
+
+// NewStagingSink returns StagingSink creating its staging directory in root, which should be on the same file system
+// as the destination of written files, so that they can be renamed
+func NewStagingSink(disk DiskSink, root string) *StagingSink {
+	return &StagingSink{Disk: disk, root: root, files: make(map[string]string)}
+}
+
+// Check returns an error if any of the given files cannot be written by Disk
+func (s *StagingSink) Check(paths ...string) error {
+	return s.Disk.Check(paths...)
+}
+
+// ConflictPolicy returns the conflict policy of Disk
+func (s *StagingSink) ConflictPolicy() ConflictPolicy {
+	return s.Disk.Conflict
+}
+
+// WriteFile writes the content into the staging directory, it is moved to path on Close
+func (s *StagingSink) WriteFile(path string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.discarded {
+		return nil
+	}
+	if s.dir == "" {
+		dir, err := os.MkdirTemp(s.root, StagingDirPattern)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrStaging, err)
+		}
+		s.dir = dir
+	}
+	staged, ok := s.files[path]
+	if !ok {
+		staged = filepath.Join(s.dir, strconv.Itoa(len(s.files)))
+	}
+	if err := os.WriteFile(staged, content, 0644); err != nil {
+		return fmt.Errorf("%w: %s", ErrStaging, err)
+	}
+	s.files[path] = staged
+	return nil
+}
+
+// ReadFile returns content of the staged file or, if there is none, of the file existing in the destination
+func (s *StagingSink) ReadFile(path string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if staged, ok := s.files[path]; ok {
+		return os.ReadFile(staged)
+	}
+	return s.Disk.ReadFile(path)
+}
+
+// Glob returns staged files and files existing in the destination matching the pattern
+func (s *StagingSink) Glob(pattern string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.files))
+	for path := range s.files {
+		paths = append(paths, path)
+	}
+	return globUnion(pattern, paths, s.Disk.Glob)
+}
+
+// Close moves staged files to their destination and removes the staging directory
+// Nothing is moved if the sink was discarded
+func (s *StagingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.discarded || s.dir == "" {
+		return nil
+	}
+	defer func() {
+		_ = os.RemoveAll(s.dir)
+		s.dir = ""
+		s.files = make(map[string]string)
+	}()
+
+	paths := make([]string, 0, len(s.files))
+	for path := range s.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := s.move(s.files[path], path); err != nil {
+			return fmt.Errorf("%w: '%s': %s", ErrStaging, path, err)
+		}
+	}
+	return nil
+}
+
+// Discard removes the staging directory with all files written so far, files written afterwards are ignored
+func (s *StagingSink) Discard() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discarded = true
+	if s.dir == "" {
+		return nil
+	}
+	err := os.RemoveAll(s.dir)
+	s.dir = ""
+	s.files = make(map[string]string)
+	return err
+}
+
+// move renames the staged file to path, unless the conflict policy requires processing existing file,
+// the content is written with Disk if the file cannot be renamed, e.g. it is located on another file system
+func (s *StagingSink) move(staged, path string) error {
+	if s.Disk.Conflict != ConflictBackup && s.Disk.Conflict != ConflictMerge {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(staged, path); err == nil {
+			return nil
+		}
+	}
+	content, err := os.ReadFile(staged)
+	if err != nil {
+		return err
+	}
+	return s.Disk.WriteFile(path, content)
+}
+
+// DiscardOutput drops files written so far into the sink or any sink wrapped by it, if it supports that
+// It is used when an export fails, so that no partial results are written
+func DiscardOutput(sink OutputSink) error {
+	for sink != nil {
+		if d, ok := sink.(discarder); ok {
+			return d.Discard()
+		}
+		u, ok := sink.(unwrapper)
+		if !ok {
+			return nil
+		}
+		sink = u.Unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStagingSink(t *testing.T) {
+	tests := map[string]struct {
+		conflict ConflictPolicy
+		wrap     func(OutputSink) OutputSink
+		discard  bool
+		expected map[string]string
+		backup   bool
+	}{
+		"files moved on close": {
+			conflict: ConflictOverwrite,
+			expected: map[string]string{
+				"main.tf":                  "resource \"b\" \"b\" {}\n",
+				"variables.tf":             "variable \"b\" {}\n",
+				"json/policy.json":         "{}",
+				"modules/records/main.tf":  "resource \"c\" \"c\" {}\n",
+				"modules/records/empty.tf": "",
+			},
+		},
+		"existing files backed up": {
+			conflict: ConflictBackup,
+			expected: map[string]string{
+				"variables.tf": "variable \"b\" {}\n",
+			},
+			backup: true,
+		},
+		"existing files merged": {
+			conflict: ConflictMerge,
+			expected: map[string]string{
+				"variables.tf": "variable \"a\" {}\n\nvariable \"b\" {}\n",
+			},
+		},
+		"discarded": {
+			conflict: ConflictOverwrite,
+			discard:  true,
+			expected: map[string]string{
+				"variables.tf": "variable \"a\" {}\n",
+			},
+		},
+		"discarded through wrapping sinks": {
+			conflict: ConflictOverwrite,
+			wrap: func(sink OutputSink) OutputSink {
+				return NewNamingSink(JSONSink{OutputSink: NewReportSink(sink, NewReport("", "", nil, nil), "")}, Naming{})
+			},
+			discard: true,
+			expected: map[string]string{
+				"variables.tf": "variable \"a\" {}\n",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "variables.tf"), []byte("variable \"a\" {}\n"), 0644))
+
+			staging := NewStagingSink(DiskSink{Conflict: test.conflict}, dir)
+			var sink OutputSink = staging
+			if test.wrap != nil {
+				sink = test.wrap(staging)
+			}
+			require.NoError(t, sink.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource \"a\" \"a\" {}\n")))
+			require.NoError(t, sink.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource \"b\" \"b\" {}\n")))
+			require.NoError(t, sink.WriteFile(filepath.Join(dir, "variables.tf"), []byte("variable \"b\" {}\n")))
+			require.NoError(t, sink.WriteFile(filepath.Join(dir, "json", "policy.json"), []byte("{}")))
+			require.NoError(t, sink.WriteFile(filepath.Join(dir, "modules", "records", "main.tf"), []byte("resource \"c\" \"c\" {}\n")))
+			require.NoError(t, sink.WriteFile(filepath.Join(dir, "modules", "records", "empty.tf"), nil))
+
+			// nothing is written into the destination before the sink is closed
+			_, err := os.Stat(filepath.Join(dir, "main.tf"))
+			assert.ErrorIs(t, err, os.ErrNotExist)
+			content, err := os.ReadFile(filepath.Join(dir, "variables.tf"))
+			require.NoError(t, err)
+			assert.Equal(t, "variable \"a\" {}\n", string(content))
+
+			if test.discard {
+				require.NoError(t, DiscardOutput(sink))
+				require.NoError(t, sink.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource \"d\" \"d\" {}\n")))
+				_, err := os.Stat(filepath.Join(dir, "main.tf"))
+				assert.ErrorIs(t, err, os.ErrNotExist)
+			}
+			require.NoError(t, sink.Close())
+
+			for path, expected := range test.expected {
+				content, err := os.ReadFile(filepath.Join(dir, path))
+				require.NoError(t, err)
+				assert.Equal(t, expected, string(content), path)
+			}
+			staged, err := filepath.Glob(filepath.Join(dir, StagingDirPattern))
+			require.NoError(t, err)
+			assert.Empty(t, staged)
+			backups, err := filepath.Glob(filepath.Join(dir, "variables.tf.*.bak"))
+			require.NoError(t, err)
+			assert.Equal(t, test.backup, len(backups) == 1)
+		})
+	}
+}
+
+func TestDiscardOutput(t *testing.T) {
+	assert.NoError(t, DiscardOutput(DiskSink{}))
+	assert.NoError(t, DiscardOutput(JSONSink{OutputSink: NewMemorySink()}))
+}
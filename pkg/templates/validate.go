@@ -0,0 +1,293 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// ErrInvalidConfiguration is returned when generated terraform configuration cannot be parsed,
+// contains references which do not resolve or duplicated addresses
+var ErrInvalidConfiguration = errors.New("invalid generated configuration")
+
+// ValidatingSink keeps terraform files written into the wrapped sink, so that exporters writing files without
+// FSTemplateProcessor can validate them with Validate before the output is closed
+type ValidatingSink struct {
+	OutputSink
+
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+var _ unwrapper = &ValidatingSink{}
+
+// builtinReferences are roots of references provided by terraform, not declared in the configuration
+var builtinReferences = map[string]struct{}{
+	"count":     {},
+	"each":      {},
+	"path":      {},
+	"self":      {},
+	"terraform": {},
+}
+
+// declarations are addresses declared in one module, i.e. one directory, with the range of their declaration
+type declarations map[string]hcl.Range
+
+// validateConfiguration parses generated .tf files and checks that each address, e.g. 'akamai_property.example'
+// or 'var.contract_id', is declared only once in a directory and that all references resolve within the directory
+// Declarations of variables in vars, keyed by directory, e.g. of secrets, and of .tf files already existing
+// in the directories of the output, e.g. created by other exports, are used to resolve references as well
+// Addresses declared again in existing files are reported, unless the file is replaced or merged with a generated one
+func validateConfiguration(output OutputSink, files map[string][]byte, vars map[string][]string) error {
+	var diags hcl.Diagnostics
+	parsed := make(map[string]*hclsyntax.Body)
+	modules := make(map[string]declarations)
+
+	paths := make([]string, 0, len(files))
+	generated := make(map[string]struct{}, len(files))
+	for path := range files {
+		if filepath.Ext(path) == ".tf" {
+			paths = append(paths, path)
+			generated[filepath.Clean(path)] = struct{}{}
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		file, fileDiags := hclsyntax.ParseConfig(files[path], path, hcl.InitialPos)
+		if fileDiags.HasErrors() {
+			diags = append(diags, fileDiags...)
+			continue
+		}
+		body := file.Body.(*hclsyntax.Body)
+		parsed[path] = body
+
+		dir := filepath.Dir(path)
+		if modules[dir] == nil {
+			modules[dir] = make(declarations)
+		}
+		diags = append(diags, modules[dir].add(body, true)...)
+	}
+
+	merge := outputConflictPolicy(output) == ConflictMerge
+	for dir, declared := range modules {
+		secrets := filepath.Join(dir, SecretsFileName)
+		for _, name := range vars[dir] {
+			declared["var."+name] = hcl.Range{Filename: secrets}
+		}
+		existing, err := GlobOutput(output, filepath.Join(dir, "*.tf"))
+		if err != nil {
+			continue
+		}
+		for _, path := range existing {
+			_, replaced := generated[filepath.Clean(path)]
+			replaced = replaced || filepath.Clean(path) == secrets && len(vars[dir]) > 0
+			if replaced && !merge {
+				continue
+			}
+			content, err := ReadOutput(output, path)
+			if err != nil {
+				continue
+			}
+			if file, fileDiags := hclsyntax.ParseConfig(content, path, hcl.InitialPos); !fileDiags.HasErrors() {
+				diags = append(diags, declared.add(file.Body.(*hclsyntax.Body), !replaced)...)
+			}
+		}
+	}
+
+	for _, path := range paths {
+		if body, ok := parsed[path]; ok {
+			diags = append(diags, checkReferences(body, modules[filepath.Dir(path)], nil)...)
+		}
+	}
+
+	if !diags.HasErrors() {
+		return nil
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Subject, diags[j].Subject
+		if a == nil || b == nil {
+			return b != nil
+		}
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Start.Byte < b.Start.Byte
+	})
+	messages := make([]string, 0, len(diags))
+	for _, diag := range diags {
+		messages = append(messages, diag.Error())
+	}
+	return fmt.Errorf("%w:\n%s", ErrInvalidConfiguration, strings.Join(messages, "\n"))
+}
+
+// NewValidatingSink returns ValidatingSink keeping terraform files written to sink
+func NewValidatingSink(sink OutputSink) *ValidatingSink {
+	return &ValidatingSink{OutputSink: sink, files: make(map[string][]byte)}
+}
+
+// WriteFile keeps the content of terraform files and writes the file into the wrapped sink
+func (s *ValidatingSink) WriteFile(path string, content []byte) error {
+	if filepath.Ext(path) == ".tf" {
+		s.mu.Lock()
+		s.files[path] = append([]byte(nil), content...)
+		s.mu.Unlock()
+	}
+	return s.OutputSink.WriteFile(path, content)
+}
+
+// Unwrap returns the wrapped sink
+func (s *ValidatingSink) Unwrap() OutputSink {
+	return s.OutputSink
+}
+
+// Validate checks terraform files written so far the same way FSTemplateProcessor checks rendered templates,
+// vars are variables declared by directory, e.g. secrets
+func (s *ValidatingSink) Validate(vars map[string][]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return validateConfiguration(s.OutputSink, s.files, vars)
+}
+
+// add records addresses declared in the body, reporting duplicates if unique is set
+func (d declarations) add(body *hclsyntax.Body, unique bool) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	declare := func(address string, rng hcl.Range) {
+		if existing, ok := d[address]; ok {
+			if unique {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate address",
+					Detail:   fmt.Sprintf("'%s' is already declared at %s", address, existing),
+					Subject:  rng.Ptr(),
+				})
+			}
+			return
+		}
+		d[address] = rng
+	}
+
+	for _, block := range body.Blocks {
+		switch {
+		case block.Type == "resource" && len(block.Labels) == 2:
+			declare(block.Labels[0]+"."+block.Labels[1], block.DefRange())
+		case block.Type == "data" && len(block.Labels) == 2:
+			declare("data."+block.Labels[0]+"."+block.Labels[1], block.DefRange())
+		case block.Type == "variable" && len(block.Labels) == 1:
+			declare("var."+block.Labels[0], block.DefRange())
+		case block.Type == "module" && len(block.Labels) == 1:
+			declare("module."+block.Labels[0], block.DefRange())
+		case block.Type == "locals":
+			for name, attr := range block.Body.Attributes {
+				declare("local."+name, attr.NameRange)
+			}
+		}
+	}
+	return diags
+}
+
+// checkReferences reports references in the body which are not declared
+// iterators contains names of dynamic block iterators available in the body
+func checkReferences(body *hclsyntax.Body, declared declarations, iterators map[string]struct{}) hcl.Diagnostics {
+	// provider references a provider configuration, not an address
+	diags := checkAttributes(body.Attributes, declared, iterators, "provider")
+
+	for _, block := range body.Blocks {
+		switch block.Type {
+		case "terraform", "lifecycle", "moved":
+			// lifecycle refers to attributes of the resource, moved to addresses which are not declared anymore
+			continue
+		case "variable":
+			// type of variable is a type constraint, not a reference
+			diags = append(diags, checkAttributes(block.Body.Attributes, declared, iterators, "type")...)
+			for _, inner := range block.Body.Blocks {
+				diags = append(diags, checkReferences(inner.Body, declared, iterators)...)
+			}
+		case "dynamic":
+			if len(block.Labels) != 1 {
+				continue
+			}
+			diags = append(diags, checkAttributes(block.Body.Attributes, declared, iterators, "iterator")...)
+			inner := make(map[string]struct{}, len(iterators)+1)
+			for name := range iterators {
+				inner[name] = struct{}{}
+			}
+			iterator := block.Labels[0]
+			if attr, ok := block.Body.Attributes["iterator"]; ok {
+				iterator = hcl.ExprAsKeyword(attr.Expr)
+			}
+			inner[iterator] = struct{}{}
+			for _, content := range block.Body.Blocks {
+				diags = append(diags, checkReferences(content.Body, declared, inner)...)
+			}
+		default:
+			diags = append(diags, checkReferences(block.Body, declared, iterators)...)
+		}
+	}
+	return diags
+}
+
+// checkAttributes reports references in the attributes, except the skipped one, which are not declared
+func checkAttributes(attrs hclsyntax.Attributes, declared declarations, iterators map[string]struct{}, skip string) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	for name, attr := range attrs {
+		if name == skip {
+			continue
+		}
+		for _, traversal := range attr.Expr.Variables() {
+			if diag := checkReference(traversal, declared, iterators); diag != nil {
+				diags = append(diags, diag)
+			}
+		}
+	}
+	return diags
+}
+
+// checkReference returns diagnostic if the traversal refers to an address which is not declared
+func checkReference(traversal hcl.Traversal, declared declarations, iterators map[string]struct{}) *hcl.Diagnostic {
+	root := traversal.RootName()
+	if _, ok := builtinReferences[root]; ok {
+		return nil
+	}
+	if _, ok := iterators[root]; ok {
+		return nil
+	}
+
+	parts := 2
+	if root == "data" {
+		parts = 3
+	}
+	address := []string{root}
+	for _, step := range traversal[1:] {
+		if len(address) == parts {
+			break
+		}
+		attr, ok := step.(hcl.TraverseAttr)
+		if !ok {
+			break
+		}
+		address = append(address, attr.Name)
+	}
+	if len(address) < parts {
+		return &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid reference",
+			Detail:   fmt.Sprintf("'%s' is not a valid reference", strings.Join(address, ".")),
+			Subject:  traversal.SourceRange().Ptr(),
+		}
+	}
+	if _, ok := declared[strings.Join(address, ".")]; ok {
+		return nil
+	}
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Reference to undeclared address",
+		Detail:   fmt.Sprintf("'%s' is not declared in the generated configuration", strings.Join(address, ".")),
+		Subject:  traversal.SourceRange().Ptr(),
+	}
+}
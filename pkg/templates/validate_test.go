@@ -0,0 +1,215 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfiguration(t *testing.T) {
+	tests := map[string]struct {
+		files     map[string]string
+		vars      map[string][]string
+		existing  map[string]string
+		conflict  ConflictPolicy
+		withError []string
+	}{
+		"valid configuration": {
+			files: map[string]string{
+				"main.tf": `
+locals {
+  names = [for n in var.names : upper(n)]
+}
+
+data "akamai_group" "group" {
+  group_name  = var.group_name
+  contract_id = var.contract_id
+}
+
+resource "akamai_property" "property" {
+  for_each    = toset(local.names)
+  name        = each.value
+  group_id    = data.akamai_group.group.id
+  contract_id = var.contract_id
+  provider    = akamai.custom
+
+  dynamic "hostnames" {
+    for_each = var.hostnames
+    iterator = hostname
+    content {
+      cname_from = hostname.value
+    }
+  }
+
+  lifecycle {
+    ignore_changes = [rules]
+  }
+}
+
+module "security" {
+  source      = "./modules/security"
+  property_id = akamai_property.property["a"].id
+  depends_on  = [akamai_property.property]
+}
+
+import {
+  to = akamai_property.property["a"]
+  id = "prp_1"
+}
+`,
+				"variables.tf": `
+variable "names" {
+  type = list(string)
+}
+variable "group_name" {
+  type = string
+}
+variable "contract_id" {
+  type = string
+}
+variable "hostnames" {
+  type = map(string)
+}
+`,
+				"modules/security/main.tf": `
+variable "property_id" {
+  type = string
+}
+
+resource "akamai_appsec_configuration" "config" {
+  name        = var.property_id
+  description = var.secret
+}
+`,
+			},
+			vars: map[string][]string{"modules/security": {"secret"}},
+		},
+		"syntax error": {
+			files: map[string]string{
+				"main.tf": "resource \"akamai_property\" \"property\" {\n  name = \"\"quoted\"\"\n}\n",
+			},
+			withError: []string{"main.tf:2,"},
+		},
+		"undeclared references": {
+			files: map[string]string{
+				"main.tf": `
+resource "akamai_property" "property" {
+  name     = var.name
+  group_id = data.akamai_group.group.id
+}
+
+module "security" {
+  source = "./modules/security"
+}
+`,
+				"modules/security/main.tf": `
+resource "akamai_appsec_configuration" "config" {
+  name = akamai_property.property.name
+}
+`,
+			},
+			withError: []string{
+				"main.tf:3,14-22: Reference to undeclared address; 'var.name'",
+				"main.tf:4,14-40: Reference to undeclared address; 'data.akamai_group.group'",
+				"modules/security/main.tf:3,10-39: Reference to undeclared address; 'akamai_property.property'",
+			},
+		},
+		"duplicate addresses": {
+			files: map[string]string{
+				"main.tf":      "resource \"akamai_property\" \"property\" {\n}\n",
+				"property.tf":  "resource \"akamai_property\" \"property\" {\n}\n",
+				"variables.tf": "variable \"name\" {\n}\nvariable \"name\" {\n}\n",
+			},
+			withError: []string{
+				"property.tf:1,1-38: Duplicate address; 'akamai_property.property' is already declared at",
+				"variables.tf:3,1-16: Duplicate address; 'var.name' is already declared at",
+			},
+		},
+		"references declared in existing files": {
+			files: map[string]string{
+				"main.tf": "resource \"akamai_property\" \"property\" {\n  name = var.name\n}\n",
+			},
+			existing: map[string]string{
+				"variables.tf": "variable \"name\" {\n}\n",
+			},
+		},
+		"addresses declared in existing files": {
+			files: map[string]string{
+				"main.tf": "resource \"akamai_property\" \"property\" {\n}\n",
+			},
+			existing: map[string]string{
+				"property.tf": "resource \"akamai_property\" \"property\" {\n}\n",
+			},
+			withError: []string{
+				"property.tf:1,1-38: Duplicate address; 'akamai_property.property' is already declared at",
+			},
+		},
+		"existing file replaced by generated one": {
+			files: map[string]string{
+				"main.tf": "resource \"akamai_property\" \"property\" {\n}\n",
+			},
+			existing: map[string]string{
+				"main.tf": "resource \"akamai_property\" \"property\" {\n}\nvariable \"name\" {\n}\n",
+			},
+			conflict: ConflictOverwrite,
+		},
+		"existing file merged with generated one": {
+			files: map[string]string{
+				"main.tf": "resource \"akamai_property\" \"property\" {\n  name = var.name\n}\n",
+			},
+			existing: map[string]string{
+				"main.tf": "resource \"akamai_property\" \"property\" {\n}\nvariable \"name\" {\n}\n",
+			},
+			conflict: ConflictMerge,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := make(map[string][]byte, len(test.files))
+			for path, content := range test.files {
+				files[filepath.Join(dir, path)] = []byte(content)
+			}
+			vars := make(map[string][]string, len(test.vars))
+			for path, names := range test.vars {
+				vars[filepath.Join(dir, path)] = names
+			}
+			for path, content := range test.existing {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0644))
+			}
+
+			err := validateConfiguration(DiskSink{Conflict: test.conflict}, files, vars)
+			if test.withError == nil {
+				require.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidConfiguration)
+			for _, message := range test.withError {
+				assert.Contains(t, err.Error(), filepath.Join(dir, message))
+			}
+		})
+	}
+}
+
+func TestValidatingSink(t *testing.T) {
+	memory := NewMemorySink()
+	sink := NewValidatingSink(memory)
+	require.NoError(t, sink.WriteFile("main.tf", []byte("resource \"akamai_dns_zone\" \"zone\" {\n  contract = var.contractid\n}\n")))
+	require.NoError(t, sink.WriteFile("import.script", []byte("terraform import akamai_dns_zone.zone example.com\n")))
+	assert.Equal(t, []string{"import.script", "main.tf"}, memory.Files())
+
+	err := sink.Validate(nil)
+	assert.ErrorIs(t, err, ErrInvalidConfiguration)
+	assert.Contains(t, err.Error(), "var.contractid")
+
+	require.NoError(t, sink.WriteFile("variables.tf", []byte("variable \"contractid\" {\n}\n")))
+	assert.NoError(t, sink.Validate(nil))
+
+	sink = NewValidatingSink(NewMemorySink())
+	require.NoError(t, sink.WriteFile("main.tf", []byte("resource \"akamai_dns_zone\" \"zone\" {\n  contract = var.contractid\n}\n")))
+	assert.NoError(t, sink.Validate(map[string][]string{".": {"contractid"}}))
+}
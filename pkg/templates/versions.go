@@ -0,0 +1,200 @@
+package templates
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/akamai/cli-terraform/pkg/tools"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type (
+	// Engine is the tool used to apply generated configuration
+	Engine string
+
+	// Versions decides versions of the engine and the akamai provider required by generated configuration
+	Versions struct {
+		// Engine decides the registry from which the provider is installed and the command used in import scripts
+		Engine Engine
+		// ProviderVersion, if set, is the version constraint of the provider replacing the one required by the exporter
+		ProviderVersion string
+		// RequiredVersion is the version constraint of the engine, DefaultRequiredVersion is used if it is empty
+		RequiredVersion string
+	}
+
+	versionsContextKey struct{}
+)
+
+const (
+	// EngineTerraform installs the provider from the terraform registry and uses `terraform` command
+	EngineTerraform Engine = "terraform"
+	// EngineOpenTofu installs the provider from the OpenTofu registry and uses `tofu` command
+	EngineOpenTofu Engine = "opentofu"
+
+	// VersionsFileName is the name of the file with the terraform block
+	VersionsFileName = "versions.tf"
+	// ProviderFileName is the name of the file with configuration of the akamai provider
+	ProviderFileName = "provider.tf"
+	// DefaultRequiredVersion is the version constraint of the engine used if none is requested
+	DefaultRequiredVersion = ">= 1.0"
+)
+
+var (
+	// ErrInvalidEngine is returned when unknown engine is requested
+	ErrInvalidEngine = errors.New("invalid engine")
+
+	providerVersionPattern = regexp.MustCompile(`\bversion\s*=\s*">=\s*([0-9.]+)"`)
+)
+
+// ParseEngine returns Engine for the given name, empty name results in EngineTerraform
+func ParseEngine(name string) (Engine, error) {
+	return tools.ParseEnum(name, EngineTerraform, ErrInvalidEngine, EngineTerraform, EngineOpenTofu)
+}
+
+// Command returns the command line tool of the engine
+func (e Engine) Command() string {
+	if e == EngineOpenTofu {
+		return "tofu"
+	}
+	return "terraform"
+}
+
+// ProviderSource returns the source address of the akamai provider
+func (e Engine) ProviderSource() string {
+	if e == EngineOpenTofu {
+		return "registry.opentofu.org/akamai/akamai"
+	}
+	return "akamai/akamai"
+}
+
+// Script replaces `terraform` commands of the given script, e.g. `terraform import`, with commands of the engine
+func (e Engine) Script(script []byte) []byte {
+	if e.Command() == "terraform" {
+		return script
+	}
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(script))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(script)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(trimmed, "terraform ") {
+			line = line[:len(line)-len(trimmed)] + e.Command() + strings.TrimPrefix(trimmed, "terraform")
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	if !bytes.HasSuffix(script, []byte("\n")) {
+		buf.Truncate(buf.Len() - 1)
+	}
+	return buf.Bytes()
+}
+
+// WithVersions returns context with the given versions
+func WithVersions(ctx context.Context, versions Versions) context.Context {
+	return context.WithValue(ctx, versionsContextKey{}, versions)
+}
+
+// GetVersions returns versions stored in the context, if there are none the defaults are returned
+func GetVersions(ctx context.Context) Versions {
+	if versions, ok := ctx.Value(versionsContextKey{}).(Versions); ok {
+		return versions
+	}
+	return Versions{}
+}
+
+// ProviderConstraint returns version constraint of the provider for configuration requiring at least minVersion of it
+func (v Versions) ProviderConstraint(minVersion string) string {
+	if v.ProviderVersion != "" {
+		return v.ProviderVersion
+	}
+	return ">= " + minVersion
+}
+
+// EngineConstraint returns version constraint of the engine
+func (v Versions) EngineConstraint() string {
+	if v.RequiredVersion != "" {
+		return v.RequiredVersion
+	}
+	return DefaultRequiredVersion
+}
+
+// WriteVersions writes versions.tf with the terraform block into dir
+// If versions.tf already exists, e.g. another product was exported into the same directory,
+// the higher of the minimal provider versions is kept, so that the file is shared by all exported products
+func (v Versions) WriteVersions(output OutputSink, dir, minVersion string) error {
+	path := filepath.Join(dir, VersionsFileName)
+	existing, err := ReadOutput(output, path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: '%s': %s", ErrSavingFiles, path, err)
+	}
+	if match := providerVersionPattern.FindSubmatch(existing); match != nil && compareVersions(string(match[1]), minVersion) > 0 {
+		minVersion = string(match[1])
+	}
+
+	file := hclwrite.NewEmptyFile()
+	terraform := file.Body().AppendNewBlock("terraform", nil).Body()
+	terraform.AppendNewBlock("required_providers", nil).Body().SetAttributeValue("akamai", cty.ObjectVal(map[string]cty.Value{
+		"source":  cty.StringVal(v.Engine.ProviderSource()),
+		"version": cty.StringVal(v.ProviderConstraint(minVersion)),
+	}))
+	terraform.SetAttributeValue("required_version", cty.StringVal(v.EngineConstraint()))
+
+	content := hclwrite.Format(file.Bytes())
+	if bytes.Equal(existing, content) {
+		return nil
+	}
+	if err := output.WriteFile(path, content); err != nil {
+		return fmt.Errorf("%w: '%s': %s", ErrSavingFiles, path, err)
+	}
+	return nil
+}
+
+// WriteProvider writes provider.tf with configuration of the akamai provider into dir, unless it already exists
+func WriteProvider(output OutputSink, dir string) error {
+	path := filepath.Join(dir, ProviderFileName)
+	if _, err := ReadOutput(output, path); err == nil {
+		return nil
+	}
+
+	file := hclwrite.NewEmptyFile()
+	provider := file.Body().AppendNewBlock("provider", []string{"akamai"}).Body()
+	provider.SetAttributeTraversal("edgerc", hcl.Traversal{hcl.TraverseRoot{Name: "var"}, hcl.TraverseAttr{Name: "edgerc_path"}})
+	provider.SetAttributeTraversal("config_section", hcl.Traversal{hcl.TraverseRoot{Name: "var"}, hcl.TraverseAttr{Name: "config_section"}})
+
+	if err := output.WriteFile(path, hclwrite.Format(file.Bytes())); err != nil {
+		return fmt.Errorf("%w: '%s': %s", ErrSavingFiles, path, err)
+	}
+	return nil
+}
+
+// compareVersions compares dot separated numeric versions, e.g. 6.4.0 and 6.10.0
+func compareVersions(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,247 @@
+package templates
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEngine(t *testing.T) {
+	tests := map[string]struct {
+		given     string
+		expected  Engine
+		withError bool
+	}{
+		"default": {
+			expected: EngineTerraform,
+		},
+		"terraform": {
+			given:    "terraform",
+			expected: EngineTerraform,
+		},
+		"opentofu": {
+			given:    "opentofu",
+			expected: EngineOpenTofu,
+		},
+		"invalid": {
+			given:     "tofu",
+			withError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			engine, err := ParseEngine(test.given)
+			if test.withError {
+				assert.ErrorIs(t, err, ErrInvalidEngine)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, engine)
+		})
+	}
+}
+
+func TestEngineScript(t *testing.T) {
+	script := "terraform init\nterraform import akamai_gtm_domain.test test.akamaidns.net\n  terraform import akamai_dns_zone.test test.com\necho terraform"
+
+	assert.Equal(t, script, string(EngineTerraform.Script([]byte(script))))
+	assert.Equal(t, "tofu init\ntofu import akamai_gtm_domain.test test.akamaidns.net\n  tofu import akamai_dns_zone.test test.com\necho terraform",
+		string(EngineOpenTofu.Script([]byte(script))))
+}
+
+func TestWriteVersions(t *testing.T) {
+	tests := map[string]struct {
+		versions   Versions
+		minVersion string
+		existing   string
+		expected   string
+	}{
+		"defaults": {
+			minVersion: "6.4.0",
+			expected: `terraform {
+  required_providers {
+    akamai = {
+      source  = "akamai/akamai"
+      version = ">= 6.4.0"
+    }
+  }
+  required_version = ">= 1.0"
+}
+`,
+		},
+		"opentofu with requested versions": {
+			versions:   Versions{Engine: EngineOpenTofu, ProviderVersion: "~> 6.4", RequiredVersion: ">= 1.6"},
+			minVersion: "6.4.0",
+			expected: `terraform {
+  required_providers {
+    akamai = {
+      source  = "registry.opentofu.org/akamai/akamai"
+      version = "~> 6.4"
+    }
+  }
+  required_version = ">= 1.6"
+}
+`,
+		},
+		"existing with higher version": {
+			minVersion: "5.4.0",
+			existing:   "terraform {\n  required_providers {\n    akamai = {\n      source  = \"akamai/akamai\"\n      version = \">= 6.10.0\"\n    }\n  }\n}\n",
+			expected: `terraform {
+  required_providers {
+    akamai = {
+      source  = "akamai/akamai"
+      version = ">= 6.10.0"
+    }
+  }
+  required_version = ">= 1.0"
+}
+`,
+		},
+		"existing with lower version": {
+			minVersion: "6.4.0",
+			existing:   "terraform {\n  required_providers {\n    akamai = {\n      source  = \"akamai/akamai\"\n      version = \">= 5.4.0\"\n    }\n  }\n}\n",
+			expected: `terraform {
+  required_providers {
+    akamai = {
+      source  = "akamai/akamai"
+      version = ">= 6.4.0"
+    }
+  }
+  required_version = ">= 1.0"
+}
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if test.existing != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, VersionsFileName), []byte(test.existing), 0644))
+			}
+			sink := NewDryRunSink(io.Discard, dir)
+
+			require.NoError(t, test.versions.WriteVersions(sink, dir, test.minVersion))
+			content, ok := sink.File(filepath.Join(dir, VersionsFileName))
+			require.True(t, ok)
+			assert.Equal(t, test.expected, string(content))
+		})
+	}
+}
+
+func TestWriteVersionsUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, Versions{}.WriteVersions(DiskSink{}, dir, "6.4.0"))
+
+	sink := NewDryRunSink(io.Discard, dir)
+	require.NoError(t, Versions{}.WriteVersions(sink, dir, "6.0.0"))
+	_, ok := sink.File(filepath.Join(dir, VersionsFileName))
+	assert.False(t, ok)
+}
+
+func TestWriteVersionsMerged(t *testing.T) {
+	dir := t.TempDir()
+	sink := DiskSink{Conflict: ConflictMerge}
+	require.NoError(t, Versions{}.WriteVersions(sink, dir, "3.1.0"))
+	require.NoError(t, Versions{}.WriteVersions(sink, dir, "6.4.0"))
+
+	content, err := os.ReadFile(filepath.Join(dir, VersionsFileName))
+	require.NoError(t, err)
+	assert.Equal(t, `terraform {
+  required_providers {
+    akamai = {
+      source  = "akamai/akamai"
+      version = ">= 6.4.0"
+    }
+  }
+  required_version = ">= 1.0"
+}
+`, string(content))
+}
+
+func TestProcessTemplatesVersions(t *testing.T) {
+	templatesFS := fstest.MapFS{
+		"main.tmpl":    {Data: []byte(`resource "akamai_gtm_domain" "test" {}`)},
+		"module.tmpl":  {Data: []byte(`resource "akamai_gtm_datacenter" "test" {}`)},
+		"imports.tmpl": {Data: []byte("terraform init\nterraform import akamai_gtm_domain.test test.akamaidns.net")},
+	}
+	tests := map[string]struct {
+		versions     Versions
+		minVersion   string
+		skipProvider bool
+		expected     map[string]string
+		missing      []string
+	}{
+		"versions and provider": {
+			minVersion: "6.0.0",
+			expected: map[string]string{
+				VersionsFileName: `terraform {
+  required_providers {
+    akamai = {
+      source  = "akamai/akamai"
+      version = ">= 6.0.0"
+    }
+  }
+  required_version = ">= 1.0"
+}
+`,
+				ProviderFileName: `provider "akamai" {
+  edgerc         = var.edgerc_path
+  config_section = var.config_section
+}
+`,
+				"import.sh": "terraform init\nterraform import akamai_gtm_domain.test test.akamaidns.net",
+			},
+			missing: []string{filepath.Join("modules", VersionsFileName)},
+		},
+		"opentofu without provider": {
+			versions:     Versions{Engine: EngineOpenTofu},
+			minVersion:   "6.0.0",
+			skipProvider: true,
+			expected: map[string]string{
+				"import.sh": "tofu init\ntofu import akamai_gtm_domain.test test.akamaidns.net",
+			},
+			missing: []string{ProviderFileName},
+		},
+		"without minimal provider version": {
+			missing: []string{VersionsFileName, ProviderFileName},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			sink := NewMemorySink()
+			processor := FSTemplateProcessor{
+				TemplatesFS: templatesFS,
+				TemplateTargets: map[string]string{
+					"main.tmpl":    filepath.Join(dir, "main.tf"),
+					"module.tmpl":  filepath.Join(dir, "modules", "main.tf"),
+					"imports.tmpl": filepath.Join(dir, "import.sh"),
+				},
+				Output:             sink,
+				Versions:           test.versions,
+				MinProviderVersion: test.minVersion,
+				SkipProvider:       test.skipProvider,
+			}
+
+			require.NoError(t, processor.ProcessTemplates(nil))
+
+			for file, expected := range test.expected {
+				content, ok := sink.File(filepath.Join(dir, file))
+				require.True(t, ok, file)
+				assert.Equal(t, expected, string(content))
+			}
+			for _, file := range test.missing {
+				_, ok := sink.File(filepath.Join(dir, file))
+				assert.False(t, ok, file)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"sync"
+)
+
+type concurrencyCtxType struct{}
+
+// WithConcurrency puts the maximum number of API calls made at the same time by an exporter into the context
+func WithConcurrency(ctx context.Context, concurrency int) context.Context {
+	return context.WithValue(ctx, concurrencyCtxType{}, concurrency)
+}
+
+// Concurrency returns the maximum number of API calls made at the same time stored in the context, 1 if it is not set
+func Concurrency(ctx context.Context) int {
+	if concurrency, ok := ctx.Value(concurrencyCtxType{}).(int); ok && concurrency > 0 {
+		return concurrency
+	}
+	return 1
+}
+
+// ForEach calls fn for indexes from 0 to n-1 using at most Concurrency(ctx) goroutines
+// fn should store its results by index, so that the output does not depend on the order in which the calls finish,
+// and should not report progress, as there is only one step in progress at a time
+// The first error cancels the context passed to the running calls, skips the remaining ones and is returned
+func ForEach(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	workers := Concurrency(ctx)
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			if err := fn(ctx, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var firstErr error
+	var wg sync.WaitGroup
+	indexes := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrency(t *testing.T) {
+	assert.Equal(t, 1, Concurrency(context.Background()))
+	assert.Equal(t, 1, Concurrency(WithConcurrency(context.Background(), 0)))
+	assert.Equal(t, 8, Concurrency(WithConcurrency(context.Background(), 8)))
+}
+
+func TestForEach(t *testing.T) {
+	tests := map[string]struct {
+		concurrency int
+		n           int
+		failAt      int
+		withError   bool
+	}{
+		"sequential": {
+			concurrency: 1,
+			n:           10,
+			failAt:      -1,
+		},
+		"concurrent": {
+			concurrency: 4,
+			n:           50,
+			failAt:      -1,
+		},
+		"more workers than calls": {
+			concurrency: 10,
+			n:           3,
+			failAt:      -1,
+		},
+		"no calls": {
+			concurrency: 4,
+			failAt:      -1,
+		},
+		"sequential error": {
+			concurrency: 1,
+			n:           10,
+			failAt:      3,
+			withError:   true,
+		},
+		"concurrent error": {
+			concurrency: 4,
+			n:           50,
+			failAt:      3,
+			withError:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var running, maxRunning, calls int32
+			results := make([]int, test.n)
+			ctx := WithConcurrency(context.Background(), test.concurrency)
+
+			err := ForEach(ctx, test.n, func(ctx context.Context, i int) error {
+				atomic.AddInt32(&calls, 1)
+				current := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+						break
+					}
+				}
+				if i == test.failAt {
+					return errors.New("oops")
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Millisecond):
+				}
+				results[i] = i * i
+				return nil
+			})
+
+			assert.LessOrEqual(t, int(maxRunning), test.concurrency)
+			if test.withError {
+				assert.EqualError(t, err, "oops")
+				assert.Less(t, int(calls), test.n)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int32(test.n), calls)
+			for i, res := range results {
+				assert.Equal(t, i*i, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseEnum returns the valid value with the given name, empty name results in def
+// Other names result in the invalid error listing the valid values, e.g. "invalid format: 'yaml', expected one of: hcl, json"
+func ParseEnum[T ~string](name string, def T, invalid error, valid ...T) (T, error) {
+	if name == "" {
+		return def, nil
+	}
+	names := make([]string, 0, len(valid))
+	for _, value := range valid {
+		if string(value) == name {
+			return value, nil
+		}
+		names = append(names, string(value))
+	}
+	return "", fmt.Errorf("%w: '%s', expected one of: %s", invalid, name, strings.Join(names, ", "))
+}
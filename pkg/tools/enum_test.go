@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnum(t *testing.T) {
+	type color string
+	errInvalidColor := errors.New("invalid color")
+
+	tests := map[string]struct {
+		given     string
+		expected  color
+		withError string
+	}{
+		"default": {
+			expected: "red",
+		},
+		"first value": {
+			given:    "red",
+			expected: "red",
+		},
+		"other value": {
+			given:    "blue",
+			expected: "blue",
+		},
+		"invalid": {
+			given:     "Blue",
+			withError: "invalid color: 'Blue', expected one of: red, green, blue",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, err := ParseEnum[color](test.given, "red", errInvalidColor, "red", "green", "blue")
+			if test.withError != "" {
+				assert.ErrorIs(t, err, errInvalidColor)
+				assert.EqualError(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
@@ -1,4 +0,0 @@
-package tools
-
-// PolicyAsHCL means that content of the policy will be generated using HCL instead of JSON file
-var PolicyAsHCL bool
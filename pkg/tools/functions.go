@@ -111,5 +111,8 @@ func EscapeQuotedStringLit(s string) string {
 func Escape(str string) string {
 	str = strings.ReplaceAll(str, `\`, `\\`)
 	str = strings.ReplaceAll(str, "\n", "\\n")
+	// double up template introducers, so that they are not interpreted by terraform
+	str = strings.ReplaceAll(str, "${", "$${")
+	str = strings.ReplaceAll(str, "%{", "%%{")
 	return strings.ReplaceAll(str, `"`, `\"`)
 }
@@ -34,6 +34,27 @@ func TestEscapeTFName(t *testing.T) {
 		assert.Equal(t, expected[i], e)
 	}
 }
+func TestEscape(t *testing.T) {
+	tests := map[string]struct {
+		data   string
+		expect string
+	}{
+		"quotes and new lines": {
+			data:   "a \"quoted\"\nvalue\\",
+			expect: `a \"quoted\"\nvalue\\`,
+		},
+		"template introducers": {
+			data:   "Strange Characters${a} %{if} $5",
+			expect: "Strange Characters$${a} %%{if} $5",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expect, Escape(test.data))
+		})
+	}
+}
+
 func TestEscapeQuotedStringLit(t *testing.T) {
 	tests := map[string]struct {
 		data   string
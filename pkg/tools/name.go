@@ -3,6 +3,7 @@ package tools
 import (
 	"regexp"
 	"strings"
+	"unicode"
 )
 
 var matchFirstCap = regexp.MustCompile("([^ _])([A-Z][a-z]+)")
@@ -24,5 +25,26 @@ func ToSnakeCase(str string) string {
 // but only a reasonable subset.
 func TerraformName(str string) string {
 	str = nameRegexp.ReplaceAllString(str, "-")
-	return ToSnakeCase(str)
+	return TerraformIdentifier(ToSnakeCase(str))
+}
+
+// TerraformIdentifier returns the name as a valid terraform identifier, used for all names of generated blocks
+// The name is prefixed with an underscore, if it does not start with a letter or underscore, and characters
+// other than letters, digits, underscores and dashes are replaced with underscores, e.g. *.example -> ___example
+func TerraformIdentifier(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if i == 0 && r != '_' && !unicode.IsLetter(r) {
+			sb.WriteRune('_')
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	if sb.Len() == 0 {
+		return "_"
+	}
+	return sb.String()
 }
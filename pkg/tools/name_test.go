@@ -23,6 +23,10 @@ func TestNotations(t *testing.T) {
 			given:    "TestNameHello1 world",
 			expected: "test_name_hello1_world",
 		},
+		"starting with number": {
+			given:    "1st rule",
+			expected: "_1st_rule",
+		},
 	}
 
 	for name, test := range tests {
@@ -32,3 +36,41 @@ func TestNotations(t *testing.T) {
 		})
 	}
 }
+
+func TestTerraformIdentifier(t *testing.T) {
+	tests := map[string]struct {
+		given    string
+		expected string
+	}{
+		"valid": {
+			given:    "my-property_1",
+			expected: "my-property_1",
+		},
+		"invalid characters": {
+			given:    "www.example.com/a b",
+			expected: "www_example_com_a_b",
+		},
+		"starting with digit": {
+			given:    "1st",
+			expected: "_1st",
+		},
+		"starting with invalid character": {
+			given:    "*.example.com",
+			expected: "___example_com",
+		},
+		"unicode letters": {
+			given:    "zażółć",
+			expected: "zażółć",
+		},
+		"empty": {
+			given:    "",
+			expected: "_",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, TerraformIdentifier(test.given))
+		})
+	}
+}